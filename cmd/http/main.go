@@ -1,23 +1,280 @@
 package main
 
 import (
+	"cmp"
 	"context"
+	"errors"
+	"fmt"
 	"log"
+	nethttp "net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/vlone310/bss/config"
+	"github.com/vlone310/bss/internal/currency"
 	db "github.com/vlone310/bss/internal/db/sqlc"
+	"github.com/vlone310/bss/internal/event"
+	"github.com/vlone310/bss/internal/fraud"
+	"github.com/vlone310/bss/internal/grpc"
+	"github.com/vlone310/bss/internal/hashchain"
 	"github.com/vlone310/bss/internal/http"
+	"github.com/vlone310/bss/internal/outbox"
+	"github.com/vlone310/bss/internal/reconcile"
+	"github.com/vlone310/bss/internal/screening"
+	"github.com/vlone310/bss/internal/snapshot"
+	"github.com/vlone310/bss/internal/stream"
+	"github.com/vlone310/bss/internal/webhook"
+	"github.com/vlone310/bss/internal/worker"
 )
 
+const (
+	entryStreamRetryInterval = 5 * time.Second
+	outboxPruneInterval      = time.Hour
+	fraudExpiryInterval      = time.Minute
+	connectTimeout           = 10 * time.Second
+	defaultShutdownTimeout   = 30 * time.Second
+	defaultGracePeriod       = 5 * time.Second
+)
+
+// workers runs the background jobs until their context is cancelled and
+// lets the shutdown wait for the runs in progress.
+type workers struct {
+	wg   sync.WaitGroup
+	list []*worker.Periodic
+}
+
+func (w *workers) start(ctx context.Context, name string, interval time.Duration, job func(ctx context.Context) error) {
+	p := worker.NewPeriodic(name, interval, job)
+	w.list = append(w.list, p)
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		p.Run(ctx)
+	}()
+}
+
 func main() {
 	ctx := context.Background()
 	config := config.MustLoadConfig(".")
 
+	// the servers are shut down on the first signal, a second one kills
+	// the process
+	signalCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// the workers outlive the servers, the requests in flight may need them
+	workerCtx, stopWorkers := context.WithCancel(ctx)
+	defer stopWorkers()
+	var jobs workers
+
 	// setup persistance layer
 	s := db.NewStore()
-	s.Connect(ctx, config.DBSource)
-	defer s.Close()
+	connectCtx, cancel := context.WithTimeout(ctx, connectTimeout)
+	err := s.Connect(connectCtx, config.DBSource)
+	cancel()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	currencies := currency.NewRegistry()
+	if err := currencies.Load(ctx, s); err != nil {
+		log.Fatal(err)
+	}
+	if config.CurrencyRefreshInterval > 0 {
+		jobs.start(workerCtx, "currency refresh", config.CurrencyRefreshInterval, func(ctx context.Context) error {
+			return currencies.Load(ctx, s)
+		})
+	}
+
+	if config.ReconcileInterval > 0 {
+		reconciler := reconcile.New(s, config.ReconcileFreezeAccounts)
+		jobs.start(workerCtx, "reconciliation", config.ReconcileInterval, func(ctx context.Context) error {
+			_, err := reconciler.Run(ctx)
+			return err
+		})
+	}
+
+	if config.SnapshotInterval > 0 {
+		snapshotter := snapshot.NewSnapshotter(s)
+		jobs.start(workerCtx, "balance snapshot", config.SnapshotInterval, func(ctx context.Context) error {
+			_, err := snapshotter.Run(ctx, time.Now())
+			return err
+		})
+	}
+
+	if config.CheckpointSigningKey != "" && config.CheckpointInterval > 0 {
+		signer, err := hashchain.NewSigner(config.CheckpointSigningKey, config.CheckpointKeyID)
+		if err != nil {
+			log.Fatal(err)
+		}
+		jobs.start(workerCtx, "ledger checkpoint", config.CheckpointInterval, func(ctx context.Context) error {
+			_, err := signer.Checkpoint(ctx, s)
+			return err
+		})
+	}
+
+	screener := screening.NewScreener(config.ScreeningMatchThreshold, config.ScreeningTokenThreshold)
+	if err := screener.Load(config.ScreeningListPaths...); err != nil {
+		log.Fatal(err)
+	}
+	if len(config.ScreeningListPaths) > 0 && config.ScreeningReloadInterval > 0 {
+		jobs.start(workerCtx, "sanctions list reload", config.ScreeningReloadInterval, func(ctx context.Context) error {
+			return screener.Load(config.ScreeningListPaths...)
+		})
+	}
+
+	thresholds := fraud.Thresholds{
+		Challenge: config.FraudChallengeScore,
+		Hold:      config.FraudHoldScore,
+		Block:     config.FraudBlockScore,
+	}
+	fraudEngine := fraud.NewEngine(thresholds)
+	if thresholds.Enabled() {
+		if err := fraudEngine.Load(ctx, s); err != nil {
+			log.Fatal(err)
+		}
+		s.SetFraudAssessor(fraudEngine)
+
+		if config.FraudRuleRefreshInterval > 0 {
+			jobs.start(workerCtx, "fraud rule refresh", config.FraudRuleRefreshInterval, func(ctx context.Context) error {
+				return fraudEngine.Load(ctx, s)
+			})
+		}
+	}
+
+	if config.FraudChallengeTTL > 0 || config.FraudHoldTTL > 0 {
+		expirer := fraud.NewExpirer(s, config.FraudChallengeTTL, config.FraudHoldTTL)
+		jobs.start(workerCtx, "fraud decision expiry", fraudExpiryInterval, func(ctx context.Context) error {
+			_, err := expirer.Run(ctx)
+			return err
+		})
+	}
+
+	if config.OutboxRelayInterval > 0 {
+		var publisher event.Publisher = event.NewWriterPublisher(os.Stdout)
+		if config.OutboxFile != "" {
+			filePublisher, f, err := event.NewFilePublisher(config.OutboxFile)
+			if err != nil {
+				log.Fatal(err)
+			}
+			defer f.Close()
+			publisher = filePublisher
+		}
+
+		publisher = event.Publishers{publisher, webhook.NewDispatcher(s)}
+		relay := outbox.NewRelay(s, publisher, config.OutboxBatchSize)
+		jobs.start(workerCtx, "outbox relay", config.OutboxRelayInterval, func(ctx context.Context) error {
+			_, err := relay.Run(ctx)
+			return err
+		})
+
+		if config.OutboxRetention > 0 {
+			jobs.start(workerCtx, "outbox retention", outboxPruneInterval, func(ctx context.Context) error {
+				_, err := relay.Prune(ctx, time.Now().Add(-config.OutboxRetention))
+				return err
+			})
+		}
+	}
+
+	if config.WebhookDeliveryInterval > 0 {
+		sender := webhook.NewSender(s, webhook.NewClient(config.WebhookAllowPrivate), config.WebhookMaxAttempts, config.WebhookBackoff)
+		jobs.start(workerCtx, "webhook delivery", config.WebhookDeliveryInterval, func(ctx context.Context) error {
+			_, err := sender.Run(ctx)
+			return err
+		})
+	}
+
+	// the listener is retried when its connection fails
+	hub := stream.NewHub(0)
+	jobs.start(workerCtx, "entry stream", entryStreamRetryInterval, func(ctx context.Context) error {
+		return hub.Run(ctx, s)
+	})
+
+	// the first server to fail takes the others down with it
+	serveErrs := make(chan error, 3)
+
+	srv, err := http.NewServer(config, s, currencies, screener, fraudEngine, hub)
+	if err != nil {
+		log.Fatal(err)
+	}
+	srv.SetWorkers(jobs.list...)
+	go func() {
+		serveErrs <- srv.ServeHTTP(config.ServerAddr)
+	}()
+
+	var grpcServer *grpc.Server
+	var gateway *grpc.Gateway
+	var gatewayServer *nethttp.Server
+	if config.GRPCServerAddr != "" {
+		grpcServer, err = grpc.NewServer(config, s, currencies, screener)
+		if err != nil {
+			log.Fatal(err)
+		}
+		go func() {
+			serveErrs <- grpcServer.ServeGRPC(config.GRPCServerAddr)
+		}()
+
+		if config.GatewayServerAddr != "" {
+			gateway, err = grpc.NewGateway(ctx, config.GRPCServerAddr)
+			if err != nil {
+				log.Fatal(err)
+			}
+			gatewayServer = http.NewHTTPServer(config, config.GatewayServerAddr, gateway)
+			go func() {
+				fmt.Println("gRPC gateway is running on", config.GatewayServerAddr)
+				serveErrs <- http.ListenAndServe(gatewayServer, config)
+			}()
+		}
+	}
+
+	var serveErr error
+	select {
+	case <-signalCtx.Done():
+		stop()
+
+		// the probes see the server draining while it still takes the
+		// requests the load balancer sends until it noticed
+		srv.Drain()
+		gracePeriod := cmp.Or(config.ShutdownGracePeriod, defaultGracePeriod)
+		log.Printf("draining for %v", gracePeriod)
+		select {
+		case <-time.After(gracePeriod):
+		case serveErr = <-serveErrs:
+		}
+		log.Println("shutting down")
+	case serveErr = <-serveErrs:
+		stop()
+		log.Printf("shutting down: %v", serveErr)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(ctx, cmp.Or(config.ShutdownTimeout, defaultShutdownTimeout))
+	defer cancel()
+
+	// the gateway calls the gRPC server, it goes first
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("http server shutdown: %v", err)
+	}
+	if gatewayServer != nil {
+		if err := gatewayServer.Shutdown(shutdownCtx); err != nil {
+			log.Printf("gateway shutdown: %v", err)
+		}
+		gateway.Close()
+	}
+	if grpcServer != nil {
+		if err := grpcServer.Shutdown(shutdownCtx); err != nil {
+			log.Printf("grpc server shutdown: %v", err)
+		}
+	}
+
+	stopWorkers()
+	jobs.wg.Wait()
+	s.Close()
 
-	srv := http.NewServer(s)
-	log.Fatal(srv.ServeHTTP(config.ServerAddr))
+	if serveErr != nil && !errors.Is(serveErr, nethttp.ErrServerClosed) {
+		log.Fatal(serveErr)
+	}
 }
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"github.com/vlone310/bss/config"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+	"github.com/vlone310/bss/internal/interest"
+)
+
+// interest is meant to run once a day, e.g. from cron, shortly after
+// midnight UTC. It accrues every day that has not been accrued yet and pays
+// out the months that are complete.
+func main() {
+	through := flag.String("through", "", "accrue up to and including this date (YYYY-MM-DD), defaults to yesterday")
+	post := flag.Bool("post", true, "post the interest of completed months")
+	flag.Parse()
+
+	ctx := context.Background()
+	config := config.MustLoadConfig(".")
+
+	s := db.NewStore()
+	if err := s.Connect(ctx, config.DBSource); err != nil {
+		log.Fatal(err)
+	}
+	defer s.Close()
+
+	day := time.Now().UTC().AddDate(0, 0, -1)
+	if *through != "" {
+		var err error
+		if day, err = time.Parse(time.DateOnly, *through); err != nil {
+			log.Fatalf("invalid -through date: %v", err)
+		}
+	}
+
+	engine := interest.NewEngine(s)
+
+	// the accounts that failed are retried by the next run, the others are
+	// posted meanwhile
+	failed := false
+
+	accrued, err := engine.Accrue(ctx, day)
+	if err != nil {
+		log.Print(err)
+		failed = true
+	}
+	log.Printf("accrued %d account days through %s", accrued, day.Format(time.DateOnly))
+
+	if *post {
+		posted, err := engine.Post(ctx, time.Now())
+		if err != nil {
+			log.Print(err)
+			failed = true
+		}
+		log.Printf("posted interest for %d account months", posted)
+	}
+
+	if failed {
+		s.Close()
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/vlone310/bss/config"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+	"github.com/vlone310/bss/internal/hashchain"
+)
+
+const usage = `usage: ledgerchain <command>
+
+commands:
+  verify [-account id]   verify the entry hash chains, exits with status 2 on a break
+  checkpoint             sign and record the current chain heads
+  export                 print signed checkpoints as JSON lines for a notary
+  verify-checkpoint id   check a checkpoint signature against the ledger`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+	config := config.MustLoadConfig(".")
+
+	s := db.NewStore()
+	if err := s.Connect(ctx, config.DBSource); err != nil {
+		log.Fatal(err)
+	}
+	defer s.Close()
+
+	cmd, args := os.Args[1], os.Args[2:]
+	switch cmd {
+	case "verify":
+		flags := flag.NewFlagSet(cmd, flag.ExitOnError)
+		account := flags.Int64("account", 0, "verify only the chain of this account")
+		flags.Parse(args)
+
+		var accountID pgtype.Int8
+		if *account > 0 {
+			accountID = pgtype.Int8{Int64: *account, Valid: true}
+		}
+
+		checked, err := hashchain.Verify(ctx, s, accountID)
+		if hashchain.IsBreak(err) {
+			log.Printf("checked %d entries: %v", checked, err)
+			s.Close()
+			os.Exit(2)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("checked %d entries, no breaks", checked)
+
+	case "checkpoint":
+		signer := mustSigner(config)
+		checkpoint, err := signer.Checkpoint(ctx, s)
+		if err != nil {
+			log.Fatal(err)
+		}
+		printJSON(hashchain.Export(checkpoint, signer.PublicKey()))
+
+	case "export":
+		signer := mustSigner(config)
+		checkpoints, err := s.ListLedgerCheckpoints(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, checkpoint := range checkpoints {
+			printJSON(hashchain.Export(checkpoint, signer.PublicKey()))
+		}
+
+	case "verify-checkpoint":
+		if len(args) != 1 {
+			log.Fatal(usage)
+		}
+		id, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		err = hashchain.VerifyCheckpoint(ctx, s, mustSigner(config).PublicKey(), id)
+		if hashchain.IsBreak(err) || err == hashchain.ErrInvalidSignature {
+			log.Printf("checkpoint %d: %v", id, err)
+			s.Close()
+			os.Exit(2)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("checkpoint %d is valid", id)
+
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		s.Close()
+		os.Exit(1)
+	}
+}
+
+func mustSigner(config config.Config) *hashchain.Signer {
+	signer, err := hashchain.NewSigner(config.CheckpointSigningKey, config.CheckpointKeyID)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return signer
+}
+
+func printJSON(v any) {
+	if err := json.NewEncoder(os.Stdout).Encode(v); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/vlone310/bss/config"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+	"github.com/vlone310/bss/internal/reconcile"
+)
+
+// reconcile runs a single reconciliation and exits with status 2 when it
+// finds discrepancies, so that it can alert from cron or CI.
+func main() {
+	freeze := flag.Bool("freeze", false, "freeze active customer accounts with a discrepancy")
+	flag.Parse()
+
+	ctx := context.Background()
+	config := config.MustLoadConfig(".")
+
+	s := db.NewStore()
+	if err := s.Connect(ctx, config.DBSource); err != nil {
+		log.Fatal(err)
+	}
+	defer s.Close()
+
+	result, err := reconcile.New(s, *freeze).Run(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("run %d checked %d accounts and %d transfers", result.Run.ID, result.Run.AccountsChecked, result.Run.TransfersChecked)
+	for _, d := range result.Discrepancies {
+		log.Printf("%s account=%d transfer=%d: %s", d.Kind, d.AccountID.Int64, d.TransferID.Int64, d.Details)
+	}
+
+	if result.Run.DiscrepancyCount > 0 {
+		s.Close()
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"time"
+
+	"github.com/vlone310/bss/config"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+	"github.com/vlone310/bss/internal/snapshot"
+)
+
+// snapshot is the end-of-day job, meant to run shortly after midnight UTC.
+// It snapshots every closed day that has no snapshot yet. After a backdated
+// correction, -rebuild-from takes the affected days again.
+func main() {
+	rebuildFrom := flag.String("rebuild-from", "", "replace the snapshots from this date (YYYY-MM-DD) on")
+	flag.Parse()
+
+	ctx := context.Background()
+	config := config.MustLoadConfig(".")
+
+	s := db.NewStore()
+	if err := s.Connect(ctx, config.DBSource); err != nil {
+		log.Fatal(err)
+	}
+	defer s.Close()
+
+	snapshotter := snapshot.NewSnapshotter(s)
+	now := time.Now()
+
+	var result db.SnapshotBalancesTxResult
+	var err error
+	if *rebuildFrom != "" {
+		from, perr := time.Parse(time.DateOnly, *rebuildFrom)
+		if perr != nil {
+			log.Fatalf("invalid -rebuild-from date: %v", perr)
+		}
+		result, err = snapshotter.Rebuild(ctx, from, now)
+	} else {
+		result, err = snapshotter.Run(ctx, now)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("deleted %d and created %d daily balances", result.Deleted, result.Created)
+}
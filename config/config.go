@@ -2,14 +2,112 @@ package config
 
 import (
 	"log"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	DBDriver   string `mapstructure:"DB_DRIVER"`
-	DBSource   string `mapstructure:"DB_SOURCE"`
-	ServerAddr string `mapstructure:"SERVER_ADDRESS"`
+	DBDriver            string        `mapstructure:"DB_DRIVER"`
+	DBSource            string        `mapstructure:"DB_SOURCE"`
+	ServerAddr          string        `mapstructure:"SERVER_ADDRESS"`
+	TokenSymmetricKey   string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
+	AccessTokenDuration time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+	CursorSecretKey     string        `mapstructure:"CURSOR_SECRET_KEY"`
+	MaxPageSize         int32         `mapstructure:"MAX_PAGE_SIZE"`
+	// SwaggerUIURL is where the docs page loads the swagger-ui-dist files
+	// from, for a copy served next to the API. Empty loads the pinned
+	// release from unpkg.
+	SwaggerUIURL string `mapstructure:"SWAGGER_UI_URL"`
+	// GRPCServerAddr is where the gRPC API listens, GatewayServerAddr where
+	// the REST gateway to it does. Empty leaves either off; the gateway
+	// needs the gRPC server.
+	GRPCServerAddr    string `mapstructure:"GRPC_SERVER_ADDRESS"`
+	GatewayServerAddr string `mapstructure:"GATEWAY_SERVER_ADDRESS"`
+	// ServerReadTimeout, ServerWriteTimeout and ServerIdleTimeout bound the
+	// connections of the HTTP servers and ServerMaxHeaderBytes the size of
+	// the request headers. Zero reads for 15 seconds, writes for 30 seconds
+	// and keeps idle connections for 2 minutes.
+	ServerReadTimeout    time.Duration `mapstructure:"SERVER_READ_TIMEOUT"`
+	ServerWriteTimeout   time.Duration `mapstructure:"SERVER_WRITE_TIMEOUT"`
+	ServerIdleTimeout    time.Duration `mapstructure:"SERVER_IDLE_TIMEOUT"`
+	ServerMaxHeaderBytes int           `mapstructure:"SERVER_MAX_HEADER_BYTES"`
+	// TLSCertFile and TLSKeyFile serve the HTTP servers over TLS when both
+	// are set.
+	TLSCertFile string `mapstructure:"TLS_CERT_FILE"`
+	TLSKeyFile  string `mapstructure:"TLS_KEY_FILE"`
+	// ShutdownTimeout is how long the requests in flight get to finish on
+	// SIGINT or SIGTERM, zero waits 30 seconds.
+	ShutdownTimeout time.Duration `mapstructure:"SHUTDOWN_TIMEOUT"`
+	// ShutdownGracePeriod is how long the servers keep taking requests
+	// with a failing readiness check before they shut down, so that the
+	// load balancer has seen it. Zero waits 5 seconds.
+	ShutdownGracePeriod time.Duration `mapstructure:"SHUTDOWN_GRACE_PERIOD"`
+	// CurrencyRefreshInterval is how often the currency registry is reloaded,
+	// so that a currency enabled on one instance reaches the others.
+	CurrencyRefreshInterval time.Duration `mapstructure:"CURRENCY_REFRESH_INTERVAL"`
+	// ReconcileInterval is how often the server reconciles the ledger, zero
+	// disables the scheduled job.
+	ReconcileInterval       time.Duration `mapstructure:"RECONCILE_INTERVAL"`
+	ReconcileFreezeAccounts bool          `mapstructure:"RECONCILE_FREEZE_ACCOUNTS"`
+	// SnapshotInterval is how often the server looks for closed days to
+	// snapshot balances for, zero leaves it to the snapshot command.
+	SnapshotInterval time.Duration `mapstructure:"SNAPSHOT_INTERVAL"`
+	// CheckpointSigningKey is a base64 ed25519 seed used to sign ledger
+	// checkpoints, checkpoints are not taken without it.
+	CheckpointSigningKey string        `mapstructure:"CHECKPOINT_SIGNING_KEY"`
+	CheckpointKeyID      string        `mapstructure:"CHECKPOINT_KEY_ID"`
+	CheckpointInterval   time.Duration `mapstructure:"CHECKPOINT_INTERVAL"`
+	// ScreeningListPaths are the sanctions list files, comma separated, that
+	// customers and transfers are screened against. Without them nobody is
+	// screened.
+	ScreeningListPaths []string `mapstructure:"SCREENING_LIST_PATHS"`
+	// ScreeningMatchThreshold and ScreeningTokenThreshold tune the name
+	// matcher, zero keeps the defaults of the screening package.
+	ScreeningMatchThreshold float64 `mapstructure:"SCREENING_MATCH_THRESHOLD"`
+	ScreeningTokenThreshold float64 `mapstructure:"SCREENING_TOKEN_THRESHOLD"`
+	// ScreeningReloadInterval is how often the list files are read again,
+	// zero loads them once at startup.
+	ScreeningReloadInterval time.Duration `mapstructure:"SCREENING_RELOAD_INTERVAL"`
+	// FraudChallengeScore, FraudHoldScore and FraudBlockScore are the fraud
+	// scores from which a transfer is challenged, held for review or
+	// blocked. Zero disables the action, transfers are not scored at all
+	// when all three are zero. The rules are in the fraud_rules table.
+	FraudChallengeScore int32 `mapstructure:"FRAUD_CHALLENGE_SCORE"`
+	FraudHoldScore      int32 `mapstructure:"FRAUD_HOLD_SCORE"`
+	FraudBlockScore     int32 `mapstructure:"FRAUD_BLOCK_SCORE"`
+	// FraudRuleRefreshInterval is how often the fraud rules are reloaded, so
+	// that a rule changed on one instance reaches the others.
+	FraudRuleRefreshInterval time.Duration `mapstructure:"FRAUD_RULE_REFRESH_INTERVAL"`
+	// FraudChallengeTTL and FraudHoldTTL are how long a challenged or held
+	// transfer waits for the sender or a reviewer before it fails. Zero
+	// keeps it pending until somebody decides on it.
+	FraudChallengeTTL time.Duration `mapstructure:"FRAUD_CHALLENGE_TTL"`
+	FraudHoldTTL      time.Duration `mapstructure:"FRAUD_HOLD_TTL"`
+	// OutboxRelayInterval is how often the outbox events are published, zero
+	// leaves them to a relay in another process.
+	OutboxRelayInterval time.Duration `mapstructure:"OUTBOX_RELAY_INTERVAL"`
+	// OutboxFile is the file the events are appended to as JSON lines,
+	// empty writes them to stdout.
+	OutboxFile      string `mapstructure:"OUTBOX_FILE"`
+	OutboxBatchSize int32  `mapstructure:"OUTBOX_BATCH_SIZE"`
+	// OutboxRetention is how long published events are kept, zero keeps
+	// them forever.
+	OutboxRetention time.Duration `mapstructure:"OUTBOX_RETENTION"`
+	// WebhookDeliveryInterval is how often due webhooks are sent, zero
+	// leaves them to another process. Webhooks are queued by the outbox
+	// relay.
+	WebhookDeliveryInterval time.Duration `mapstructure:"WEBHOOK_DELIVERY_INTERVAL"`
+	// WebhookMaxAttempts is how often a webhook is tried before it is dead,
+	// WebhookBackoff the wait after the first failure, doubled after each
+	// further one. Zero picks the defaults of package webhook.
+	WebhookMaxAttempts int32         `mapstructure:"WEBHOOK_MAX_ATTEMPTS"`
+	WebhookBackoff     time.Duration `mapstructure:"WEBHOOK_BACKOFF"`
+	// WebhookAllowHTTP accepts endpoints without TLS, for development.
+	WebhookAllowHTTP bool `mapstructure:"WEBHOOK_ALLOW_HTTP"`
+	// WebhookAllowPrivate accepts endpoints on loopback, private and
+	// link-local addresses, for development.
+	WebhookAllowPrivate bool `mapstructure:"WEBHOOK_ALLOW_PRIVATE"`
 }
 
 func MustLoadConfig(path string) (config Config) {
@@ -0,0 +1,22 @@
+// Package currency holds the ISO 4217 currencies the bank knows about.
+package currency
+
+import db "github.com/vlone310/bss/internal/db/sqlc"
+
+type Currency struct {
+	Code    string `json:"code"`
+	Numeric int32  `json:"numeric_code"`
+	// MinorUnits is the number of decimals amounts are stored with, e.g. 2
+	// for EUR, 0 for JPY and 3 for KWD.
+	MinorUnits int32 `json:"minor_units"`
+	Enabled    bool  `json:"enabled"`
+}
+
+func FromDB(c db.Currency) Currency {
+	return Currency{
+		Code:       c.Code,
+		Numeric:    c.NumericCode,
+		MinorUnits: c.MinorUnits,
+		Enabled:    c.Enabled,
+	}
+}
@@ -0,0 +1,42 @@
+package currency
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/vlone310/bss/internal/db/mock"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+)
+
+func TestRegistry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().ListCurrencies(gomock.Any()).Times(1).Return([]db.Currency{
+		{Code: "EUR", NumericCode: 978, MinorUnits: 2, Enabled: true},
+		{Code: "JPY", NumericCode: 392, MinorUnits: 0, Enabled: false},
+	}, nil)
+
+	registry := NewRegistry()
+	require.NoError(t, registry.Load(context.Background(), store))
+
+	require.True(t, registry.Enabled("EUR"))
+	require.False(t, registry.Enabled("JPY"))
+	require.False(t, registry.Enabled("XXX"))
+
+	jpy, ok := registry.Lookup("JPY")
+	require.True(t, ok)
+	require.Equal(t, int32(0), jpy.MinorUnits)
+
+	jpy.Enabled = true
+	registry.Set(jpy)
+	require.True(t, registry.Enabled("JPY"))
+
+	list := registry.List()
+	require.Len(t, list, 2)
+	require.Equal(t, "EUR", list[0].Code)
+	require.Equal(t, "JPY", list[1].Code)
+}
@@ -0,0 +1,93 @@
+package currency
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+
+	db "github.com/vlone310/bss/internal/db/sqlc"
+)
+
+// Registry is the in-memory view of the currencies table. It is safe for
+// concurrent use and can be reloaded while the server runs, so enabling a
+// currency needs no redeploy.
+type Registry struct {
+	mu         sync.RWMutex
+	currencies map[string]Currency
+}
+
+func NewRegistry(currencies ...Currency) *Registry {
+	r := &Registry{}
+	r.Replace(currencies)
+	return r
+}
+
+// Lister is the part of the store the registry loads from.
+type Lister interface {
+	ListCurrencies(ctx context.Context) ([]db.Currency, error)
+}
+
+// Load replaces the registry with the currencies stored in the database.
+func (r *Registry) Load(ctx context.Context, store Lister) error {
+	rows, err := store.ListCurrencies(ctx)
+	if err != nil {
+		return err
+	}
+
+	currencies := make([]Currency, 0, len(rows))
+	for _, row := range rows {
+		currencies = append(currencies, FromDB(row))
+	}
+
+	r.Replace(currencies)
+	return nil
+}
+
+func (r *Registry) Replace(currencies []Currency) {
+	m := make(map[string]Currency, len(currencies))
+	for _, c := range currencies {
+		m[c.Code] = c
+	}
+
+	r.mu.Lock()
+	r.currencies = m
+	r.mu.Unlock()
+}
+
+// Set adds or updates a single currency, e.g. right after an admin changed
+// it, without waiting for the next Load.
+func (r *Registry) Set(c Currency) {
+	r.mu.Lock()
+	r.currencies[c.Code] = c
+	r.mu.Unlock()
+}
+
+func (r *Registry) Lookup(code string) (Currency, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c, ok := r.currencies[code]
+	return c, ok
+}
+
+// Enabled reports whether new accounts and transfers may use the currency.
+func (r *Registry) Enabled(code string) bool {
+	c, ok := r.Lookup(code)
+	return ok && c.Enabled
+}
+
+// List returns the currencies ordered by code.
+func (r *Registry) List() []Currency {
+	r.mu.RLock()
+	currencies := make([]Currency, 0, len(r.currencies))
+	for _, c := range r.currencies {
+		currencies = append(currencies, c)
+	}
+	r.mu.RUnlock()
+
+	slices.SortFunc(currencies, func(a, b Currency) int {
+		return strings.Compare(a.Code, b.Code)
+	})
+	return currencies
+}
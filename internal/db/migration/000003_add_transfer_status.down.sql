@@ -0,0 +1,2 @@
+ALTER TABLE IF EXISTS transfers DROP CONSTRAINT IF EXISTS transfers_status_check;
+ALTER TABLE IF EXISTS transfers DROP COLUMN IF EXISTS status;
@@ -0,0 +1,5 @@
+ALTER TABLE transfers ADD COLUMN status varchar NOT NULL DEFAULT 'completed';
+
+ALTER TABLE transfers ADD CONSTRAINT transfers_status_check CHECK (status IN ('pending', 'completed', 'failed'));
+
+COMMENT ON COLUMN transfers.status IS 'pending, completed or failed';
@@ -0,0 +1,7 @@
+ALTER TABLE entries ADD COLUMN transfer_id bigint;
+
+ALTER TABLE entries ADD FOREIGN KEY (transfer_id) REFERENCES transfers (id);
+
+CREATE INDEX ON entries (account_id, created_at, id);
+
+COMMENT ON COLUMN entries.transfer_id IS 'transfer that produced the entry, if any';
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS transfers_to_account_id_created_at_id_idx;
+DROP INDEX IF EXISTS transfers_from_account_id_created_at_id_idx;
@@ -0,0 +1,8 @@
+ALTER TABLE IF EXISTS users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE IF EXISTS users DROP COLUMN IF EXISTS role;
+
+ALTER TABLE IF EXISTS accounts DROP CONSTRAINT IF EXISTS accounts_status_check;
+ALTER TABLE IF EXISTS accounts DROP COLUMN IF EXISTS closed_at;
+ALTER TABLE IF EXISTS accounts DROP COLUMN IF EXISTS status_changed_at;
+ALTER TABLE IF EXISTS accounts DROP COLUMN IF EXISTS status_reason;
+ALTER TABLE IF EXISTS accounts DROP COLUMN IF EXISTS status;
@@ -0,0 +1,17 @@
+ALTER TABLE accounts ADD COLUMN status varchar NOT NULL DEFAULT 'active';
+
+ALTER TABLE accounts ADD COLUMN status_reason varchar;
+
+ALTER TABLE accounts ADD COLUMN status_changed_at timestamptz;
+
+ALTER TABLE accounts ADD COLUMN closed_at timestamptz;
+
+ALTER TABLE accounts ADD CONSTRAINT accounts_status_check CHECK (status IN ('active', 'frozen', 'closed'));
+
+ALTER TABLE users ADD COLUMN role varchar NOT NULL DEFAULT 'customer';
+
+ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('customer', 'admin'));
+
+COMMENT ON COLUMN accounts.status IS 'active, frozen or closed';
+
+COMMENT ON COLUMN accounts.status_reason IS 'why the account was last frozen, unfrozen or closed';
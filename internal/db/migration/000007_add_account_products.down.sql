@@ -0,0 +1,24 @@
+-- An owner may hold several products in one currency since, which the old
+-- unique key does not allow. The rollback refuses rather than pick the
+-- accounts to delete.
+DO $$
+BEGIN
+  IF EXISTS (
+    SELECT 1 FROM accounts
+    GROUP BY owner, currency
+    HAVING count(*) > 1
+  ) THEN
+    RAISE EXCEPTION 'owners hold several accounts in one currency, the account products cannot be rolled back'
+      USING ERRCODE = 'unique_violation';
+  END IF;
+END $$;
+
+ALTER TABLE IF EXISTS accounts DROP CONSTRAINT IF EXISTS owner_currency_product_key;
+ALTER TABLE IF EXISTS accounts ADD CONSTRAINT owner_currency_key UNIQUE (owner, currency);
+
+ALTER TABLE IF EXISTS accounts DROP CONSTRAINT IF EXISTS accounts_min_balance_check;
+ALTER TABLE IF EXISTS accounts DROP CONSTRAINT IF EXISTS accounts_product_fkey;
+ALTER TABLE IF EXISTS accounts DROP COLUMN IF EXISTS min_balance;
+ALTER TABLE IF EXISTS accounts DROP COLUMN IF EXISTS product;
+
+DROP TABLE IF EXISTS products;
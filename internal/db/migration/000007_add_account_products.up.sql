@@ -0,0 +1,34 @@
+CREATE TABLE products (
+  code varchar PRIMARY KEY,
+  name varchar NOT NULL,
+  allows_negative_balance boolean NOT NULL DEFAULT false,
+  max_monthly_withdrawals int,
+  max_balance bigint
+);
+
+COMMENT ON COLUMN products.allows_negative_balance IS 'whether an overdraft or credit limit can be approved';
+
+COMMENT ON COLUMN products.max_monthly_withdrawals IS 'outgoing transfers allowed per calendar month, unlimited when null';
+
+COMMENT ON COLUMN products.max_balance IS 'highest balance a deposit may leave, unlimited when null';
+
+-- A credit line only holds what was drawn on its approved limit: it cannot
+-- be paid above zero.
+INSERT INTO products (code, name, allows_negative_balance, max_monthly_withdrawals, max_balance) VALUES
+  ('checking', 'Checking account', true, NULL, NULL),
+  ('savings', 'Savings account', false, 6, NULL),
+  ('credit_line', 'Credit line', true, NULL, 0);
+
+ALTER TABLE accounts ADD COLUMN product varchar NOT NULL DEFAULT 'checking';
+
+ALTER TABLE accounts ADD COLUMN min_balance bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE accounts ADD FOREIGN KEY (product) REFERENCES products (code);
+
+ALTER TABLE accounts ADD CONSTRAINT accounts_min_balance_check CHECK (min_balance <= 0);
+
+COMMENT ON COLUMN accounts.min_balance IS 'lowest balance allowed, negative for an approved overdraft or credit limit';
+
+ALTER TABLE accounts DROP CONSTRAINT owner_currency_key;
+
+ALTER TABLE accounts ADD CONSTRAINT owner_currency_product_key UNIQUE (owner, currency, product);
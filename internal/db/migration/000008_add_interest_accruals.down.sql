@@ -0,0 +1,28 @@
+-- Interest that was paid out is booked against the house accounts, their
+-- transfers and entries would lose an account. Such a database cannot go
+-- back to before house accounts, the rollback refuses instead of deleting
+-- the ledger.
+DO $$
+BEGIN
+  IF EXISTS (
+    SELECT 1 FROM house_accounts h
+    WHERE EXISTS (SELECT 1 FROM entries e WHERE e.account_id = h.account_id)
+      OR EXISTS (SELECT 1 FROM transfers t WHERE h.account_id IN (t.from_account_id, t.to_account_id))
+  ) THEN
+    RAISE EXCEPTION 'house accounts have transfers, the interest accruals cannot be rolled back'
+      USING ERRCODE = 'dependent_objects_still_exist';
+  END IF;
+END $$;
+
+DROP TABLE IF EXISTS interest_accruals;
+
+DELETE FROM accounts WHERE id IN (SELECT account_id FROM house_accounts);
+DROP TABLE IF EXISTS house_accounts;
+
+DELETE FROM users WHERE username = 'house';
+DELETE FROM products WHERE code = 'house';
+
+ALTER TABLE IF EXISTS products DROP CONSTRAINT IF EXISTS products_day_count_check;
+ALTER TABLE IF EXISTS products DROP CONSTRAINT IF EXISTS products_interest_rate_bps_check;
+ALTER TABLE IF EXISTS products DROP COLUMN IF EXISTS day_count;
+ALTER TABLE IF EXISTS products DROP COLUMN IF EXISTS interest_rate_bps;
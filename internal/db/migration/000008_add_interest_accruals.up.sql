@@ -0,0 +1,65 @@
+ALTER TABLE products ADD COLUMN interest_rate_bps int NOT NULL DEFAULT 0;
+
+ALTER TABLE products ADD COLUMN day_count varchar NOT NULL DEFAULT 'ACT/365';
+
+ALTER TABLE products ADD CONSTRAINT products_interest_rate_bps_check CHECK (interest_rate_bps >= 0);
+
+ALTER TABLE products ADD CONSTRAINT products_day_count_check CHECK (day_count IN ('ACT/365', '30/360'));
+
+COMMENT ON COLUMN products.interest_rate_bps IS 'annual interest rate in basis points, 150 is 1.50%';
+
+COMMENT ON COLUMN products.day_count IS 'day-count convention used to accrue interest';
+
+UPDATE products SET interest_rate_bps = 150 WHERE code = 'savings';
+
+INSERT INTO products (code, name, allows_negative_balance) VALUES
+  ('house', 'House account', true);
+
+-- The bank itself owns the house accounts. It has no usable password and
+-- no role beyond a customer's.
+INSERT INTO users (username, hashed_password, full_name, email) VALUES
+  ('house', '!', 'House', 'house@bss.invalid');
+
+CREATE TABLE house_accounts (
+  purpose varchar NOT NULL,
+  currency varchar NOT NULL,
+  account_id bigint UNIQUE NOT NULL,
+  PRIMARY KEY (purpose, currency)
+);
+
+ALTER TABLE house_accounts ADD FOREIGN KEY (account_id) REFERENCES accounts (id);
+
+COMMENT ON COLUMN house_accounts.purpose IS 'what the bank books on the account, e.g. interest_expense';
+
+WITH created AS (
+  INSERT INTO accounts (owner, balance, currency, product)
+  SELECT 'house', 0, c, 'house' FROM unnest(ARRAY['EUR', 'USD', 'CAD']) AS c
+  RETURNING id, currency
+)
+INSERT INTO house_accounts (purpose, currency, account_id)
+SELECT 'interest_expense', currency, id FROM created;
+
+CREATE TABLE interest_accruals (
+  account_id bigint NOT NULL,
+  accrual_date date NOT NULL,
+  balance bigint NOT NULL,
+  rate_bps int NOT NULL,
+  day_count varchar NOT NULL,
+  amount_micros bigint NOT NULL,
+  posted_at timestamptz,
+  transfer_id bigint,
+  created_at timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY (account_id, accrual_date)
+);
+
+ALTER TABLE interest_accruals ADD FOREIGN KEY (account_id) REFERENCES accounts (id);
+
+ALTER TABLE interest_accruals ADD FOREIGN KEY (transfer_id) REFERENCES transfers (id);
+
+CREATE INDEX ON interest_accruals (accrual_date) WHERE posted_at IS NULL;
+
+COMMENT ON COLUMN interest_accruals.balance IS 'end-of-day balance the interest was calculated on';
+
+COMMENT ON COLUMN interest_accruals.amount_micros IS 'interest in millionths of the minor unit, rounded half to even';
+
+COMMENT ON COLUMN interest_accruals.transfer_id IS 'posting that paid the interest out, null while unposted or when it rounded to zero';
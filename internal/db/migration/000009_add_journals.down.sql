@@ -0,0 +1,17 @@
+DROP TRIGGER IF EXISTS entries_journal_balanced ON entries;
+DROP FUNCTION IF EXISTS check_journal_balanced();
+
+ALTER TABLE IF EXISTS entries ALTER COLUMN account_id DROP NOT NULL;
+ALTER TABLE IF EXISTS entries DROP COLUMN IF EXISTS journal_id;
+
+DROP TABLE IF EXISTS journals;
+
+-- Only the interest expense accounts existed before journals
+DELETE FROM entries WHERE account_id IN (SELECT account_id FROM house_accounts WHERE purpose <> 'interest_expense');
+DELETE FROM house_accounts WHERE purpose <> 'interest_expense';
+DELETE FROM accounts a WHERE a.product = 'house' AND NOT EXISTS (SELECT 1 FROM house_accounts h WHERE h.account_id = a.id);
+
+DROP INDEX IF EXISTS owner_currency_product_key;
+ALTER TABLE IF EXISTS accounts ADD CONSTRAINT owner_currency_product_key UNIQUE (owner, currency, product);
+
+ALTER TABLE IF EXISTS house_accounts DROP CONSTRAINT IF EXISTS house_accounts_purpose_check;
@@ -0,0 +1,121 @@
+CREATE TABLE journals (
+  id bigserial PRIMARY KEY,
+  kind varchar NOT NULL,
+  description varchar,
+  created_at timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE journals ADD CONSTRAINT journals_kind_check CHECK (kind IN ('transfer', 'interest', 'opening_balance', 'adjustment'));
+
+COMMENT ON TABLE journals IS 'one business event, its entries must sum to zero per currency';
+
+ALTER TABLE entries ADD COLUMN journal_id bigint;
+
+ALTER TABLE entries ADD FOREIGN KEY (journal_id) REFERENCES journals (id);
+
+-- Every currency in use gets the full set of house accounts
+ALTER TABLE house_accounts ADD CONSTRAINT house_accounts_purpose_check CHECK (purpose IN ('interest_expense', 'fees', 'fx', 'suspense'));
+
+ALTER TABLE accounts DROP CONSTRAINT owner_currency_product_key;
+
+CREATE UNIQUE INDEX owner_currency_product_key ON accounts (owner, currency, product) WHERE product <> 'house';
+
+DO $$
+DECLARE
+  v_currency varchar;
+  v_purpose varchar;
+  v_account_id bigint;
+BEGIN
+  FOR v_currency IN SELECT DISTINCT currency FROM accounts LOOP
+    FOREACH v_purpose IN ARRAY ARRAY['interest_expense', 'fees', 'fx', 'suspense'] LOOP
+      IF NOT EXISTS (SELECT 1 FROM house_accounts h WHERE h.purpose = v_purpose AND h.currency = v_currency) THEN
+        INSERT INTO accounts (owner, balance, currency, product) VALUES ('house', 0, v_currency, 'house')
+        RETURNING id INTO v_account_id;
+        INSERT INTO house_accounts (purpose, currency, account_id) VALUES (v_purpose, v_currency, v_account_id);
+      END IF;
+    END LOOP;
+  END LOOP;
+END $$;
+
+-- Entries that belong to no account never affected a balance
+DELETE FROM entries WHERE account_id IS NULL;
+
+-- Existing transfers become journals, entries booked outside of a transfer
+-- and balances that were set without any entry are moved against suspense.
+DO $$
+DECLARE
+  rec record;
+  v_journal_id bigint;
+BEGIN
+  FOR rec IN
+    SELECT transfer_id, MIN(created_at) AS created_at FROM entries
+    WHERE transfer_id IS NOT NULL
+    GROUP BY transfer_id
+  LOOP
+    INSERT INTO journals (kind, created_at) VALUES ('transfer', rec.created_at) RETURNING id INTO v_journal_id;
+    UPDATE entries SET journal_id = v_journal_id WHERE transfer_id = rec.transfer_id;
+  END LOOP;
+
+  FOR rec IN
+    SELECT e.id, e.account_id, e.amount_cents, e.created_at, a.currency FROM entries e
+    JOIN accounts a ON a.id = e.account_id
+    WHERE e.journal_id IS NULL
+  LOOP
+    INSERT INTO journals (kind, description, created_at) VALUES ('adjustment', 'booked before journals', rec.created_at) RETURNING id INTO v_journal_id;
+    UPDATE entries SET journal_id = v_journal_id WHERE id = rec.id;
+    INSERT INTO entries (account_id, amount_cents, journal_id, created_at)
+    SELECT h.account_id, -rec.amount_cents, v_journal_id, rec.created_at FROM house_accounts h
+    WHERE h.purpose = 'suspense' AND h.currency = rec.currency;
+  END LOOP;
+
+  FOR rec IN
+    SELECT a.id, a.currency, a.created_at, a.balance - COALESCE(SUM(e.amount_cents), 0) AS missing
+    FROM accounts a
+    LEFT JOIN entries e ON e.account_id = a.id
+    WHERE a.product <> 'house'
+    GROUP BY a.id
+    HAVING a.balance <> COALESCE(SUM(e.amount_cents), 0)
+  LOOP
+    INSERT INTO journals (kind, created_at) VALUES ('opening_balance', rec.created_at) RETURNING id INTO v_journal_id;
+    INSERT INTO entries (account_id, amount_cents, journal_id, created_at) VALUES (rec.id, rec.missing, v_journal_id, rec.created_at);
+    INSERT INTO entries (account_id, amount_cents, journal_id, created_at)
+    SELECT h.account_id, -rec.missing, v_journal_id, rec.created_at FROM house_accounts h
+    WHERE h.purpose = 'suspense' AND h.currency = rec.currency;
+  END LOOP;
+END $$;
+
+UPDATE accounts a SET balance = (SELECT COALESCE(SUM(e.amount_cents), 0) FROM entries e WHERE e.account_id = a.id)
+WHERE a.product = 'house';
+
+ALTER TABLE entries ALTER COLUMN account_id SET NOT NULL;
+
+ALTER TABLE entries ALTER COLUMN journal_id SET NOT NULL;
+
+CREATE INDEX ON entries (journal_id);
+
+CREATE FUNCTION check_journal_balanced() RETURNS trigger AS $$
+DECLARE
+  unbalanced record;
+BEGIN
+  SELECT a.currency, SUM(e.amount_cents) AS total INTO unbalanced
+  FROM entries e
+  JOIN accounts a ON a.id = e.account_id
+  WHERE e.journal_id = NEW.journal_id
+  GROUP BY a.currency
+  HAVING SUM(e.amount_cents) <> 0
+  LIMIT 1;
+
+  IF FOUND THEN
+    RAISE EXCEPTION 'journal % does not balance in %: %', NEW.journal_id, unbalanced.currency, unbalanced.total
+      USING ERRCODE = 'check_violation';
+  END IF;
+
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Checked at commit so that all entries of a journal can be inserted first
+CREATE CONSTRAINT TRIGGER entries_journal_balanced
+AFTER INSERT OR UPDATE ON entries
+DEFERRABLE INITIALLY DEFERRED
+FOR EACH ROW EXECUTE FUNCTION check_journal_balanced();
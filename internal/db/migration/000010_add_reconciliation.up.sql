@@ -0,0 +1,33 @@
+CREATE TABLE reconciliation_runs (
+  id bigserial PRIMARY KEY,
+  accounts_checked bigint NOT NULL,
+  transfers_checked bigint NOT NULL,
+  discrepancy_count bigint NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE reconciliation_discrepancies (
+  id bigserial PRIMARY KEY,
+  run_id bigint NOT NULL,
+  kind varchar NOT NULL,
+  account_id bigint,
+  transfer_id bigint,
+  expected bigint NOT NULL,
+  actual bigint NOT NULL,
+  details varchar NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE reconciliation_discrepancies ADD CONSTRAINT reconciliation_discrepancies_kind_check CHECK (kind IN ('balance_mismatch', 'transfer_entries'));
+
+ALTER TABLE reconciliation_discrepancies ADD FOREIGN KEY (run_id) REFERENCES reconciliation_runs (id);
+
+ALTER TABLE reconciliation_discrepancies ADD FOREIGN KEY (account_id) REFERENCES accounts (id);
+
+ALTER TABLE reconciliation_discrepancies ADD FOREIGN KEY (transfer_id) REFERENCES transfers (id);
+
+CREATE INDEX ON reconciliation_discrepancies (run_id);
+
+COMMENT ON COLUMN reconciliation_discrepancies.expected IS 'balance_mismatch: sum of the entries, transfer_entries: matching entries required';
+
+COMMENT ON COLUMN reconciliation_discrepancies.actual IS 'balance_mismatch: cached balance, transfer_entries: matching entries found';
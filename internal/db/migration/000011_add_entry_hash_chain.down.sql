@@ -0,0 +1,14 @@
+DROP TABLE IF EXISTS ledger_checkpoint_heads;
+DROP TABLE IF EXISTS ledger_checkpoints;
+
+DROP TRIGGER IF EXISTS entries_append_only ON entries;
+DROP FUNCTION IF EXISTS reject_entry_change();
+
+DROP TRIGGER IF EXISTS entries_link ON entries;
+DROP FUNCTION IF EXISTS link_entry();
+DROP FUNCTION IF EXISTS entry_hash(bytea, entries);
+
+DROP INDEX IF EXISTS entries_account_id_id_idx;
+
+ALTER TABLE IF EXISTS entries DROP COLUMN IF EXISTS hash;
+ALTER TABLE IF EXISTS entries DROP COLUMN IF EXISTS prev_hash;
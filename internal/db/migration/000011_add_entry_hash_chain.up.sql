@@ -0,0 +1,95 @@
+ALTER TABLE entries ADD COLUMN prev_hash bytea;
+
+ALTER TABLE entries ADD COLUMN hash bytea;
+
+COMMENT ON COLUMN entries.hash IS 'sha256 of the entry and prev_hash, chained per account';
+
+CREATE INDEX ON entries (account_id, id);
+
+-- The layout must match hashchain.EntryHash: a version tag, the previous
+-- hash and the big-endian id, account_id, journal_id, amount_cents,
+-- transfer_id (0 when null) and created_at in unix microseconds.
+CREATE FUNCTION entry_hash(prev bytea, e entries) RETURNS bytea AS $$
+  SELECT sha256(
+    'bss-entry-v1'::bytea
+    || prev
+    || int8send(e.id)
+    || int8send(e.account_id)
+    || int8send(e.journal_id)
+    || int8send(e.amount_cents)
+    || int8send(COALESCE(e.transfer_id, 0))
+    || int8send((extract(epoch FROM e.created_at) * 1000000)::bigint)
+  );
+$$ LANGUAGE sql IMMUTABLE;
+
+-- Entries of an account are written while the account row is locked, so the
+-- latest entry read here is the head of the chain.
+CREATE FUNCTION link_entry() RETURNS trigger AS $$
+BEGIN
+  NEW.prev_hash := COALESCE(
+    (SELECT e.hash FROM entries e WHERE e.account_id = NEW.account_id ORDER BY e.id DESC LIMIT 1),
+    '\x0000000000000000000000000000000000000000000000000000000000000000'::bytea
+  );
+  NEW.hash := entry_hash(NEW.prev_hash, NEW);
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DO $$
+DECLARE
+  rec entries;
+  prev bytea;
+  last_account bigint;
+BEGIN
+  FOR rec IN SELECT * FROM entries ORDER BY account_id, id LOOP
+    IF last_account IS DISTINCT FROM rec.account_id THEN
+      prev := '\x0000000000000000000000000000000000000000000000000000000000000000'::bytea;
+      last_account := rec.account_id;
+    END IF;
+
+    UPDATE entries SET prev_hash = prev, hash = entry_hash(prev, rec) WHERE id = rec.id;
+    prev := entry_hash(prev, rec);
+  END LOOP;
+END $$;
+
+ALTER TABLE entries ALTER COLUMN prev_hash SET NOT NULL;
+
+ALTER TABLE entries ALTER COLUMN hash SET NOT NULL;
+
+CREATE TRIGGER entries_link
+BEFORE INSERT ON entries
+FOR EACH ROW EXECUTE FUNCTION link_entry();
+
+CREATE FUNCTION reject_entry_change() RETURNS trigger AS $$
+BEGIN
+  RAISE EXCEPTION 'entries are append-only' USING ERRCODE = 'restrict_violation';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER entries_append_only
+BEFORE UPDATE OR DELETE ON entries
+FOR EACH ROW EXECUTE FUNCTION reject_entry_change();
+
+CREATE TABLE ledger_checkpoints (
+  id bigserial PRIMARY KEY,
+  root_hash bytea NOT NULL,
+  signature bytea NOT NULL,
+  key_id varchar NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT (now())
+);
+
+COMMENT ON COLUMN ledger_checkpoints.root_hash IS 'sha256 over the chain heads, see hashchain.CheckpointRoot';
+
+COMMENT ON COLUMN ledger_checkpoints.signature IS 'ed25519 signature of root_hash';
+
+CREATE TABLE ledger_checkpoint_heads (
+  checkpoint_id bigint NOT NULL,
+  account_id bigint NOT NULL,
+  entry_id bigint NOT NULL,
+  hash bytea NOT NULL,
+  PRIMARY KEY (checkpoint_id, account_id)
+);
+
+ALTER TABLE ledger_checkpoint_heads ADD FOREIGN KEY (checkpoint_id) REFERENCES ledger_checkpoints (id);
+
+ALTER TABLE ledger_checkpoint_heads ADD FOREIGN KEY (entry_id) REFERENCES entries (id);
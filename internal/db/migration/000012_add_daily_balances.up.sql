@@ -0,0 +1,15 @@
+CREATE TABLE daily_balances (
+  account_id bigint NOT NULL,
+  balance_date date NOT NULL,
+  balance bigint NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY (account_id, balance_date)
+);
+
+ALTER TABLE daily_balances ADD FOREIGN KEY (account_id) REFERENCES accounts (id);
+
+CREATE INDEX ON daily_balances (balance_date);
+
+COMMENT ON COLUMN daily_balances.balance IS 'closing balance at the end of balance_date in UTC';
+
+CREATE INDEX ON entries (account_id, created_at);
@@ -0,0 +1,3 @@
+ALTER TABLE house_accounts DROP CONSTRAINT IF EXISTS house_accounts_currency_fkey;
+ALTER TABLE accounts DROP CONSTRAINT IF EXISTS accounts_currency_fkey;
+DROP TABLE IF EXISTS currencies;
@@ -0,0 +1,56 @@
+CREATE TABLE currencies (
+  code varchar(3) PRIMARY KEY,
+  numeric_code int UNIQUE NOT NULL,
+  minor_units int NOT NULL,
+  name varchar NOT NULL,
+  enabled boolean NOT NULL DEFAULT false,
+  updated_at timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE currencies ADD CONSTRAINT currencies_minor_units_check CHECK (minor_units BETWEEN 0 AND 4);
+
+COMMENT ON TABLE currencies IS 'ISO 4217 currencies, accounts can only be opened in enabled ones';
+
+COMMENT ON COLUMN currencies.minor_units IS 'decimal places of the minor unit amounts are stored in, e.g. 2 for cents';
+
+INSERT INTO currencies (code, numeric_code, minor_units, name, enabled) VALUES
+  ('AED', 784, 2, 'UAE Dirham', false),
+  ('AUD', 36, 2, 'Australian Dollar', false),
+  ('BHD', 48, 3, 'Bahraini Dinar', false),
+  ('BRL', 986, 2, 'Brazilian Real', false),
+  ('CAD', 124, 2, 'Canadian Dollar', true),
+  ('CHF', 756, 2, 'Swiss Franc', false),
+  ('CLP', 152, 0, 'Chilean Peso', false),
+  ('CNY', 156, 2, 'Yuan Renminbi', false),
+  ('CZK', 203, 2, 'Czech Koruna', false),
+  ('DKK', 208, 2, 'Danish Krone', false),
+  ('EUR', 978, 2, 'Euro', true),
+  ('GBP', 826, 2, 'Pound Sterling', false),
+  ('HKD', 344, 2, 'Hong Kong Dollar', false),
+  ('HUF', 348, 2, 'Forint', false),
+  ('IDR', 360, 2, 'Rupiah', false),
+  ('ILS', 376, 2, 'New Israeli Sheqel', false),
+  ('INR', 356, 2, 'Indian Rupee', false),
+  ('ISK', 352, 0, 'Iceland Krona', false),
+  ('JOD', 400, 3, 'Jordanian Dinar', false),
+  ('JPY', 392, 0, 'Yen', false),
+  ('KRW', 410, 0, 'Won', false),
+  ('KWD', 414, 3, 'Kuwaiti Dinar', false),
+  ('MXN', 484, 2, 'Mexican Peso', false),
+  ('NOK', 578, 2, 'Norwegian Krone', false),
+  ('NZD', 554, 2, 'New Zealand Dollar', false),
+  ('OMR', 512, 3, 'Rial Omani', false),
+  ('PLN', 985, 2, 'Zloty', false),
+  ('SAR', 682, 2, 'Saudi Riyal', false),
+  ('SEK', 752, 2, 'Swedish Krona', false),
+  ('SGD', 702, 2, 'Singapore Dollar', false),
+  ('THB', 764, 2, 'Baht', false),
+  ('TND', 788, 3, 'Tunisian Dinar', false),
+  ('TRY', 949, 2, 'Turkish Lira', false),
+  ('UAH', 980, 2, 'Hryvnia', false),
+  ('USD', 840, 2, 'US Dollar', true),
+  ('ZAR', 710, 2, 'Rand', false);
+
+ALTER TABLE accounts ADD FOREIGN KEY (currency) REFERENCES currencies (code);
+
+ALTER TABLE house_accounts ADD FOREIGN KEY (currency) REFERENCES currencies (code);
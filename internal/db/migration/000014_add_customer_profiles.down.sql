@@ -0,0 +1,3 @@
+ALTER TABLE IF EXISTS users DROP COLUMN IF EXISTS kyc_exempt;
+ALTER TABLE IF EXISTS currencies DROP COLUMN IF EXISTS unverified_max_balance;
+DROP TABLE IF EXISTS customer_profiles;
@@ -0,0 +1,100 @@
+CREATE TABLE customer_profiles (
+  username varchar PRIMARY KEY,
+  date_of_birth date NOT NULL,
+  address_line1 varchar NOT NULL,
+  address_line2 varchar,
+  city varchar NOT NULL,
+  postal_code varchar NOT NULL,
+  country_code int NOT NULL,
+  phone varchar NOT NULL,
+  kyc_status varchar NOT NULL DEFAULT 'pending',
+  kyc_reason varchar,
+  submitted_at timestamptz NOT NULL DEFAULT (now()),
+  reviewed_by varchar,
+  reviewed_at timestamptz,
+  created_at timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE customer_profiles ADD FOREIGN KEY (username) REFERENCES users (username);
+
+ALTER TABLE customer_profiles ADD FOREIGN KEY (country_code) REFERENCES countries (code);
+
+ALTER TABLE customer_profiles ADD FOREIGN KEY (reviewed_by) REFERENCES users (username);
+
+ALTER TABLE customer_profiles ADD CONSTRAINT customer_profiles_kyc_status_check CHECK (kyc_status IN ('pending', 'verified', 'rejected'));
+
+CREATE INDEX ON customer_profiles (kyc_status, submitted_at, username);
+
+COMMENT ON COLUMN customer_profiles.country_code IS 'ISO 3166-1 numeric code of the country of residence';
+
+COMMENT ON COLUMN customer_profiles.phone IS 'E.164, e.g. +4915112345678';
+
+COMMENT ON COLUMN customer_profiles.kyc_status IS 'pending, verified or rejected, customers without a profile are unverified';
+
+COMMENT ON COLUMN customer_profiles.kyc_reason IS 'why the profile was last rejected';
+
+-- the customers from before KYC keep the access they had, the default only
+-- applies to the users created from now on
+ALTER TABLE users ADD COLUMN kyc_exempt boolean NOT NULL DEFAULT true;
+
+ALTER TABLE users ALTER COLUMN kyc_exempt SET DEFAULT false;
+
+COMMENT ON COLUMN users.kyc_exempt IS 'counts as verified without a profile, for the users created before KYC';
+
+ALTER TABLE currencies ADD COLUMN unverified_max_balance bigint;
+
+COMMENT ON COLUMN currencies.unverified_max_balance IS 'highest balance an unverified customer may hold, unlimited when null';
+
+UPDATE currencies SET unverified_max_balance = 100000 WHERE code IN ('CAD', 'EUR', 'USD');
+
+INSERT INTO countries (code, name, continent_name) VALUES
+  (36, 'Australia', 'Oceania'),
+  (40, 'Austria', 'Europe'),
+  (56, 'Belgium', 'Europe'),
+  (76, 'Brazil', 'South America'),
+  (124, 'Canada', 'North America'),
+  (152, 'Chile', 'South America'),
+  (156, 'China', 'Asia'),
+  (191, 'Croatia', 'Europe'),
+  (196, 'Cyprus', 'Europe'),
+  (203, 'Czechia', 'Europe'),
+  (208, 'Denmark', 'Europe'),
+  (233, 'Estonia', 'Europe'),
+  (246, 'Finland', 'Europe'),
+  (250, 'France', 'Europe'),
+  (276, 'Germany', 'Europe'),
+  (300, 'Greece', 'Europe'),
+  (344, 'Hong Kong', 'Asia'),
+  (348, 'Hungary', 'Europe'),
+  (352, 'Iceland', 'Europe'),
+  (356, 'India', 'Asia'),
+  (372, 'Ireland', 'Europe'),
+  (376, 'Israel', 'Asia'),
+  (380, 'Italy', 'Europe'),
+  (392, 'Japan', 'Asia'),
+  (410, 'Korea, Republic of', 'Asia'),
+  (414, 'Kuwait', 'Asia'),
+  (428, 'Latvia', 'Europe'),
+  (440, 'Lithuania', 'Europe'),
+  (442, 'Luxembourg', 'Europe'),
+  (470, 'Malta', 'Europe'),
+  (484, 'Mexico', 'North America'),
+  (528, 'Netherlands', 'Europe'),
+  (554, 'New Zealand', 'Oceania'),
+  (578, 'Norway', 'Europe'),
+  (616, 'Poland', 'Europe'),
+  (620, 'Portugal', 'Europe'),
+  (642, 'Romania', 'Europe'),
+  (682, 'Saudi Arabia', 'Asia'),
+  (702, 'Singapore', 'Asia'),
+  (703, 'Slovakia', 'Europe'),
+  (705, 'Slovenia', 'Europe'),
+  (710, 'South Africa', 'Africa'),
+  (724, 'Spain', 'Europe'),
+  (752, 'Sweden', 'Europe'),
+  (756, 'Switzerland', 'Europe'),
+  (784, 'United Arab Emirates', 'Asia'),
+  (804, 'Ukraine', 'Europe'),
+  (826, 'United Kingdom', 'Europe'),
+  (840, 'United States', 'North America')
+ON CONFLICT (code) DO NOTHING;
@@ -0,0 +1,3 @@
+ALTER TABLE users DROP COLUMN IF EXISTS blocked_at;
+DROP TABLE IF EXISTS screening_hits;
+DROP TABLE IF EXISTS screening_cases;
@@ -0,0 +1,56 @@
+CREATE TABLE screening_cases (
+  id bigserial PRIMARY KEY,
+  subject varchar NOT NULL,
+  username varchar NOT NULL,
+  transfer_id bigint,
+  screened_name varchar NOT NULL,
+  status varchar NOT NULL DEFAULT 'open',
+  resolution_note varchar,
+  resolved_by varchar,
+  resolved_at timestamptz,
+  created_at timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE screening_hits (
+  case_id bigint NOT NULL,
+  list_source varchar NOT NULL,
+  entry_uid varchar NOT NULL,
+  listed_name varchar NOT NULL,
+  matched_name varchar NOT NULL,
+  score double precision NOT NULL,
+  PRIMARY KEY (case_id, list_source, entry_uid)
+);
+
+ALTER TABLE screening_cases ADD FOREIGN KEY (username) REFERENCES users (username);
+
+ALTER TABLE screening_cases ADD FOREIGN KEY (transfer_id) REFERENCES transfers (id);
+
+ALTER TABLE screening_cases ADD FOREIGN KEY (resolved_by) REFERENCES users (username);
+
+ALTER TABLE screening_hits ADD FOREIGN KEY (case_id) REFERENCES screening_cases (id);
+
+ALTER TABLE screening_cases ADD CONSTRAINT screening_cases_subject_check CHECK (subject IN ('user', 'transfer'));
+
+ALTER TABLE screening_cases ADD CONSTRAINT screening_cases_status_check CHECK (status IN ('open', 'cleared', 'confirmed'));
+
+CREATE INDEX ON screening_cases (status, created_at, id);
+
+CREATE INDEX ON screening_cases (username);
+
+CREATE INDEX ON screening_cases (transfer_id);
+
+COMMENT ON TABLE screening_cases IS 'sanctions list matches waiting for or given a compliance decision';
+
+COMMENT ON COLUMN screening_cases.subject IS 'user for a new user, transfer for a held transfer';
+
+COMMENT ON COLUMN screening_cases.username IS 'user whose name matched';
+
+COMMENT ON COLUMN screening_cases.status IS 'open, cleared as a false positive, or confirmed as a true match';
+
+COMMENT ON COLUMN screening_hits.matched_name IS 'the listed name or alias that matched';
+
+COMMENT ON COLUMN screening_hits.score IS 'name similarity between 0 and 1';
+
+ALTER TABLE users ADD COLUMN blocked_at timestamptz;
+
+COMMENT ON COLUMN users.blocked_at IS 'when a sanctions match of the user was confirmed, their accounts are frozen and they cannot open new ones';
@@ -0,0 +1,7 @@
+ALTER TABLE users DROP COLUMN IF EXISTS totp_last_step;
+ALTER TABLE users DROP COLUMN IF EXISTS totp_enabled_at;
+ALTER TABLE users DROP COLUMN IF EXISTS totp_secret;
+DROP INDEX IF EXISTS transfers_from_account_id_to_account_id_status_idx;
+DROP TABLE IF EXISTS fraud_rule_hits;
+DROP TABLE IF EXISTS fraud_decisions;
+DROP TABLE IF EXISTS fraud_rules;
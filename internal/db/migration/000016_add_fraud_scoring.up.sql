@@ -0,0 +1,92 @@
+CREATE TABLE fraud_rules (
+  name varchar PRIMARY KEY,
+  kind varchar NOT NULL,
+  params jsonb NOT NULL DEFAULT '{}',
+  score integer NOT NULL,
+  enabled boolean NOT NULL DEFAULT true,
+  updated_at timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE fraud_decisions (
+  id bigserial PRIMARY KEY,
+  from_account_id bigint NOT NULL,
+  to_account_id bigint NOT NULL,
+  amount_cents bigint NOT NULL,
+  transfer_id bigint,
+  score integer NOT NULL,
+  action varchar NOT NULL,
+  confirm_attempts integer NOT NULL DEFAULT 0,
+  review_outcome varchar,
+  reviewed_by varchar,
+  reviewed_at timestamptz,
+  created_at timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE fraud_rule_hits (
+  decision_id bigint NOT NULL,
+  rule_name varchar NOT NULL,
+  score integer NOT NULL,
+  detail varchar NOT NULL,
+  PRIMARY KEY (decision_id, rule_name)
+);
+
+ALTER TABLE fraud_decisions ADD FOREIGN KEY (from_account_id) REFERENCES accounts (id);
+
+ALTER TABLE fraud_decisions ADD FOREIGN KEY (to_account_id) REFERENCES accounts (id);
+
+ALTER TABLE fraud_decisions ADD FOREIGN KEY (transfer_id) REFERENCES transfers (id);
+
+ALTER TABLE fraud_decisions ADD FOREIGN KEY (reviewed_by) REFERENCES users (username);
+
+ALTER TABLE fraud_rule_hits ADD FOREIGN KEY (decision_id) REFERENCES fraud_decisions (id);
+
+ALTER TABLE fraud_rules ADD CONSTRAINT fraud_rules_kind_check CHECK (kind IN ('velocity', 'new_payee', 'unusual_amount', 'rapid_in_out'));
+
+ALTER TABLE fraud_rules ADD CONSTRAINT fraud_rules_score_check CHECK (score >= 0);
+
+ALTER TABLE fraud_decisions ADD CONSTRAINT fraud_decisions_action_check CHECK (action IN ('allow', 'challenge', 'hold', 'block'));
+
+ALTER TABLE fraud_decisions ADD CONSTRAINT fraud_decisions_review_outcome_check CHECK (review_outcome IN ('approved', 'rejected', 'expired'));
+
+CREATE INDEX ON fraud_decisions (action, created_at);
+
+CREATE UNIQUE INDEX ON fraud_decisions (transfer_id);
+
+CREATE INDEX ON transfers (from_account_id, to_account_id, status);
+
+COMMENT ON TABLE fraud_rules IS 'rules the fraud engine scores transfers with';
+
+COMMENT ON COLUMN fraud_rules.params IS 'parameters of the rule kind, durations as Go duration strings';
+
+COMMENT ON COLUMN fraud_rules.score IS 'points added to the score of a transfer the rule hits';
+
+COMMENT ON TABLE fraud_decisions IS 'outcome of scoring a transfer, one per transfer attempt';
+
+COMMENT ON COLUMN fraud_decisions.transfer_id IS 'the booked or pending transfer, null when blocked';
+
+COMMENT ON COLUMN fraud_decisions.action IS 'allow, challenge the sender, hold for review, or block';
+
+COMMENT ON COLUMN fraud_decisions.confirm_attempts IS 'wrong one-time codes entered to confirm a challenge';
+
+COMMENT ON COLUMN fraud_decisions.review_outcome IS 'approved, rejected, or expired unreviewed, for challenged and held transfers';
+
+COMMENT ON COLUMN fraud_decisions.reviewed_by IS 'the sender or reviewer, null when decided by the server';
+
+ALTER TABLE users ADD COLUMN totp_secret varchar;
+
+ALTER TABLE users ADD COLUMN totp_enabled_at timestamptz;
+
+ALTER TABLE users ADD COLUMN totp_last_step bigint;
+
+COMMENT ON COLUMN users.totp_secret IS 'base32 RFC 6238 secret, challenged transfers are confirmed with its codes';
+
+COMMENT ON COLUMN users.totp_enabled_at IS 'when the first code was verified, the secret is not used before';
+
+COMMENT ON COLUMN users.totp_last_step IS 'time step of the last code used, so that a code works once';
+
+INSERT INTO fraud_rules (name, kind, params, score) VALUES
+  ('velocity', 'velocity', '{"count": 5, "window": "10m"}', 40),
+  ('new_payee', 'new_payee', '{}', 20),
+  ('unusual_amount', 'unusual_amount', '{"factor": 5, "min_transfers": 5, "window": "2160h"}', 30),
+  ('rapid_in_out', 'rapid_in_out', '{"ratio": 0.8, "window": "1h"}', 40)
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,43 @@
+CREATE TABLE audit_events (
+  id bigserial PRIMARY KEY,
+  actor varchar,
+  action varchar NOT NULL,
+  resource_type varchar NOT NULL,
+  resource_id varchar NOT NULL,
+  before jsonb,
+  after jsonb,
+  request_id varchar NOT NULL,
+  ip varchar NOT NULL,
+  user_agent varchar NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT (now())
+);
+
+COMMENT ON COLUMN audit_events.actor IS 'username of the caller, null for calls without a token and for jobs';
+
+COMMENT ON COLUMN audit_events.action IS 'e.g. account.create or transfer.create';
+
+COMMENT ON COLUMN audit_events.before IS 'the resource before the change with sensitive fields redacted, null when it was created';
+
+COMMENT ON COLUMN audit_events.after IS 'the resource after the change with sensitive fields redacted, null when it was deleted';
+
+CREATE INDEX ON audit_events (actor, id);
+
+CREATE INDEX ON audit_events (resource_type, resource_id, id);
+
+CREATE INDEX ON audit_events (request_id);
+
+CREATE INDEX ON audit_events (created_at);
+
+CREATE FUNCTION reject_audit_event_change() RETURNS trigger AS $$
+BEGIN
+  RAISE EXCEPTION 'audit events are append-only' USING ERRCODE = 'restrict_violation';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_events_append_only
+BEFORE UPDATE OR DELETE ON audit_events
+FOR EACH ROW EXECUTE FUNCTION reject_audit_event_change();
+
+CREATE TRIGGER audit_events_no_truncate
+BEFORE TRUNCATE ON audit_events
+FOR EACH STATEMENT EXECUTE FUNCTION reject_audit_event_change();
@@ -0,0 +1,18 @@
+CREATE TABLE outbox (
+  id bigserial PRIMARY KEY,
+  aggregate_type varchar NOT NULL,
+  aggregate_id varchar NOT NULL,
+  event_type varchar NOT NULL,
+  schema_version int NOT NULL,
+  payload jsonb NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT (now()),
+  published_at timestamptz
+);
+
+COMMENT ON TABLE outbox IS 'domain events written with the change they describe, see package event';
+
+COMMENT ON COLUMN outbox.published_at IS 'null until the relay delivered the event';
+
+CREATE INDEX ON outbox (id) WHERE published_at IS NULL;
+
+CREATE INDEX ON outbox (published_at) WHERE published_at IS NOT NULL;
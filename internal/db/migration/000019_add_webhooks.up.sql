@@ -0,0 +1,57 @@
+CREATE TABLE webhook_endpoints (
+  id bigserial PRIMARY KEY,
+  owner varchar NOT NULL,
+  url varchar NOT NULL,
+  secret varchar NOT NULL,
+  event_types varchar[] NOT NULL DEFAULT '{}',
+  created_at timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE webhook_deliveries (
+  id bigserial PRIMARY KEY,
+  endpoint_id bigint NOT NULL,
+  event_id bigint NOT NULL,
+  event_type varchar NOT NULL,
+  payload jsonb NOT NULL,
+  status varchar NOT NULL DEFAULT 'pending',
+  attempts integer NOT NULL DEFAULT 0,
+  next_attempt_at timestamptz NOT NULL DEFAULT (now()),
+  last_error varchar,
+  delivered_at timestamptz,
+  created_at timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE webhook_delivery_attempts (
+  id bigserial PRIMARY KEY,
+  delivery_id bigint NOT NULL,
+  status_code integer,
+  error varchar,
+  duration_ms integer NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE webhook_endpoints ADD FOREIGN KEY (owner) REFERENCES users (username);
+
+ALTER TABLE webhook_deliveries ADD FOREIGN KEY (endpoint_id) REFERENCES webhook_endpoints (id) ON DELETE CASCADE;
+
+ALTER TABLE webhook_delivery_attempts ADD FOREIGN KEY (delivery_id) REFERENCES webhook_deliveries (id) ON DELETE CASCADE;
+
+ALTER TABLE webhook_deliveries ADD CONSTRAINT webhook_deliveries_status_check CHECK (status IN ('pending', 'succeeded', 'dead'));
+
+CREATE INDEX ON webhook_endpoints (owner);
+
+CREATE UNIQUE INDEX ON webhook_deliveries (endpoint_id, event_id);
+
+CREATE INDEX ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+
+CREATE INDEX ON webhook_delivery_attempts (delivery_id, id);
+
+COMMENT ON COLUMN webhook_endpoints.secret IS 'HMAC-SHA256 key the payloads are signed with';
+
+COMMENT ON COLUMN webhook_endpoints.event_types IS 'event types sent to the endpoint, empty for all';
+
+COMMENT ON COLUMN webhook_deliveries.event_id IS 'id of the outbox event, a redelivered event keeps it';
+
+COMMENT ON COLUMN webhook_deliveries.payload IS 'the event envelope as sent';
+
+COMMENT ON COLUMN webhook_deliveries.status IS 'pending until delivered, dead once out of attempts';
@@ -0,0 +1,25 @@
+package migration
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatest(t *testing.T) {
+	ups, err := fs.Glob(FS, "*.up.sql")
+	require.NoError(t, err)
+	require.NotEmpty(t, ups)
+
+	latest, err := Latest()
+	require.NoError(t, err)
+	require.EqualValues(t, len(ups), latest)
+
+	// every migration can be rolled back
+	for _, up := range ups {
+		_, err := fs.Stat(FS, strings.TrimSuffix(up, ".up.sql")+".down.sql")
+		require.NoError(t, err, up)
+	}
+}
@@ -1,8 +1,9 @@
 -- name: CreateAccount :one
+-- Accounts always open empty, money only arrives through journals.
 INSERT INTO accounts  (
-  owner, balance, currency
+  owner, balance, currency, product
 ) VALUES (
-  $1, $2, $3
+  $1, 0, $2, $3
 ) RETURNING *;
 
 -- name: GetAccount :one
@@ -13,9 +14,20 @@ SELECT * FROM accounts WHERE id = $1 LIMIT 1 FOR NO KEY UPDATE;
 
 -- name: ListAccounts :many
 SELECT * FROM accounts
+WHERE owner = $1
 ORDER BY id
-LIMIT $1
-OFFSET $2;
+LIMIT $2
+OFFSET $3;
+
+-- name: ListOwnerAccounts :many
+SELECT * FROM accounts
+WHERE owner = sqlc.arg(owner) AND id > sqlc.arg(after_id)::bigint
+ORDER BY id
+LIMIT sqlc.arg(limit_count);
+
+-- name: SumOwnerBalances :one
+SELECT COALESCE(SUM(balance), 0)::bigint FROM accounts
+WHERE owner = sqlc.arg(owner) AND currency = sqlc.arg(currency);
 
 -- name: UpdateAccount :one
 UPDATE accounts
@@ -29,6 +41,22 @@ SET balance = balance + sqlc.arg(amount)
 WHERE id = sqlc.arg(id)
 RETURNING *;
 
+-- name: UpdateAccountStatus :one
+UPDATE accounts
+SET
+  status = sqlc.arg(status),
+  status_reason = sqlc.narg(status_reason),
+  status_changed_at = now(),
+  closed_at = CASE WHEN sqlc.arg(status) = 'closed' THEN now() ELSE closed_at END
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: UpdateAccountMinBalance :one
+UPDATE accounts
+SET min_balance = sqlc.arg(min_balance)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
 -- name: DeleteAccount :exec
 DELETE FROM accounts
 WHERE id = $1;
@@ -0,0 +1,53 @@
+-- name: CreateDailyBalances :execrows
+-- Closing balances of every account that existed at the end of the day,
+-- continued from each account's latest earlier snapshot. Days that already
+-- have a snapshot are kept, which makes reruns safe.
+INSERT INTO daily_balances (account_id, balance_date, balance)
+SELECT
+  a.id,
+  sqlc.arg(balance_date)::date,
+  (COALESCE(p.balance, 0) + COALESCE(SUM(e.amount_cents), 0))::bigint
+FROM accounts a
+LEFT JOIN LATERAL (
+  SELECT d.balance, d.balance_date FROM daily_balances d
+  WHERE d.account_id = a.id AND d.balance_date < sqlc.arg(balance_date)::date
+  ORDER BY d.balance_date DESC
+  LIMIT 1
+) p ON true
+LEFT JOIN entries e ON e.account_id = a.id
+  AND e.created_at >= COALESCE((p.balance_date + 1)::timestamp AT TIME ZONE 'UTC', '-infinity')
+  AND e.created_at < (sqlc.arg(balance_date)::date + 1)::timestamp AT TIME ZONE 'UTC'
+WHERE a.created_at < (sqlc.arg(balance_date)::date + 1)::timestamp AT TIME ZONE 'UTC'
+GROUP BY a.id, p.balance
+ON CONFLICT (account_id, balance_date) DO NOTHING;
+
+-- name: DeleteDailyBalancesFrom :execrows
+DELETE FROM daily_balances
+WHERE balance_date >= sqlc.arg(from_date)::date;
+
+-- name: GetLatestDailyBalanceDate :one
+SELECT MAX(balance_date)::date AS balance_date FROM daily_balances;
+
+-- name: GetFirstEntryDate :one
+SELECT (MIN(created_at) AT TIME ZONE 'UTC')::date AS entry_date FROM entries;
+
+-- name: GetAccountBalanceAsOf :one
+-- Balance including every entry up to as_of: the latest snapshot of a day
+-- that ended by then plus the entries booked after it.
+SELECT
+  s.balance_date AS snapshot_date,
+  (COALESCE(s.balance, 0) + COALESCE((
+    SELECT SUM(e.amount_cents) FROM entries e
+    WHERE e.account_id = a.id
+      AND e.created_at >= COALESCE((s.balance_date + 1)::timestamp AT TIME ZONE 'UTC', '-infinity')
+      AND e.created_at <= sqlc.arg(as_of)::timestamptz
+  ), 0))::bigint AS balance
+FROM accounts a
+LEFT JOIN LATERAL (
+  SELECT d.balance, d.balance_date FROM daily_balances d
+  WHERE d.account_id = a.id
+    AND (d.balance_date + 1)::timestamp AT TIME ZONE 'UTC' <= sqlc.arg(as_of)::timestamptz
+  ORDER BY d.balance_date DESC
+  LIMIT 1
+) s ON true
+WHERE a.id = sqlc.arg(account_id);
@@ -0,0 +1,36 @@
+-- name: ListEntryChain :many
+-- Entries in chain order, optionally of a single account.
+SELECT * FROM entries
+WHERE sqlc.narg(account_id)::bigint IS NULL OR account_id = sqlc.narg(account_id)
+ORDER BY account_id, id;
+
+-- name: ListEntryChainHeads :many
+SELECT DISTINCT ON (account_id) account_id, id AS entry_id, hash
+FROM entries
+ORDER BY account_id, id DESC;
+
+-- name: CreateLedgerCheckpoint :one
+INSERT INTO ledger_checkpoints (
+  root_hash, signature, key_id
+) VALUES (
+  $1, $2, $3
+) RETURNING *;
+
+-- name: CreateLedgerCheckpointHeads :copyfrom
+INSERT INTO ledger_checkpoint_heads (
+  checkpoint_id, account_id, entry_id, hash
+) VALUES (
+  $1, $2, $3, $4
+);
+
+-- name: GetLedgerCheckpoint :one
+SELECT * FROM ledger_checkpoints WHERE id = $1 LIMIT 1;
+
+-- name: ListLedgerCheckpoints :many
+SELECT * FROM ledger_checkpoints
+ORDER BY id;
+
+-- name: ListLedgerCheckpointHeads :many
+SELECT * FROM ledger_checkpoint_heads
+WHERE checkpoint_id = $1
+ORDER BY account_id;
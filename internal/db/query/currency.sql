@@ -0,0 +1,20 @@
+-- name: ListCurrencies :many
+SELECT * FROM currencies
+ORDER BY code;
+
+-- name: GetCurrency :one
+SELECT * FROM currencies
+WHERE code = $1 LIMIT 1;
+
+-- name: SetCurrencyEnabled :one
+UPDATE currencies
+SET enabled = sqlc.arg(enabled), updated_at = now()
+WHERE code = sqlc.arg(code)
+RETURNING *;
+
+-- name: CreateHouseAccount :one
+INSERT INTO house_accounts (
+  purpose, currency, account_id
+) VALUES (
+  $1, $2, $3
+) RETURNING *;
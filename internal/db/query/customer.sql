@@ -0,0 +1,65 @@
+-- name: SubmitCustomerProfile :one
+-- Resubmitting replaces the profile and sends it back for review, so
+-- changed details are never treated as verified.
+INSERT INTO customer_profiles (
+  username, date_of_birth, address_line1, address_line2, city, postal_code, country_code, phone
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8
+)
+ON CONFLICT (username) DO UPDATE
+SET
+  date_of_birth = EXCLUDED.date_of_birth,
+  address_line1 = EXCLUDED.address_line1,
+  address_line2 = EXCLUDED.address_line2,
+  city = EXCLUDED.city,
+  postal_code = EXCLUDED.postal_code,
+  country_code = EXCLUDED.country_code,
+  phone = EXCLUDED.phone,
+  kyc_status = 'pending',
+  kyc_reason = NULL,
+  submitted_at = now(),
+  reviewed_by = NULL,
+  reviewed_at = NULL
+RETURNING *;
+
+-- name: GetCustomerProfile :one
+SELECT * FROM customer_profiles
+WHERE username = $1 LIMIT 1;
+
+-- name: ListCustomerProfiles :many
+SELECT * FROM customer_profiles
+WHERE kyc_status = sqlc.arg(kyc_status)
+  AND (submitted_at, username) > (sqlc.arg(after_submitted_at)::timestamptz, sqlc.arg(after_username)::varchar)
+ORDER BY submitted_at, username
+LIMIT sqlc.arg(limit_count);
+
+-- name: ReviewCustomerProfile :one
+-- Only pending profiles can be reviewed, a decision is not overwritten.
+UPDATE customer_profiles
+SET
+  kyc_status = sqlc.arg(kyc_status),
+  kyc_reason = sqlc.narg(kyc_reason),
+  reviewed_by = sqlc.arg(reviewed_by),
+  reviewed_at = now()
+WHERE username = sqlc.arg(username) AND kyc_status = 'pending'
+RETURNING *;
+
+-- name: GetKYCStatus :one
+-- Users who never submitted a profile are pending, unless they are exempt.
+SELECT COALESCE(
+  p.kyc_status,
+  CASE WHEN u.kyc_exempt THEN 'verified' ELSE 'pending' END
+)::varchar AS kyc_status
+FROM users u
+LEFT JOIN customer_profiles p ON p.username = u.username
+WHERE u.username = $1 LIMIT 1;
+
+-- name: LockUserForUpdate :exec
+-- Serializes the checks that span all accounts of a user.
+SELECT username FROM users
+WHERE username = $1
+FOR NO KEY UPDATE;
+
+-- name: ListCountries :many
+SELECT * FROM countries
+ORDER BY name;
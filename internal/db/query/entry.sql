@@ -8,9 +8,63 @@ ORDER BY id
 LIMIT $2
 OFFSET $3;
 
+-- name: ListEntriesAfter :many
+SELECT * FROM entries
+WHERE account_id = sqlc.arg(account_id)
+  AND (created_at, id) > (sqlc.arg(after_created_at)::timestamptz, sqlc.arg(after_id)::bigint)
+ORDER BY created_at, id
+LIMIT sqlc.arg(limit_count);
+
+-- name: ListEntriesAfterID :many
+-- The entries of an account are created under its row lock, so their ids
+-- grow in the order they are committed.
+SELECT * FROM entries
+WHERE account_id = sqlc.arg(account_id)
+  AND id > sqlc.arg(after_id)
+ORDER BY id
+LIMIT sqlc.arg(limit_count);
+
+-- name: NotifyEntry :exec
+-- Listeners get the notification once the transaction commits.
+SELECT pg_notify('account_entries', sqlc.arg(payload)::text);
+
 -- name: CreateEntry :one
 INSERT INTO entries (
-  account_id, amount_cents
+  journal_id, account_id, amount_cents, transfer_id
 ) VALUES (
-  $1, $2
+  $1, $2, $3, $4
 ) RETURNING *;
+
+-- name: GetStatementSummary :one
+-- Balances are derived backwards from the cached account balance so that
+-- accounts opened with a non-zero balance still report correct figures.
+SELECT
+  (a.balance - COALESCE(SUM(e.amount_cents), 0))::bigint AS opening_balance,
+  (a.balance - COALESCE(SUM(e.amount_cents) FILTER (WHERE e.created_at >= sqlc.arg(to_time)::timestamptz), 0))::bigint AS closing_balance,
+  (-COALESCE(SUM(e.amount_cents) FILTER (WHERE e.created_at < sqlc.arg(to_time)::timestamptz AND e.amount_cents < 0), 0))::bigint AS total_debits,
+  COALESCE(SUM(e.amount_cents) FILTER (WHERE e.created_at < sqlc.arg(to_time)::timestamptz AND e.amount_cents >= 0), 0)::bigint AS total_credits,
+  COUNT(e.id) FILTER (WHERE e.created_at < sqlc.arg(to_time)::timestamptz AND e.amount_cents < 0) AS debit_count,
+  COUNT(e.id) FILTER (WHERE e.created_at < sqlc.arg(to_time)::timestamptz AND e.amount_cents >= 0) AS credit_count
+FROM accounts a
+LEFT JOIN entries e ON e.account_id = a.id AND e.created_at >= sqlc.arg(from_time)::timestamptz
+WHERE a.id = sqlc.arg(account_id)
+GROUP BY a.id;
+
+-- name: ListStatementEntries :many
+SELECT
+  e.id,
+  e.amount_cents,
+  e.created_at,
+  e.transfer_id,
+  c.id AS counterparty_account_id,
+  c.owner AS counterparty_owner
+FROM entries e
+LEFT JOIN transfers t ON t.id = e.transfer_id
+LEFT JOIN accounts c ON c.id = CASE
+  WHEN t.from_account_id = e.account_id THEN t.to_account_id
+  ELSE t.from_account_id
+END
+WHERE e.account_id = sqlc.arg(account_id)
+  AND e.created_at >= sqlc.arg(from_time)::timestamptz
+  AND e.created_at < sqlc.arg(to_time)::timestamptz
+ORDER BY e.created_at, e.id;
@@ -0,0 +1,118 @@
+-- name: ListFraudRules :many
+SELECT * FROM fraud_rules
+ORDER BY name;
+
+-- name: GetFraudRule :one
+SELECT * FROM fraud_rules
+WHERE name = $1 LIMIT 1;
+
+-- name: UpsertFraudRule :one
+INSERT INTO fraud_rules (
+  name, kind, params, score, enabled
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+ON CONFLICT (name) DO UPDATE
+SET
+  kind = EXCLUDED.kind,
+  params = EXCLUDED.params,
+  score = EXCLUDED.score,
+  enabled = EXCLUDED.enabled,
+  updated_at = now()
+RETURNING *;
+
+-- name: CountTransfersFromSince :one
+-- Count the transfers an account sent or tried to send since a point in
+-- time, held ones included.
+SELECT count(*) FROM transfers
+WHERE from_account_id = sqlc.arg(account_id)
+  AND created_at >= sqlc.arg(since)
+  AND status <> 'failed';
+
+-- name: CountTransfersBetween :one
+SELECT count(*) FROM transfers
+WHERE from_account_id = $1 AND to_account_id = $2 AND status = 'completed';
+
+-- name: GetOutgoingTransferStats :one
+SELECT
+  count(*) AS transfer_count,
+  COALESCE(avg(amount_cents), 0)::float8 AS average_amount
+FROM transfers
+WHERE from_account_id = sqlc.arg(account_id)
+  AND created_at >= sqlc.arg(since)
+  AND status = 'completed';
+
+-- name: SumCreditsSince :one
+-- Sum the money that arrived on an account since a point in time, by
+-- transfer or any other journal.
+SELECT COALESCE(sum(amount_cents), 0)::bigint FROM entries
+WHERE account_id = sqlc.arg(account_id)
+  AND amount_cents > 0
+  AND created_at >= sqlc.arg(since);
+
+-- name: CreateFraudDecision :one
+INSERT INTO fraud_decisions (
+  from_account_id, to_account_id, amount_cents, transfer_id, score, action
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: CreateFraudRuleHit :one
+INSERT INTO fraud_rule_hits (
+  decision_id, rule_name, score, detail
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetFraudDecision :one
+SELECT * FROM fraud_decisions
+WHERE id = $1 LIMIT 1;
+
+-- name: GetFraudDecisionByTransfer :one
+SELECT * FROM fraud_decisions
+WHERE transfer_id = $1 LIMIT 1;
+
+-- name: GetFraudDecisionForUpdate :one
+SELECT * FROM fraud_decisions
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListFraudDecisions :many
+-- List decisions newest first, optionally of one action, and optionally only
+-- those still waiting for a review.
+SELECT * FROM fraud_decisions
+WHERE (sqlc.narg(action)::varchar IS NULL OR action = sqlc.narg(action))
+  AND (NOT sqlc.arg(unreviewed)::boolean OR (action IN ('challenge', 'hold') AND reviewed_at IS NULL))
+  AND (created_at, id) < (sqlc.arg(before_created_at)::timestamptz, sqlc.arg(before_id)::bigint)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(limit_count);
+
+-- name: ListFraudRuleHits :many
+SELECT * FROM fraud_rule_hits
+WHERE decision_id = $1
+ORDER BY score DESC, rule_name;
+
+-- name: ReviewFraudDecision :one
+UPDATE fraud_decisions
+SET
+  review_outcome = sqlc.arg(review_outcome),
+  reviewed_by = sqlc.arg(reviewed_by),
+  reviewed_at = now()
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: AddFraudConfirmAttempt :one
+UPDATE fraud_decisions
+SET confirm_attempts = confirm_attempts + 1
+WHERE id = $1
+RETURNING *;
+
+-- name: ListStaleFraudDecisions :many
+-- List decisions of an action still waiting for a review that were made
+-- before the cutoff, oldest first.
+SELECT * FROM fraud_decisions
+WHERE action = sqlc.arg(action)
+  AND reviewed_at IS NULL
+  AND created_at < sqlc.arg(created_before)
+ORDER BY created_at, id
+LIMIT sqlc.arg(limit_count);
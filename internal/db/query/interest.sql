@@ -0,0 +1,77 @@
+-- name: ListInterestBearingAccounts :many
+-- Accounts whose product pays interest, with the last day already accrued so
+-- that a run can continue where the previous one stopped.
+SELECT
+  a.id,
+  a.currency,
+  a.created_at,
+  p.interest_rate_bps,
+  p.day_count,
+  (SELECT MAX(ia.accrual_date) FROM interest_accruals ia WHERE ia.account_id = a.id)::date AS last_accrual_date
+FROM accounts a
+JOIN products p ON p.code = a.product
+WHERE p.interest_rate_bps > 0
+  AND a.status <> 'closed'
+ORDER BY a.id;
+
+-- name: GetAccountBalanceAt :one
+-- Balance right before the given instant, derived backwards from the cached
+-- balance like the statement figures.
+SELECT (a.balance - COALESCE(SUM(e.amount_cents), 0))::bigint AS balance
+FROM accounts a
+LEFT JOIN entries e ON e.account_id = a.id AND e.created_at >= sqlc.arg(at)::timestamptz
+WHERE a.id = sqlc.arg(account_id)
+GROUP BY a.id;
+
+-- name: CreateInterestAccrual :execrows
+-- Accruing the same day twice is a no-op, which makes reruns safe.
+INSERT INTO interest_accruals (
+  account_id, accrual_date, balance, rate_bps, day_count, amount_micros
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+) ON CONFLICT (account_id, accrual_date) DO NOTHING;
+
+-- name: ListInterestAccruals :many
+SELECT * FROM interest_accruals
+WHERE account_id = sqlc.arg(account_id)
+  AND accrual_date >= sqlc.arg(from_date)::date
+  AND accrual_date < sqlc.arg(to_date)::date
+ORDER BY accrual_date;
+
+-- name: ListUnpostedInterestPeriods :many
+-- Only active accounts can be paid, the interest of the others is posted
+-- once they are active again.
+SELECT
+  ia.account_id,
+  date_trunc('month', ia.accrual_date)::date AS period_start
+FROM interest_accruals ia
+JOIN accounts a ON a.id = ia.account_id
+WHERE ia.posted_at IS NULL
+  AND ia.accrual_date < sqlc.arg(before)::date
+  AND a.status = 'active'
+GROUP BY ia.account_id, period_start
+ORDER BY ia.account_id, period_start;
+
+-- name: MarkInterestAccrualsPosted :many
+-- Claims the unposted accruals of a period. Rows claimed by a concurrent run
+-- are skipped once it commits, so a period is never paid twice.
+UPDATE interest_accruals
+SET posted_at = now()
+WHERE account_id = sqlc.arg(account_id)
+  AND accrual_date >= sqlc.arg(from_date)::date
+  AND accrual_date < sqlc.arg(to_date)::date
+  AND posted_at IS NULL
+RETURNING amount_micros;
+
+-- name: SetInterestAccrualsTransfer :exec
+UPDATE interest_accruals
+SET transfer_id = sqlc.arg(transfer_id)
+WHERE account_id = sqlc.arg(account_id)
+  AND accrual_date >= sqlc.arg(from_date)::date
+  AND accrual_date < sqlc.arg(to_date)::date;
+
+-- name: GetHouseAccount :one
+SELECT a.* FROM accounts a
+JOIN house_accounts h ON h.account_id = a.id
+WHERE h.purpose = $1 AND h.currency = $2
+LIMIT 1;
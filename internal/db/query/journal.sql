@@ -0,0 +1,20 @@
+-- name: CreateJournal :one
+INSERT INTO journals (
+  kind, description
+) VALUES (
+  $1, $2
+) RETURNING *;
+
+-- name: GetJournal :one
+SELECT * FROM journals WHERE id = $1 LIMIT 1;
+
+-- name: ListJournalEntries :many
+SELECT * FROM entries
+WHERE journal_id = $1
+ORDER BY id;
+
+-- name: ListHouseAccounts :many
+SELECT h.purpose, sqlc.embed(a) FROM house_accounts h
+JOIN accounts a ON a.id = h.account_id
+ORDER BY a.currency, h.purpose;
+
@@ -0,0 +1,32 @@
+-- name: CreateOutboxEvent :one
+INSERT INTO outbox (
+  aggregate_type, aggregate_id, event_type, schema_version, payload
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: ListUnpublishedOutboxEvents :many
+SELECT * FROM outbox
+WHERE published_at IS NULL
+ORDER BY id
+LIMIT $1;
+
+-- name: MarkOutboxEventsPublished :exec
+UPDATE outbox
+SET published_at = now()
+WHERE id = ANY(sqlc.arg(ids)::bigint[]);
+
+-- name: TryLockOutboxRelay :one
+-- Take the lock only one relay may hold, until the end of the transaction.
+SELECT pg_try_advisory_xact_lock(hashtext('outbox_relay'));
+
+-- name: DeletePublishedOutboxEvents :execrows
+-- Delete up to limit_count of the events published before the time, the
+-- oldest first.
+DELETE FROM outbox
+WHERE id IN (
+  SELECT id FROM outbox
+  WHERE published_at < sqlc.arg(published_before)::timestamptz
+  ORDER BY id
+  LIMIT sqlc.arg(limit_count)
+);
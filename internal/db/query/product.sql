@@ -0,0 +1,10 @@
+-- name: GetProduct :one
+SELECT * FROM products WHERE code = $1 LIMIT 1;
+
+-- name: CountWithdrawalsSince :one
+-- Only completed transfers are withdrawals. A pending transfer is counted
+-- once it is released, a failed one never.
+SELECT COUNT(*) FROM transfers
+WHERE from_account_id = sqlc.arg(account_id)
+  AND status = 'completed'
+  AND created_at >= sqlc.arg(since)::timestamptz;
@@ -0,0 +1,61 @@
+-- name: CountLedger :one
+SELECT
+  (SELECT COUNT(*) FROM accounts) AS accounts,
+  (SELECT COUNT(*) FROM transfers WHERE status = 'completed') AS transfers;
+
+-- name: ListBalanceMismatches :many
+-- Accounts whose cached balance differs from the sum of their entries.
+SELECT
+  a.id AS account_id,
+  a.balance,
+  COALESCE(SUM(e.amount_cents), 0)::bigint AS entries_total
+FROM accounts a
+LEFT JOIN entries e ON e.account_id = a.id
+GROUP BY a.id
+HAVING a.balance <> COALESCE(SUM(e.amount_cents), 0)
+ORDER BY a.id;
+
+-- name: ListTransferEntryMismatches :many
+-- Completed transfers that are not booked as exactly one debit of the sender
+-- and one credit of the recipient for the transfer amount.
+SELECT
+  t.id AS transfer_id,
+  t.from_account_id,
+  t.to_account_id,
+  COUNT(e.id) FILTER (
+    WHERE (e.account_id = t.from_account_id AND e.amount_cents = -t.amount_cents)
+       OR (e.account_id = t.to_account_id AND e.amount_cents = t.amount_cents)
+  ) AS matching_entries,
+  COUNT(e.id) AS total_entries
+FROM transfers t
+LEFT JOIN entries e ON e.transfer_id = t.id
+WHERE t.status = 'completed'
+GROUP BY t.id
+HAVING COUNT(e.id) <> 2
+  OR COUNT(e.id) FILTER (WHERE e.account_id = t.from_account_id AND e.amount_cents = -t.amount_cents) <> 1
+  OR COUNT(e.id) FILTER (WHERE e.account_id = t.to_account_id AND e.amount_cents = t.amount_cents) <> 1
+ORDER BY t.id;
+
+-- name: CreateReconciliationRun :one
+INSERT INTO reconciliation_runs (
+  accounts_checked, transfers_checked, discrepancy_count
+) VALUES (
+  $1, $2, $3
+) RETURNING *;
+
+-- name: GetLatestReconciliationRun :one
+SELECT * FROM reconciliation_runs
+ORDER BY id DESC
+LIMIT 1;
+
+-- name: CreateReconciliationDiscrepancy :one
+INSERT INTO reconciliation_discrepancies (
+  run_id, kind, account_id, transfer_id, expected, actual, details
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: ListReconciliationDiscrepancies :many
+SELECT * FROM reconciliation_discrepancies
+WHERE run_id = $1
+ORDER BY id;
@@ -0,0 +1,55 @@
+-- name: CreateScreeningCase :one
+INSERT INTO screening_cases (
+  subject, username, transfer_id, screened_name
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING *;
+
+-- name: CreateScreeningHit :one
+INSERT INTO screening_hits (
+  case_id, list_source, entry_uid, listed_name, matched_name, score
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: GetScreeningCase :one
+SELECT * FROM screening_cases
+WHERE id = $1 LIMIT 1;
+
+-- name: GetScreeningCaseForUpdate :one
+SELECT * FROM screening_cases
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListScreeningCases :many
+SELECT * FROM screening_cases
+WHERE status = sqlc.arg(status)
+  AND (created_at, id) > (sqlc.arg(after_created_at)::timestamptz, sqlc.arg(after_id)::bigint)
+ORDER BY created_at, id
+LIMIT sqlc.arg(limit_count);
+
+-- name: ListScreeningHits :many
+SELECT * FROM screening_hits
+WHERE case_id = $1
+ORDER BY score DESC, list_source, entry_uid;
+
+-- name: ListClearedScreeningEntries :many
+-- List entries a user was already cleared of, so that a false positive
+-- does not hold every transfer they make.
+SELECT DISTINCT h.list_source, h.entry_uid FROM screening_hits h
+JOIN screening_cases c ON c.id = h.case_id
+WHERE c.username = $1 AND c.status = 'cleared';
+
+-- name: CountOpenTransferScreeningCases :one
+SELECT count(*) FROM screening_cases
+WHERE transfer_id = $1 AND status = 'open';
+
+-- name: ResolveScreeningCase :one
+UPDATE screening_cases
+SET
+  status = sqlc.arg(status),
+  resolution_note = sqlc.narg(resolution_note),
+  resolved_by = sqlc.arg(resolved_by),
+  resolved_at = now()
+WHERE id = sqlc.arg(id)
+RETURNING *;
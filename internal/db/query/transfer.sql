@@ -2,11 +2,52 @@
 SELECT * FROM transfers WHERE id = $1 LIMIT 1;
 
 -- name: ListTransfers :many
-SELECT * FROM transfers
-WHERE from_account_id = $1 OR to_account_id = $2
-ORDER BY id
-LIMIT $3
-OFFSET $4;
+-- Each side of the union is a plain equality on an indexed column, so the
+-- planner can use the (from_account_id, to_account_id) composite index for
+-- outgoing transfers and the to_account_id index for incoming ones instead of
+-- falling back to a sequential scan for the OR.
+SELECT * FROM (
+  SELECT o.* FROM transfers o
+  WHERE o.from_account_id = sqlc.arg(account_id)
+    AND sqlc.arg(direction)::text IN ('out', 'both')
+  UNION ALL
+  SELECT i.* FROM transfers i
+  WHERE i.to_account_id = sqlc.arg(account_id)
+    AND sqlc.arg(direction)::text IN ('in', 'both')
+    -- self transfers are already returned by the outgoing side
+    AND (i.from_account_id <> sqlc.arg(account_id) OR sqlc.arg(direction)::text = 'in')
+) t
+WHERE (sqlc.narg(from_time)::timestamptz IS NULL OR t.created_at >= sqlc.narg(from_time))
+  AND (sqlc.narg(to_time)::timestamptz IS NULL OR t.created_at < sqlc.narg(to_time))
+  AND (sqlc.narg(min_amount)::bigint IS NULL OR t.amount_cents >= sqlc.narg(min_amount))
+  AND (sqlc.narg(max_amount)::bigint IS NULL OR t.amount_cents <= sqlc.narg(max_amount))
+  AND (sqlc.narg(status)::varchar IS NULL OR t.status = sqlc.narg(status))
+ORDER BY t.id
+LIMIT sqlc.arg(limit_count)
+OFFSET sqlc.arg(offset_count);
+
+-- name: ListTransfersAfter :many
+-- Keyset variant of ListTransfers. Both sides of the union are read from the
+-- (account, created_at, id) indexes in order and merged.
+SELECT * FROM (
+  SELECT o.* FROM transfers o
+  WHERE o.from_account_id = sqlc.arg(account_id)
+    AND sqlc.arg(direction)::text IN ('out', 'both')
+    AND (o.created_at, o.id) > (sqlc.arg(after_created_at)::timestamptz, sqlc.arg(after_id)::bigint)
+  UNION ALL
+  SELECT i.* FROM transfers i
+  WHERE i.to_account_id = sqlc.arg(account_id)
+    AND sqlc.arg(direction)::text IN ('in', 'both')
+    AND (i.from_account_id <> sqlc.arg(account_id) OR sqlc.arg(direction)::text = 'in')
+    AND (i.created_at, i.id) > (sqlc.arg(after_created_at)::timestamptz, sqlc.arg(after_id)::bigint)
+) t
+WHERE (sqlc.narg(from_time)::timestamptz IS NULL OR t.created_at >= sqlc.narg(from_time))
+  AND (sqlc.narg(to_time)::timestamptz IS NULL OR t.created_at < sqlc.narg(to_time))
+  AND (sqlc.narg(min_amount)::bigint IS NULL OR t.amount_cents >= sqlc.narg(min_amount))
+  AND (sqlc.narg(max_amount)::bigint IS NULL OR t.amount_cents <= sqlc.narg(max_amount))
+  AND (sqlc.narg(status)::varchar IS NULL OR t.status = sqlc.narg(status))
+ORDER BY t.created_at, t.id
+LIMIT sqlc.arg(limit_count);
 
 -- name: CreateTransfer :one
 INSERT INTO transfers (
@@ -14,3 +55,20 @@ INSERT INTO transfers (
 ) VALUES (
   $1, $2, $3
 ) RETURNING *;
+
+-- name: CreatePendingTransfer :one
+-- A pending transfer has no journal yet, it is booked once released.
+INSERT INTO transfers (
+  from_account_id, to_account_id, amount_cents, status
+) VALUES (
+  $1, $2, $3, 'pending'
+) RETURNING *;
+
+-- name: GetTransferForUpdate :one
+SELECT * FROM transfers WHERE id = $1 LIMIT 1 FOR NO KEY UPDATE;
+
+-- name: UpdateTransferStatus :one
+UPDATE transfers
+SET status = sqlc.arg(status)
+WHERE id = sqlc.arg(id)
+RETURNING *;
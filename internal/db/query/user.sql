@@ -8,3 +8,41 @@ INSERT INTO users (
 
 -- name: GetUser :one
 SELECT * FROM users WHERE username = $1 LIMIT 1;
+
+-- name: SetUserTOTPSecret :one
+-- Start an enrollment with a new secret, which is not used before a code of
+-- it is verified. Fails with no rows once the secret is enabled.
+UPDATE users
+SET
+  totp_secret = sqlc.arg(totp_secret)::varchar,
+  totp_enabled_at = NULL,
+  totp_last_step = NULL
+WHERE username = sqlc.arg(username) AND totp_enabled_at IS NULL
+RETURNING *;
+
+-- name: EnableUserTOTP :one
+-- Enable the secret a code was checked with, unless it was replaced since.
+UPDATE users
+SET
+  totp_enabled_at = now(),
+  totp_last_step = sqlc.arg(totp_last_step)::bigint
+WHERE username = sqlc.arg(username)
+  AND totp_secret = sqlc.arg(totp_secret)::varchar
+  AND totp_enabled_at IS NULL
+RETURNING *;
+
+-- name: UseUserTOTPStep :one
+-- Record the time step of a code that was used. Fails with no rows when a
+-- code of that step or a later one was used already.
+UPDATE users
+SET totp_last_step = sqlc.arg(totp_last_step)::bigint
+WHERE username = sqlc.arg(username)
+  AND totp_enabled_at IS NOT NULL
+  AND (totp_last_step IS NULL OR totp_last_step < sqlc.arg(totp_last_step)::bigint)
+RETURNING *;
+
+-- name: BlockUser :one
+UPDATE users
+SET blocked_at = COALESCE(blocked_at, now())
+WHERE username = $1
+RETURNING *;
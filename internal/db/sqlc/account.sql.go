@@ -7,13 +7,15 @@ package db
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const addAccountBalance = `-- name: AddAccountBalance :one
 UPDATE accounts
 SET balance = balance + $1
 WHERE id = $2
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, status, status_reason, status_changed_at, closed_at, product, min_balance
 `
 
 type AddAccountBalanceParams struct {
@@ -30,26 +32,33 @@ func (q *Queries) AddAccountBalance(ctx context.Context, arg AddAccountBalancePa
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+		&i.ClosedAt,
+		&i.Product,
+		&i.MinBalance,
 	)
 	return i, err
 }
 
 const createAccount = `-- name: CreateAccount :one
 INSERT INTO accounts  (
-  owner, balance, currency
+  owner, balance, currency, product
 ) VALUES (
-  $1, $2, $3
-) RETURNING id, owner, balance, currency, created_at
+  $1, 0, $2, $3
+) RETURNING id, owner, balance, currency, created_at, status, status_reason, status_changed_at, closed_at, product, min_balance
 `
 
 type CreateAccountParams struct {
 	Owner    string `json:"owner"`
-	Balance  int64  `json:"balance"`
 	Currency string `json:"currency"`
+	Product  string `json:"product"`
 }
 
+// Accounts always open empty, money only arrives through journals.
 func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error) {
-	row := q.db.QueryRow(ctx, createAccount, arg.Owner, arg.Balance, arg.Currency)
+	row := q.db.QueryRow(ctx, createAccount, arg.Owner, arg.Currency, arg.Product)
 	var i Account
 	err := row.Scan(
 		&i.ID,
@@ -57,6 +66,12 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+		&i.ClosedAt,
+		&i.Product,
+		&i.MinBalance,
 	)
 	return i, err
 }
@@ -72,7 +87,7 @@ func (q *Queries) DeleteAccount(ctx context.Context, id int64) error {
 }
 
 const getAccount = `-- name: GetAccount :one
-SELECT id, owner, balance, currency, created_at FROM accounts WHERE id = $1 LIMIT 1
+SELECT id, owner, balance, currency, created_at, status, status_reason, status_changed_at, closed_at, product, min_balance FROM accounts WHERE id = $1 LIMIT 1
 `
 
 func (q *Queries) GetAccount(ctx context.Context, id int64) (Account, error) {
@@ -84,12 +99,18 @@ func (q *Queries) GetAccount(ctx context.Context, id int64) (Account, error) {
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+		&i.ClosedAt,
+		&i.Product,
+		&i.MinBalance,
 	)
 	return i, err
 }
 
 const getAccountForUpdate = `-- name: GetAccountForUpdate :one
-SELECT id, owner, balance, currency, created_at FROM accounts WHERE id = $1 LIMIT 1 FOR NO KEY UPDATE
+SELECT id, owner, balance, currency, created_at, status, status_reason, status_changed_at, closed_at, product, min_balance FROM accounts WHERE id = $1 LIMIT 1 FOR NO KEY UPDATE
 `
 
 func (q *Queries) GetAccountForUpdate(ctx context.Context, id int64) (Account, error) {
@@ -101,24 +122,77 @@ func (q *Queries) GetAccountForUpdate(ctx context.Context, id int64) (Account, e
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+		&i.ClosedAt,
+		&i.Product,
+		&i.MinBalance,
 	)
 	return i, err
 }
 
 const listAccounts = `-- name: ListAccounts :many
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, status, status_reason, status_changed_at, closed_at, product, min_balance FROM accounts
+WHERE owner = $1
 ORDER BY id
-LIMIT $1
-OFFSET $2
+LIMIT $2
+OFFSET $3
 `
 
 type ListAccountsParams struct {
-	Limit  int32 `json:"limit"`
-	Offset int32 `json:"offset"`
+	Owner  string `json:"owner"`
+	Limit  int32  `json:"limit"`
+	Offset int32  `json:"offset"`
 }
 
 func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error) {
-	rows, err := q.db.Query(ctx, listAccounts, arg.Limit, arg.Offset)
+	rows, err := q.db.Query(ctx, listAccounts, arg.Owner, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Account{}
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Balance,
+			&i.Currency,
+			&i.CreatedAt,
+			&i.Status,
+			&i.StatusReason,
+			&i.StatusChangedAt,
+			&i.ClosedAt,
+			&i.Product,
+			&i.MinBalance,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOwnerAccounts = `-- name: ListOwnerAccounts :many
+SELECT id, owner, balance, currency, created_at, status, status_reason, status_changed_at, closed_at, product, min_balance FROM accounts
+WHERE owner = $1 AND id > $2::bigint
+ORDER BY id
+LIMIT $3
+`
+
+type ListOwnerAccountsParams struct {
+	Owner      string `json:"owner"`
+	AfterID    int64  `json:"after_id"`
+	LimitCount int32  `json:"limit_count"`
+}
+
+func (q *Queries) ListOwnerAccounts(ctx context.Context, arg ListOwnerAccountsParams) ([]Account, error) {
+	rows, err := q.db.Query(ctx, listOwnerAccounts, arg.Owner, arg.AfterID, arg.LimitCount)
 	if err != nil {
 		return nil, err
 	}
@@ -132,6 +206,12 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 			&i.Balance,
 			&i.Currency,
 			&i.CreatedAt,
+			&i.Status,
+			&i.StatusReason,
+			&i.StatusChangedAt,
+			&i.ClosedAt,
+			&i.Product,
+			&i.MinBalance,
 		); err != nil {
 			return nil, err
 		}
@@ -143,11 +223,28 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 	return items, nil
 }
 
+const sumOwnerBalances = `-- name: SumOwnerBalances :one
+SELECT COALESCE(SUM(balance), 0)::bigint FROM accounts
+WHERE owner = $1 AND currency = $2
+`
+
+type SumOwnerBalancesParams struct {
+	Owner    string `json:"owner"`
+	Currency string `json:"currency"`
+}
+
+func (q *Queries) SumOwnerBalances(ctx context.Context, arg SumOwnerBalancesParams) (int64, error) {
+	row := q.db.QueryRow(ctx, sumOwnerBalances, arg.Owner, arg.Currency)
+	var column_1 int64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
 const updateAccount = `-- name: UpdateAccount :one
 UPDATE accounts
 SET balance = $2
 WHERE id = $1
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, status, status_reason, status_changed_at, closed_at, product, min_balance
 `
 
 type UpdateAccountParams struct {
@@ -164,6 +261,79 @@ func (q *Queries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+		&i.ClosedAt,
+		&i.Product,
+		&i.MinBalance,
+	)
+	return i, err
+}
+
+const updateAccountMinBalance = `-- name: UpdateAccountMinBalance :one
+UPDATE accounts
+SET min_balance = $1
+WHERE id = $2
+RETURNING id, owner, balance, currency, created_at, status, status_reason, status_changed_at, closed_at, product, min_balance
+`
+
+type UpdateAccountMinBalanceParams struct {
+	MinBalance int64 `json:"min_balance"`
+	ID         int64 `json:"id"`
+}
+
+func (q *Queries) UpdateAccountMinBalance(ctx context.Context, arg UpdateAccountMinBalanceParams) (Account, error) {
+	row := q.db.QueryRow(ctx, updateAccountMinBalance, arg.MinBalance, arg.ID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+		&i.ClosedAt,
+		&i.Product,
+		&i.MinBalance,
+	)
+	return i, err
+}
+
+const updateAccountStatus = `-- name: UpdateAccountStatus :one
+UPDATE accounts
+SET
+  status = $1,
+  status_reason = $2,
+  status_changed_at = now(),
+  closed_at = CASE WHEN $1 = 'closed' THEN now() ELSE closed_at END
+WHERE id = $3
+RETURNING id, owner, balance, currency, created_at, status, status_reason, status_changed_at, closed_at, product, min_balance
+`
+
+type UpdateAccountStatusParams struct {
+	Status       string      `json:"status"`
+	StatusReason pgtype.Text `json:"status_reason"`
+	ID           int64       `json:"id"`
+}
+
+func (q *Queries) UpdateAccountStatus(ctx context.Context, arg UpdateAccountStatusParams) (Account, error) {
+	row := q.db.QueryRow(ctx, updateAccountStatus, arg.Status, arg.StatusReason, arg.ID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+		&i.ClosedAt,
+		&i.Product,
+		&i.MinBalance,
 	)
 	return i, err
 }
@@ -0,0 +1,137 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	AccountStatusActive = "active"
+	AccountStatusFrozen = "frozen"
+	AccountStatusClosed = "closed"
+)
+
+const (
+	UserRoleCustomer = "customer"
+	UserRoleAdmin    = "admin"
+)
+
+var ErrAccountNotActive = errors.New("account is not active")
+var ErrInvalidStatusTransition = errors.New("invalid account status transition")
+var ErrNonZeroBalance = errors.New("account balance is not zero")
+var ErrUserBlocked = errors.New("user is blocked after a confirmed sanctions match")
+
+// accountStatusTransitions lists the statuses an account may move to from
+// each status. Closed is final.
+var accountStatusTransitions = map[string][]string{
+	AccountStatusActive: {AccountStatusFrozen, AccountStatusClosed},
+	AccountStatusFrozen: {AccountStatusActive},
+}
+
+type ChangeAccountStatusTxParams struct {
+	AccountID int64  `json:"account_id"`
+	Status    string `json:"status"`
+	Reason    string `json:"reason"`
+}
+
+// ChangeAccountStatusTx moves the account to a new status. The account row is
+// locked for the duration of the check so that a concurrent transfer cannot
+// change the balance of an account that is being closed.
+func (s *SQLStore) ChangeAccountStatusTx(ctx context.Context, arg ChangeAccountStatusTxParams) (Account, error) {
+	var result Account
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		account, err := q.GetAccountForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		result, err = changeAccountStatus(ctx, q, account, arg.Status, arg.Reason)
+		return err
+	})
+
+	return result, err
+}
+
+// changeAccountStatus moves an account the caller has locked to a new status,
+// and records the change in the outbox and the audit log.
+func changeAccountStatus(ctx context.Context, q *Queries, account Account, status, reason string) (Account, error) {
+	if !canTransition(account.Status, status) {
+		return Account{}, fmt.Errorf("%w: %s to %s", ErrInvalidStatusTransition, account.Status, status)
+	}
+
+	if status == AccountStatusClosed && account.Balance != 0 {
+		return Account{}, fmt.Errorf("%w: %d", ErrNonZeroBalance, account.Balance)
+	}
+
+	// the accounts of a blocked user stay frozen
+	if status == AccountStatusActive {
+		owner, err := q.GetUser(ctx, account.Owner)
+		if err != nil {
+			return Account{}, err
+		}
+		if owner.BlockedAt.Valid {
+			return Account{}, fmt.Errorf("account [%d] owner %s: %w", account.ID, owner.Username, ErrUserBlocked)
+		}
+	}
+
+	result, err := q.UpdateAccountStatus(ctx, UpdateAccountStatusParams{
+		ID:           account.ID,
+		Status:       status,
+		StatusReason: pgtype.Text{String: reason, Valid: reason != ""},
+	})
+	if err != nil {
+		return result, err
+	}
+
+	if err = enqueueAccountEvent(ctx, q, accountStatusEvents[status], result); err != nil {
+		return result, err
+	}
+
+	return result, audit(ctx, q, accountStatusActions[status], "account", result.ID, account, result)
+}
+
+func canTransition(from, to string) bool {
+	return slices.Contains(accountStatusTransitions[from], to)
+}
+
+// lockAccounts locks the accounts in id order, the same order in which their
+// balances are updated, so that concurrent journals cannot deadlock.
+func lockAccounts(ctx context.Context, q *Queries, accountIDs ...int64) (map[int64]Account, error) {
+	accountIDs = slices.Clone(accountIDs)
+	slices.Sort(accountIDs)
+
+	accounts := make(map[int64]Account, len(accountIDs))
+	for _, id := range slices.Compact(accountIDs) {
+		account, err := q.GetAccountForUpdate(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		accounts[id] = account
+	}
+
+	return accounts, nil
+}
+
+// lockActiveAccounts locks the accounts like lockAccounts and fails unless
+// all of them are active.
+func lockActiveAccounts(ctx context.Context, q *Queries, accountIDs ...int64) (map[int64]Account, error) {
+	accounts, err := lockAccounts(ctx, q, accountIDs...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range slices.Sorted(maps.Keys(accounts)) {
+		if account := accounts[id]; account.Status != AccountStatusActive {
+			return nil, fmt.Errorf("account [%d] is %s: %w", account.ID, account.Status, ErrAccountNotActive)
+		}
+	}
+
+	return accounts, nil
+}
@@ -0,0 +1,87 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vlone310/bss/internal/money"
+)
+
+func TestChangeAccountStatusTx(t *testing.T) {
+	account := createRandomAccount(t)
+
+	frozen, err := testStore.ChangeAccountStatusTx(context.Background(), ChangeAccountStatusTxParams{
+		AccountID: account.ID,
+		Status:    AccountStatusFrozen,
+		Reason:    "investigation",
+	})
+	require.NoError(t, err)
+	require.Equal(t, AccountStatusFrozen, frozen.Status)
+	require.Equal(t, "investigation", frozen.StatusReason.String)
+	require.True(t, frozen.StatusChangedAt.Valid)
+
+	// a frozen account cannot be closed
+	_, err = testStore.ChangeAccountStatusTx(context.Background(), ChangeAccountStatusTxParams{
+		AccountID: account.ID,
+		Status:    AccountStatusClosed,
+	})
+	require.ErrorIs(t, err, ErrInvalidStatusTransition)
+
+	active, err := testStore.ChangeAccountStatusTx(context.Background(), ChangeAccountStatusTxParams{
+		AccountID: account.ID,
+		Status:    AccountStatusActive,
+	})
+	require.NoError(t, err)
+	require.Equal(t, AccountStatusActive, active.Status)
+	require.False(t, active.StatusReason.Valid)
+}
+
+func TestCloseAccountRequiresZeroBalance(t *testing.T) {
+	account := createRandomAccount(t)
+
+	_, err := testStore.UpdateAccount(context.Background(), UpdateAccountParams{ID: account.ID, Balance: 100})
+	require.NoError(t, err)
+
+	_, err = testStore.ChangeAccountStatusTx(context.Background(), ChangeAccountStatusTxParams{
+		AccountID: account.ID,
+		Status:    AccountStatusClosed,
+	})
+	require.ErrorIs(t, err, ErrNonZeroBalance)
+
+	_, err = testStore.UpdateAccount(context.Background(), UpdateAccountParams{ID: account.ID, Balance: 0})
+	require.NoError(t, err)
+
+	closed, err := testStore.ChangeAccountStatusTx(context.Background(), ChangeAccountStatusTxParams{
+		AccountID: account.ID,
+		Status:    AccountStatusClosed,
+	})
+	require.NoError(t, err)
+	require.Equal(t, AccountStatusClosed, closed.Status)
+	require.True(t, closed.ClosedAt.Valid)
+}
+
+func TestTransferTxInactiveAccount(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	_, err := testStore.ChangeAccountStatusTx(context.Background(), ChangeAccountStatusTxParams{
+		AccountID: account2.ID,
+		Status:    AccountStatusFrozen,
+		Reason:    "investigation",
+	})
+	require.NoError(t, err)
+
+	for _, arg := range []TransferTxParams{
+		{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: money.New(10, account1.Currency)},
+		{FromAccountID: account2.ID, ToAccountID: account1.ID, Amount: money.New(10, account1.Currency)},
+	} {
+		_, err = testStore.TransferTx(context.Background(), arg)
+		require.ErrorIs(t, err, ErrAccountNotActive)
+	}
+
+	// nothing was booked
+	updated, err := testStore.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, updated.Balance)
+}
@@ -12,12 +12,12 @@ import (
 func createRandomAccount(t *testing.T) Account {
 	t.Helper()
 
-	user := createRandomUser(t)
+	user := createVerifiedUser(t)
 
 	arg := CreateAccountParams{
 		Owner:    user.Username,
-		Balance:  testutil.RandomMoney(),
 		Currency: testutil.RandomCurrency(),
+		Product:  ProductChecking,
 	}
 
 	account, err := testStore.CreateAccount(context.Background(), arg)
@@ -25,12 +25,65 @@ func createRandomAccount(t *testing.T) Account {
 	require.NoError(t, err)
 	require.NotEmpty(t, account)
 	require.Equal(t, arg.Owner, account.Owner)
-	require.Equal(t, arg.Balance, account.Balance)
+	require.Zero(t, account.Balance)
 	require.Equal(t, arg.Currency, account.Currency)
+	require.Equal(t, arg.Product, account.Product)
+	require.Zero(t, account.MinBalance)
+	require.Equal(t, AccountStatusActive, account.Status)
 
 	require.NotZero(t, account.ID)
 	require.NotZero(t, account.CreatedAt)
 
+	// Keep enough balance for the transfer tests now that TransferTx refuses
+	// to go below the account's minimum balance.
+	return fundAccount(t, account, testutil.RandomMoney()+100_000)
+}
+
+// createAccountInCurrency creates a funded account that can exchange
+// transfers with accounts of the given currency.
+func createAccountInCurrency(t *testing.T, currency string) Account {
+	t.Helper()
+
+	user := createVerifiedUser(t)
+
+	account, err := testStore.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    user.Username,
+		Currency: currency,
+		Product:  ProductChecking,
+	})
+	require.NoError(t, err)
+
+	return fundAccount(t, account, 100_000)
+}
+
+// fundAccount books an opening balance against the suspense account of the
+// account's currency.
+func fundAccount(t *testing.T, account Account, amount int64) Account {
+	t.Helper()
+
+	suspense, err := testStore.GetHouseAccount(context.Background(), GetHouseAccountParams{
+		Purpose:  HousePurposeSuspense,
+		Currency: account.Currency,
+	})
+	require.NoError(t, err)
+
+	result, err := testStore.PostJournalTx(context.Background(), PostJournalTxParams{
+		Kind: JournalKindOpeningBalance,
+		Postings: []Posting{
+			{AccountID: suspense.ID, AmountCents: -amount},
+			{AccountID: account.ID, AmountCents: amount},
+		},
+	})
+	require.NoError(t, err)
+
+	for _, updated := range result.Accounts {
+		if updated.ID == account.ID {
+			require.Equal(t, account.Balance+amount, updated.Balance)
+			return updated
+		}
+	}
+
+	t.Fatalf("account [%d] was not posted to", account.ID)
 	return account
 }
 
@@ -86,22 +139,64 @@ func TestDeleteAccount(t *testing.T) {
 }
 
 func TestListAccounts(t *testing.T) {
-	for i := 0; i < 10; i++ {
-		createRandomAccount(t)
+	user := createVerifiedUser(t)
+	// an account of someone else is left out
+	createRandomAccount(t)
+
+	var created []Account
+	for _, product := range []string{ProductChecking, ProductSavings, ProductCreditLine} {
+		account, err := testStore.CreateAccount(context.Background(), CreateAccountParams{
+			Owner:    user.Username,
+			Currency: testutil.RandomCurrency(),
+			Product:  product,
+		})
+		require.NoError(t, err)
+		created = append(created, account)
 	}
 
 	arg := ListAccountsParams{
-		Limit:  5,
-		Offset: 5,
+		Owner:  user.Username,
+		Limit:  2,
+		Offset: 1,
 	}
 
 	accounts, err := testStore.ListAccounts(context.Background(), arg)
-
 	require.NoError(t, err)
-	require.Len(t, accounts, 5)
+	require.Len(t, accounts, 2)
+	require.Equal(t, created[1].ID, accounts[0].ID)
+	require.Equal(t, created[2].ID, accounts[1].ID)
+}
 
-	for _, account := range accounts {
-		require.NotEmpty(t, account)
+func TestListOwnerAccounts(t *testing.T) {
+	user := createVerifiedUser(t)
+	// an account of someone else is left out
+	createRandomAccount(t)
+
+	var created []Account
+	for _, product := range []string{ProductChecking, ProductSavings, ProductCreditLine} {
+		account, err := testStore.CreateAccount(context.Background(), CreateAccountParams{
+			Owner:    user.Username,
+			Currency: testutil.RandomCurrency(),
+			Product:  product,
+		})
+		require.NoError(t, err)
+		created = append(created, account)
+	}
+
+	arg := ListOwnerAccountsParams{
+		Owner:      user.Username,
+		LimitCount: 2,
 	}
 
+	accounts, err := testStore.ListOwnerAccounts(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, accounts, 2)
+	require.Equal(t, created[0].ID, accounts[0].ID)
+	require.Equal(t, created[1].ID, accounts[1].ID)
+
+	arg.AfterID = accounts[1].ID
+	accounts, err = testStore.ListOwnerAccounts(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, accounts, 1)
+	require.Equal(t, created[2].ID, accounts[0].ID)
 }
@@ -0,0 +1,349 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/vlone310/bss/internal/event"
+)
+
+const (
+	AuditActionUserCreate           = "user.create"
+	AuditActionUserBlock            = "user.block"
+	AuditActionUserEnrollTOTP       = "user.enroll_totp"
+	AuditActionUserEnableTOTP       = "user.enable_totp"
+	AuditActionAccountCreate        = "account.create"
+	AuditActionAccountFreeze        = "account.freeze"
+	AuditActionAccountUnfreeze      = "account.unfreeze"
+	AuditActionAccountClose         = "account.close"
+	AuditActionAccountSetLimit      = "account.set_limit"
+	AuditActionTransferCreate       = "transfer.create"
+	AuditActionTransferHold         = "transfer.hold"
+	AuditActionTransferBlock        = "transfer.block"
+	AuditActionCurrencyEnable       = "currency.enable"
+	AuditActionCurrencyDisable      = "currency.disable"
+	AuditActionProfileSubmit        = "profile.submit"
+	AuditActionProfileReview        = "profile.review"
+	AuditActionScreeningCaseOpen    = "screening_case.open"
+	AuditActionScreeningCaseResolve = "screening_case.resolve"
+	AuditActionFraudRuleUpdate      = "fraud_rule.update"
+	AuditActionFraudDecisionReview  = "fraud_decision.review"
+	AuditActionWebhookCreate        = "webhook_endpoint.create"
+	AuditActionWebhookDelete        = "webhook_endpoint.delete"
+	AuditActionWebhookRedeliver     = "webhook_delivery.redeliver"
+	auditRedacted                   = "[REDACTED]"
+)
+
+// accountStatusActions names the audit action of a move to each status.
+var accountStatusActions = map[string]string{
+	AccountStatusActive: AuditActionAccountUnfreeze,
+	AccountStatusFrozen: AuditActionAccountFreeze,
+	AccountStatusClosed: AuditActionAccountClose,
+}
+
+// auditRedactedFields are the JSON fields whose values never reach the audit
+// log, at any depth of a snapshot.
+var auditRedactedFields = []string{"hashed_password", "password", "totp_secret", "secret"}
+
+// AuditMeta describes the API call a change is made for.
+type AuditMeta struct {
+	// Actor is the authenticated username, empty for calls without a token.
+	Actor     string
+	RequestID string
+	IP        string
+	UserAgent string
+}
+
+type auditMetaKey struct{}
+
+// WithAuditMeta returns a context whose changes are recorded in the audit log
+// as made by the call.
+func WithAuditMeta(ctx context.Context, meta AuditMeta) context.Context {
+	return context.WithValue(ctx, auditMetaKey{}, meta)
+}
+
+// AuditMetaFrom returns the call a context was made for by WithAuditMeta.
+func AuditMetaFrom(ctx context.Context) (AuditMeta, bool) {
+	meta, ok := ctx.Value(auditMetaKey{}).(AuditMeta)
+	return meta, ok
+}
+
+// audit is the hook through which the store records a change in the audit
+// log. It writes with q, so the event is committed with the change or not at
+// all. Changes without AuditMeta, such as those made by the workers, are not
+// API calls and are not recorded, unless the worker supplies its own. A nil
+// before means the resource was created, a nil after that it was deleted.
+func audit(ctx context.Context, q *Queries, action, resourceType string, resourceID any, before, after any) error {
+	meta, ok := AuditMetaFrom(ctx)
+	if !ok {
+		return nil
+	}
+
+	beforeJSON, err := redactedJSON(before)
+	if err != nil {
+		return fmt.Errorf("cannot audit %s: %w", action, err)
+	}
+	afterJSON, err := redactedJSON(after)
+	if err != nil {
+		return fmt.Errorf("cannot audit %s: %w", action, err)
+	}
+
+	_, err = q.CreateAuditEvent(ctx, CreateAuditEventParams{
+		Actor:        pgtype.Text{String: meta.Actor, Valid: meta.Actor != ""},
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   fmt.Sprint(resourceID),
+		Before:       beforeJSON,
+		After:        afterJSON,
+		RequestID:    meta.RequestID,
+		Ip:           meta.IP,
+		UserAgent:    meta.UserAgent,
+	})
+	return err
+}
+
+// redactedJSON encodes a snapshot with the values of auditRedactedFields
+// replaced. A nil snapshot stays nil.
+func redactedJSON(v any) ([]byte, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var doc any
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(redact(doc))
+}
+
+func redact(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if slices.Contains(auditRedactedFields, key) {
+				v[key] = auditRedacted
+				continue
+			}
+			v[key] = redact(value)
+		}
+	case []any:
+		for i, value := range v {
+			v[i] = redact(value)
+		}
+	}
+	return v
+}
+
+// The queries below change state on their own when the API calls them, so
+// the store runs each in a transaction with its audit event and, when there
+// is one, its outbox event.
+
+func (s *SQLStore) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
+	result, err := s.CreateUserTx(ctx, CreateUserTxParams{CreateUserParams: arg})
+	return result.User, err
+}
+
+func (s *SQLStore) CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error) {
+	var account Account
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		// serializes with blockUser, an account opened meanwhile is frozen
+		if err := q.LockUserForUpdate(ctx, arg.Owner); err != nil {
+			return err
+		}
+		owner, err := q.GetUser(ctx, arg.Owner)
+		if err == nil && owner.BlockedAt.Valid {
+			return fmt.Errorf("user %s: %w", owner.Username, ErrUserBlocked)
+		}
+		// an unknown owner fails on the foreign key of the account
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+
+		account, err = q.CreateAccount(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		if err = enqueueAccountEvent(ctx, q, event.TypeAccountCreated, account); err != nil {
+			return err
+		}
+		return audit(ctx, q, AuditActionAccountCreate, "account", account.ID, nil, account)
+	})
+
+	return account, err
+}
+
+func (s *SQLStore) SetUserTOTPSecret(ctx context.Context, arg SetUserTOTPSecretParams) (User, error) {
+	var user User
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		before, err := q.GetUser(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+
+		user, err = q.SetUserTOTPSecret(ctx, arg)
+		if err != nil {
+			return err
+		}
+		return audit(ctx, q, AuditActionUserEnrollTOTP, "user", user.Username, before, user)
+	})
+
+	return user, err
+}
+
+func (s *SQLStore) EnableUserTOTP(ctx context.Context, arg EnableUserTOTPParams) (User, error) {
+	var user User
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		before, err := q.GetUser(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+
+		user, err = q.EnableUserTOTP(ctx, arg)
+		if err != nil {
+			return err
+		}
+		return audit(ctx, q, AuditActionUserEnableTOTP, "user", user.Username, before, user)
+	})
+
+	return user, err
+}
+
+func (s *SQLStore) SubmitCustomerProfile(ctx context.Context, arg SubmitCustomerProfileParams) (CustomerProfile, error) {
+	var profile CustomerProfile
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		before, err := optional(q.GetCustomerProfile(ctx, arg.Username))
+		if err != nil {
+			return err
+		}
+
+		profile, err = q.SubmitCustomerProfile(ctx, arg)
+		if err != nil {
+			return err
+		}
+		return audit(ctx, q, AuditActionProfileSubmit, "profile", profile.Username, before, profile)
+	})
+
+	return profile, err
+}
+
+func (s *SQLStore) ReviewCustomerProfile(ctx context.Context, arg ReviewCustomerProfileParams) (CustomerProfile, error) {
+	var profile CustomerProfile
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		before, err := optional(q.GetCustomerProfile(ctx, arg.Username))
+		if err != nil {
+			return err
+		}
+
+		profile, err = q.ReviewCustomerProfile(ctx, arg)
+		if err != nil {
+			return err
+		}
+		return audit(ctx, q, AuditActionProfileReview, "profile", profile.Username, before, profile)
+	})
+
+	return profile, err
+}
+
+func (s *SQLStore) UpsertFraudRule(ctx context.Context, arg UpsertFraudRuleParams) (FraudRule, error) {
+	var rule FraudRule
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		before, err := optional(q.GetFraudRule(ctx, arg.Name))
+		if err != nil {
+			return err
+		}
+
+		rule, err = q.UpsertFraudRule(ctx, arg)
+		if err != nil {
+			return err
+		}
+		return audit(ctx, q, AuditActionFraudRuleUpdate, "fraud_rule", rule.Name, before, rule)
+	})
+
+	return rule, err
+}
+
+func (s *SQLStore) CreateWebhookEndpoint(ctx context.Context, arg CreateWebhookEndpointParams) (WebhookEndpoint, error) {
+	var endpoint WebhookEndpoint
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		var err error
+		endpoint, err = q.CreateWebhookEndpoint(ctx, arg)
+		if err != nil {
+			return err
+		}
+		return audit(ctx, q, AuditActionWebhookCreate, "webhook_endpoint", endpoint.ID, nil, endpoint)
+	})
+
+	return endpoint, err
+}
+
+func (s *SQLStore) DeleteWebhookEndpoint(ctx context.Context, id int64) error {
+	return s.execTx(ctx, func(q *Queries) error {
+		before, err := optional(q.GetWebhookEndpoint(ctx, id))
+		if err != nil {
+			return err
+		}
+		// nothing was deleted
+		if before == nil {
+			return nil
+		}
+
+		if err := q.DeleteWebhookEndpoint(ctx, id); err != nil {
+			return err
+		}
+		return audit(ctx, q, AuditActionWebhookDelete, "webhook_endpoint", id, before, nil)
+	})
+}
+
+func (s *SQLStore) RedeliverWebhookDelivery(ctx context.Context, id int64) (WebhookDelivery, error) {
+	var delivery WebhookDelivery
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		before, err := q.GetWebhookDelivery(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		delivery, err = q.RedeliverWebhookDelivery(ctx, id)
+		if err != nil {
+			return err
+		}
+		return audit(ctx, q, AuditActionWebhookRedeliver, "webhook_delivery", delivery.ID, before, delivery)
+	})
+
+	return delivery, err
+}
+
+// optional returns the row of a query as the before snapshot of a change,
+// nil when there is no row yet.
+func optional[T any](row T, err error) (any, error) {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return row, nil
+}
@@ -0,0 +1,244 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"github.com/vlone310/bss/testutil"
+	"github.com/vlone310/bss/util"
+)
+
+func auditContext(actor string) (context.Context, AuditMeta) {
+	meta := AuditMeta{
+		Actor:     actor,
+		RequestID: testutil.RandomString(16),
+		IP:        "192.0.2.1",
+		UserAgent: "bss-test/1.0",
+	}
+	return WithAuditMeta(context.Background(), meta), meta
+}
+
+func listRequestAuditEvents(t *testing.T, requestID string) []AuditEvent {
+	t.Helper()
+
+	events, err := testStore.ListAuditEvents(context.Background(), ListAuditEventsParams{
+		RequestID:  pgtype.Text{String: requestID, Valid: true},
+		LimitCount: 10,
+	})
+	require.NoError(t, err)
+	return events
+}
+
+func TestAuditCreateUser(t *testing.T) {
+	hashedPassword, err := util.HashPassword(testutil.RandomString(6))
+	require.NoError(t, err)
+
+	ctx, meta := auditContext("")
+	user, err := testStore.CreateUser(ctx, CreateUserParams{
+		Username:       testutil.RandomOwner(),
+		HashedPassword: hashedPassword,
+		FullName:       testutil.RandomOwner(),
+		Email:          testutil.RandomEmail(),
+	})
+	require.NoError(t, err)
+
+	events := listRequestAuditEvents(t, meta.RequestID)
+	require.Len(t, events, 1)
+	require.Equal(t, AuditActionUserCreate, events[0].Action)
+	require.Equal(t, "user", events[0].ResourceType)
+	require.Equal(t, user.Username, events[0].ResourceID)
+	require.False(t, events[0].Actor.Valid)
+	require.Nil(t, events[0].Before)
+	require.Equal(t, meta.IP, events[0].Ip)
+	require.Equal(t, meta.UserAgent, events[0].UserAgent)
+
+	var after map[string]any
+	require.NoError(t, json.Unmarshal(events[0].After, &after))
+	require.Equal(t, user.Email, after["email"])
+	require.Equal(t, auditRedacted, after["hashed_password"])
+	require.NotContains(t, string(events[0].After), hashedPassword)
+}
+
+func TestAuditCreateAccount(t *testing.T) {
+	user := createVerifiedUser(t)
+
+	ctx, meta := auditContext(user.Username)
+	account, err := testStore.CreateAccount(ctx, CreateAccountParams{
+		Owner:    user.Username,
+		Currency: testutil.RandomCurrency(),
+		Product:  ProductChecking,
+	})
+	require.NoError(t, err)
+
+	events := listRequestAuditEvents(t, meta.RequestID)
+	require.Len(t, events, 1)
+	require.Equal(t, AuditActionAccountCreate, events[0].Action)
+	require.Equal(t, fmt.Sprint(account.ID), events[0].ResourceID)
+	require.Equal(t, user.Username, events[0].Actor.String)
+	require.Nil(t, events[0].Before)
+}
+
+func TestAuditChangeAccountStatus(t *testing.T) {
+	admin := createRandomUser(t)
+	account := createRandomAccount(t)
+
+	ctx, meta := auditContext(admin.Username)
+	_, err := testStore.ChangeAccountStatusTx(ctx, ChangeAccountStatusTxParams{
+		AccountID: account.ID,
+		Status:    AccountStatusFrozen,
+		Reason:    "chargeback",
+	})
+	require.NoError(t, err)
+
+	events := listRequestAuditEvents(t, meta.RequestID)
+	require.Len(t, events, 1)
+	require.Equal(t, AuditActionAccountFreeze, events[0].Action)
+	require.Equal(t, admin.Username, events[0].Actor.String)
+
+	var before, after Account
+	require.NoError(t, json.Unmarshal(events[0].Before, &before))
+	require.NoError(t, json.Unmarshal(events[0].After, &after))
+	require.Equal(t, AccountStatusActive, before.Status)
+	require.Equal(t, AccountStatusFrozen, after.Status)
+
+	// a failed change leaves no event
+	ctx, meta = auditContext(admin.Username)
+	_, err = testStore.ChangeAccountStatusTx(ctx, ChangeAccountStatusTxParams{
+		AccountID: account.ID,
+		Status:    AccountStatusClosed,
+	})
+	require.ErrorIs(t, err, ErrInvalidStatusTransition)
+	require.Empty(t, listRequestAuditEvents(t, meta.RequestID))
+}
+
+func TestAuditUserTOTP(t *testing.T) {
+	user := createRandomUser(t)
+	secret := "JBSWY3DPEHPK3PXP"
+
+	ctx, meta := auditContext(user.Username)
+	_, err := testStore.SetUserTOTPSecret(ctx, SetUserTOTPSecretParams{Username: user.Username, TotpSecret: secret})
+	require.NoError(t, err)
+
+	events := listRequestAuditEvents(t, meta.RequestID)
+	require.Len(t, events, 1)
+	require.Equal(t, AuditActionUserEnrollTOTP, events[0].Action)
+	require.Equal(t, user.Username, events[0].ResourceID)
+
+	var after map[string]any
+	require.NoError(t, json.Unmarshal(events[0].After, &after))
+	require.Equal(t, auditRedacted, after["totp_secret"])
+	require.NotContains(t, string(events[0].After), secret)
+
+	ctx, meta = auditContext(user.Username)
+	enabled, err := testStore.EnableUserTOTP(ctx, EnableUserTOTPParams{Username: user.Username, TotpSecret: secret, TotpLastStep: 10})
+	require.NoError(t, err)
+	require.True(t, enabled.TotpEnabledAt.Valid)
+
+	events = listRequestAuditEvents(t, meta.RequestID)
+	require.Len(t, events, 1)
+	require.Equal(t, AuditActionUserEnableTOTP, events[0].Action)
+	require.NotContains(t, string(events[0].Before), secret)
+	require.NotContains(t, string(events[0].After), secret)
+
+	// enabling it again fails and leaves no event
+	ctx, meta = auditContext(user.Username)
+	_, err = testStore.EnableUserTOTP(ctx, EnableUserTOTPParams{Username: user.Username, TotpSecret: secret, TotpLastStep: 11})
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+	require.Empty(t, listRequestAuditEvents(t, meta.RequestID))
+}
+
+func TestAuditWebhookEndpoint(t *testing.T) {
+	user := createRandomUser(t)
+	secret := testutil.RandomString(32)
+
+	ctx, meta := auditContext(user.Username)
+	endpoint, err := testStore.CreateWebhookEndpoint(ctx, CreateWebhookEndpointParams{
+		Owner:      user.Username,
+		Url:        "https://example.com/hooks",
+		Secret:     secret,
+		EventTypes: []string{},
+	})
+	require.NoError(t, err)
+
+	events := listRequestAuditEvents(t, meta.RequestID)
+	require.Len(t, events, 1)
+	require.Equal(t, AuditActionWebhookCreate, events[0].Action)
+	require.Equal(t, fmt.Sprint(endpoint.ID), events[0].ResourceID)
+	require.Nil(t, events[0].Before)
+
+	var after map[string]any
+	require.NoError(t, json.Unmarshal(events[0].After, &after))
+	require.Equal(t, endpoint.Url, after["url"])
+	require.Equal(t, auditRedacted, after["secret"])
+	require.NotContains(t, string(events[0].After), secret)
+
+	ctx, meta = auditContext(user.Username)
+	require.NoError(t, testStore.DeleteWebhookEndpoint(ctx, endpoint.ID))
+
+	events = listRequestAuditEvents(t, meta.RequestID)
+	require.Len(t, events, 1)
+	require.Equal(t, AuditActionWebhookDelete, events[0].Action)
+	require.NotContains(t, string(events[0].Before), secret)
+	require.Nil(t, events[0].After)
+
+	// deleting it again changes nothing and leaves no event
+	ctx, meta = auditContext(user.Username)
+	require.NoError(t, testStore.DeleteWebhookEndpoint(ctx, endpoint.ID))
+	require.Empty(t, listRequestAuditEvents(t, meta.RequestID))
+}
+
+func TestAuditWithoutMeta(t *testing.T) {
+	account := createRandomAccount(t)
+
+	events, err := testStore.ListAuditEvents(context.Background(), ListAuditEventsParams{
+		ResourceType: pgtype.Text{String: "account", Valid: true},
+		ResourceID:   pgtype.Text{String: fmt.Sprint(account.ID), Valid: true},
+		LimitCount:   10,
+	})
+	require.NoError(t, err)
+	require.Empty(t, events)
+}
+
+func TestAuditEventsAppendOnly(t *testing.T) {
+	user := createRandomUser(t)
+
+	ctx, meta := auditContext(user.Username)
+	_, err := testStore.CreateAccount(ctx, CreateAccountParams{
+		Owner:    user.Username,
+		Currency: testutil.RandomCurrency(),
+		Product:  ProductChecking,
+	})
+	require.NoError(t, err)
+	event := listRequestAuditEvents(t, meta.RequestID)[0]
+
+	pool := testStore.(*SQLStore).db
+
+	_, err = pool.Exec(context.Background(), "UPDATE audit_events SET actor = 'mallory' WHERE id = $1", event.ID)
+	var pgErr *pgconn.PgError
+	require.ErrorAs(t, err, &pgErr)
+	require.Equal(t, "23001", pgErr.Code)
+
+	_, err = pool.Exec(context.Background(), "DELETE FROM audit_events WHERE id = $1", event.ID)
+	require.ErrorAs(t, err, &pgErr)
+	require.Equal(t, "23001", pgErr.Code)
+}
+
+func TestRedactedJSON(t *testing.T) {
+	data, err := redactedJSON(map[string]any{
+		"username": "alice",
+		"users":    []any{map[string]any{"hashed_password": "$2a$10$abc"}},
+		"password": "secret",
+	})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"username":"alice","users":[{"hashed_password":"[REDACTED]"}],"password":"[REDACTED]"}`, string(data))
+
+	data, err = redactedJSON(nil)
+	require.NoError(t, err)
+	require.Nil(t, data)
+}
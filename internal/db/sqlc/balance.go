@@ -0,0 +1,51 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type SnapshotBalancesTxParams struct {
+	From    time.Time `json:"from"`
+	Through time.Time `json:"through"`
+	// Rebuild drops the snapshots from From on before taking them again,
+	// which is needed after entries were booked into a day already
+	// snapshotted.
+	Rebuild bool `json:"rebuild"`
+}
+
+type SnapshotBalancesTxResult struct {
+	Deleted int64 `json:"deleted"`
+	Created int64 `json:"created"`
+}
+
+// SnapshotBalancesTx stores the closing balance of every account for each
+// day in [From, Through]. The days are taken in order so that every snapshot
+// continues from the one of the day before.
+func (s *SQLStore) SnapshotBalancesTx(ctx context.Context, arg SnapshotBalancesTxParams) (SnapshotBalancesTxResult, error) {
+	var result SnapshotBalancesTxResult
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		if arg.Rebuild {
+			n, err := q.DeleteDailyBalancesFrom(ctx, pgtype.Date{Time: arg.From, Valid: true})
+			if err != nil {
+				return err
+			}
+			result.Deleted = n
+		}
+
+		for day := arg.From; !day.After(arg.Through); day = day.AddDate(0, 0, 1) {
+			n, err := q.CreateDailyBalances(ctx, pgtype.Date{Time: day, Valid: true})
+			if err != nil {
+				return err
+			}
+			result.Created += n
+		}
+
+		return nil
+	})
+
+	return result, err
+}
@@ -0,0 +1,75 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"github.com/vlone310/bss/internal/money"
+)
+
+func TestSnapshotBalancesTx(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createAccountInCurrency(t, account1.Currency)
+
+	_, err := testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        money.New(10, account1.Currency),
+	})
+	require.NoError(t, err)
+
+	y, m, d := time.Now().UTC().Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	tomorrow := today.AddDate(0, 0, 1)
+
+	result, err := testStore.SnapshotBalancesTx(context.Background(), SnapshotBalancesTxParams{
+		From:    today,
+		Through: today,
+		Rebuild: true,
+	})
+	require.NoError(t, err)
+	require.Positive(t, result.Created)
+
+	// before the day ended the snapshot is not used
+	now := time.Now()
+	balance, err := testStore.GetAccountBalanceAsOf(context.Background(), GetAccountBalanceAsOfParams{
+		AccountID: account1.ID,
+		AsOf:      pgtype.Timestamptz{Time: now, Valid: true},
+	})
+	require.NoError(t, err)
+	require.False(t, balance.SnapshotDate.Valid)
+	require.Equal(t, account1.Balance-10, balance.Balance)
+
+	balance, err = testStore.GetAccountBalanceAsOf(context.Background(), GetAccountBalanceAsOfParams{
+		AccountID: account1.ID,
+		AsOf:      pgtype.Timestamptz{Time: tomorrow.Add(time.Hour), Valid: true},
+	})
+	require.NoError(t, err)
+	require.True(t, balance.SnapshotDate.Valid)
+	require.True(t, today.Equal(balance.SnapshotDate.Time))
+	require.Equal(t, account1.Balance-10, balance.Balance)
+
+	// nothing before the account existed
+	balance, err = testStore.GetAccountBalanceAsOf(context.Background(), GetAccountBalanceAsOfParams{
+		AccountID: account2.ID,
+		AsOf:      pgtype.Timestamptz{Time: today.AddDate(0, 0, -1), Valid: true},
+	})
+	require.NoError(t, err)
+	require.Zero(t, balance.Balance)
+
+	// a rerun keeps the existing snapshots, a rebuild replaces them
+	again, err := testStore.SnapshotBalancesTx(context.Background(), SnapshotBalancesTxParams{From: today, Through: today})
+	require.NoError(t, err)
+	require.Zero(t, again.Created)
+
+	rebuilt, err := testStore.SnapshotBalancesTx(context.Background(), SnapshotBalancesTxParams{
+		From:    today,
+		Through: today,
+		Rebuild: true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, rebuilt.Deleted, rebuilt.Created)
+}
@@ -0,0 +1,90 @@
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// WalkEntryChain calls fn for every entry in chain order, that is by account
+// and then by id, optionally for a single account. The entries are streamed
+// from a single snapshot.
+func (s *SQLStore) WalkEntryChain(ctx context.Context, accountID pgtype.Int8, fn func(Entry) error) error {
+	return s.readTx(ctx, func(q *Queries) error {
+		rows, err := q.db.Query(ctx, listEntryChain, accountID)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var entry Entry
+			if err := rows.Scan(
+				&entry.ID,
+				&entry.AccountID,
+				&entry.AmountCents,
+				&entry.CreatedAt,
+				&entry.TransferID,
+				&entry.JournalID,
+				&entry.PrevHash,
+				&entry.Hash,
+			); err != nil {
+				return err
+			}
+
+			if err := fn(entry); err != nil {
+				return err
+			}
+		}
+
+		return rows.Err()
+	})
+}
+
+type CreateLedgerCheckpointTxParams struct {
+	KeyID string `json:"key_id"`
+	// Sign returns the root hash over the chain heads and its signature.
+	Sign func(heads []ListEntryChainHeadsRow) (root []byte, signature []byte, err error) `json:"-"`
+}
+
+// CreateLedgerCheckpointTx records the current head of every account's entry
+// chain together with a signature over all of them.
+func (s *SQLStore) CreateLedgerCheckpointTx(ctx context.Context, arg CreateLedgerCheckpointTxParams) (LedgerCheckpoint, error) {
+	var result LedgerCheckpoint
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		heads, err := q.ListEntryChainHeads(ctx)
+		if err != nil {
+			return err
+		}
+
+		root, signature, err := arg.Sign(heads)
+		if err != nil {
+			return err
+		}
+
+		result, err = q.CreateLedgerCheckpoint(ctx, CreateLedgerCheckpointParams{
+			RootHash:  root,
+			Signature: signature,
+			KeyID:     arg.KeyID,
+		})
+		if err != nil {
+			return err
+		}
+
+		rows := make([]CreateLedgerCheckpointHeadsParams, 0, len(heads))
+		for _, head := range heads {
+			rows = append(rows, CreateLedgerCheckpointHeadsParams{
+				CheckpointID: result.ID,
+				AccountID:    head.AccountID,
+				EntryID:      head.EntryID,
+				Hash:         head.Hash,
+			})
+		}
+
+		_, err = q.CreateLedgerCheckpointHeads(ctx, rows)
+		return err
+	})
+
+	return result, err
+}
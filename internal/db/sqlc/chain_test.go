@@ -0,0 +1,80 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"github.com/vlone310/bss/internal/money"
+)
+
+func TestEntryChainLinks(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createAccountInCurrency(t, account1.Currency)
+
+	for i := 0; i < 3; i++ {
+		_, err := testStore.TransferTx(context.Background(), TransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        money.New(10, account1.Currency),
+		})
+		require.NoError(t, err)
+	}
+
+	var entries []Entry
+	err := testStore.WalkEntryChain(context.Background(), pgtype.Int8{Int64: account1.ID, Valid: true}, func(entry Entry) error {
+		entries = append(entries, entry)
+		return nil
+	})
+	require.NoError(t, err)
+	// the funding entry and three transfers
+	require.Len(t, entries, 4)
+
+	require.Equal(t, make([]byte, 32), entries[0].PrevHash)
+	for i := 1; i < len(entries); i++ {
+		require.Equal(t, entries[i-1].Hash, entries[i].PrevHash)
+		require.Len(t, entries[i].Hash, 32)
+		require.False(t, bytes.Equal(entries[i].Hash, entries[i-1].Hash))
+	}
+}
+
+func TestEntriesAreAppendOnly(t *testing.T) {
+	account := createRandomAccount(t)
+	pool := testStore.(*SQLStore).db
+
+	_, err := pool.Exec(context.Background(), "UPDATE entries SET amount_cents = amount_cents + 1 WHERE account_id = $1", account.ID)
+	require.Error(t, err)
+
+	_, err = pool.Exec(context.Background(), "DELETE FROM entries WHERE account_id = $1", account.ID)
+	require.Error(t, err)
+}
+
+func TestCreateLedgerCheckpointTx(t *testing.T) {
+	account := createRandomAccount(t)
+
+	var signed []ListEntryChainHeadsRow
+	checkpoint, err := testStore.CreateLedgerCheckpointTx(context.Background(), CreateLedgerCheckpointTxParams{
+		KeyID: "test",
+		Sign: func(heads []ListEntryChainHeadsRow) ([]byte, []byte, error) {
+			signed = heads
+			return []byte("root"), []byte("signature"), nil
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "test", checkpoint.KeyID)
+	require.Equal(t, []byte("root"), checkpoint.RootHash)
+
+	heads, err := testStore.ListLedgerCheckpointHeads(context.Background(), checkpoint.ID)
+	require.NoError(t, err)
+	require.Len(t, heads, len(signed))
+
+	var found bool
+	for _, head := range heads {
+		if head.AccountID == account.ID {
+			found = true
+		}
+	}
+	require.True(t, found)
+}
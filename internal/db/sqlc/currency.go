@@ -0,0 +1,81 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type SetCurrencyEnabledTxParams struct {
+	Code    string `json:"code"`
+	Enabled bool   `json:"enabled"`
+}
+
+type SetCurrencyEnabledTxResult struct {
+	Currency Currency `json:"currency"`
+	// HouseAccounts lists the house accounts opened for the currency.
+	HouseAccounts []HouseAccount `json:"house_accounts"`
+}
+
+// SetCurrencyEnabledTx enables or disables a currency for new accounts.
+// Enabling it also opens any house account the currency is missing, so that
+// it can be funded and pay interest like the others.
+func (s *SQLStore) SetCurrencyEnabledTx(ctx context.Context, arg SetCurrencyEnabledTxParams) (SetCurrencyEnabledTxResult, error) {
+	result := SetCurrencyEnabledTxResult{HouseAccounts: []HouseAccount{}}
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		before, err := q.GetCurrency(ctx, arg.Code)
+		if err != nil {
+			return err
+		}
+
+		result.Currency, err = q.SetCurrencyEnabled(ctx, SetCurrencyEnabledParams{
+			Code:    arg.Code,
+			Enabled: arg.Enabled,
+		})
+		if err != nil {
+			return err
+		}
+
+		if !arg.Enabled {
+			return audit(ctx, q, AuditActionCurrencyDisable, "currency", arg.Code, before, result.Currency)
+		}
+		if err = audit(ctx, q, AuditActionCurrencyEnable, "currency", arg.Code, before, result.Currency); err != nil {
+			return err
+		}
+
+		for _, purpose := range HousePurposes {
+			_, err := q.GetHouseAccount(ctx, GetHouseAccountParams{Purpose: purpose, Currency: arg.Code})
+			if err == nil {
+				continue
+			}
+			if !errors.Is(err, pgx.ErrNoRows) {
+				return err
+			}
+
+			account, err := q.CreateAccount(ctx, CreateAccountParams{
+				Owner:    HouseOwner,
+				Currency: arg.Code,
+				Product:  ProductHouse,
+			})
+			if err != nil {
+				return err
+			}
+
+			house, err := q.CreateHouseAccount(ctx, CreateHouseAccountParams{
+				Purpose:   purpose,
+				Currency:  arg.Code,
+				AccountID: account.ID,
+			})
+			if err != nil {
+				return err
+			}
+			result.HouseAccounts = append(result.HouseAccounts, house)
+		}
+
+		return nil
+	})
+
+	return result, err
+}
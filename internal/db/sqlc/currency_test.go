@@ -0,0 +1,36 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetCurrencyEnabledTx(t *testing.T) {
+	result, err := testStore.SetCurrencyEnabledTx(context.Background(), SetCurrencyEnabledTxParams{Code: "KWD", Enabled: true})
+	require.NoError(t, err)
+	require.True(t, result.Currency.Enabled)
+	require.Equal(t, int32(3), result.Currency.MinorUnits)
+	require.Len(t, result.HouseAccounts, len(HousePurposes))
+
+	for _, purpose := range HousePurposes {
+		house, err := testStore.GetHouseAccount(context.Background(), GetHouseAccountParams{Purpose: purpose, Currency: "KWD"})
+		require.NoError(t, err)
+		require.Equal(t, HouseOwner, house.Owner)
+		require.Equal(t, ProductHouse, house.Product)
+	}
+
+	// enabling again opens no second set of house accounts
+	again, err := testStore.SetCurrencyEnabledTx(context.Background(), SetCurrencyEnabledTxParams{Code: "KWD", Enabled: true})
+	require.NoError(t, err)
+	require.Empty(t, again.HouseAccounts)
+
+	disabled, err := testStore.SetCurrencyEnabledTx(context.Background(), SetCurrencyEnabledTxParams{Code: "KWD", Enabled: false})
+	require.NoError(t, err)
+	require.False(t, disabled.Currency.Enabled)
+
+	_, err = testStore.SetCurrencyEnabledTx(context.Background(), SetCurrencyEnabledTxParams{Code: "XXX", Enabled: true})
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
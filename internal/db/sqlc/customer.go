@@ -0,0 +1,73 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	KYCStatusPending  = "pending"
+	KYCStatusVerified = "verified"
+	KYCStatusRejected = "rejected"
+)
+
+var ErrKYCRequired = errors.New("customer identity is not verified")
+var ErrUnverifiedBalanceLimit = errors.New("balance limit for unverified customers reached")
+
+// kycStatus returns the KYC status of a user. Users who never submitted a
+// profile are reported as pending, unless they were created before KYC.
+func kycStatus(ctx context.Context, q *Queries, username string) (string, error) {
+	status, err := q.GetKYCStatus(ctx, username)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return KYCStatusPending, nil
+	}
+	return status, err
+}
+
+// checkKYC applies the policies for unverified customers to a transfer:
+// they cannot send money, and they cannot hold more than the
+// unverified_max_balance of the currency across all their accounts. The
+// sender learns that the recipient's limit is reached, never its balance.
+func checkKYC(ctx context.Context, q *Queries, from Account, to Account, amountCents int64) error {
+	status, err := kycStatus(ctx, q, from.Owner)
+	if err != nil {
+		return err
+	}
+	if status != KYCStatusVerified {
+		return fmt.Errorf("account [%d] owner is %s: %w", from.ID, status, ErrKYCRequired)
+	}
+
+	status, err = kycStatus(ctx, q, to.Owner)
+	if err != nil {
+		return err
+	}
+	if status == KYCStatusVerified {
+		return nil
+	}
+
+	currency, err := q.GetCurrency(ctx, to.Currency)
+	if err != nil {
+		return err
+	}
+	if !currency.UnverifiedMaxBalance.Valid {
+		return nil
+	}
+
+	// only the two accounts of the transfer are locked, a concurrent
+	// transfer to another account of the owner must wait for this one
+	if err := q.LockUserForUpdate(ctx, to.Owner); err != nil {
+		return err
+	}
+	balance, err := q.SumOwnerBalances(ctx, SumOwnerBalancesParams{Owner: to.Owner, Currency: to.Currency})
+	if err != nil {
+		return err
+	}
+	if balance+amountCents > currency.UnverifiedMaxBalance.Int64 {
+		return ErrUnverifiedBalanceLimit
+	}
+
+	return nil
+}
@@ -0,0 +1,199 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"github.com/vlone310/bss/internal/money"
+	"github.com/vlone310/bss/testutil"
+)
+
+const countryGermany = 276
+
+func submitRandomProfile(t *testing.T, username string) CustomerProfile {
+	t.Helper()
+
+	arg := SubmitCustomerProfileParams{
+		Username:     username,
+		DateOfBirth:  pgtype.Date{Time: time.Date(1990, 5, 17, 0, 0, 0, 0, time.UTC), Valid: true},
+		AddressLine1: testutil.RandomString(12),
+		City:         testutil.RandomString(8),
+		PostalCode:   "10115",
+		CountryCode:  countryGermany,
+		Phone:        "+4915112345678",
+	}
+
+	profile, err := testStore.SubmitCustomerProfile(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Username, profile.Username)
+	require.Equal(t, arg.DateOfBirth.Time, profile.DateOfBirth.Time)
+	require.Equal(t, arg.CountryCode, profile.CountryCode)
+	require.Equal(t, KYCStatusPending, profile.KycStatus)
+	require.False(t, profile.ReviewedAt.Valid)
+
+	return profile
+}
+
+// createVerifiedUser creates a user whose identity has been verified, so
+// that their accounts are not held back by the unverified policies.
+func createVerifiedUser(t *testing.T) User {
+	t.Helper()
+
+	user := createRandomUser(t)
+	submitRandomProfile(t, user.Username)
+
+	profile, err := testStore.ReviewCustomerProfile(context.Background(), ReviewCustomerProfileParams{
+		Username:  user.Username,
+		KycStatus: KYCStatusVerified,
+	})
+	require.NoError(t, err)
+	require.Equal(t, KYCStatusVerified, profile.KycStatus)
+
+	return user
+}
+
+func TestSubmitCustomerProfile(t *testing.T) {
+	user := createRandomUser(t)
+	reviewer := createRandomUser(t)
+	submitRandomProfile(t, user.Username)
+
+	rejected, err := testStore.ReviewCustomerProfile(context.Background(), ReviewCustomerProfileParams{
+		Username:   user.Username,
+		KycStatus:  KYCStatusRejected,
+		KycReason:  pgtype.Text{String: "document expired", Valid: true},
+		ReviewedBy: pgtype.Text{String: reviewer.Username, Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, KYCStatusRejected, rejected.KycStatus)
+	require.Equal(t, "document expired", rejected.KycReason.String)
+	require.Equal(t, reviewer.Username, rejected.ReviewedBy.String)
+	require.True(t, rejected.ReviewedAt.Valid)
+
+	// a decision is final until the customer submits again
+	_, err = testStore.ReviewCustomerProfile(context.Background(), ReviewCustomerProfileParams{
+		Username:  user.Username,
+		KycStatus: KYCStatusVerified,
+	})
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	resubmitted := submitRandomProfile(t, user.Username)
+	require.False(t, resubmitted.KycReason.Valid)
+	require.False(t, resubmitted.ReviewedBy.Valid)
+
+	pending, err := testStore.ListCustomerProfiles(context.Background(), ListCustomerProfilesParams{
+		KycStatus:        KYCStatusPending,
+		AfterSubmittedAt: pgtype.Timestamptz{InfinityModifier: pgtype.NegativeInfinity, Valid: true},
+		LimitCount:       1000,
+	})
+	require.NoError(t, err)
+
+	found := false
+	for _, profile := range pending {
+		if profile.Username == user.Username {
+			found = true
+		}
+	}
+	require.True(t, found)
+}
+
+func TestSubmitCustomerProfileUnknownCountry(t *testing.T) {
+	user := createRandomUser(t)
+
+	_, err := testStore.SubmitCustomerProfile(context.Background(), SubmitCustomerProfileParams{
+		Username:     user.Username,
+		DateOfBirth:  pgtype.Date{Time: time.Date(1990, 5, 17, 0, 0, 0, 0, time.UTC), Valid: true},
+		AddressLine1: testutil.RandomString(12),
+		City:         testutil.RandomString(8),
+		PostalCode:   "10115",
+		CountryCode:  999,
+		Phone:        "+4915112345678",
+	})
+
+	var pgErr *pgconn.PgError
+	require.ErrorAs(t, err, &pgErr)
+	require.Equal(t, "23503", pgErr.Code)
+}
+
+func TestTransferTxUnverifiedSender(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createAccountInCurrency(t, account1.Currency)
+
+	user := createRandomUser(t)
+	unverified, err := testStore.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    user.Username,
+		Currency: account1.Currency,
+		Product:  ProductChecking,
+	})
+	require.NoError(t, err)
+	unverified = fundAccount(t, unverified, 1000)
+
+	_, err = testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: unverified.ID,
+		ToAccountID:   account2.ID,
+		Amount:        money.New(100, account1.Currency),
+	})
+	require.ErrorIs(t, err, ErrKYCRequired)
+
+	// receiving is allowed up to the limit of the currency
+	currency, err := testStore.GetCurrency(context.Background(), account1.Currency)
+	require.NoError(t, err)
+	require.True(t, currency.UnverifiedMaxBalance.Valid)
+
+	_, err = testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   unverified.ID,
+		Amount:        money.New(100, account1.Currency),
+	})
+	require.NoError(t, err)
+
+	_, err = testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   unverified.ID,
+		Amount:        money.New(currency.UnverifiedMaxBalance.Int64, account1.Currency),
+	})
+	// the sender is not told the balance of the recipient
+	require.EqualError(t, err, ErrUnverifiedBalanceLimit.Error())
+
+	// a second account of the same currency shares the limit
+	second, err := testStore.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    user.Username,
+		Currency: account1.Currency,
+		Product:  ProductSavings,
+	})
+	require.NoError(t, err)
+
+	_, err = testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   second.ID,
+		Amount:        money.New(currency.UnverifiedMaxBalance.Int64-99, account1.Currency),
+	})
+	require.ErrorIs(t, err, ErrUnverifiedBalanceLimit)
+}
+
+func TestKYCExemptUser(t *testing.T) {
+	user := createRandomUser(t)
+	require.False(t, user.KycExempt)
+
+	status, err := testStore.GetKYCStatus(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, KYCStatusPending, status)
+
+	// the users from before KYC are exempt
+	_, err = testStore.(*SQLStore).db.Exec(context.Background(), "UPDATE users SET kyc_exempt = true WHERE username = $1", user.Username)
+	require.NoError(t, err)
+
+	status, err = testStore.GetKYCStatus(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, KYCStatusVerified, status)
+
+	// a submitted profile is reviewed like any other
+	submitRandomProfile(t, user.Username)
+	status, err = testStore.GetKYCStatus(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, KYCStatusPending, status)
+}
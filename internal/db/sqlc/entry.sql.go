@@ -13,31 +13,42 @@ import (
 
 const createEntry = `-- name: CreateEntry :one
 INSERT INTO entries (
-  account_id, amount_cents
+  journal_id, account_id, amount_cents, transfer_id
 ) VALUES (
-  $1, $2
-) RETURNING id, account_id, amount_cents, created_at
+  $1, $2, $3, $4
+) RETURNING id, account_id, amount_cents, created_at, transfer_id, journal_id, prev_hash, hash
 `
 
 type CreateEntryParams struct {
-	AccountID   pgtype.Int8 `json:"account_id"`
+	JournalID   int64       `json:"journal_id"`
+	AccountID   int64       `json:"account_id"`
 	AmountCents int64       `json:"amount_cents"`
+	TransferID  pgtype.Int8 `json:"transfer_id"`
 }
 
 func (q *Queries) CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error) {
-	row := q.db.QueryRow(ctx, createEntry, arg.AccountID, arg.AmountCents)
+	row := q.db.QueryRow(ctx, createEntry,
+		arg.JournalID,
+		arg.AccountID,
+		arg.AmountCents,
+		arg.TransferID,
+	)
 	var i Entry
 	err := row.Scan(
 		&i.ID,
 		&i.AccountID,
 		&i.AmountCents,
 		&i.CreatedAt,
+		&i.TransferID,
+		&i.JournalID,
+		&i.PrevHash,
+		&i.Hash,
 	)
 	return i, err
 }
 
 const getEntry = `-- name: GetEntry :one
-SELECT id, account_id, amount_cents, created_at FROM entries WHERE id = $1 LIMIT 1
+SELECT id, account_id, amount_cents, created_at, transfer_id, journal_id, prev_hash, hash FROM entries WHERE id = $1 LIMIT 1
 `
 
 func (q *Queries) GetEntry(ctx context.Context, id int64) (Entry, error) {
@@ -48,12 +59,61 @@ func (q *Queries) GetEntry(ctx context.Context, id int64) (Entry, error) {
 		&i.AccountID,
 		&i.AmountCents,
 		&i.CreatedAt,
+		&i.TransferID,
+		&i.JournalID,
+		&i.PrevHash,
+		&i.Hash,
+	)
+	return i, err
+}
+
+const getStatementSummary = `-- name: GetStatementSummary :one
+SELECT
+  (a.balance - COALESCE(SUM(e.amount_cents), 0))::bigint AS opening_balance,
+  (a.balance - COALESCE(SUM(e.amount_cents) FILTER (WHERE e.created_at >= $1::timestamptz), 0))::bigint AS closing_balance,
+  (-COALESCE(SUM(e.amount_cents) FILTER (WHERE e.created_at < $1::timestamptz AND e.amount_cents < 0), 0))::bigint AS total_debits,
+  COALESCE(SUM(e.amount_cents) FILTER (WHERE e.created_at < $1::timestamptz AND e.amount_cents >= 0), 0)::bigint AS total_credits,
+  COUNT(e.id) FILTER (WHERE e.created_at < $1::timestamptz AND e.amount_cents < 0) AS debit_count,
+  COUNT(e.id) FILTER (WHERE e.created_at < $1::timestamptz AND e.amount_cents >= 0) AS credit_count
+FROM accounts a
+LEFT JOIN entries e ON e.account_id = a.id AND e.created_at >= $2::timestamptz
+WHERE a.id = $3
+GROUP BY a.id
+`
+
+type GetStatementSummaryParams struct {
+	ToTime    pgtype.Timestamptz `json:"to_time"`
+	FromTime  pgtype.Timestamptz `json:"from_time"`
+	AccountID int64              `json:"account_id"`
+}
+
+type GetStatementSummaryRow struct {
+	OpeningBalance int64 `json:"opening_balance"`
+	ClosingBalance int64 `json:"closing_balance"`
+	TotalDebits    int64 `json:"total_debits"`
+	TotalCredits   int64 `json:"total_credits"`
+	DebitCount     int64 `json:"debit_count"`
+	CreditCount    int64 `json:"credit_count"`
+}
+
+// Balances are derived backwards from the cached account balance so that
+// accounts opened with a non-zero balance still report correct figures.
+func (q *Queries) GetStatementSummary(ctx context.Context, arg GetStatementSummaryParams) (GetStatementSummaryRow, error) {
+	row := q.db.QueryRow(ctx, getStatementSummary, arg.ToTime, arg.FromTime, arg.AccountID)
+	var i GetStatementSummaryRow
+	err := row.Scan(
+		&i.OpeningBalance,
+		&i.ClosingBalance,
+		&i.TotalDebits,
+		&i.TotalCredits,
+		&i.DebitCount,
+		&i.CreditCount,
 	)
 	return i, err
 }
 
 const listEntries = `-- name: ListEntries :many
-SELECT id, account_id, amount_cents, created_at FROM entries
+SELECT id, account_id, amount_cents, created_at, transfer_id, journal_id, prev_hash, hash FROM entries
 WHERE account_id = $1
 ORDER BY id
 LIMIT $2
@@ -61,9 +121,9 @@ OFFSET $3
 `
 
 type ListEntriesParams struct {
-	AccountID pgtype.Int8 `json:"account_id"`
-	Limit     int32       `json:"limit"`
-	Offset    int32       `json:"offset"`
+	AccountID int64 `json:"account_id"`
+	Limit     int32 `json:"limit"`
+	Offset    int32 `json:"offset"`
 }
 
 func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error) {
@@ -80,6 +140,166 @@ func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Ent
 			&i.AccountID,
 			&i.AmountCents,
 			&i.CreatedAt,
+			&i.TransferID,
+			&i.JournalID,
+			&i.PrevHash,
+			&i.Hash,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEntriesAfter = `-- name: ListEntriesAfter :many
+SELECT id, account_id, amount_cents, created_at, transfer_id, journal_id, prev_hash, hash FROM entries
+WHERE account_id = $1
+  AND (created_at, id) > ($2::timestamptz, $3::bigint)
+ORDER BY created_at, id
+LIMIT $4
+`
+
+type ListEntriesAfterParams struct {
+	AccountID      int64              `json:"account_id"`
+	AfterCreatedAt pgtype.Timestamptz `json:"after_created_at"`
+	AfterID        int64              `json:"after_id"`
+	LimitCount     int32              `json:"limit_count"`
+}
+
+func (q *Queries) ListEntriesAfter(ctx context.Context, arg ListEntriesAfterParams) ([]Entry, error) {
+	rows, err := q.db.Query(ctx, listEntriesAfter,
+		arg.AccountID,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entry{}
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.AmountCents,
+			&i.CreatedAt,
+			&i.TransferID,
+			&i.JournalID,
+			&i.PrevHash,
+			&i.Hash,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEntriesAfterID = `-- name: ListEntriesAfterID :many
+SELECT id, account_id, amount_cents, created_at, transfer_id, journal_id, prev_hash, hash FROM entries
+WHERE account_id = $1
+  AND id > $2
+ORDER BY id
+LIMIT $3
+`
+
+type ListEntriesAfterIDParams struct {
+	AccountID  int64 `json:"account_id"`
+	AfterID    int64 `json:"after_id"`
+	LimitCount int32 `json:"limit_count"`
+}
+
+// The entries of an account are created under its row lock, so their ids
+// grow in the order they are committed.
+func (q *Queries) ListEntriesAfterID(ctx context.Context, arg ListEntriesAfterIDParams) ([]Entry, error) {
+	rows, err := q.db.Query(ctx, listEntriesAfterID, arg.AccountID, arg.AfterID, arg.LimitCount)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entry{}
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.AmountCents,
+			&i.CreatedAt,
+			&i.TransferID,
+			&i.JournalID,
+			&i.PrevHash,
+			&i.Hash,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listStatementEntries = `-- name: ListStatementEntries :many
+SELECT
+  e.id,
+  e.amount_cents,
+  e.created_at,
+  e.transfer_id,
+  c.id AS counterparty_account_id,
+  c.owner AS counterparty_owner
+FROM entries e
+LEFT JOIN transfers t ON t.id = e.transfer_id
+LEFT JOIN accounts c ON c.id = CASE
+  WHEN t.from_account_id = e.account_id THEN t.to_account_id
+  ELSE t.from_account_id
+END
+WHERE e.account_id = $1
+  AND e.created_at >= $2::timestamptz
+  AND e.created_at < $3::timestamptz
+ORDER BY e.created_at, e.id
+`
+
+type ListStatementEntriesParams struct {
+	AccountID int64              `json:"account_id"`
+	FromTime  pgtype.Timestamptz `json:"from_time"`
+	ToTime    pgtype.Timestamptz `json:"to_time"`
+}
+
+type ListStatementEntriesRow struct {
+	ID                    int64              `json:"id"`
+	AmountCents           int64              `json:"amount_cents"`
+	CreatedAt             pgtype.Timestamptz `json:"created_at"`
+	TransferID            pgtype.Int8        `json:"transfer_id"`
+	CounterpartyAccountID pgtype.Int8        `json:"counterparty_account_id"`
+	CounterpartyOwner     pgtype.Text        `json:"counterparty_owner"`
+}
+
+func (q *Queries) ListStatementEntries(ctx context.Context, arg ListStatementEntriesParams) ([]ListStatementEntriesRow, error) {
+	rows, err := q.db.Query(ctx, listStatementEntries, arg.AccountID, arg.FromTime, arg.ToTime)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListStatementEntriesRow{}
+	for rows.Next() {
+		var i ListStatementEntriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.AmountCents,
+			&i.CreatedAt,
+			&i.TransferID,
+			&i.CounterpartyAccountID,
+			&i.CounterpartyOwner,
 		); err != nil {
 			return nil, err
 		}
@@ -90,3 +310,13 @@ func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Ent
 	}
 	return items, nil
 }
+
+const notifyEntry = `-- name: NotifyEntry :exec
+SELECT pg_notify('account_entries', $1::text)
+`
+
+// Listeners get the notification once the transaction commits.
+func (q *Queries) NotifyEntry(ctx context.Context, payload string) error {
+	_, err := q.db.Exec(ctx, notifyEntry, payload)
+	return err
+}
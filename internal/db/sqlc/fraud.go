@@ -0,0 +1,176 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/vlone310/bss/internal/money"
+)
+
+const (
+	FraudActionAllow     = "allow"
+	FraudActionChallenge = "challenge"
+	FraudActionHold      = "hold"
+	FraudActionBlock     = "block"
+)
+
+const (
+	FraudReviewApproved = "approved"
+	FraudReviewRejected = "rejected"
+	// FraudReviewExpired fails a transfer nobody decided on in time.
+	FraudReviewExpired = "expired"
+)
+
+var ErrTransferBlocked = errors.New("transfer was declined")
+var ErrFraudDecisionNotReviewable = errors.New("fraud decision is not waiting for a review")
+
+// FraudRuleMatch is a fraud rule a transfer hit.
+type FraudRuleMatch struct {
+	Rule   string `json:"rule"`
+	Score  int32  `json:"score"`
+	Detail string `json:"detail"`
+}
+
+// FraudAssessment is the verdict of a FraudAssessor on a transfer.
+type FraudAssessment struct {
+	Score int32 `json:"score"`
+	// Action is one of the FraudAction constants.
+	Action string           `json:"action"`
+	Hits   []FraudRuleMatch `json:"hits"`
+}
+
+// FraudAssessor scores a transfer inside TransferTx, once it passed the
+// transfer rules and before it is booked. Both accounts are locked, so the
+// history it reads through q cannot change under it.
+type FraudAssessor interface {
+	AssessTransfer(ctx context.Context, q *Queries, from, to Account, amount money.Money) (FraudAssessment, error)
+}
+
+// SetFraudAssessor makes TransferTx score every transfer with the assessor.
+// It must be called before the store is used.
+func (s *SQLStore) SetFraudAssessor(assessor FraudAssessor) {
+	s.fraud = assessor
+}
+
+// assessTransfer books, holds or refuses a transfer by the verdict of the
+// assessor and records the decision with the rules it hit. A challenged
+// transfer waits as pending for the sender to confirm it, a held one for a
+// review. The caller must have locked both accounts.
+func assessTransfer(ctx context.Context, q *Queries, assessor FraudAssessor, accounts map[int64]Account, arg TransferTxParams) (TransferTxResult, error) {
+	from, to := accounts[arg.FromAccountID], accounts[arg.ToAccountID]
+
+	assessment, err := assessor.AssessTransfer(ctx, q, from, to, arg.Amount)
+	if err != nil {
+		return TransferTxResult{}, fmt.Errorf("cannot assess transfer: %w", err)
+	}
+
+	result := TransferTxResult{Amount: arg.Amount}
+	switch assessment.Action {
+	case FraudActionAllow:
+		result, err = bookTransfer(ctx, q, accounts, arg, JournalKindTransfer)
+	case FraudActionChallenge, FraudActionHold:
+		result.Transfer, err = q.CreatePendingTransfer(ctx, CreatePendingTransferParams{
+			FromAccountID: arg.FromAccountID,
+			ToAccountID:   arg.ToAccountID,
+			AmountCents:   arg.Amount.Amount,
+		})
+	case FraudActionBlock:
+		// only the decision is recorded
+	default:
+		err = fmt.Errorf("unknown fraud action %q", assessment.Action)
+	}
+	if err != nil {
+		return result, err
+	}
+
+	decision, err := q.CreateFraudDecision(ctx, CreateFraudDecisionParams{
+		FromAccountID: arg.FromAccountID,
+		ToAccountID:   arg.ToAccountID,
+		AmountCents:   arg.Amount.Amount,
+		TransferID:    pgtype.Int8{Int64: result.Transfer.ID, Valid: result.Transfer.ID != 0},
+		Score:         assessment.Score,
+		Action:        assessment.Action,
+	})
+	if err != nil {
+		return result, err
+	}
+	result.FraudDecision = &decision
+
+	for _, hit := range assessment.Hits {
+		_, err = q.CreateFraudRuleHit(ctx, CreateFraudRuleHitParams{
+			DecisionID: decision.ID,
+			RuleName:   hit.Rule,
+			Score:      hit.Score,
+			Detail:     hit.Detail,
+		})
+		if err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+type ReviewFraudDecisionTxParams struct {
+	DecisionID int64 `json:"decision_id"`
+	// Outcome is one of the FraudReview constants.
+	Outcome string `json:"outcome"`
+	// ReviewedBy is empty for a decision the server takes on its own.
+	ReviewedBy string `json:"reviewed_by"`
+}
+
+type ReviewFraudDecisionTxResult struct {
+	Decision FraudDecision `json:"decision"`
+	Transfer Transfer      `json:"transfer"`
+	// FailureReason tells why an approved transfer could not be booked.
+	FailureReason string `json:"failure_reason,omitempty"`
+}
+
+// ReviewFraudDecisionTx settles a challenged or held transfer. An approved
+// transfer is booked unless it no longer passes the transfer rules, a
+// rejected or expired one fails. The sender approves a challenge, a reviewer
+// a hold.
+func (s *SQLStore) ReviewFraudDecisionTx(ctx context.Context, arg ReviewFraudDecisionTxParams) (ReviewFraudDecisionTxResult, error) {
+	var result ReviewFraudDecisionTxResult
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		decision, err := q.GetFraudDecisionForUpdate(ctx, arg.DecisionID)
+		if err != nil {
+			return err
+		}
+		if decision.Action != FraudActionChallenge && decision.Action != FraudActionHold || decision.ReviewedAt.Valid {
+			return fmt.Errorf("decision [%d] to %s: %w", decision.ID, decision.Action, ErrFraudDecisionNotReviewable)
+		}
+
+		result.Decision, err = q.ReviewFraudDecision(ctx, ReviewFraudDecisionParams{
+			ID:            decision.ID,
+			ReviewOutcome: pgtype.Text{String: arg.Outcome, Valid: true},
+			ReviewedBy:    pgtype.Text{String: arg.ReviewedBy, Valid: arg.ReviewedBy != ""},
+		})
+		if err != nil {
+			return err
+		}
+
+		err = audit(ctx, q, AuditActionFraudDecisionReview, "fraud_decision", decision.ID, decision, result.Decision)
+		if err != nil {
+			return err
+		}
+
+		result.Transfer, err = q.GetTransferForUpdate(ctx, decision.TransferID.Int64)
+		if err != nil || result.Transfer.Status != TransferStatusPending {
+			return err
+		}
+
+		if arg.Outcome != FraudReviewApproved {
+			result.Transfer, err = settleTransfer(ctx, q, result.Transfer, TransferStatusFailed)
+			return err
+		}
+
+		result.Transfer, result.FailureReason, err = releaseTransfer(ctx, q, result.Transfer)
+		return err
+	})
+
+	return result, err
+}
@@ -0,0 +1,168 @@
+package db
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"github.com/vlone310/bss/internal/money"
+)
+
+// fixedAssessor gives every transfer the same verdict.
+type fixedAssessor FraudAssessment
+
+func (a fixedAssessor) AssessTransfer(context.Context, *Queries, Account, Account, money.Money) (FraudAssessment, error) {
+	return FraudAssessment(a), nil
+}
+
+// setFraudAssessor scores the transfers of the test with the assessor.
+func setFraudAssessor(t *testing.T, assessor FraudAssessor) {
+	t.Helper()
+
+	testStore.SetFraudAssessor(assessor)
+	t.Cleanup(func() { testStore.SetFraudAssessor(nil) })
+}
+
+func transferRandomScored(t *testing.T, action string, amount int64) (Account, Account, TransferTxResult, error) {
+	t.Helper()
+
+	setFraudAssessor(t, fixedAssessor{
+		Score:  50,
+		Action: action,
+		Hits:   []FraudRuleMatch{{Rule: "velocity", Score: 50, Detail: "6 transfers in the last 10m0s"}},
+	})
+
+	from := createRandomAccount(t)
+	to := createAccountInCurrency(t, from.Currency)
+
+	result, err := testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        money.New(amount, from.Currency),
+	})
+	return from, to, result, err
+}
+
+func TestTransferTxFraudHold(t *testing.T) {
+	reviewer := createRandomUser(t)
+	from, to, held, err := transferRandomScored(t, FraudActionHold, 10)
+	require.NoError(t, err)
+	require.Equal(t, TransferStatusPending, held.Transfer.Status)
+	require.NotNil(t, held.FraudDecision)
+	require.Equal(t, held.Transfer.ID, held.FraudDecision.TransferID.Int64)
+
+	hits, err := testStore.ListFraudRuleHits(context.Background(), held.FraudDecision.ID)
+	require.NoError(t, err)
+	require.Len(t, hits, 1)
+	require.Equal(t, "velocity", hits[0].RuleName)
+
+	// nothing is booked while the transfer is held
+	fromHeld, err := testStore.GetAccount(context.Background(), from.ID)
+	require.NoError(t, err)
+	require.Equal(t, from.Balance, fromHeld.Balance)
+
+	result, err := testStore.ReviewFraudDecisionTx(context.Background(), ReviewFraudDecisionTxParams{
+		DecisionID: held.FraudDecision.ID,
+		Outcome:    FraudReviewApproved,
+		ReviewedBy: reviewer.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, FraudReviewApproved, result.Decision.ReviewOutcome.String)
+	require.Equal(t, TransferStatusCompleted, result.Transfer.Status)
+	require.Empty(t, result.FailureReason)
+
+	toAfter, err := testStore.GetAccount(context.Background(), to.ID)
+	require.NoError(t, err)
+	require.Equal(t, to.Balance+10, toAfter.Balance)
+
+	// a decision is reviewed once
+	_, err = testStore.ReviewFraudDecisionTx(context.Background(), ReviewFraudDecisionTxParams{
+		DecisionID: held.FraudDecision.ID,
+		Outcome:    FraudReviewRejected,
+		ReviewedBy: reviewer.Username,
+	})
+	require.ErrorIs(t, err, ErrFraudDecisionNotReviewable)
+}
+
+func TestTransferTxFraudChallengeRejected(t *testing.T) {
+	from, _, challenged, err := transferRandomScored(t, FraudActionChallenge, 10)
+	require.NoError(t, err)
+	require.Equal(t, TransferStatusPending, challenged.Transfer.Status)
+
+	result, err := testStore.ReviewFraudDecisionTx(context.Background(), ReviewFraudDecisionTxParams{
+		DecisionID: challenged.FraudDecision.ID,
+		Outcome:    FraudReviewRejected,
+		ReviewedBy: from.Owner,
+	})
+	require.NoError(t, err)
+	require.Equal(t, TransferStatusFailed, result.Transfer.Status)
+
+	fromAfter, err := testStore.GetAccount(context.Background(), from.ID)
+	require.NoError(t, err)
+	require.Equal(t, from.Balance, fromAfter.Balance)
+}
+
+func TestTransferTxFraudBlock(t *testing.T) {
+	from, _, _, err := transferRandomScored(t, FraudActionBlock, 10)
+	require.ErrorIs(t, err, ErrTransferBlocked)
+
+	// the decision is kept for the reviewers
+	decisions, err := testStore.ListFraudDecisions(context.Background(), ListFraudDecisionsParams{
+		Action:          pgtype.Text{String: FraudActionBlock, Valid: true},
+		BeforeCreatedAt: pgtype.Timestamptz{InfinityModifier: pgtype.Infinity, Valid: true},
+		LimitCount:      100,
+	})
+	require.NoError(t, err)
+
+	var found bool
+	for _, decision := range decisions {
+		if decision.FromAccountID == from.ID {
+			found = true
+			require.False(t, decision.TransferID.Valid)
+		}
+	}
+	require.True(t, found)
+
+	fromAfter, err := testStore.GetAccount(context.Background(), from.ID)
+	require.NoError(t, err)
+	require.Equal(t, from.Balance, fromAfter.Balance)
+}
+
+func TestTransferTxFraudHoldExpired(t *testing.T) {
+	from, _, held, err := transferRandomScored(t, FraudActionHold, 10)
+	require.NoError(t, err)
+
+	stale, err := testStore.ListStaleFraudDecisions(context.Background(), ListStaleFraudDecisionsParams{
+		Action:        FraudActionHold,
+		CreatedBefore: pgtype.Timestamptz{Time: time.Now().Add(time.Minute), Valid: true},
+		LimitCount:    1000,
+	})
+	require.NoError(t, err)
+	require.True(t, slices.ContainsFunc(stale, func(d FraudDecision) bool { return d.ID == held.FraudDecision.ID }))
+
+	result, err := testStore.ReviewFraudDecisionTx(context.Background(), ReviewFraudDecisionTxParams{
+		DecisionID: held.FraudDecision.ID,
+		Outcome:    FraudReviewExpired,
+	})
+	require.NoError(t, err)
+	require.Equal(t, FraudReviewExpired, result.Decision.ReviewOutcome.String)
+	require.False(t, result.Decision.ReviewedBy.Valid)
+	require.Equal(t, TransferStatusFailed, result.Transfer.Status)
+
+	fromAfter, err := testStore.GetAccount(context.Background(), from.ID)
+	require.NoError(t, err)
+	require.Equal(t, from.Balance, fromAfter.Balance)
+}
+
+func TestAddFraudConfirmAttempt(t *testing.T) {
+	_, _, challenged, err := transferRandomScored(t, FraudActionChallenge, 10)
+	require.NoError(t, err)
+	require.Zero(t, challenged.FraudDecision.ConfirmAttempts)
+
+	decision, err := testStore.AddFraudConfirmAttempt(context.Background(), challenged.FraudDecision.ID)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), decision.ConfirmAttempts)
+}
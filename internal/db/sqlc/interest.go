@@ -0,0 +1,91 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/vlone310/bss/internal/money"
+)
+
+type PostInterestTxParams struct {
+	AccountID   int64     `json:"account_id"`
+	PeriodStart time.Time `json:"period_start"`
+	PeriodEnd   time.Time `json:"period_end"`
+}
+
+type PostInterestTxResult struct {
+	AmountCents int64     `json:"amount_cents"`
+	Transfer    *Transfer `json:"transfer"`
+}
+
+// PostInterestTx pays out the interest accrued on the account in
+// [PeriodStart, PeriodEnd) from the house interest-expense account of the
+// same currency. The accruals are summed first and rounded once, so the
+// posted amount does not depend on how the days were grouped into runs. A
+// period that was already posted yields a zero amount and no transfer.
+func (s *SQLStore) PostInterestTx(ctx context.Context, arg PostInterestTxParams) (PostInterestTxResult, error) {
+	var result PostInterestTxResult
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		from := pgtype.Date{Time: arg.PeriodStart, Valid: true}
+		to := pgtype.Date{Time: arg.PeriodEnd, Valid: true}
+
+		amounts, err := q.MarkInterestAccrualsPosted(ctx, MarkInterestAccrualsPostedParams{
+			AccountID: arg.AccountID,
+			FromDate:  from,
+			ToDate:    to,
+		})
+		if err != nil {
+			return err
+		}
+
+		var micros int64
+		for _, amount := range amounts {
+			micros += amount
+		}
+
+		result.AmountCents = money.RoundMicros(micros)
+		if result.AmountCents <= 0 {
+			return nil
+		}
+
+		account, err := q.GetAccount(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		house, err := q.GetHouseAccount(ctx, GetHouseAccountParams{
+			Purpose:  HousePurposeInterestExpense,
+			Currency: account.Currency,
+		})
+		if err != nil {
+			return fmt.Errorf("no interest expense account for %s: %w", account.Currency, err)
+		}
+
+		accounts, err := lockActiveAccounts(ctx, q, house.ID, account.ID)
+		if err != nil {
+			return err
+		}
+
+		transfer, err := bookTransfer(ctx, q, accounts, TransferTxParams{
+			FromAccountID: house.ID,
+			ToAccountID:   account.ID,
+			Amount:        money.New(result.AmountCents, account.Currency),
+		}, JournalKindInterest)
+		if err != nil {
+			return err
+		}
+		result.Transfer = &transfer.Transfer
+
+		return q.SetInterestAccrualsTransfer(ctx, SetInterestAccrualsTransferParams{
+			TransferID: pgtype.Int8{Int64: transfer.Transfer.ID, Valid: true},
+			AccountID:  arg.AccountID,
+			FromDate:   from,
+			ToDate:     to,
+		})
+	})
+
+	return result, err
+}
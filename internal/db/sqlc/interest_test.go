@@ -0,0 +1,67 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostInterestTx(t *testing.T) {
+	account := createProductAccount(t, ProductSavings, 100_000)
+	start := time.Date(2000, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	// 31 days of half a cent are summed before rounding, so 15.5 cents are
+	// paid as 16 while rounding every day on its own would pay nothing
+	for day := start; day.Month() == time.March; day = day.AddDate(0, 0, 1) {
+		n, err := testStore.CreateInterestAccrual(context.Background(), CreateInterestAccrualParams{
+			AccountID:    account.ID,
+			AccrualDate:  pgtype.Date{Time: day, Valid: true},
+			Balance:      100_000,
+			RateBps:      150,
+			DayCount:     "ACT/365",
+			AmountMicros: 500_000,
+		})
+		require.NoError(t, err)
+		require.Equal(t, int64(1), n)
+	}
+
+	// accruing a day twice is a no-op
+	n, err := testStore.CreateInterestAccrual(context.Background(), CreateInterestAccrualParams{
+		AccountID:    account.ID,
+		AccrualDate:  pgtype.Date{Time: start, Valid: true},
+		Balance:      100_000,
+		RateBps:      150,
+		DayCount:     "ACT/365",
+		AmountMicros: 500_000,
+	})
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	arg := PostInterestTxParams{AccountID: account.ID, PeriodStart: start, PeriodEnd: start.AddDate(0, 1, 0)}
+
+	result, err := testStore.PostInterestTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int64(16), result.AmountCents)
+	require.NotNil(t, result.Transfer)
+	require.Equal(t, account.ID, result.Transfer.ToAccountID)
+
+	house, err := testStore.GetHouseAccount(context.Background(), GetHouseAccountParams{
+		Purpose:  HousePurposeInterestExpense,
+		Currency: account.Currency,
+	})
+	require.NoError(t, err)
+	require.Equal(t, house.ID, result.Transfer.FromAccountID)
+
+	updated, err := testStore.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account.Balance+16, updated.Balance)
+
+	// posting the same period again pays nothing
+	result, err = testStore.PostInterestTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Zero(t, result.AmountCents)
+	require.Nil(t, result.Transfer)
+}
@@ -0,0 +1,163 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	JournalKindTransfer       = "transfer"
+	JournalKindInterest       = "interest"
+	JournalKindOpeningBalance = "opening_balance"
+	JournalKindAdjustment     = "adjustment"
+)
+
+// House accounts belong to the bank. There is one per purpose and currency.
+const (
+	HouseOwner = "house"
+
+	HousePurposeInterestExpense = "interest_expense"
+	HousePurposeFees            = "fees"
+	HousePurposeFX              = "fx"
+	HousePurposeSuspense        = "suspense"
+)
+
+var HousePurposes = []string{HousePurposeInterestExpense, HousePurposeFees, HousePurposeFX, HousePurposeSuspense}
+
+var ErrUnbalancedJournal = errors.New("journal does not balance")
+var ErrCurrencyMismatch = errors.New("currency mismatch")
+
+// Posting moves an amount into (positive) or out of (negative) an account.
+type Posting struct {
+	AccountID   int64 `json:"account_id"`
+	AmountCents int64 `json:"amount_cents"`
+}
+
+type PostJournalTxParams struct {
+	Kind        string    `json:"kind"`
+	Description string    `json:"description"`
+	Postings    []Posting `json:"postings"`
+}
+
+type PostJournalTxResult struct {
+	Journal  Journal   `json:"journal"`
+	Entries  []Entry   `json:"entries"`
+	Accounts []Account `json:"accounts"`
+}
+
+// PostJournalTx books a journal that is not a transfer, such as an opening
+// balance or a correction against a house account. Closed accounts cannot be
+// posted to, frozen ones can.
+func (s *SQLStore) PostJournalTx(ctx context.Context, arg PostJournalTxParams) (PostJournalTxResult, error) {
+	var result PostJournalTxResult
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		ids := make([]int64, 0, len(arg.Postings))
+		for _, posting := range arg.Postings {
+			ids = append(ids, posting.AccountID)
+		}
+
+		accounts, err := lockAccounts(ctx, q, ids...)
+		if err != nil {
+			return err
+		}
+
+		for _, account := range accounts {
+			if account.Status == AccountStatusClosed {
+				return fmt.Errorf("account [%d] is %s: %w", account.ID, account.Status, ErrAccountNotActive)
+			}
+		}
+
+		result, err = postJournal(ctx, q, accounts, arg, pgtype.Int8{})
+		return err
+	})
+
+	return result, err
+}
+
+// postJournal writes the journal and its entries and applies them to the
+// cached balances. The accounts must be locked by the caller. The database
+// checks again at commit that the journal balances, the check here only
+// gives a clearer error.
+func postJournal(
+	ctx context.Context,
+	q *Queries,
+	accounts map[int64]Account,
+	arg PostJournalTxParams,
+	transferID pgtype.Int8,
+) (PostJournalTxResult, error) {
+	var result PostJournalTxResult
+
+	if err := checkBalanced(accounts, arg.Postings); err != nil {
+		return result, err
+	}
+
+	var err error
+	result.Journal, err = q.CreateJournal(ctx, CreateJournalParams{
+		Kind:        arg.Kind,
+		Description: pgtype.Text{String: arg.Description, Valid: arg.Description != ""},
+	})
+	if err != nil {
+		return result, err
+	}
+
+	totals := make(map[int64]int64, len(accounts))
+	for _, posting := range arg.Postings {
+		entry, err := q.CreateEntry(ctx, CreateEntryParams{
+			JournalID:   result.Journal.ID,
+			AccountID:   posting.AccountID,
+			AmountCents: posting.AmountCents,
+			TransferID:  transferID,
+		})
+		if err != nil {
+			return result, err
+		}
+
+		result.Entries = append(result.Entries, entry)
+		totals[posting.AccountID] += posting.AmountCents
+	}
+
+	// Balances are updated in id order, the order the accounts were locked in
+	for _, id := range slices.Sorted(maps.Keys(totals)) {
+		account, err := q.AddAccountBalance(ctx, AddAccountBalanceParams{
+			ID:     id,
+			Amount: totals[id],
+		})
+		if err != nil {
+			return result, err
+		}
+
+		result.Accounts = append(result.Accounts, account)
+	}
+
+	return result, notifyEntries(ctx, q, result)
+}
+
+func checkBalanced(accounts map[int64]Account, postings []Posting) error {
+	if len(postings) < 2 {
+		return fmt.Errorf("%w: needs at least two postings", ErrUnbalancedJournal)
+	}
+
+	sums := make(map[string]int64)
+	for _, posting := range postings {
+		account, ok := accounts[posting.AccountID]
+		if !ok {
+			return fmt.Errorf("account [%d] is not locked", posting.AccountID)
+		}
+
+		sums[account.Currency] += posting.AmountCents
+	}
+
+	for _, currency := range slices.Sorted(maps.Keys(sums)) {
+		if sums[currency] != 0 {
+			return fmt.Errorf("%w: %s is off by %d", ErrUnbalancedJournal, currency, sums[currency])
+		}
+	}
+
+	return nil
+}
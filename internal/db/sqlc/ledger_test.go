@@ -0,0 +1,110 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+	"github.com/vlone310/bss/internal/money"
+)
+
+func TestPostJournalTxUnbalanced(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createAccountInCurrency(t, account1.Currency)
+
+	_, err := testStore.PostJournalTx(context.Background(), PostJournalTxParams{
+		Kind: JournalKindAdjustment,
+		Postings: []Posting{
+			{AccountID: account1.ID, AmountCents: -10},
+			{AccountID: account2.ID, AmountCents: 9},
+		},
+	})
+	require.ErrorIs(t, err, ErrUnbalancedJournal)
+
+	updated, err := testStore.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, updated.Balance)
+}
+
+func TestJournalBalancedConstraint(t *testing.T) {
+	account := createRandomAccount(t)
+
+	journal, err := testStore.CreateJournal(context.Background(), CreateJournalParams{Kind: JournalKindAdjustment})
+	require.NoError(t, err)
+
+	// a lone entry is refused by the database when its statement commits
+	_, err = testStore.CreateEntry(context.Background(), CreateEntryParams{
+		JournalID:   journal.ID,
+		AccountID:   account.ID,
+		AmountCents: 10,
+	})
+	require.Error(t, err)
+
+	var pgErr *pgconn.PgError
+	require.ErrorAs(t, err, &pgErr)
+	require.Equal(t, "23514", pgErr.Code)
+
+	entries, err := testStore.ListJournalEntries(context.Background(), journal.ID)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+func TestTransferTxJournal(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createAccountInCurrency(t, account1.Currency)
+
+	result, err := testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        money.New(10, account1.Currency),
+	})
+	require.NoError(t, err)
+	require.Equal(t, JournalKindTransfer, result.Journal.Kind)
+
+	entries, err := testStore.ListJournalEntries(context.Background(), result.Journal.ID)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	var sum int64
+	for _, entry := range entries {
+		require.Equal(t, result.Transfer.ID, entry.TransferID.Int64)
+		sum += entry.AmountCents
+	}
+	require.Zero(t, sum)
+}
+
+func TestTransferTxCurrencyMismatch(t *testing.T) {
+	account1 := createRandomAccount(t)
+	currency := "EUR"
+	if account1.Currency == currency {
+		currency = "USD"
+	}
+	account2 := createAccountInCurrency(t, currency)
+
+	_, err := testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        money.New(10, account1.Currency),
+	})
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+}
+
+func TestListHouseAccounts(t *testing.T) {
+	accounts, err := testStore.ListHouseAccounts(context.Background())
+	require.NoError(t, err)
+
+	purposes := make(map[string][]string)
+	for _, account := range accounts {
+		purposes[account.Account.Currency] = append(purposes[account.Account.Currency], account.Purpose)
+	}
+
+	for _, currency := range []string{"CAD", "EUR", "USD"} {
+		require.ElementsMatch(t, []string{
+			HousePurposeFees,
+			HousePurposeFX,
+			HousePurposeInterestExpense,
+			HousePurposeSuspense,
+		}, purposes[currency])
+	}
+}
@@ -14,6 +14,33 @@ type Account struct {
 	Balance   int64              `json:"balance"`
 	Currency  string             `json:"currency"`
 	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	// active, frozen or closed
+	Status string `json:"status"`
+	// why the account was last frozen, unfrozen or closed
+	StatusReason    pgtype.Text        `json:"status_reason"`
+	StatusChangedAt pgtype.Timestamptz `json:"status_changed_at"`
+	ClosedAt        pgtype.Timestamptz `json:"closed_at"`
+	Product         string             `json:"product"`
+	// lowest balance allowed, negative for an approved overdraft or credit limit
+	MinBalance int64 `json:"min_balance"`
+}
+
+type AuditEvent struct {
+	ID int64 `json:"id"`
+	// username of the caller, null for calls without a token and for jobs
+	Actor pgtype.Text `json:"actor"`
+	// e.g. account.create or transfer.create
+	Action       string `json:"action"`
+	ResourceType string `json:"resource_type"`
+	ResourceID   string `json:"resource_id"`
+	// the resource before the change with sensitive fields redacted, null when it was created
+	Before []byte `json:"before"`
+	// the resource after the change with sensitive fields redacted, null when it was deleted
+	After     []byte             `json:"after"`
+	RequestID string             `json:"request_id"`
+	Ip        string             `json:"ip"`
+	UserAgent string             `json:"user_agent"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
 }
 
 type Country struct {
@@ -22,12 +49,225 @@ type Country struct {
 	ContinentName string `json:"continent_name"`
 }
 
+// ISO 4217 currencies, accounts can only be opened in enabled ones
+type Currency struct {
+	Code        string `json:"code"`
+	NumericCode int32  `json:"numeric_code"`
+	// decimal places of the minor unit amounts are stored in, e.g. 2 for cents
+	MinorUnits int32              `json:"minor_units"`
+	Name       string             `json:"name"`
+	Enabled    bool               `json:"enabled"`
+	UpdatedAt  pgtype.Timestamptz `json:"updated_at"`
+	// highest balance an unverified customer may hold, unlimited when null
+	UnverifiedMaxBalance pgtype.Int8 `json:"unverified_max_balance"`
+}
+
+type CustomerProfile struct {
+	Username     string      `json:"username"`
+	DateOfBirth  pgtype.Date `json:"date_of_birth"`
+	AddressLine1 string      `json:"address_line1"`
+	AddressLine2 pgtype.Text `json:"address_line2"`
+	City         string      `json:"city"`
+	PostalCode   string      `json:"postal_code"`
+	// ISO 3166-1 numeric code of the country of residence
+	CountryCode int32 `json:"country_code"`
+	// E.164, e.g. +4915112345678
+	Phone string `json:"phone"`
+	// pending, verified or rejected, customers without a profile are unverified
+	KycStatus string `json:"kyc_status"`
+	// why the profile was last rejected
+	KycReason   pgtype.Text        `json:"kyc_reason"`
+	SubmittedAt pgtype.Timestamptz `json:"submitted_at"`
+	ReviewedBy  pgtype.Text        `json:"reviewed_by"`
+	ReviewedAt  pgtype.Timestamptz `json:"reviewed_at"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+}
+
+type DailyBalance struct {
+	AccountID   int64       `json:"account_id"`
+	BalanceDate pgtype.Date `json:"balance_date"`
+	// closing balance at the end of balance_date in UTC
+	Balance   int64              `json:"balance"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
 type Entry struct {
-	ID        int64       `json:"id"`
-	AccountID pgtype.Int8 `json:"account_id"`
+	ID        int64 `json:"id"`
+	AccountID int64 `json:"account_id"`
 	// can be negative and positive
 	AmountCents int64              `json:"amount_cents"`
 	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	// transfer that produced the entry, if any
+	TransferID pgtype.Int8 `json:"transfer_id"`
+	JournalID  int64       `json:"journal_id"`
+	PrevHash   []byte      `json:"prev_hash"`
+	// sha256 of the entry and prev_hash, chained per account
+	Hash []byte `json:"hash"`
+}
+
+// outcome of scoring a transfer, one per transfer attempt
+type FraudDecision struct {
+	ID            int64 `json:"id"`
+	FromAccountID int64 `json:"from_account_id"`
+	ToAccountID   int64 `json:"to_account_id"`
+	AmountCents   int64 `json:"amount_cents"`
+	// the booked or pending transfer, null when blocked
+	TransferID pgtype.Int8 `json:"transfer_id"`
+	Score      int32       `json:"score"`
+	// allow, challenge the sender, hold for review, or block
+	Action string `json:"action"`
+	// wrong one-time codes entered to confirm a challenge
+	ConfirmAttempts int32 `json:"confirm_attempts"`
+	// approved, rejected, or expired unreviewed, for challenged and held transfers
+	ReviewOutcome pgtype.Text `json:"review_outcome"`
+	// the sender or reviewer, null when decided by the server
+	ReviewedBy pgtype.Text        `json:"reviewed_by"`
+	ReviewedAt pgtype.Timestamptz `json:"reviewed_at"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
+// rules the fraud engine scores transfers with
+type FraudRule struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+	// parameters of the rule kind, durations as Go duration strings
+	Params []byte `json:"params"`
+	// points added to the score of a transfer the rule hits
+	Score     int32              `json:"score"`
+	Enabled   bool               `json:"enabled"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
+type FraudRuleHit struct {
+	DecisionID int64  `json:"decision_id"`
+	RuleName   string `json:"rule_name"`
+	Score      int32  `json:"score"`
+	Detail     string `json:"detail"`
+}
+
+type HouseAccount struct {
+	// what the bank books on the account, e.g. interest_expense
+	Purpose   string `json:"purpose"`
+	Currency  string `json:"currency"`
+	AccountID int64  `json:"account_id"`
+}
+
+type InterestAccrual struct {
+	AccountID   int64       `json:"account_id"`
+	AccrualDate pgtype.Date `json:"accrual_date"`
+	// end-of-day balance the interest was calculated on
+	Balance  int64  `json:"balance"`
+	RateBps  int32  `json:"rate_bps"`
+	DayCount string `json:"day_count"`
+	// interest in millionths of the minor unit, rounded half to even
+	AmountMicros int64              `json:"amount_micros"`
+	PostedAt     pgtype.Timestamptz `json:"posted_at"`
+	// posting that paid the interest out, null while unposted or when it rounded to zero
+	TransferID pgtype.Int8        `json:"transfer_id"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
+// one business event, its entries must sum to zero per currency
+type Journal struct {
+	ID          int64              `json:"id"`
+	Kind        string             `json:"kind"`
+	Description pgtype.Text        `json:"description"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+}
+
+type LedgerCheckpoint struct {
+	ID int64 `json:"id"`
+	// sha256 over the chain heads, see hashchain.CheckpointRoot
+	RootHash []byte `json:"root_hash"`
+	// ed25519 signature of root_hash
+	Signature []byte             `json:"signature"`
+	KeyID     string             `json:"key_id"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type LedgerCheckpointHead struct {
+	CheckpointID int64  `json:"checkpoint_id"`
+	AccountID    int64  `json:"account_id"`
+	EntryID      int64  `json:"entry_id"`
+	Hash         []byte `json:"hash"`
+}
+
+// domain events written with the change they describe, see package event
+type Outbox struct {
+	ID            int64              `json:"id"`
+	AggregateType string             `json:"aggregate_type"`
+	AggregateID   string             `json:"aggregate_id"`
+	EventType     string             `json:"event_type"`
+	SchemaVersion int32              `json:"schema_version"`
+	Payload       []byte             `json:"payload"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	// null until the relay delivered the event
+	PublishedAt pgtype.Timestamptz `json:"published_at"`
+}
+
+type Product struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+	// whether an overdraft or credit limit can be approved
+	AllowsNegativeBalance bool `json:"allows_negative_balance"`
+	// outgoing transfers allowed per calendar month, unlimited when null
+	MaxMonthlyWithdrawals pgtype.Int4 `json:"max_monthly_withdrawals"`
+	// highest balance a deposit may leave, unlimited when null
+	MaxBalance pgtype.Int8 `json:"max_balance"`
+	// annual interest rate in basis points, 150 is 1.50%
+	InterestRateBps int32 `json:"interest_rate_bps"`
+	// day-count convention used to accrue interest
+	DayCount string `json:"day_count"`
+}
+
+type ReconciliationDiscrepancy struct {
+	ID         int64       `json:"id"`
+	RunID      int64       `json:"run_id"`
+	Kind       string      `json:"kind"`
+	AccountID  pgtype.Int8 `json:"account_id"`
+	TransferID pgtype.Int8 `json:"transfer_id"`
+	// balance_mismatch: sum of the entries, transfer_entries: matching entries required
+	Expected int64 `json:"expected"`
+	// balance_mismatch: cached balance, transfer_entries: matching entries found
+	Actual    int64              `json:"actual"`
+	Details   string             `json:"details"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type ReconciliationRun struct {
+	ID               int64              `json:"id"`
+	AccountsChecked  int64              `json:"accounts_checked"`
+	TransfersChecked int64              `json:"transfers_checked"`
+	DiscrepancyCount int64              `json:"discrepancy_count"`
+	CreatedAt        pgtype.Timestamptz `json:"created_at"`
+}
+
+// sanctions list matches waiting for or given a compliance decision
+type ScreeningCase struct {
+	ID int64 `json:"id"`
+	// user for a new user, transfer for a held transfer
+	Subject string `json:"subject"`
+	// user whose name matched
+	Username     string      `json:"username"`
+	TransferID   pgtype.Int8 `json:"transfer_id"`
+	ScreenedName string      `json:"screened_name"`
+	// open, cleared as a false positive, or confirmed as a true match
+	Status         string             `json:"status"`
+	ResolutionNote pgtype.Text        `json:"resolution_note"`
+	ResolvedBy     pgtype.Text        `json:"resolved_by"`
+	ResolvedAt     pgtype.Timestamptz `json:"resolved_at"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+}
+
+type ScreeningHit struct {
+	CaseID     int64  `json:"case_id"`
+	ListSource string `json:"list_source"`
+	EntryUid   string `json:"entry_uid"`
+	ListedName string `json:"listed_name"`
+	// the listed name or alias that matched
+	MatchedName string `json:"matched_name"`
+	// name similarity between 0 and 1
+	Score float64 `json:"score"`
 }
 
 type Transfer struct {
@@ -37,6 +277,8 @@ type Transfer struct {
 	// must be positive
 	AmountCents int64              `json:"amount_cents"`
 	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	// pending, completed or failed
+	Status string `json:"status"`
 }
 
 type User struct {
@@ -46,4 +288,52 @@ type User struct {
 	Email             string             `json:"email"`
 	PasswordChangedAt pgtype.Timestamptz `json:"password_changed_at"`
 	CreatedAt         pgtype.Timestamptz `json:"created_at"`
+	Role              string             `json:"role"`
+	// counts as verified without a profile, for the users created before KYC
+	KycExempt bool `json:"kyc_exempt"`
+	// when a sanctions match of the user was confirmed, their accounts are frozen and they cannot open new ones
+	BlockedAt pgtype.Timestamptz `json:"blocked_at"`
+	// base32 RFC 6238 secret, challenged transfers are confirmed with its codes
+	TotpSecret pgtype.Text `json:"totp_secret"`
+	// when the first code was verified, the secret is not used before
+	TotpEnabledAt pgtype.Timestamptz `json:"totp_enabled_at"`
+	// time step of the last code used, so that a code works once
+	TotpLastStep pgtype.Int8 `json:"totp_last_step"`
+}
+
+type WebhookDelivery struct {
+	ID         int64 `json:"id"`
+	EndpointID int64 `json:"endpoint_id"`
+	// id of the outbox event, a redelivered event keeps it
+	EventID   int64  `json:"event_id"`
+	EventType string `json:"event_type"`
+	// the event envelope as sent
+	Payload []byte `json:"payload"`
+	// pending until delivered, dead once out of attempts
+	Status        string             `json:"status"`
+	Attempts      int32              `json:"attempts"`
+	NextAttemptAt pgtype.Timestamptz `json:"next_attempt_at"`
+	LastError     pgtype.Text        `json:"last_error"`
+	DeliveredAt   pgtype.Timestamptz `json:"delivered_at"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+}
+
+type WebhookDeliveryAttempt struct {
+	ID         int64              `json:"id"`
+	DeliveryID int64              `json:"delivery_id"`
+	StatusCode pgtype.Int4        `json:"status_code"`
+	Error      pgtype.Text        `json:"error"`
+	DurationMs int32              `json:"duration_ms"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
+type WebhookEndpoint struct {
+	ID    int64  `json:"id"`
+	Owner string `json:"owner"`
+	Url   string `json:"url"`
+	// HMAC-SHA256 key the payloads are signed with
+	Secret string `json:"secret"`
+	// event types sent to the endpoint, empty for all
+	EventTypes []string           `json:"event_types"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
 }
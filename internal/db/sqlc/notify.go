@@ -0,0 +1,68 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// EntriesChannel is the channel every posted entry is notified on.
+const EntriesChannel = "account_entries"
+
+// EntryNotification is the payload of a notification on EntriesChannel. The
+// hashes of the entry are left out to keep it small.
+type EntryNotification struct {
+	Entry Entry `json:"entry"`
+	// Balance is that of the account once the journal of the entry posted.
+	Balance int64 `json:"balance"`
+}
+
+// notifyEntries notifies the entries of a journal. Postgres holds the
+// notifications until the transaction commits and drops them on rollback.
+func notifyEntries(ctx context.Context, q *Queries, journal PostJournalTxResult) error {
+	balances := make(map[int64]int64, len(journal.Accounts))
+	for _, account := range journal.Accounts {
+		balances[account.ID] = account.Balance
+	}
+
+	for _, entry := range journal.Entries {
+		entry.PrevHash, entry.Hash = nil, nil
+		payload, err := json.Marshal(EntryNotification{Entry: entry, Balance: balances[entry.AccountID]})
+		if err != nil {
+			return err
+		}
+		if err := q.NotifyEntry(ctx, string(payload)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ListenEntries calls fn with every entry notified until ctx is done or the
+// connection fails. It holds a connection of the pool meanwhile, so a single
+// listener should serve the whole process.
+func (s *SQLStore) ListenEntries(ctx context.Context, fn func(EntryNotification)) error {
+	conn, err := s.db.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, "LISTEN "+EntriesChannel); err != nil {
+		return err
+	}
+
+	for {
+		notification, err := conn.Conn().WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		var n EntryNotification
+		if err := json.Unmarshal([]byte(notification.Payload), &n); err != nil {
+			return fmt.Errorf("entry notification: %w", err)
+		}
+		fn(n)
+	}
+}
@@ -0,0 +1,75 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vlone310/bss/internal/money"
+)
+
+func TestListenEntries(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createAccountInCurrency(t, account1.Currency)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	notifications := make(chan EntryNotification, 100)
+	done := make(chan error, 1)
+	go func() {
+		done <- testStore.ListenEntries(ctx, func(n EntryNotification) {
+			notifications <- n
+		})
+	}()
+
+	// wait until the listener is up by notifying a marker until it arrives
+	marker, err := json.Marshal(EntryNotification{Entry: Entry{AccountID: -1}})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		require.NoError(t, testStore.NotifyEntry(context.Background(), string(marker)))
+		for {
+			select {
+			case n := <-notifications:
+				if n.Entry.AccountID == -1 {
+					return true
+				}
+			case <-time.After(50 * time.Millisecond):
+				return false
+			}
+		}
+	}, 5*time.Second, 10*time.Millisecond)
+
+	result, err := testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        money.New(10, account1.Currency),
+	})
+	require.NoError(t, err)
+
+	got := map[int64]EntryNotification{}
+	for len(got) < 2 {
+		select {
+		case n := <-notifications:
+			if n.Entry.AccountID == account1.ID || n.Entry.AccountID == account2.ID {
+				got[n.Entry.ID] = n
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("entries were not notified")
+		}
+	}
+
+	from := got[result.FromEntry.ID]
+	require.Equal(t, account1.ID, from.Entry.AccountID)
+	require.Equal(t, int64(-10), from.Entry.AmountCents)
+	require.Equal(t, result.FromAccount.Balance, from.Balance)
+	require.Nil(t, from.Entry.Hash)
+
+	to := got[result.ToEntry.ID]
+	require.Equal(t, result.ToAccount.Balance, to.Balance)
+
+	cancel()
+	require.Error(t, <-done)
+}
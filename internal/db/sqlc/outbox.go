@@ -0,0 +1,164 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/vlone310/bss/internal/event"
+	"github.com/vlone310/bss/internal/money"
+)
+
+// accountStatusEvents names the event of a move to each status.
+var accountStatusEvents = map[string]string{
+	AccountStatusActive: event.TypeAccountUnfrozen,
+	AccountStatusFrozen: event.TypeAccountFrozen,
+	AccountStatusClosed: event.TypeAccountClosed,
+}
+
+// transferStatusEvents names the event of a pending transfer that settled.
+var transferStatusEvents = map[string]string{
+	TransferStatusCompleted: event.TypeTransferCompleted,
+	TransferStatusFailed:    event.TypeTransferFailed,
+}
+
+// enqueue writes an event to the outbox with q, so that it is published if
+// and only if the change it describes is committed. Changes of an aggregate
+// hold a lock on its row, so its events are written in the order they
+// happened.
+func enqueue(ctx context.Context, q *Queries, aggregateType string, aggregateID any, eventType string, version int32, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("cannot encode %s event: %w", eventType, err)
+	}
+
+	_, err = q.CreateOutboxEvent(ctx, CreateOutboxEventParams{
+		AggregateType: aggregateType,
+		AggregateID:   fmt.Sprint(aggregateID),
+		EventType:     eventType,
+		SchemaVersion: version,
+		Payload:       payload,
+	})
+	return err
+}
+
+func enqueueAccountEvent(ctx context.Context, q *Queries, eventType string, account Account) error {
+	return enqueue(ctx, q, event.AggregateAccount, account.ID, eventType, event.AccountVersion, event.AccountV1{
+		ID:           account.ID,
+		Owner:        account.Owner,
+		Currency:     account.Currency,
+		Product:      account.Product,
+		Status:       account.Status,
+		StatusReason: account.StatusReason.String,
+	})
+}
+
+// enqueueTransferEvent writes the amount in the decimals of the currency
+// the transfer is in, like the API does.
+func enqueueTransferEvent(ctx context.Context, q *Queries, eventType string, transfer Transfer, currency string) error {
+	c, err := q.GetCurrency(ctx, currency)
+	if err != nil {
+		return err
+	}
+
+	return enqueue(ctx, q, event.AggregateTransfer, transfer.ID, eventType, event.TransferVersion, event.TransferV1{
+		ID:            transfer.ID,
+		FromAccountID: transfer.FromAccountID,
+		ToAccountID:   transfer.ToAccountID,
+		Amount:        money.New(transfer.AmountCents, currency).JSON(c.MinorUnits),
+		Status:        transfer.Status,
+		CreatedAt:     transfer.CreatedAt.Time,
+	})
+}
+
+// settleTransfer moves a pending transfer to its final status and writes
+// the event of it.
+func settleTransfer(ctx context.Context, q *Queries, transfer Transfer, status string) (Transfer, error) {
+	transfer, err := q.UpdateTransferStatus(ctx, UpdateTransferStatusParams{ID: transfer.ID, Status: status})
+	if err != nil {
+		return transfer, err
+	}
+
+	from, err := q.GetAccount(ctx, transfer.FromAccountID)
+	if err != nil {
+		return transfer, err
+	}
+
+	return transfer, enqueueTransferEvent(ctx, q, transferStatusEvents[status], transfer, from.Currency)
+}
+
+// NewEvent returns the envelope an outbox row is published in.
+func NewEvent(row Outbox) event.Event {
+	return event.Event{
+		ID:            row.ID,
+		Type:          row.EventType,
+		Version:       row.SchemaVersion,
+		AggregateType: row.AggregateType,
+		AggregateID:   row.AggregateID,
+		OccurredAt:    row.CreatedAt.Time,
+		Data:          row.Payload,
+	}
+}
+
+type RelayOutboxTxParams struct {
+	Limit int32 `json:"limit"`
+	// Publish delivers an event. An event it fails is left for the next
+	// run, together with the later events of its aggregate.
+	Publish func(ctx context.Context, e event.Event) error `json:"-"`
+}
+
+type RelayOutboxTxResult struct {
+	Published int `json:"published"`
+	// Failed counts the events left for the next run.
+	Failed int `json:"failed"`
+	// Locked is false when another relay was running, nothing was
+	// published then.
+	Locked bool `json:"locked"`
+}
+
+// RelayOutboxTx publishes up to Limit of the oldest unpublished events in
+// order and marks the delivered ones. An event can be delivered and still
+// not be marked, say when the commit fails, and is then published again:
+// delivery is at-least-once. Only one relay runs at a time, so that the
+// events of an aggregate are never published out of order.
+func (s *SQLStore) RelayOutboxTx(ctx context.Context, arg RelayOutboxTxParams) (RelayOutboxTxResult, error) {
+	var result RelayOutboxTxResult
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		var err error
+		result.Locked, err = q.TryLockOutboxRelay(ctx)
+		if err != nil || !result.Locked {
+			return err
+		}
+
+		rows, err := q.ListUnpublishedOutboxEvents(ctx, arg.Limit)
+		if err != nil {
+			return err
+		}
+
+		published := make([]int64, 0, len(rows))
+		failed := map[string]bool{}
+		for _, row := range rows {
+			aggregate := row.AggregateType + "/" + row.AggregateID
+			if failed[aggregate] {
+				result.Failed++
+				continue
+			}
+
+			if err := arg.Publish(ctx, NewEvent(row)); err != nil {
+				failed[aggregate] = true
+				result.Failed++
+				continue
+			}
+			published = append(published, row.ID)
+		}
+
+		result.Published = len(published)
+		if len(published) == 0 {
+			return nil
+		}
+		return q.MarkOutboxEventsPublished(ctx, published)
+	})
+
+	return result, err
+}
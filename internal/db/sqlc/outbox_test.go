@@ -0,0 +1,175 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"github.com/vlone310/bss/internal/event"
+	"github.com/vlone310/bss/internal/money"
+)
+
+// relayOutbox publishes the whole outbox to the publisher and returns the
+// events published for the aggregate.
+func relayOutbox(t *testing.T, publisher *event.MemoryPublisher, aggregateType string, aggregateID int64) []event.Event {
+	t.Helper()
+
+	for {
+		result, err := testStore.RelayOutboxTx(context.Background(), RelayOutboxTxParams{
+			Limit:   1000,
+			Publish: publisher.Publish,
+		})
+		require.NoError(t, err)
+		require.True(t, result.Locked)
+		if result.Published < 1000 {
+			break
+		}
+	}
+
+	var events []event.Event
+	for _, e := range publisher.Events() {
+		if e.AggregateType == aggregateType && e.AggregateID == fmt.Sprint(aggregateID) {
+			events = append(events, e)
+		}
+	}
+	return events
+}
+
+func TestOutboxAccountEvents(t *testing.T) {
+	account := createRandomAccount(t)
+
+	_, err := testStore.ChangeAccountStatusTx(context.Background(), ChangeAccountStatusTxParams{
+		AccountID: account.ID,
+		Status:    AccountStatusFrozen,
+		Reason:    "chargeback",
+	})
+	require.NoError(t, err)
+
+	events := relayOutbox(t, &event.MemoryPublisher{}, event.AggregateAccount, account.ID)
+	require.Len(t, events, 2)
+	require.Equal(t, event.TypeAccountCreated, events[0].Type)
+	require.Equal(t, event.TypeAccountFrozen, events[1].Type)
+	require.Equal(t, int32(event.AccountVersion), events[1].Version)
+
+	var data event.AccountV1
+	require.NoError(t, json.Unmarshal(events[1].Data, &data))
+	require.Equal(t, AccountStatusFrozen, data.Status)
+	require.Equal(t, "chargeback", data.StatusReason)
+}
+
+func TestOutboxReconciliationFreeze(t *testing.T) {
+	account := createRandomAccount(t)
+
+	_, err := testStore.UpdateAccount(context.Background(), UpdateAccountParams{
+		ID:      account.ID,
+		Balance: account.Balance + 1,
+	})
+	require.NoError(t, err)
+
+	result, err := testStore.ReconcileTx(context.Background(), ReconcileTxParams{FreezeAccounts: true})
+	require.NoError(t, err)
+	require.Contains(t, result.FrozenAccountIDs, account.ID)
+
+	events := relayOutbox(t, &event.MemoryPublisher{}, event.AggregateAccount, account.ID)
+	require.Len(t, events, 2)
+	require.Equal(t, event.TypeAccountFrozen, events[1].Type)
+
+	var data event.AccountV1
+	require.NoError(t, json.Unmarshal(events[1].Data, &data))
+	require.Equal(t, fmt.Sprintf("reconciliation run %d", result.Run.ID), data.StatusReason)
+}
+
+func TestDeletePublishedOutboxEvents(t *testing.T) {
+	published := createRandomAccount(t)
+	require.Len(t, relayOutbox(t, &event.MemoryPublisher{}, event.AggregateAccount, published.ID), 1)
+	unpublished := createRandomAccount(t)
+
+	countEvents := func(accountID int64) (count int) {
+		err := testStore.(*SQLStore).db.QueryRow(context.Background(),
+			"SELECT count(*) FROM outbox WHERE aggregate_type = $1 AND aggregate_id = $2",
+			event.AggregateAccount, fmt.Sprint(accountID)).Scan(&count)
+		require.NoError(t, err)
+		return count
+	}
+	deleteBefore := func(before time.Time) {
+		_, err := testStore.DeletePublishedOutboxEvents(context.Background(), DeletePublishedOutboxEventsParams{
+			PublishedBefore: pgtype.Timestamptz{Time: before, Valid: true},
+			LimitCount:      1_000_000,
+		})
+		require.NoError(t, err)
+	}
+
+	// events published after the time are kept
+	deleteBefore(time.Now().Add(-time.Hour))
+	require.Equal(t, 1, countEvents(published.ID))
+
+	// unpublished events are never deleted
+	deleteBefore(time.Now().Add(time.Minute))
+	require.Zero(t, countEvents(published.ID))
+	require.Equal(t, 1, countEvents(unpublished.ID))
+}
+
+func TestOutboxTransferEvents(t *testing.T) {
+	reviewer := createRandomUser(t)
+	from, _, held := holdRandomTransfer(t, 10)
+
+	_, err := testStore.ResolveScreeningCaseTx(context.Background(), ResolveScreeningCaseTxParams{
+		CaseID:     held.Cases[0].ID,
+		Status:     ScreeningCaseCleared,
+		ResolvedBy: reviewer.Username,
+	})
+	require.NoError(t, err)
+
+	events := relayOutbox(t, &event.MemoryPublisher{}, event.AggregateTransfer, held.Transfer.ID)
+	require.Len(t, events, 2)
+	require.Equal(t, event.TypeTransferCreated, events[0].Type)
+	require.Equal(t, event.TypeTransferCompleted, events[1].Type)
+
+	var data event.TransferV1
+	require.NoError(t, json.Unmarshal(events[0].Data, &data))
+	require.Equal(t, TransferStatusPending, data.Status)
+	// the random currencies all have two decimals
+	require.Equal(t, money.New(10, from.Currency).JSON(2), data.Amount)
+}
+
+func TestRelayOutboxTxFailure(t *testing.T) {
+	from := createRandomAccount(t)
+	to := createAccountInCurrency(t, from.Currency)
+
+	var transfers []Transfer
+	for range 2 {
+		result, err := testStore.TransferTx(context.Background(), TransferTxParams{
+			FromAccountID: from.ID,
+			ToAccountID:   to.ID,
+			Amount:        money.New(10, from.Currency),
+		})
+		require.NoError(t, err)
+		transfers = append(transfers, result.Transfer)
+	}
+
+	// the first transfer cannot be published, the second one can
+	failing := fmt.Sprint(transfers[0].ID)
+	publisher := &event.MemoryPublisher{
+		Fail: func(e event.Event) error {
+			if e.AggregateType == event.AggregateTransfer && e.AggregateID == failing {
+				return errors.New("broker unavailable")
+			}
+			return nil
+		},
+	}
+
+	result, err := testStore.RelayOutboxTx(context.Background(), RelayOutboxTxParams{Limit: 10_000, Publish: publisher.Publish})
+	require.NoError(t, err)
+	require.Positive(t, result.Failed)
+	require.Len(t, relayOutbox(t, publisher, event.AggregateTransfer, transfers[0].ID), 0)
+	require.Len(t, relayOutbox(t, publisher, event.AggregateTransfer, transfers[1].ID), 1)
+
+	// the failed event is published by a later run
+	publisher.Fail = nil
+	require.Len(t, relayOutbox(t, publisher, event.AggregateTransfer, transfers[0].ID), 1)
+}
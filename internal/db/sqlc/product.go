@@ -0,0 +1,111 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	ProductChecking   = "checking"
+	ProductSavings    = "savings"
+	ProductCreditLine = "credit_line"
+	// ProductHouse is used for the bank's own accounts only.
+	ProductHouse = "house"
+)
+
+var ErrInsufficientFunds = errors.New("insufficient funds")
+var ErrWithdrawalLimitReached = errors.New("monthly withdrawal limit reached")
+var ErrNegativeBalanceNotAllowed = errors.New("product does not allow a negative balance")
+var ErrMaxBalanceExceeded = errors.New("deposit exceeds the maximum balance of the product")
+
+// checkWithdrawal applies the rules of the account's product to an outgoing
+// transfer. The account must be locked by the caller.
+func checkWithdrawal(ctx context.Context, q *Queries, account Account, amountCents int64) error {
+	if account.Balance-amountCents < account.MinBalance {
+		return fmt.Errorf("account [%d] balance %d, limit %d: %w", account.ID, account.Balance, account.MinBalance, ErrInsufficientFunds)
+	}
+
+	product, err := q.GetProduct(ctx, account.Product)
+	if err != nil {
+		return err
+	}
+
+	if product.MaxMonthlyWithdrawals.Valid {
+		now := time.Now().UTC()
+		monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+		count, err := q.CountWithdrawalsSince(ctx, CountWithdrawalsSinceParams{
+			AccountID: account.ID,
+			Since:     pgtype.Timestamptz{Time: monthStart, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		if count >= int64(product.MaxMonthlyWithdrawals.Int32) {
+			return fmt.Errorf("account [%d] made %d withdrawals this month: %w", account.ID, count, ErrWithdrawalLimitReached)
+		}
+	}
+
+	return nil
+}
+
+// checkDeposit applies the rules of the account's product to an incoming
+// transfer, a credit line cannot be paid above zero. The account must be
+// locked by the caller. The account is usually somebody else's, so the
+// error does not tell its balance.
+func checkDeposit(ctx context.Context, q *Queries, account Account, amountCents int64) error {
+	product, err := q.GetProduct(ctx, account.Product)
+	if err != nil {
+		return err
+	}
+
+	if product.MaxBalance.Valid && account.Balance+amountCents > product.MaxBalance.Int64 {
+		return ErrMaxBalanceExceeded
+	}
+
+	return nil
+}
+
+type SetAccountMinBalanceTxParams struct {
+	AccountID  int64 `json:"account_id"`
+	MinBalance int64 `json:"min_balance"`
+}
+
+// SetAccountMinBalanceTx approves an overdraft or credit limit. Products that
+// must not go below zero, such as savings, are refused.
+func (s *SQLStore) SetAccountMinBalanceTx(ctx context.Context, arg SetAccountMinBalanceTxParams) (Account, error) {
+	var result Account
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		account, err := q.GetAccountForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		product, err := q.GetProduct(ctx, account.Product)
+		if err != nil {
+			return err
+		}
+
+		if arg.MinBalance < 0 && !product.AllowsNegativeBalance {
+			return fmt.Errorf("%w: %s", ErrNegativeBalanceNotAllowed, product.Code)
+		}
+
+		result, err = q.UpdateAccountMinBalance(ctx, UpdateAccountMinBalanceParams{
+			ID:         arg.AccountID,
+			MinBalance: arg.MinBalance,
+		})
+		if err != nil {
+			return err
+		}
+
+		return audit(ctx, q, AuditActionAccountSetLimit, "account", result.ID, account, result)
+	})
+
+	return result, err
+}
@@ -0,0 +1,135 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vlone310/bss/internal/money"
+	"github.com/vlone310/bss/testutil"
+)
+
+func createProductAccount(t *testing.T, product string, balance int64) Account {
+	t.Helper()
+
+	user := createVerifiedUser(t)
+
+	account, err := testStore.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    user.Username,
+		Currency: testutil.RandomCurrency(),
+		Product:  product,
+	})
+	require.NoError(t, err)
+	require.Equal(t, product, account.Product)
+
+	return fundAccount(t, account, balance)
+}
+
+func TestTransferTxOverdraft(t *testing.T) {
+	account1 := createProductAccount(t, ProductChecking, 100)
+	account2 := createAccountInCurrency(t, account1.Currency)
+
+	arg := TransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: money.New(300, account1.Currency)}
+
+	_, err := testStore.TransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	_, err = testStore.SetAccountMinBalanceTx(context.Background(), SetAccountMinBalanceTxParams{
+		AccountID:  account1.ID,
+		MinBalance: -200,
+	})
+	require.NoError(t, err)
+
+	_, err = testStore.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	updated, err := testStore.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(-200), updated.Balance)
+
+	// the overdraft is used up
+	arg.Amount = money.New(1, account1.Currency)
+	_, err = testStore.TransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+}
+
+func TestSavingsWithdrawalLimit(t *testing.T) {
+	account1 := createProductAccount(t, ProductSavings, 100_000)
+	account2 := createAccountInCurrency(t, account1.Currency)
+
+	product, err := testStore.GetProduct(context.Background(), ProductSavings)
+	require.NoError(t, err)
+	require.True(t, product.MaxMonthlyWithdrawals.Valid)
+
+	// a failed transfer is not a withdrawal
+	failed, err := testStore.CreatePendingTransfer(context.Background(), CreatePendingTransferParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		AmountCents:   10,
+	})
+	require.NoError(t, err)
+	_, err = testStore.UpdateTransferStatus(context.Background(), UpdateTransferStatusParams{ID: failed.ID, Status: TransferStatusFailed})
+	require.NoError(t, err)
+
+	arg := TransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: money.New(10, account1.Currency)}
+	for range product.MaxMonthlyWithdrawals.Int32 {
+		_, err = testStore.TransferTx(context.Background(), arg)
+		require.NoError(t, err)
+	}
+
+	_, err = testStore.TransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrWithdrawalLimitReached)
+
+	// deposits are not limited
+	_, err = testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account2.ID,
+		ToAccountID:   account1.ID,
+		Amount:        money.New(10, account1.Currency),
+	})
+	require.NoError(t, err)
+
+	_, err = testStore.SetAccountMinBalanceTx(context.Background(), SetAccountMinBalanceTxParams{
+		AccountID:  account1.ID,
+		MinBalance: -100,
+	})
+	require.ErrorIs(t, err, ErrNegativeBalanceNotAllowed)
+}
+
+func TestCreditLine(t *testing.T) {
+	user := createVerifiedUser(t)
+	creditLine, err := testStore.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    user.Username,
+		Currency: testutil.RandomCurrency(),
+		Product:  ProductCreditLine,
+	})
+	require.NoError(t, err)
+	account2 := createAccountInCurrency(t, creditLine.Currency)
+
+	draw := TransferTxParams{FromAccountID: creditLine.ID, ToAccountID: account2.ID, Amount: money.New(300, creditLine.Currency)}
+	repay := TransferTxParams{FromAccountID: account2.ID, ToAccountID: creditLine.ID, Amount: money.New(300, creditLine.Currency)}
+
+	// nothing to draw on before a limit is approved, and nothing to repay
+	_, err = testStore.TransferTx(context.Background(), draw)
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+	_, err = testStore.TransferTx(context.Background(), repay)
+	require.EqualError(t, err, ErrMaxBalanceExceeded.Error())
+
+	_, err = testStore.SetAccountMinBalanceTx(context.Background(), SetAccountMinBalanceTxParams{
+		AccountID:  creditLine.ID,
+		MinBalance: -500,
+	})
+	require.NoError(t, err)
+
+	_, err = testStore.TransferTx(context.Background(), draw)
+	require.NoError(t, err)
+
+	// a repayment cannot take the credit line above zero
+	repay.Amount = money.New(301, creditLine.Currency)
+	_, err = testStore.TransferTx(context.Background(), repay)
+	require.ErrorIs(t, err, ErrMaxBalanceExceeded)
+
+	repay.Amount = money.New(300, creditLine.Currency)
+	result, err := testStore.TransferTx(context.Background(), repay)
+	require.NoError(t, err)
+	require.Zero(t, result.ToAccount.Balance)
+}
@@ -6,24 +6,196 @@ package db
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type Querier interface {
 	AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error)
+	AddFraudConfirmAttempt(ctx context.Context, id int64) (FraudDecision, error)
+	BlockUser(ctx context.Context, username string) (User, error)
+	// Claim pending deliveries that are due by moving their next attempt to
+	// claim_until, so that a concurrent sender skips them meanwhile.
+	ClaimDueWebhookDeliveries(ctx context.Context, arg ClaimDueWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	CountLedger(ctx context.Context) (CountLedgerRow, error)
+	CountOpenTransferScreeningCases(ctx context.Context, transferID pgtype.Int8) (int64, error)
+	CountTransfersBetween(ctx context.Context, arg CountTransfersBetweenParams) (int64, error)
+	// Count the transfers an account sent or tried to send since a point in
+	// time, held ones included.
+	CountTransfersFromSince(ctx context.Context, arg CountTransfersFromSinceParams) (int64, error)
+	// Only completed transfers are withdrawals. A pending transfer is counted
+	// once it is released, a failed one never.
+	CountWithdrawalsSince(ctx context.Context, arg CountWithdrawalsSinceParams) (int64, error)
+	// Accounts always open empty, money only arrives through journals.
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
+	CreateAuditEvent(ctx context.Context, arg CreateAuditEventParams) (AuditEvent, error)
+	// Closing balances of every account that existed at the end of the day,
+	// continued from each account's latest earlier snapshot. Days that already
+	// have a snapshot are kept, which makes reruns safe.
+	CreateDailyBalances(ctx context.Context, balanceDate pgtype.Date) (int64, error)
 	CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error)
+	CreateFraudDecision(ctx context.Context, arg CreateFraudDecisionParams) (FraudDecision, error)
+	CreateFraudRuleHit(ctx context.Context, arg CreateFraudRuleHitParams) (FraudRuleHit, error)
+	CreateHouseAccount(ctx context.Context, arg CreateHouseAccountParams) (HouseAccount, error)
+	// Accruing the same day twice is a no-op, which makes reruns safe.
+	CreateInterestAccrual(ctx context.Context, arg CreateInterestAccrualParams) (int64, error)
+	CreateJournal(ctx context.Context, arg CreateJournalParams) (Journal, error)
+	CreateLedgerCheckpoint(ctx context.Context, arg CreateLedgerCheckpointParams) (LedgerCheckpoint, error)
+	CreateLedgerCheckpointHeads(ctx context.Context, arg []CreateLedgerCheckpointHeadsParams) (int64, error)
+	CreateOutboxEvent(ctx context.Context, arg CreateOutboxEventParams) (Outbox, error)
+	// A pending transfer has no journal yet, it is booked once released.
+	CreatePendingTransfer(ctx context.Context, arg CreatePendingTransferParams) (Transfer, error)
+	CreateReconciliationDiscrepancy(ctx context.Context, arg CreateReconciliationDiscrepancyParams) (ReconciliationDiscrepancy, error)
+	CreateReconciliationRun(ctx context.Context, arg CreateReconciliationRunParams) (ReconciliationRun, error)
+	CreateScreeningCase(ctx context.Context, arg CreateScreeningCaseParams) (ScreeningCase, error)
+	CreateScreeningHit(ctx context.Context, arg CreateScreeningHitParams) (ScreeningHit, error)
 	CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	// Queue an event for an endpoint, once however often it is published.
+	CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) error
+	CreateWebhookDeliveryAttempt(ctx context.Context, arg CreateWebhookDeliveryAttemptParams) (WebhookDeliveryAttempt, error)
+	CreateWebhookEndpoint(ctx context.Context, arg CreateWebhookEndpointParams) (WebhookEndpoint, error)
 	DeleteAccount(ctx context.Context, id int64) error
+	DeleteDailyBalancesFrom(ctx context.Context, fromDate pgtype.Date) (int64, error)
+	// Delete up to limit_count of the events published before the time, the
+	// oldest first.
+	DeletePublishedOutboxEvents(ctx context.Context, arg DeletePublishedOutboxEventsParams) (int64, error)
+	DeleteWebhookEndpoint(ctx context.Context, id int64) error
+	// Enable the secret a code was checked with, unless it was replaced since.
+	EnableUserTOTP(ctx context.Context, arg EnableUserTOTPParams) (User, error)
 	GetAccount(ctx context.Context, id int64) (Account, error)
+	// Balance including every entry up to as_of: the latest snapshot of a day
+	// that ended by then plus the entries booked after it.
+	GetAccountBalanceAsOf(ctx context.Context, arg GetAccountBalanceAsOfParams) (GetAccountBalanceAsOfRow, error)
+	// Balance right before the given instant, derived backwards from the cached
+	// balance like the statement figures.
+	GetAccountBalanceAt(ctx context.Context, arg GetAccountBalanceAtParams) (int64, error)
 	GetAccountForUpdate(ctx context.Context, id int64) (Account, error)
+	GetCurrency(ctx context.Context, code string) (Currency, error)
+	GetCustomerProfile(ctx context.Context, username string) (CustomerProfile, error)
 	GetEntry(ctx context.Context, id int64) (Entry, error)
+	GetFirstEntryDate(ctx context.Context) (pgtype.Date, error)
+	GetFraudDecision(ctx context.Context, id int64) (FraudDecision, error)
+	GetFraudDecisionByTransfer(ctx context.Context, transferID pgtype.Int8) (FraudDecision, error)
+	GetFraudDecisionForUpdate(ctx context.Context, id int64) (FraudDecision, error)
+	GetFraudRule(ctx context.Context, name string) (FraudRule, error)
+	GetHouseAccount(ctx context.Context, arg GetHouseAccountParams) (Account, error)
+	GetJournal(ctx context.Context, id int64) (Journal, error)
+	// Users who never submitted a profile are pending, unless they are exempt.
+	GetKYCStatus(ctx context.Context, username string) (string, error)
+	GetLatestDailyBalanceDate(ctx context.Context) (pgtype.Date, error)
+	GetLatestReconciliationRun(ctx context.Context) (ReconciliationRun, error)
+	GetLedgerCheckpoint(ctx context.Context, id int64) (LedgerCheckpoint, error)
+	GetOutgoingTransferStats(ctx context.Context, arg GetOutgoingTransferStatsParams) (GetOutgoingTransferStatsRow, error)
+	GetProduct(ctx context.Context, code string) (Product, error)
+	GetScreeningCase(ctx context.Context, id int64) (ScreeningCase, error)
+	GetScreeningCaseForUpdate(ctx context.Context, id int64) (ScreeningCase, error)
+	// Balances are derived backwards from the cached account balance so that
+	// accounts opened with a non-zero balance still report correct figures.
+	GetStatementSummary(ctx context.Context, arg GetStatementSummaryParams) (GetStatementSummaryRow, error)
 	GetTransfer(ctx context.Context, id int64) (Transfer, error)
+	GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error)
 	GetUser(ctx context.Context, username string) (User, error)
+	GetWebhookDelivery(ctx context.Context, id int64) (WebhookDelivery, error)
+	GetWebhookEndpoint(ctx context.Context, id int64) (WebhookEndpoint, error)
 	ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error)
+	// List the newest events first. Every filter is optional.
+	ListAuditEvents(ctx context.Context, arg ListAuditEventsParams) ([]AuditEvent, error)
+	// Accounts whose cached balance differs from the sum of their entries.
+	ListBalanceMismatches(ctx context.Context) ([]ListBalanceMismatchesRow, error)
+	// List entries a user was already cleared of, so that a false positive
+	// does not hold every transfer they make.
+	ListClearedScreeningEntries(ctx context.Context, username string) ([]ListClearedScreeningEntriesRow, error)
+	ListCountries(ctx context.Context) ([]Country, error)
+	ListCurrencies(ctx context.Context) ([]Currency, error)
+	ListCustomerProfiles(ctx context.Context, arg ListCustomerProfilesParams) ([]CustomerProfile, error)
 	ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error)
+	ListEntriesAfter(ctx context.Context, arg ListEntriesAfterParams) ([]Entry, error)
+	// The entries of an account are created under its row lock, so their ids
+	// grow in the order they are committed.
+	ListEntriesAfterID(ctx context.Context, arg ListEntriesAfterIDParams) ([]Entry, error)
+	// Entries in chain order, optionally of a single account.
+	ListEntryChain(ctx context.Context, accountID pgtype.Int8) ([]Entry, error)
+	ListEntryChainHeads(ctx context.Context) ([]ListEntryChainHeadsRow, error)
+	// List decisions newest first, optionally of one action, and optionally only
+	// those still waiting for a review.
+	ListFraudDecisions(ctx context.Context, arg ListFraudDecisionsParams) ([]FraudDecision, error)
+	ListFraudRuleHits(ctx context.Context, decisionID int64) ([]FraudRuleHit, error)
+	ListFraudRules(ctx context.Context) ([]FraudRule, error)
+	ListHouseAccounts(ctx context.Context) ([]ListHouseAccountsRow, error)
+	ListInterestAccruals(ctx context.Context, arg ListInterestAccrualsParams) ([]InterestAccrual, error)
+	// Accounts whose product pays interest, with the last day already accrued so
+	// that a run can continue where the previous one stopped.
+	ListInterestBearingAccounts(ctx context.Context) ([]ListInterestBearingAccountsRow, error)
+	ListJournalEntries(ctx context.Context, journalID int64) ([]Entry, error)
+	ListLedgerCheckpointHeads(ctx context.Context, checkpointID int64) ([]LedgerCheckpointHead, error)
+	ListLedgerCheckpoints(ctx context.Context) ([]LedgerCheckpoint, error)
+	ListOwnerAccounts(ctx context.Context, arg ListOwnerAccountsParams) ([]Account, error)
+	ListReconciliationDiscrepancies(ctx context.Context, runID int64) ([]ReconciliationDiscrepancy, error)
+	ListScreeningCases(ctx context.Context, arg ListScreeningCasesParams) ([]ScreeningCase, error)
+	ListScreeningHits(ctx context.Context, caseID int64) ([]ScreeningHit, error)
+	// List decisions of an action still waiting for a review that were made
+	// before the cutoff, oldest first.
+	ListStaleFraudDecisions(ctx context.Context, arg ListStaleFraudDecisionsParams) ([]FraudDecision, error)
+	ListStatementEntries(ctx context.Context, arg ListStatementEntriesParams) ([]ListStatementEntriesRow, error)
+	// Completed transfers that are not booked as exactly one debit of the sender
+	// and one credit of the recipient for the transfer amount.
+	ListTransferEntryMismatches(ctx context.Context) ([]ListTransferEntryMismatchesRow, error)
+	// Each side of the union is a plain equality on an indexed column, so the
+	// planner can use the (from_account_id, to_account_id) composite index for
+	// outgoing transfers and the to_account_id index for incoming ones instead of
+	// falling back to a sequential scan for the OR.
 	ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error)
+	// Keyset variant of ListTransfers. Both sides of the union are read from the
+	// (account, created_at, id) indexes in order and merged.
+	ListTransfersAfter(ctx context.Context, arg ListTransfersAfterParams) ([]Transfer, error)
+	// Only active accounts can be paid, the interest of the others is posted
+	// once they are active again.
+	ListUnpostedInterestPeriods(ctx context.Context, before pgtype.Date) ([]ListUnpostedInterestPeriodsRow, error)
+	ListUnpublishedOutboxEvents(ctx context.Context, limit int32) ([]Outbox, error)
+	ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	ListWebhookDeliveryAttempts(ctx context.Context, deliveryID int64) ([]WebhookDeliveryAttempt, error)
+	ListWebhookEndpoints(ctx context.Context, owner string) ([]WebhookEndpoint, error)
+	// List the endpoints of the owners that subscribed to the event type.
+	ListWebhookEndpointsForEvent(ctx context.Context, arg ListWebhookEndpointsForEventParams) ([]WebhookEndpoint, error)
+	// Serializes the checks that span all accounts of a user.
+	LockUserForUpdate(ctx context.Context, username string) error
+	// Claims the unposted accruals of a period. Rows claimed by a concurrent run
+	// are skipped once it commits, so a period is never paid twice.
+	MarkInterestAccrualsPosted(ctx context.Context, arg MarkInterestAccrualsPostedParams) ([]int64, error)
+	MarkOutboxEventsPublished(ctx context.Context, ids []int64) error
+	// Listeners get the notification once the transaction commits.
+	NotifyEntry(ctx context.Context, payload string) error
+	// Queue a delivery again with a fresh set of attempts. A delivery that is
+	// still pending is not changed.
+	RedeliverWebhookDelivery(ctx context.Context, id int64) (WebhookDelivery, error)
+	ResolveScreeningCase(ctx context.Context, arg ResolveScreeningCaseParams) (ScreeningCase, error)
+	// Only pending profiles can be reviewed, a decision is not overwritten.
+	ReviewCustomerProfile(ctx context.Context, arg ReviewCustomerProfileParams) (CustomerProfile, error)
+	ReviewFraudDecision(ctx context.Context, arg ReviewFraudDecisionParams) (FraudDecision, error)
+	SetCurrencyEnabled(ctx context.Context, arg SetCurrencyEnabledParams) (Currency, error)
+	SetInterestAccrualsTransfer(ctx context.Context, arg SetInterestAccrualsTransferParams) error
+	// Start an enrollment with a new secret, which is not used before a code of
+	// it is verified. Fails with no rows once the secret is enabled.
+	SetUserTOTPSecret(ctx context.Context, arg SetUserTOTPSecretParams) (User, error)
+	// Resubmitting replaces the profile and sends it back for review, so
+	// changed details are never treated as verified.
+	SubmitCustomerProfile(ctx context.Context, arg SubmitCustomerProfileParams) (CustomerProfile, error)
+	// Sum the money that arrived on an account since a point in time, by
+	// transfer or any other journal.
+	SumCreditsSince(ctx context.Context, arg SumCreditsSinceParams) (int64, error)
+	SumOwnerBalances(ctx context.Context, arg SumOwnerBalancesParams) (int64, error)
+	// Take the lock only one relay may hold, until the end of the transaction.
+	TryLockOutboxRelay(ctx context.Context) (bool, error)
 	UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error)
+	UpdateAccountMinBalance(ctx context.Context, arg UpdateAccountMinBalanceParams) (Account, error)
+	UpdateAccountStatus(ctx context.Context, arg UpdateAccountStatusParams) (Account, error)
+	UpdateTransferStatus(ctx context.Context, arg UpdateTransferStatusParams) (Transfer, error)
+	UpdateWebhookDelivery(ctx context.Context, arg UpdateWebhookDeliveryParams) (WebhookDelivery, error)
+	UpsertFraudRule(ctx context.Context, arg UpsertFraudRuleParams) (FraudRule, error)
+	// Record the time step of a code that was used. Fails with no rows when a
+	// code of that step or a later one was used already.
+	UseUserTOTPStep(ctx context.Context, arg UseUserTOTPStepParams) (User, error)
 }
 
 var _ Querier = (*Queries)(nil)
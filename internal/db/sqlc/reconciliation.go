@@ -0,0 +1,148 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	DiscrepancyKindBalanceMismatch = "balance_mismatch"
+	DiscrepancyKindTransferEntries = "transfer_entries"
+)
+
+// ReconciliationAuditAgent is the user agent of the audit events of the
+// accounts a scheduled reconciliation run freezes.
+const ReconciliationAuditAgent = "reconciliation"
+
+type ReconcileTxParams struct {
+	// FreezeAccounts freezes the active customer accounts that have a
+	// discrepancy so that no more money moves until someone has looked.
+	FreezeAccounts bool `json:"freeze_accounts"`
+}
+
+type ReconcileTxResult struct {
+	Run              ReconciliationRun           `json:"run"`
+	Discrepancies    []ReconciliationDiscrepancy `json:"discrepancies"`
+	FrozenAccountIDs []int64                     `json:"frozen_account_ids"`
+}
+
+// ReconcileTx compares every cached balance with the sum of its entries and
+// every completed transfer with its two entries, and records the result as a
+// reconciliation run. The checks read a single snapshot so that transfers in
+// flight are never reported.
+func (s *SQLStore) ReconcileTx(ctx context.Context, arg ReconcileTxParams) (ReconcileTxResult, error) {
+	var result ReconcileTxResult
+
+	var counts CountLedgerRow
+	var balances []ListBalanceMismatchesRow
+	var transfers []ListTransferEntryMismatchesRow
+
+	err := s.readTx(ctx, func(q *Queries) error {
+		var err error
+		if counts, err = q.CountLedger(ctx); err != nil {
+			return err
+		}
+
+		if balances, err = q.ListBalanceMismatches(ctx); err != nil {
+			return err
+		}
+
+		transfers, err = q.ListTransferEntryMismatches(ctx)
+		return err
+	})
+	if err != nil {
+		return result, err
+	}
+
+	err = s.execTx(ctx, func(q *Queries) error {
+		var err error
+		result.Run, err = q.CreateReconciliationRun(ctx, CreateReconciliationRunParams{
+			AccountsChecked:  counts.Accounts,
+			TransfersChecked: counts.Transfers,
+			DiscrepancyCount: int64(len(balances) + len(transfers)),
+		})
+		if err != nil {
+			return err
+		}
+
+		affected := make(map[int64]bool)
+		record := func(arg CreateReconciliationDiscrepancyParams) error {
+			arg.RunID = result.Run.ID
+			discrepancy, err := q.CreateReconciliationDiscrepancy(ctx, arg)
+			if err != nil {
+				return err
+			}
+
+			result.Discrepancies = append(result.Discrepancies, discrepancy)
+			return nil
+		}
+
+		for _, row := range balances {
+			affected[row.AccountID] = true
+			if err := record(CreateReconciliationDiscrepancyParams{
+				Kind:      DiscrepancyKindBalanceMismatch,
+				AccountID: pgtype.Int8{Int64: row.AccountID, Valid: true},
+				Expected:  row.EntriesTotal,
+				Actual:    row.Balance,
+				Details:   fmt.Sprintf("cached balance %d, entries sum to %d", row.Balance, row.EntriesTotal),
+			}); err != nil {
+				return err
+			}
+		}
+
+		for _, row := range transfers {
+			affected[row.FromAccountID] = true
+			affected[row.ToAccountID] = true
+			if err := record(CreateReconciliationDiscrepancyParams{
+				Kind:       DiscrepancyKindTransferEntries,
+				TransferID: pgtype.Int8{Int64: row.TransferID, Valid: true},
+				Expected:   2,
+				Actual:     row.MatchingEntries,
+				Details:    fmt.Sprintf("%d entries, %d matching the transfer", row.TotalEntries, row.MatchingEntries),
+			}); err != nil {
+				return err
+			}
+		}
+
+		if !arg.FreezeAccounts || len(affected) == 0 {
+			return nil
+		}
+
+		accounts, err := lockAccounts(ctx, q, slices.Collect(maps.Keys(affected))...)
+		if err != nil {
+			return err
+		}
+
+		// a scheduled run has no API call to answer for its freezes, the run
+		// takes its place in the audit log
+		auditCtx := ctx
+		if _, ok := AuditMetaFrom(ctx); !ok {
+			auditCtx = WithAuditMeta(ctx, AuditMeta{
+				RequestID: fmt.Sprintf("reconciliation-%d", result.Run.ID),
+				UserAgent: ReconciliationAuditAgent,
+			})
+		}
+
+		for _, id := range slices.Sorted(maps.Keys(accounts)) {
+			account := accounts[id]
+			if account.Status != AccountStatusActive || account.Product == ProductHouse {
+				continue
+			}
+
+			reason := fmt.Sprintf("reconciliation run %d", result.Run.ID)
+			if _, err := changeAccountStatus(auditCtx, q, account, AccountStatusFrozen, reason); err != nil {
+				return err
+			}
+
+			result.FrozenAccountIDs = append(result.FrozenAccountIDs, id)
+		}
+
+		return nil
+	})
+
+	return result, err
+}
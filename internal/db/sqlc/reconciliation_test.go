@@ -0,0 +1,80 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"github.com/vlone310/bss/internal/money"
+)
+
+func TestReconcileTx(t *testing.T) {
+	account := createRandomAccount(t)
+
+	// a manual update makes the cached balance drift from the entries
+	_, err := testStore.UpdateAccount(context.Background(), UpdateAccountParams{
+		ID:      account.ID,
+		Balance: account.Balance + 1,
+	})
+	require.NoError(t, err)
+
+	result, err := testStore.ReconcileTx(context.Background(), ReconcileTxParams{FreezeAccounts: true})
+	require.NoError(t, err)
+	require.NotZero(t, result.Run.ID)
+	require.Equal(t, int64(len(result.Discrepancies)), result.Run.DiscrepancyCount)
+
+	var found bool
+	for _, d := range result.Discrepancies {
+		if d.AccountID.Int64 == account.ID {
+			found = true
+			require.Equal(t, DiscrepancyKindBalanceMismatch, d.Kind)
+			require.Equal(t, account.Balance, d.Expected)
+			require.Equal(t, account.Balance+1, d.Actual)
+		}
+	}
+	require.True(t, found)
+	require.Contains(t, result.FrozenAccountIDs, account.ID)
+
+	frozen, err := testStore.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, AccountStatusFrozen, frozen.Status)
+
+	events, err := testStore.ListAuditEvents(context.Background(), ListAuditEventsParams{
+		ResourceType: pgtype.Text{String: "account", Valid: true},
+		ResourceID:   pgtype.Text{String: fmt.Sprint(account.ID), Valid: true},
+		LimitCount:   10,
+	})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, AuditActionAccountFreeze, events[0].Action)
+	require.Equal(t, fmt.Sprintf("reconciliation-%d", result.Run.ID), events[0].RequestID)
+	require.Equal(t, ReconciliationAuditAgent, events[0].UserAgent)
+	require.False(t, events[0].Actor.Valid)
+
+	latest, err := testStore.GetLatestReconciliationRun(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, result.Run.ID, latest.ID)
+}
+
+func TestReconcileTxBalancedTransfers(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createAccountInCurrency(t, account1.Currency)
+
+	_, err := testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        money.New(10, account1.Currency),
+	})
+	require.NoError(t, err)
+
+	result, err := testStore.ReconcileTx(context.Background(), ReconcileTxParams{})
+	require.NoError(t, err)
+	require.Empty(t, result.FrozenAccountIDs)
+
+	for _, d := range result.Discrepancies {
+		require.NotEqual(t, account1.ID, d.AccountID.Int64)
+		require.NotEqual(t, account2.ID, d.AccountID.Int64)
+	}
+}
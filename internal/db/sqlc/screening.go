@@ -0,0 +1,365 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/vlone310/bss/internal/event"
+	"github.com/vlone310/bss/internal/money"
+)
+
+const (
+	ScreeningSubjectUser     = "user"
+	ScreeningSubjectTransfer = "transfer"
+)
+
+const (
+	ScreeningCaseOpen      = "open"
+	ScreeningCaseCleared   = "cleared"
+	ScreeningCaseConfirmed = "confirmed"
+)
+
+var ErrScreeningCaseResolved = errors.New("screening case is already resolved")
+
+// ScreeningMatch is a sanctions list entry a name matched.
+type ScreeningMatch struct {
+	ListSource  string  `json:"list_source"`
+	EntryUID    string  `json:"entry_uid"`
+	ListedName  string  `json:"listed_name"`
+	MatchedName string  `json:"matched_name"`
+	Score       float64 `json:"score"`
+}
+
+type OpenScreeningCaseTxParams struct {
+	Username     string           `json:"username"`
+	ScreenedName string           `json:"screened_name"`
+	Matches      []ScreeningMatch `json:"matches"`
+}
+
+type CreateUserTxParams struct {
+	CreateUserParams
+	// Screening holds the sanctions list entries the full name matched. A
+	// case is opened for review when there are any.
+	Screening []ScreeningMatch `json:"screening"`
+}
+
+type CreateUserTxResult struct {
+	User User `json:"user"`
+	// ScreeningCase is the case opened for the name, if it matched.
+	ScreeningCase *ScreeningCase `json:"screening_case,omitempty"`
+}
+
+// CreateUserTx creates a user together with the screening case of their
+// name, so that there is no user who was not screened.
+func (s *SQLStore) CreateUserTx(ctx context.Context, arg CreateUserTxParams) (CreateUserTxResult, error) {
+	var result CreateUserTxResult
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		var err error
+		result.User, err = q.CreateUser(ctx, arg.CreateUserParams)
+		if err != nil {
+			return err
+		}
+
+		err = audit(ctx, q, AuditActionUserCreate, "user", result.User.Username, nil, result.User)
+		if err != nil || len(arg.Screening) == 0 {
+			return err
+		}
+
+		screeningCase, err := openScreeningCase(ctx, q, ScreeningSubjectUser, pgtype.Int8{}, OpenScreeningCaseTxParams{
+			Username:     result.User.Username,
+			ScreenedName: result.User.FullName,
+			Matches:      arg.Screening,
+		})
+		if err != nil {
+			return err
+		}
+		result.ScreeningCase = &screeningCase
+
+		return audit(ctx, q, AuditActionScreeningCaseOpen, "screening_case", screeningCase.ID, nil, screeningCase)
+	})
+
+	return result, err
+}
+
+func openScreeningCase(ctx context.Context, q *Queries, subject string, transferID pgtype.Int8, arg OpenScreeningCaseTxParams) (ScreeningCase, error) {
+	screeningCase, err := q.CreateScreeningCase(ctx, CreateScreeningCaseParams{
+		Subject:      subject,
+		Username:     arg.Username,
+		TransferID:   transferID,
+		ScreenedName: arg.ScreenedName,
+	})
+	if err != nil {
+		return screeningCase, err
+	}
+
+	for _, match := range arg.Matches {
+		_, err = q.CreateScreeningHit(ctx, CreateScreeningHitParams{
+			CaseID:      screeningCase.ID,
+			ListSource:  match.ListSource,
+			EntryUid:    match.EntryUID,
+			ListedName:  match.ListedName,
+			MatchedName: match.MatchedName,
+			Score:       match.Score,
+		})
+		if err != nil {
+			return screeningCase, err
+		}
+	}
+
+	return screeningCase, nil
+}
+
+type HoldTransferTxParams struct {
+	TransferTxParams
+	// Cases holds one entry per party whose name matched.
+	Cases []OpenScreeningCaseTxParams `json:"cases"`
+}
+
+type HoldTransferTxResult struct {
+	Transfer Transfer        `json:"transfer"`
+	Cases    []ScreeningCase `json:"cases"`
+}
+
+// HoldTransferTx records a transfer whose parties matched a sanctions list
+// as pending, without booking it, and opens a case per matched party. The
+// transfer must pass the same rules as TransferTx, so that only transfers
+// that could have been made wait for review.
+func (s *SQLStore) HoldTransferTx(ctx context.Context, arg HoldTransferTxParams) (HoldTransferTxResult, error) {
+	var result HoldTransferTxResult
+
+	if !arg.Amount.IsPositive() {
+		return result, ErrAmountNotPositive
+	}
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		accounts, err := lockActiveAccounts(ctx, q, arg.FromAccountID, arg.ToAccountID)
+		if err != nil {
+			return err
+		}
+
+		if err = checkTransfer(ctx, q, accounts, arg.TransferTxParams); err != nil {
+			return err
+		}
+
+		result.Transfer, err = q.CreatePendingTransfer(ctx, CreatePendingTransferParams{
+			FromAccountID: arg.FromAccountID,
+			ToAccountID:   arg.ToAccountID,
+			AmountCents:   arg.Amount.Amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		transferID := pgtype.Int8{Int64: result.Transfer.ID, Valid: true}
+		for _, screeningCase := range arg.Cases {
+			opened, err := openScreeningCase(ctx, q, ScreeningSubjectTransfer, transferID, screeningCase)
+			if err != nil {
+				return err
+			}
+			result.Cases = append(result.Cases, opened)
+		}
+
+		err = enqueueTransferEvent(ctx, q, event.TypeTransferCreated, result.Transfer, arg.Amount.Currency)
+		if err != nil {
+			return err
+		}
+
+		return audit(ctx, q, AuditActionTransferHold, "transfer", result.Transfer.ID, nil, result.Transfer)
+	})
+
+	return result, err
+}
+
+type ResolveScreeningCaseTxParams struct {
+	CaseID int64 `json:"case_id"`
+	// Status is ScreeningCaseCleared or ScreeningCaseConfirmed.
+	Status     string `json:"status"`
+	Note       string `json:"note"`
+	ResolvedBy string `json:"resolved_by"`
+}
+
+type ResolveScreeningCaseTxResult struct {
+	Case ScreeningCase `json:"case"`
+	// Transfer is the held transfer of a transfer case after the decision.
+	Transfer *Transfer `json:"transfer,omitempty"`
+	// FailureReason tells why a cleared transfer could not be booked.
+	FailureReason string `json:"failure_reason,omitempty"`
+}
+
+// ResolveScreeningCaseTx records the compliance decision on a case. A
+// confirmed case blocks the user, see blockSanctionedUser. A held transfer
+// fails once any of its cases is confirmed, and is booked once all of them
+// are cleared. A transfer that no longer passes the transfer rules
+// at that point, say because the sender spent the money meanwhile, fails.
+func (s *SQLStore) ResolveScreeningCaseTx(ctx context.Context, arg ResolveScreeningCaseTxParams) (ResolveScreeningCaseTxResult, error) {
+	var result ResolveScreeningCaseTxResult
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		screeningCase, err := q.GetScreeningCaseForUpdate(ctx, arg.CaseID)
+		if err != nil {
+			return err
+		}
+		if screeningCase.Status != ScreeningCaseOpen {
+			return fmt.Errorf("case [%d] is %s: %w", screeningCase.ID, screeningCase.Status, ErrScreeningCaseResolved)
+		}
+
+		result.Case, err = q.ResolveScreeningCase(ctx, ResolveScreeningCaseParams{
+			ID:             arg.CaseID,
+			Status:         arg.Status,
+			ResolutionNote: pgtype.Text{String: arg.Note, Valid: arg.Note != ""},
+			ResolvedBy:     pgtype.Text{String: arg.ResolvedBy, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		err = audit(ctx, q, AuditActionScreeningCaseResolve, "screening_case", result.Case.ID, screeningCase, result.Case)
+		if err != nil {
+			return err
+		}
+
+		if arg.Status == ScreeningCaseConfirmed {
+			err = blockSanctionedUser(ctx, q, screeningCase.Username, fmt.Sprintf("screening case %d confirmed", screeningCase.ID))
+			if err != nil {
+				return err
+			}
+		}
+
+		if !screeningCase.TransferID.Valid {
+			return nil
+		}
+
+		transfer, err := q.GetTransferForUpdate(ctx, screeningCase.TransferID.Int64)
+		if err != nil {
+			return err
+		}
+		result.Transfer = &transfer
+
+		// another case of the transfer was confirmed already
+		if transfer.Status != TransferStatusPending {
+			return nil
+		}
+
+		if arg.Status == ScreeningCaseConfirmed {
+			transfer, err = settleTransfer(ctx, q, transfer, TransferStatusFailed)
+			result.Transfer = &transfer
+			return err
+		}
+
+		open, err := q.CountOpenTransferScreeningCases(ctx, screeningCase.TransferID)
+		if err != nil || open > 0 {
+			return err
+		}
+
+		transfer, reason, err := releaseTransfer(ctx, q, transfer)
+		result.Transfer, result.FailureReason = &transfer, reason
+		return err
+	})
+
+	return result, err
+}
+
+// blockBatchSize is how many accounts blockSanctionedUser reads at a time.
+const blockBatchSize = 100
+
+// blockSanctionedUser blocks a user whose sanctions match was confirmed and
+// freezes their active accounts. The accounts are locked before the user
+// row, in the order TransferTx locks them. CreateAccount locks the user row,
+// so the accounts opened before it was locked here are picked up after it.
+func blockSanctionedUser(ctx context.Context, q *Queries, username, reason string) error {
+	var afterID int64
+	for blocked := false; ; {
+		accounts, err := q.ListOwnerAccounts(ctx, ListOwnerAccountsParams{
+			Owner:      username,
+			AfterID:    afterID,
+			LimitCount: blockBatchSize,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, account := range accounts {
+			afterID = account.ID
+			account, err = q.GetAccountForUpdate(ctx, account.ID)
+			if err != nil {
+				return err
+			}
+			if account.Status != AccountStatusActive {
+				continue
+			}
+			if _, err = changeAccountStatus(ctx, q, account, AccountStatusFrozen, reason); err != nil {
+				return err
+			}
+		}
+
+		if len(accounts) == blockBatchSize {
+			continue
+		}
+		if blocked {
+			return nil
+		}
+
+		before, err := q.GetUser(ctx, username)
+		if err != nil {
+			return err
+		}
+		user, err := q.BlockUser(ctx, username)
+		if err != nil {
+			return err
+		}
+		if err = audit(ctx, q, AuditActionUserBlock, "user", username, before, user); err != nil {
+			return err
+		}
+		blocked = true
+	}
+}
+
+// releaseTransfer books a held transfer, or fails it with the reason when it
+// breaks a transfer rule.
+func releaseTransfer(ctx context.Context, q *Queries, transfer Transfer) (Transfer, string, error) {
+	accounts, err := lockActiveAccounts(ctx, q, transfer.FromAccountID, transfer.ToAccountID)
+	if err == nil {
+		err = checkTransfer(ctx, q, accounts, TransferTxParams{
+			FromAccountID: transfer.FromAccountID,
+			ToAccountID:   transfer.ToAccountID,
+			Amount:        money.New(transfer.AmountCents, accounts[transfer.FromAccountID].Currency),
+		})
+	}
+
+	if isTransferRuleError(err) {
+		failed, updateErr := settleTransfer(ctx, q, transfer, TransferStatusFailed)
+		return failed, err.Error(), updateErr
+	}
+	if err != nil {
+		return transfer, "", err
+	}
+
+	transfer, err = settleTransfer(ctx, q, transfer, TransferStatusCompleted)
+	if err != nil {
+		return transfer, "", err
+	}
+
+	from := accounts[transfer.FromAccountID]
+	_, err = postTransfer(ctx, q, accounts, transfer, money.New(transfer.AmountCents, from.Currency), JournalKindTransfer)
+	return transfer, "", err
+}
+
+func isTransferRuleError(err error) bool {
+	for _, target := range []error{
+		ErrAccountNotActive,
+		ErrCurrencyMismatch,
+		ErrInsufficientFunds,
+		ErrWithdrawalLimitReached,
+		ErrMaxBalanceExceeded,
+		ErrKYCRequired,
+		ErrUnverifiedBalanceLimit,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
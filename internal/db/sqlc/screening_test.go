@@ -0,0 +1,208 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+	"github.com/vlone310/bss/internal/money"
+	"github.com/vlone310/bss/testutil"
+	"github.com/vlone310/bss/util"
+)
+
+func screeningCaseFor(username string) OpenScreeningCaseTxParams {
+	return OpenScreeningCaseTxParams{
+		Username:     username,
+		ScreenedName: "Ivan Petrov",
+		Matches: []ScreeningMatch{{
+			ListSource:  "sdn.csv",
+			EntryUID:    "2674",
+			ListedName:  "PETROV, Ivan Sergeyevich",
+			MatchedName: "PETROV, Ivan Sergeyevich",
+			Score:       0.95,
+		}},
+	}
+}
+
+// holdRandomTransfer holds a transfer between two new accounts with a case
+// for the sender.
+func holdRandomTransfer(t *testing.T, amount int64) (Account, Account, HoldTransferTxResult) {
+	t.Helper()
+
+	from := createRandomAccount(t)
+	to := createAccountInCurrency(t, from.Currency)
+
+	result, err := testStore.HoldTransferTx(context.Background(), HoldTransferTxParams{
+		TransferTxParams: TransferTxParams{
+			FromAccountID: from.ID,
+			ToAccountID:   to.ID,
+			Amount:        money.New(amount, from.Currency),
+		},
+		Cases: []OpenScreeningCaseTxParams{screeningCaseFor(from.Owner)},
+	})
+	require.NoError(t, err)
+	require.Equal(t, TransferStatusPending, result.Transfer.Status)
+	require.Len(t, result.Cases, 1)
+	require.Equal(t, ScreeningSubjectTransfer, result.Cases[0].Subject)
+	require.Equal(t, ScreeningCaseOpen, result.Cases[0].Status)
+	require.Equal(t, result.Transfer.ID, result.Cases[0].TransferID.Int64)
+
+	// nothing is booked while the transfer is held
+	held, err := testStore.GetAccount(context.Background(), from.ID)
+	require.NoError(t, err)
+	require.Equal(t, from.Balance, held.Balance)
+
+	return from, to, result
+}
+
+func TestHoldTransferTxClear(t *testing.T) {
+	reviewer := createRandomUser(t)
+	from, to, held := holdRandomTransfer(t, 10)
+
+	result, err := testStore.ResolveScreeningCaseTx(context.Background(), ResolveScreeningCaseTxParams{
+		CaseID:     held.Cases[0].ID,
+		Status:     ScreeningCaseCleared,
+		ResolvedBy: reviewer.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, ScreeningCaseCleared, result.Case.Status)
+	require.Equal(t, reviewer.Username, result.Case.ResolvedBy.String)
+	require.NotNil(t, result.Transfer)
+	require.Equal(t, TransferStatusCompleted, result.Transfer.Status)
+	require.Empty(t, result.FailureReason)
+
+	fromAfter, err := testStore.GetAccount(context.Background(), from.ID)
+	require.NoError(t, err)
+	require.Equal(t, from.Balance-10, fromAfter.Balance)
+
+	toAfter, err := testStore.GetAccount(context.Background(), to.ID)
+	require.NoError(t, err)
+	require.Equal(t, to.Balance+10, toAfter.Balance)
+
+	// the sender is not held again for the same entry
+	cleared, err := testStore.ListClearedScreeningEntries(context.Background(), from.Owner)
+	require.NoError(t, err)
+	require.Equal(t, []ListClearedScreeningEntriesRow{{ListSource: "sdn.csv", EntryUid: "2674"}}, cleared)
+
+	// a decision is final
+	_, err = testStore.ResolveScreeningCaseTx(context.Background(), ResolveScreeningCaseTxParams{
+		CaseID:     held.Cases[0].ID,
+		Status:     ScreeningCaseConfirmed,
+		ResolvedBy: reviewer.Username,
+	})
+	require.ErrorIs(t, err, ErrScreeningCaseResolved)
+}
+
+func TestHoldTransferTxConfirm(t *testing.T) {
+	reviewer := createRandomUser(t)
+	from, _, held := holdRandomTransfer(t, 10)
+
+	result, err := testStore.ResolveScreeningCaseTx(context.Background(), ResolveScreeningCaseTxParams{
+		CaseID:     held.Cases[0].ID,
+		Status:     ScreeningCaseConfirmed,
+		Note:       "same date of birth",
+		ResolvedBy: reviewer.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, ScreeningCaseConfirmed, result.Case.Status)
+	require.Equal(t, "same date of birth", result.Case.ResolutionNote.String)
+	require.Equal(t, TransferStatusFailed, result.Transfer.Status)
+
+	fromAfter, err := testStore.GetAccount(context.Background(), from.ID)
+	require.NoError(t, err)
+	require.Equal(t, from.Balance, fromAfter.Balance)
+
+	// the sender is blocked and their accounts frozen
+	require.Equal(t, AccountStatusFrozen, fromAfter.Status)
+
+	owner, err := testStore.GetUser(context.Background(), from.Owner)
+	require.NoError(t, err)
+	require.True(t, owner.BlockedAt.Valid)
+
+	_, err = testStore.ChangeAccountStatusTx(context.Background(), ChangeAccountStatusTxParams{
+		AccountID: from.ID,
+		Status:    AccountStatusActive,
+	})
+	require.ErrorIs(t, err, ErrUserBlocked)
+
+	_, err = testStore.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    from.Owner,
+		Currency: from.Currency,
+		Product:  ProductSavings,
+	})
+	require.ErrorIs(t, err, ErrUserBlocked)
+}
+
+func TestHoldTransferTxClearInsufficientFunds(t *testing.T) {
+	reviewer := createRandomUser(t)
+	from, to, held := holdRandomTransfer(t, 10)
+
+	// the sender spends the money while the transfer is held
+	_, err := testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        money.New(from.Balance, from.Currency),
+	})
+	require.NoError(t, err)
+
+	result, err := testStore.ResolveScreeningCaseTx(context.Background(), ResolveScreeningCaseTxParams{
+		CaseID:     held.Cases[0].ID,
+		Status:     ScreeningCaseCleared,
+		ResolvedBy: reviewer.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, ScreeningCaseCleared, result.Case.Status)
+	require.Equal(t, TransferStatusFailed, result.Transfer.Status)
+	require.NotEmpty(t, result.FailureReason)
+}
+
+func TestCreateUserTxScreening(t *testing.T) {
+	hashedPassword, err := util.HashPassword(testutil.RandomString(6))
+	require.NoError(t, err)
+
+	screened := screeningCaseFor("")
+	result, err := testStore.CreateUserTx(context.Background(), CreateUserTxParams{
+		CreateUserParams: CreateUserParams{
+			Username:       testutil.RandomOwner(),
+			HashedPassword: hashedPassword,
+			FullName:       screened.ScreenedName,
+			Email:          testutil.RandomEmail(),
+		},
+		Screening: screened.Matches,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, result.ScreeningCase)
+
+	opened := result.ScreeningCase
+	require.Equal(t, ScreeningSubjectUser, opened.Subject)
+	require.Equal(t, result.User.Username, opened.Username)
+	require.Equal(t, screened.ScreenedName, opened.ScreenedName)
+	require.False(t, opened.TransferID.Valid)
+
+	hits, err := testStore.ListScreeningHits(context.Background(), opened.ID)
+	require.NoError(t, err)
+	require.Len(t, hits, 1)
+	require.Equal(t, "2674", hits[0].EntryUid)
+}
+
+func TestCreateUserTxScreeningRollback(t *testing.T) {
+	hashedPassword, err := util.HashPassword(testutil.RandomString(6))
+	require.NoError(t, err)
+
+	// a match that cannot be stored fails the user with it
+	screened := screeningCaseFor("")
+	screened.Matches = append(screened.Matches, screened.Matches[0])
+
+	arg := CreateUserParams{
+		Username:       testutil.RandomOwner(),
+		HashedPassword: hashedPassword,
+		FullName:       screened.ScreenedName,
+		Email:          testutil.RandomEmail(),
+	}
+	_, err = testStore.CreateUserTx(context.Background(), CreateUserTxParams{CreateUserParams: arg, Screening: screened.Matches})
+	require.Error(t, err)
+
+	_, err = testStore.GetUser(context.Background(), arg.Username)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
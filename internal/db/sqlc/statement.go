@@ -0,0 +1,109 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type AccountStatementParams struct {
+	AccountID int64     `json:"account_id"`
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to"`
+}
+
+// StatementLine is a single entry of a statement together with the account
+// balance right after it was booked.
+type StatementLine struct {
+	ListStatementEntriesRow
+	RunningBalance int64 `json:"running_balance"`
+}
+
+type AccountStatement struct {
+	GetStatementSummaryRow
+	Lines []StatementLine `json:"lines"`
+}
+
+// AccountStatement returns the entries booked on the account in [From, To)
+// with running balances.
+func (s *SQLStore) AccountStatement(ctx context.Context, arg AccountStatementParams) (AccountStatement, error) {
+	var result AccountStatement
+
+	err := s.StreamAccountStatement(ctx, arg,
+		func(summary GetStatementSummaryRow) error {
+			result.GetStatementSummaryRow = summary
+			result.Lines = make([]StatementLine, 0, summary.DebitCount+summary.CreditCount)
+			return nil
+		},
+		func(line StatementLine) error {
+			result.Lines = append(result.Lines, line)
+			return nil
+		},
+	)
+
+	return result, err
+}
+
+// StreamAccountStatement calls onSummary once with the statement totals and
+// then onLine for every entry in [From, To), in booking order, without
+// holding the whole period in memory. All figures are read from the same
+// snapshot so they always add up, even while transfers are being made.
+func (s *SQLStore) StreamAccountStatement(
+	ctx context.Context,
+	arg AccountStatementParams,
+	onSummary func(GetStatementSummaryRow) error,
+	onLine func(StatementLine) error,
+) error {
+	return s.readTx(ctx, func(q *Queries) error {
+		from := pgtype.Timestamptz{Time: arg.From, Valid: true}
+		to := pgtype.Timestamptz{Time: arg.To, Valid: true}
+
+		summary, err := q.GetStatementSummary(ctx, GetStatementSummaryParams{
+			AccountID: arg.AccountID,
+			FromTime:  from,
+			ToTime:    to,
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := onSummary(summary); err != nil {
+			return err
+		}
+
+		rows, err := q.db.Query(ctx, listStatementEntries,
+			pgtype.Int8{Int64: arg.AccountID, Valid: true},
+			from,
+			to,
+		)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		balance := summary.OpeningBalance
+		for rows.Next() {
+			var line StatementLine
+			if err := rows.Scan(
+				&line.ID,
+				&line.AmountCents,
+				&line.CreatedAt,
+				&line.TransferID,
+				&line.CounterpartyAccountID,
+				&line.CounterpartyOwner,
+			); err != nil {
+				return err
+			}
+
+			balance += line.AmountCents
+			line.RunningBalance = balance
+
+			if err := onLine(line); err != nil {
+				return err
+			}
+		}
+
+		return rows.Err()
+	})
+}
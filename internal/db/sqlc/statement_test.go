@@ -0,0 +1,63 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vlone310/bss/internal/money"
+)
+
+func TestAccountStatement(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createAccountInCurrency(t, account1.Currency)
+
+	amount := int64(100)
+	transfer := func() {
+		_, err := testStore.TransferTx(context.Background(), TransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        money.New(amount, account1.Currency),
+		})
+		require.NoError(t, err)
+	}
+
+	// history before the statement period
+	transfer()
+	transfer()
+	time.Sleep(10 * time.Millisecond)
+
+	from := time.Now()
+	transfer()
+	transfer()
+	transfer()
+	to := time.Now()
+
+	// history after the statement period
+	time.Sleep(10 * time.Millisecond)
+	transfer()
+
+	statement, err := testStore.AccountStatement(context.Background(), AccountStatementParams{
+		AccountID: account2.ID,
+		From:      from,
+		To:        to,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, account2.Balance+2*amount, statement.OpeningBalance)
+	require.Equal(t, account2.Balance+5*amount, statement.ClosingBalance)
+	require.Equal(t, 3*amount, statement.TotalCredits)
+	require.Zero(t, statement.TotalDebits)
+	require.Len(t, statement.Lines, 3)
+
+	balance := statement.OpeningBalance
+	for _, line := range statement.Lines {
+		balance += line.AmountCents
+		require.Equal(t, balance, line.RunningBalance)
+		require.True(t, line.TransferID.Valid)
+		require.Equal(t, account1.ID, line.CounterpartyAccountID.Int64)
+		require.Equal(t, account1.Owner, line.CounterpartyOwner.String)
+	}
+	require.Equal(t, statement.ClosingBalance, balance)
+}
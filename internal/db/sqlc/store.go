@@ -2,23 +2,57 @@ package db
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/vlone310/bss/internal/event"
+	"github.com/vlone310/bss/internal/money"
 )
 
+const (
+	TransferStatusPending   = "pending"
+	TransferStatusCompleted = "completed"
+	TransferStatusFailed    = "failed"
+)
+
+var ErrAmountNotPositive = errors.New("amount must be positive")
+
 type Store interface {
 	Querier
 	TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error)
+	AccountStatement(ctx context.Context, arg AccountStatementParams) (AccountStatement, error)
+	PostJournalTx(ctx context.Context, arg PostJournalTxParams) (PostJournalTxResult, error)
+	ReconcileTx(ctx context.Context, arg ReconcileTxParams) (ReconcileTxResult, error)
+	PostInterestTx(ctx context.Context, arg PostInterestTxParams) (PostInterestTxResult, error)
+	SetAccountMinBalanceTx(ctx context.Context, arg SetAccountMinBalanceTxParams) (Account, error)
+	ChangeAccountStatusTx(ctx context.Context, arg ChangeAccountStatusTxParams) (Account, error)
+	WalkEntryChain(ctx context.Context, accountID pgtype.Int8, fn func(Entry) error) error
+	CreateLedgerCheckpointTx(ctx context.Context, arg CreateLedgerCheckpointTxParams) (LedgerCheckpoint, error)
+	SetCurrencyEnabledTx(ctx context.Context, arg SetCurrencyEnabledTxParams) (SetCurrencyEnabledTxResult, error)
+	SnapshotBalancesTx(ctx context.Context, arg SnapshotBalancesTxParams) (SnapshotBalancesTxResult, error)
+	CreateUserTx(ctx context.Context, arg CreateUserTxParams) (CreateUserTxResult, error)
+	HoldTransferTx(ctx context.Context, arg HoldTransferTxParams) (HoldTransferTxResult, error)
+	ResolveScreeningCaseTx(ctx context.Context, arg ResolveScreeningCaseTxParams) (ResolveScreeningCaseTxResult, error)
+	ReviewFraudDecisionTx(ctx context.Context, arg ReviewFraudDecisionTxParams) (ReviewFraudDecisionTxResult, error)
+	SetFraudAssessor(assessor FraudAssessor)
+	RelayOutboxTx(ctx context.Context, arg RelayOutboxTxParams) (RelayOutboxTxResult, error)
+	RecordWebhookAttemptTx(ctx context.Context, arg RecordWebhookAttemptTxParams) (WebhookDelivery, error)
+	ListenEntries(ctx context.Context, fn func(EntryNotification)) error
+	StreamAccountStatement(ctx context.Context, arg AccountStatementParams, onSummary func(GetStatementSummaryRow) error, onLine func(StatementLine) error) error
 	Connect(ctx context.Context, dbSource string) error
+	Ping(ctx context.Context) error
+	MigrationVersion(ctx context.Context) (version int64, dirty bool, err error)
 	Close()
 }
 
 type SQLStore struct {
 	*Queries
-	db *pgxpool.Pool
+	db    *pgxpool.Pool
+	fraud FraudAssessor
 }
 
 func NewStore() Store {
@@ -40,6 +74,13 @@ func (s *SQLStore) Connect(ctx context.Context, dbSource string) error {
 		return fmt.Errorf("can not connect to db %v", err)
 	}
 
+	// the pool connects lazily, a wrong address would only show on the
+	// first query
+	if err := connPool.Ping(ctx); err != nil {
+		connPool.Close()
+		return fmt.Errorf("can not reach db %v", err)
+	}
+
 	s.db = connPool
 	s.Queries = New(connPool)
 
@@ -50,8 +91,33 @@ func (s *SQLStore) Close() {
 	s.db.Close()
 }
 
+// Ping checks that a connection of the pool reaches the database.
+func (s *SQLStore) Ping(ctx context.Context) error {
+	return s.db.Ping(ctx)
+}
+
+// MigrationVersion returns the version golang-migrate last applied. A dirty
+// version failed halfway and has to be fixed by hand.
+func (s *SQLStore) MigrationVersion(ctx context.Context) (version int64, dirty bool, err error) {
+	err = s.db.QueryRow(ctx, "SELECT version, dirty FROM schema_migrations").Scan(&version, &dirty)
+	return version, dirty, err
+}
+
 func (s *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) error {
-	tx, err := s.db.Begin(ctx)
+	return s.execTxWithOptions(ctx, pgx.TxOptions{}, fn)
+}
+
+// readTx runs fn in a read-only transaction that sees a single snapshot of the
+// database, so that several queries can be combined into a consistent result.
+func (s *SQLStore) readTx(ctx context.Context, fn func(*Queries) error) error {
+	return s.execTxWithOptions(ctx, pgx.TxOptions{
+		IsoLevel:   pgx.RepeatableRead,
+		AccessMode: pgx.ReadOnly,
+	}, fn)
+}
+
+func (s *SQLStore) execTxWithOptions(ctx context.Context, opts pgx.TxOptions, fn func(*Queries) error) error {
+	tx, err := s.db.BeginTx(ctx, opts)
 	if err != nil {
 		return err
 	}
@@ -71,85 +137,136 @@ func (s *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) error {
 type TransferTxParams struct {
 	FromAccountID int64 `json:"from_account_id"`
 	ToAccountID   int64 `json:"to_account_id"`
-	AmountCents   int64 `json:"amount_cents"`
+	// Amount must be positive and in the currency of both accounts.
+	Amount money.Money `json:"amount"`
 }
 
 type TransferTxResult struct {
-	Transfer    Transfer `json:"transfer"`
-	FromAccount Account  `json:"from_account"`
-	ToAccount   Account  `json:"to_account"`
-	FromEntry   Entry    `json:"from_entry"`
-	ToEntry     Entry    `json:"to_entry"`
+	Transfer    Transfer    `json:"transfer"`
+	Journal     Journal     `json:"journal"`
+	Amount      money.Money `json:"amount"`
+	FromAccount Account     `json:"from_account"`
+	ToAccount   Account     `json:"to_account"`
+	FromEntry   Entry       `json:"from_entry"`
+	ToEntry     Entry       `json:"to_entry"`
+	// FraudDecision is the recorded verdict when a FraudAssessor is set. It
+	// is kept from the JSON, customers do not get to see their score.
+	FraudDecision *FraudDecision `json:"-"`
 }
 
+// TransferTx moves money between two customer accounts of the same currency
+// as a single journal. With a FraudAssessor set the transfer may instead be
+// left pending, or refused with ErrTransferBlocked, see assessTransfer.
 func (s *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error) {
 	var result TransferTxResult
 
-	err := s.execTx(ctx, func(q *Queries) error {
-		var err error
+	if !arg.Amount.IsPositive() {
+		return result, ErrAmountNotPositive
+	}
 
-		// Create the transfer record
-		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams(arg))
+	err := s.execTx(ctx, func(q *Queries) error {
+		// Frozen and closed accounts can neither send nor receive money
+		accounts, err := lockActiveAccounts(ctx, q, arg.FromAccountID, arg.ToAccountID)
 		if err != nil {
 			return err
 		}
 
-		// Create entry for the sender (negative amount)
-		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID:   pgtype.Int8{Int64: arg.FromAccountID, Valid: true},
-			AmountCents: -arg.AmountCents,
-		})
-		if err != nil {
+		if err = checkTransfer(ctx, q, accounts, arg); err != nil {
 			return err
 		}
 
-		// Create entry for the recipient (positive amount)
-		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID:   pgtype.Int8{Int64: arg.ToAccountID, Valid: true},
-			AmountCents: arg.AmountCents,
-		})
+		if s.fraud != nil {
+			result, err = assessTransfer(ctx, q, s.fraud, accounts, arg)
+		} else {
+			result, err = bookTransfer(ctx, q, accounts, arg, JournalKindTransfer)
+		}
 		if err != nil {
 			return err
 		}
 
-		// Update account balances
-		if arg.FromAccountID < arg.ToAccountID {
-			if result.FromAccount, result.ToAccount, err = addMoney(ctx, q, arg.FromAccountID, -arg.AmountCents, arg.ToAccountID, arg.AmountCents); err != nil {
-				return err
-			}
-		} else {
-			if result.ToAccount, result.FromAccount, err = addMoney(ctx, q, arg.ToAccountID, arg.AmountCents, arg.FromAccountID, -arg.AmountCents); err != nil {
-				return err
-			}
+		if result.Transfer.ID == 0 {
+			return audit(ctx, q, AuditActionTransferBlock, "fraud_decision", result.FraudDecision.ID, nil, result.FraudDecision)
 		}
 
-		return nil
+		err = enqueueTransferEvent(ctx, q, event.TypeTransferCreated, result.Transfer, arg.Amount.Currency)
+		if err != nil {
+			return err
+		}
+		return audit(ctx, q, AuditActionTransferCreate, "transfer", result.Transfer.ID, nil, result.Transfer)
 	})
 
+	// the decision to block is committed, the transfer is not made
+	if err == nil && result.FraudDecision != nil && result.FraudDecision.Action == FraudActionBlock {
+		err = ErrTransferBlocked
+	}
+
 	return result, err
 }
 
-func addMoney(
-	ctx context.Context,
-	q *Queries,
-	accountID1 int64,
-	amountCents1 int64,
-	accountID2 int64,
-	amountCents2 int64,
-) (account1 Account, account2 Account, err error) {
-	if account1, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
-		ID:     accountID1,
-		Amount: amountCents1,
-	}); err != nil {
-		return
+// checkTransfer applies the rules a customer transfer must pass. The caller
+// must have locked both accounts.
+func checkTransfer(ctx context.Context, q *Queries, accounts map[int64]Account, arg TransferTxParams) error {
+	from, to := accounts[arg.FromAccountID], accounts[arg.ToAccountID]
+	if from.Currency != to.Currency {
+		return fmt.Errorf("%w: account [%d] is %s, account [%d] is %s", ErrCurrencyMismatch, from.ID, from.Currency, to.ID, to.Currency)
+	}
+	if arg.Amount.Currency != from.Currency {
+		return fmt.Errorf("%w: amount is %s, accounts are %s", ErrCurrencyMismatch, arg.Amount.Currency, from.Currency)
+	}
+
+	if err := checkKYC(ctx, q, from, to, arg.Amount.Amount); err != nil {
+		return err
+	}
+
+	if err := checkWithdrawal(ctx, q, from, arg.Amount.Amount); err != nil {
+		return err
 	}
 
-	if account2, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
-		ID:     accountID2,
-		Amount: amountCents2,
-	}); err != nil {
-		return
+	return checkDeposit(ctx, q, to, arg.Amount.Amount)
+}
+
+// bookTransfer records the transfer and its journal without any checks. The
+// caller must have locked both accounts.
+func bookTransfer(ctx context.Context, q *Queries, accounts map[int64]Account, arg TransferTxParams, kind string) (TransferTxResult, error) {
+	transfer, err := q.CreateTransfer(ctx, CreateTransferParams{
+		FromAccountID: arg.FromAccountID,
+		ToAccountID:   arg.ToAccountID,
+		AmountCents:   arg.Amount.Amount,
+	})
+	if err != nil {
+		return TransferTxResult{}, err
+	}
+
+	return postTransfer(ctx, q, accounts, transfer, arg.Amount, kind)
+}
+
+// postTransfer books the journal of a recorded transfer. The caller must
+// have locked both accounts.
+func postTransfer(ctx context.Context, q *Queries, accounts map[int64]Account, transfer Transfer, amount money.Money, kind string) (TransferTxResult, error) {
+	result := TransferTxResult{Transfer: transfer, Amount: amount}
+
+	journal, err := postJournal(ctx, q, accounts, PostJournalTxParams{
+		Kind: kind,
+		Postings: []Posting{
+			{AccountID: transfer.FromAccountID, AmountCents: -amount.Amount},
+			{AccountID: transfer.ToAccountID, AmountCents: amount.Amount},
+		},
+	}, pgtype.Int8{Int64: transfer.ID, Valid: true})
+	if err != nil {
+		return result, err
+	}
+
+	result.Journal = journal.Journal
+	result.FromEntry, result.ToEntry = journal.Entries[0], journal.Entries[1]
+
+	for _, account := range journal.Accounts {
+		if account.ID == transfer.FromAccountID {
+			result.FromAccount = account
+		}
+		if account.ID == transfer.ToAccountID {
+			result.ToAccount = account
+		}
 	}
 
-	return
+	return result, nil
 }
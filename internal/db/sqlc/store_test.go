@@ -5,11 +5,13 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"github.com/vlone310/bss/internal/db/migration"
+	"github.com/vlone310/bss/internal/money"
 )
 
 func TestTransferTx(t *testing.T) {
 	account1 := createRandomAccount(t)
-	account2 := createRandomAccount(t)
+	account2 := createAccountInCurrency(t, account1.Currency)
 
 	// run n concurrent transfer transactions
 	n := 5
@@ -23,7 +25,7 @@ func TestTransferTx(t *testing.T) {
 			result, err := testStore.TransferTx(context.Background(), TransferTxParams{
 				FromAccountID: account1.ID,
 				ToAccountID:   account2.ID,
-				AmountCents:   amount,
+				Amount:        money.New(amount, account1.Currency),
 			})
 
 			errs <- err
@@ -54,8 +56,9 @@ func TestTransferTx(t *testing.T) {
 		// check from entry
 		fromEntry := result.FromEntry
 		require.NotEmpty(t, fromEntry)
-		require.Equal(t, account1.ID, fromEntry.AccountID.Int64)
+		require.Equal(t, account1.ID, fromEntry.AccountID)
 		require.Equal(t, -amount, fromEntry.AmountCents)
+		require.Equal(t, transfer.ID, fromEntry.TransferID.Int64)
 		require.NotZero(t, fromEntry.ID)
 		require.NotZero(t, fromEntry.CreatedAt.Time)
 
@@ -65,8 +68,9 @@ func TestTransferTx(t *testing.T) {
 		// check to entry
 		toEntry := result.ToEntry
 		require.NotEmpty(t, toEntry)
-		require.Equal(t, account2.ID, toEntry.AccountID.Int64)
+		require.Equal(t, account2.ID, toEntry.AccountID)
 		require.Equal(t, amount, toEntry.AmountCents)
+		require.Equal(t, transfer.ID, toEntry.TransferID.Int64)
 		require.NotZero(t, toEntry.ID)
 		require.NotZero(t, toEntry.CreatedAt.Time)
 
@@ -108,7 +112,7 @@ func TestTransferTx(t *testing.T) {
 
 func TestTransferTxDeadlock(t *testing.T) {
 	account1 := createRandomAccount(t)
-	account2 := createRandomAccount(t)
+	account2 := createAccountInCurrency(t, account1.Currency)
 
 	// run n concurrent transfer transactions
 	n := 10
@@ -128,7 +132,7 @@ func TestTransferTxDeadlock(t *testing.T) {
 			_, err := testStore.TransferTx(context.Background(), TransferTxParams{
 				FromAccountID: fromAccountID,
 				ToAccountID:   toAccountID,
-				AmountCents:   amount,
+				Amount:        money.New(amount, account1.Currency),
 			})
 
 			errs <- err
@@ -151,3 +155,38 @@ func TestTransferTxDeadlock(t *testing.T) {
 	require.Equal(t, account1.Balance, updatedAccount1.Balance)
 	require.Equal(t, account2.Balance, updatedAccount2.Balance)
 }
+
+func TestTransferTxInvalidAmount(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createAccountInCurrency(t, account1.Currency)
+
+	_, err := testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        money.New(0, account1.Currency),
+	})
+	require.ErrorIs(t, err, ErrAmountNotPositive)
+
+	other := "EUR"
+	if account1.Currency == other {
+		other = "USD"
+	}
+	_, err = testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        money.New(100, other),
+	})
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+}
+
+func TestMigrationVersion(t *testing.T) {
+	require.NoError(t, testStore.Ping(context.Background()))
+
+	latest, err := migration.Latest()
+	require.NoError(t, err)
+
+	version, dirty, err := testStore.MigrationVersion(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, latest, version)
+	require.False(t, dirty)
+}
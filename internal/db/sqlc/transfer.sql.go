@@ -7,14 +7,45 @@ package db
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const createPendingTransfer = `-- name: CreatePendingTransfer :one
+INSERT INTO transfers (
+  from_account_id, to_account_id, amount_cents, status
+) VALUES (
+  $1, $2, $3, 'pending'
+) RETURNING id, from_account_id, to_account_id, amount_cents, created_at, status
+`
+
+type CreatePendingTransferParams struct {
+	FromAccountID int64 `json:"from_account_id"`
+	ToAccountID   int64 `json:"to_account_id"`
+	AmountCents   int64 `json:"amount_cents"`
+}
+
+// A pending transfer has no journal yet, it is booked once released.
+func (q *Queries) CreatePendingTransfer(ctx context.Context, arg CreatePendingTransferParams) (Transfer, error) {
+	row := q.db.QueryRow(ctx, createPendingTransfer, arg.FromAccountID, arg.ToAccountID, arg.AmountCents)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.AmountCents,
+		&i.CreatedAt,
+		&i.Status,
+	)
+	return i, err
+}
+
 const createTransfer = `-- name: CreateTransfer :one
 INSERT INTO transfers (
   from_account_id, to_account_id, amount_cents
 ) VALUES (
   $1, $2, $3
-) RETURNING id, from_account_id, to_account_id, amount_cents, created_at
+) RETURNING id, from_account_id, to_account_id, amount_cents, created_at, status
 `
 
 type CreateTransferParams struct {
@@ -32,12 +63,13 @@ func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams)
 		&i.ToAccountID,
 		&i.AmountCents,
 		&i.CreatedAt,
+		&i.Status,
 	)
 	return i, err
 }
 
 const getTransfer = `-- name: GetTransfer :one
-SELECT id, from_account_id, to_account_id, amount_cents, created_at FROM transfers WHERE id = $1 LIMIT 1
+SELECT id, from_account_id, to_account_id, amount_cents, created_at, status FROM transfers WHERE id = $1 LIMIT 1
 `
 
 func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
@@ -49,31 +81,153 @@ func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
 		&i.ToAccountID,
 		&i.AmountCents,
 		&i.CreatedAt,
+		&i.Status,
+	)
+	return i, err
+}
+
+const getTransferForUpdate = `-- name: GetTransferForUpdate :one
+SELECT id, from_account_id, to_account_id, amount_cents, created_at, status FROM transfers WHERE id = $1 LIMIT 1 FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error) {
+	row := q.db.QueryRow(ctx, getTransferForUpdate, id)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.AmountCents,
+		&i.CreatedAt,
+		&i.Status,
 	)
 	return i, err
 }
 
 const listTransfers = `-- name: ListTransfers :many
-SELECT id, from_account_id, to_account_id, amount_cents, created_at FROM transfers
-WHERE from_account_id = $1 OR to_account_id = $2
-ORDER BY id
-LIMIT $3
-OFFSET $4
+SELECT id, from_account_id, to_account_id, amount_cents, created_at, status FROM (
+  SELECT o.id, o.from_account_id, o.to_account_id, o.amount_cents, o.created_at, o.status FROM transfers o
+  WHERE o.from_account_id = $1
+    AND $2::text IN ('out', 'both')
+  UNION ALL
+  SELECT i.id, i.from_account_id, i.to_account_id, i.amount_cents, i.created_at, i.status FROM transfers i
+  WHERE i.to_account_id = $1
+    AND $2::text IN ('in', 'both')
+    -- self transfers are already returned by the outgoing side
+    AND (i.from_account_id <> $1 OR $2::text = 'in')
+) t
+WHERE ($3::timestamptz IS NULL OR t.created_at >= $3)
+  AND ($4::timestamptz IS NULL OR t.created_at < $4)
+  AND ($5::bigint IS NULL OR t.amount_cents >= $5)
+  AND ($6::bigint IS NULL OR t.amount_cents <= $6)
+  AND ($7::varchar IS NULL OR t.status = $7)
+ORDER BY t.id
+LIMIT $9
+OFFSET $8
 `
 
 type ListTransfersParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Limit         int32 `json:"limit"`
-	Offset        int32 `json:"offset"`
+	AccountID   int64              `json:"account_id"`
+	Direction   string             `json:"direction"`
+	FromTime    pgtype.Timestamptz `json:"from_time"`
+	ToTime      pgtype.Timestamptz `json:"to_time"`
+	MinAmount   pgtype.Int8        `json:"min_amount"`
+	MaxAmount   pgtype.Int8        `json:"max_amount"`
+	Status      pgtype.Text        `json:"status"`
+	OffsetCount int32              `json:"offset_count"`
+	LimitCount  int32              `json:"limit_count"`
 }
 
+// Each side of the union is a plain equality on an indexed column, so the
+// planner can use the (from_account_id, to_account_id) composite index for
+// outgoing transfers and the to_account_id index for incoming ones instead of
+// falling back to a sequential scan for the OR.
 func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error) {
 	rows, err := q.db.Query(ctx, listTransfers,
-		arg.FromAccountID,
-		arg.ToAccountID,
-		arg.Limit,
-		arg.Offset,
+		arg.AccountID,
+		arg.Direction,
+		arg.FromTime,
+		arg.ToTime,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.Status,
+		arg.OffsetCount,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.AmountCents,
+			&i.CreatedAt,
+			&i.Status,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransfersAfter = `-- name: ListTransfersAfter :many
+SELECT id, from_account_id, to_account_id, amount_cents, created_at, status FROM (
+  SELECT o.id, o.from_account_id, o.to_account_id, o.amount_cents, o.created_at, o.status FROM transfers o
+  WHERE o.from_account_id = $1
+    AND $2::text IN ('out', 'both')
+    AND (o.created_at, o.id) > ($3::timestamptz, $4::bigint)
+  UNION ALL
+  SELECT i.id, i.from_account_id, i.to_account_id, i.amount_cents, i.created_at, i.status FROM transfers i
+  WHERE i.to_account_id = $1
+    AND $2::text IN ('in', 'both')
+    AND (i.from_account_id <> $1 OR $2::text = 'in')
+    AND (i.created_at, i.id) > ($3::timestamptz, $4::bigint)
+) t
+WHERE ($5::timestamptz IS NULL OR t.created_at >= $5)
+  AND ($6::timestamptz IS NULL OR t.created_at < $6)
+  AND ($7::bigint IS NULL OR t.amount_cents >= $7)
+  AND ($8::bigint IS NULL OR t.amount_cents <= $8)
+  AND ($9::varchar IS NULL OR t.status = $9)
+ORDER BY t.created_at, t.id
+LIMIT $10
+`
+
+type ListTransfersAfterParams struct {
+	AccountID      int64              `json:"account_id"`
+	Direction      string             `json:"direction"`
+	AfterCreatedAt pgtype.Timestamptz `json:"after_created_at"`
+	AfterID        int64              `json:"after_id"`
+	FromTime       pgtype.Timestamptz `json:"from_time"`
+	ToTime         pgtype.Timestamptz `json:"to_time"`
+	MinAmount      pgtype.Int8        `json:"min_amount"`
+	MaxAmount      pgtype.Int8        `json:"max_amount"`
+	Status         pgtype.Text        `json:"status"`
+	LimitCount     int32              `json:"limit_count"`
+}
+
+// Keyset variant of ListTransfers. Both sides of the union are read from the
+// (account, created_at, id) indexes in order and merged.
+func (q *Queries) ListTransfersAfter(ctx context.Context, arg ListTransfersAfterParams) ([]Transfer, error) {
+	rows, err := q.db.Query(ctx, listTransfersAfter,
+		arg.AccountID,
+		arg.Direction,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.FromTime,
+		arg.ToTime,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.Status,
+		arg.LimitCount,
 	)
 	if err != nil {
 		return nil, err
@@ -88,6 +242,7 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 			&i.ToAccountID,
 			&i.AmountCents,
 			&i.CreatedAt,
+			&i.Status,
 		); err != nil {
 			return nil, err
 		}
@@ -98,3 +253,29 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 	}
 	return items, nil
 }
+
+const updateTransferStatus = `-- name: UpdateTransferStatus :one
+UPDATE transfers
+SET status = $1
+WHERE id = $2
+RETURNING id, from_account_id, to_account_id, amount_cents, created_at, status
+`
+
+type UpdateTransferStatusParams struct {
+	Status string `json:"status"`
+	ID     int64  `json:"id"`
+}
+
+func (q *Queries) UpdateTransferStatus(ctx context.Context, arg UpdateTransferStatusParams) (Transfer, error) {
+	row := q.db.QueryRow(ctx, updateTransferStatus, arg.Status, arg.ID)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.AmountCents,
+		&i.CreatedAt,
+		&i.Status,
+	)
+	return i, err
+}
@@ -1,15 +1,152 @@
 package db
 
-import "testing"
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"github.com/vlone310/bss/testutil"
+)
+
+func createRandomTransfer(t *testing.T, account1, account2 Account) Transfer {
+	t.Helper()
+
+	arg := CreateTransferParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		AmountCents:   testutil.RandomMoney() + 1,
+	}
+
+	transfer, err := testStore.CreateTransfer(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, transfer)
+
+	require.Equal(t, arg.FromAccountID, transfer.FromAccountID)
+	require.Equal(t, arg.ToAccountID, transfer.ToAccountID)
+	require.Equal(t, arg.AmountCents, transfer.AmountCents)
+	require.Equal(t, "completed", transfer.Status)
+
+	require.NotZero(t, transfer.ID)
+	require.NotZero(t, transfer.CreatedAt)
+
+	return transfer
+}
 
 func TestCreateTransfer(t *testing.T) {
-	t.Skip("TODO: write test")
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	createRandomTransfer(t, account1, account2)
 }
 
 func TestGetTransfer(t *testing.T) {
-	t.Skip("TODO: write test")
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	transfer1 := createRandomTransfer(t, account1, account2)
+
+	transfer2, err := testStore.GetTransfer(context.Background(), transfer1.ID)
+	require.NoError(t, err)
+	require.NotEmpty(t, transfer2)
+
+	require.Equal(t, transfer1.ID, transfer2.ID)
+	require.Equal(t, transfer1.FromAccountID, transfer2.FromAccountID)
+	require.Equal(t, transfer1.ToAccountID, transfer2.ToAccountID)
+	require.Equal(t, transfer1.AmountCents, transfer2.AmountCents)
+	require.Equal(t, transfer1.Status, transfer2.Status)
+	require.WithinDuration(t, transfer1.CreatedAt.Time, transfer2.CreatedAt.Time, time.Second)
 }
 
 func TestListTransfers(t *testing.T) {
-	t.Skip("TODO: write test")
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	for range 5 {
+		createRandomTransfer(t, account1, account2)
+		createRandomTransfer(t, account2, account1)
+	}
+
+	testCases := []struct {
+		direction string
+		count     int
+		check     func(t *testing.T, transfer Transfer)
+	}{
+		{
+			direction: "out",
+			count:     5,
+			check: func(t *testing.T, transfer Transfer) {
+				require.Equal(t, account1.ID, transfer.FromAccountID)
+			},
+		},
+		{
+			direction: "in",
+			count:     5,
+			check: func(t *testing.T, transfer Transfer) {
+				require.Equal(t, account1.ID, transfer.ToAccountID)
+			},
+		},
+		{
+			direction: "both",
+			count:     10,
+			check: func(t *testing.T, transfer Transfer) {
+				require.True(t, transfer.FromAccountID == account1.ID || transfer.ToAccountID == account1.ID)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.direction, func(t *testing.T) {
+			transfers, err := testStore.ListTransfers(context.Background(), ListTransfersParams{
+				AccountID:   account1.ID,
+				Direction:   tc.direction,
+				LimitCount:  20,
+				OffsetCount: 0,
+			})
+			require.NoError(t, err)
+			require.Len(t, transfers, tc.count)
+
+			for _, transfer := range transfers {
+				tc.check(t, transfer)
+			}
+		})
+	}
+}
+
+func TestListTransfersFilters(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	var transfers []Transfer
+	for range 5 {
+		transfers = append(transfers, createRandomTransfer(t, account1, account2))
+	}
+
+	amount := transfers[0].AmountCents
+	got, err := testStore.ListTransfers(context.Background(), ListTransfersParams{
+		AccountID:   account1.ID,
+		Direction:   "both",
+		FromTime:    pgtype.Timestamptz{Time: transfers[0].CreatedAt.Time.Add(-time.Minute), Valid: true},
+		ToTime:      pgtype.Timestamptz{Time: time.Now().Add(time.Minute), Valid: true},
+		MinAmount:   pgtype.Int8{Int64: amount, Valid: true},
+		MaxAmount:   pgtype.Int8{Int64: amount, Valid: true},
+		Status:      pgtype.Text{String: "completed", Valid: true},
+		LimitCount:  10,
+		OffsetCount: 0,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, got)
+
+	for _, transfer := range got {
+		require.Equal(t, amount, transfer.AmountCents)
+	}
+
+	got, err = testStore.ListTransfers(context.Background(), ListTransfersParams{
+		AccountID:   account1.ID,
+		Direction:   "both",
+		Status:      pgtype.Text{String: "pending", Valid: true},
+		LimitCount:  10,
+		OffsetCount: 0,
+	})
+	require.NoError(t, err)
+	require.Empty(t, got)
 }
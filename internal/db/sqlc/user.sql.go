@@ -9,12 +9,39 @@ import (
 	"context"
 )
 
+const blockUser = `-- name: BlockUser :one
+UPDATE users
+SET blocked_at = COALESCE(blocked_at, now())
+WHERE username = $1
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, role, kyc_exempt, blocked_at, totp_secret, totp_enabled_at, totp_last_step
+`
+
+func (q *Queries) BlockUser(ctx context.Context, username string) (User, error) {
+	row := q.db.QueryRow(ctx, blockUser, username)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Role,
+		&i.KycExempt,
+		&i.BlockedAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+	)
+	return i, err
+}
+
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (
   username, hashed_password, full_name, email
 ) VALUES (
   $1, $2, $3, $4
-) RETURNING username, hashed_password, full_name, email, password_changed_at, created_at
+) RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, role, kyc_exempt, blocked_at, totp_secret, totp_enabled_at, totp_last_step
 `
 
 type CreateUserParams struct {
@@ -39,12 +66,56 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Email,
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
+		&i.Role,
+		&i.KycExempt,
+		&i.BlockedAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+	)
+	return i, err
+}
+
+const enableUserTOTP = `-- name: EnableUserTOTP :one
+UPDATE users
+SET
+  totp_enabled_at = now(),
+  totp_last_step = $1::bigint
+WHERE username = $2
+  AND totp_secret = $3::varchar
+  AND totp_enabled_at IS NULL
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, role, kyc_exempt, blocked_at, totp_secret, totp_enabled_at, totp_last_step
+`
+
+type EnableUserTOTPParams struct {
+	TotpLastStep int64  `json:"totp_last_step"`
+	Username     string `json:"username"`
+	TotpSecret   string `json:"totp_secret"`
+}
+
+// Enable the secret a code was checked with, unless it was replaced since.
+func (q *Queries) EnableUserTOTP(ctx context.Context, arg EnableUserTOTPParams) (User, error) {
+	row := q.db.QueryRow(ctx, enableUserTOTP, arg.TotpLastStep, arg.Username, arg.TotpSecret)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Role,
+		&i.KycExempt,
+		&i.BlockedAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
 	)
 	return i, err
 }
 
 const getUser = `-- name: GetUser :one
-SELECT username, hashed_password, full_name, email, password_changed_at, created_at FROM users WHERE username = $1 LIMIT 1
+SELECT username, hashed_password, full_name, email, password_changed_at, created_at, role, kyc_exempt, blocked_at, totp_secret, totp_enabled_at, totp_last_step FROM users WHERE username = $1 LIMIT 1
 `
 
 func (q *Queries) GetUser(ctx context.Context, username string) (User, error) {
@@ -57,6 +128,85 @@ func (q *Queries) GetUser(ctx context.Context, username string) (User, error) {
 		&i.Email,
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
+		&i.Role,
+		&i.KycExempt,
+		&i.BlockedAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+	)
+	return i, err
+}
+
+const setUserTOTPSecret = `-- name: SetUserTOTPSecret :one
+UPDATE users
+SET
+  totp_secret = $1::varchar,
+  totp_enabled_at = NULL,
+  totp_last_step = NULL
+WHERE username = $2 AND totp_enabled_at IS NULL
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, role, kyc_exempt, blocked_at, totp_secret, totp_enabled_at, totp_last_step
+`
+
+type SetUserTOTPSecretParams struct {
+	TotpSecret string `json:"totp_secret"`
+	Username   string `json:"username"`
+}
+
+// Start an enrollment with a new secret, which is not used before a code of
+// it is verified. Fails with no rows once the secret is enabled.
+func (q *Queries) SetUserTOTPSecret(ctx context.Context, arg SetUserTOTPSecretParams) (User, error) {
+	row := q.db.QueryRow(ctx, setUserTOTPSecret, arg.TotpSecret, arg.Username)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Role,
+		&i.KycExempt,
+		&i.BlockedAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+	)
+	return i, err
+}
+
+const useUserTOTPStep = `-- name: UseUserTOTPStep :one
+UPDATE users
+SET totp_last_step = $1::bigint
+WHERE username = $2
+  AND totp_enabled_at IS NOT NULL
+  AND (totp_last_step IS NULL OR totp_last_step < $1::bigint)
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, role, kyc_exempt, blocked_at, totp_secret, totp_enabled_at, totp_last_step
+`
+
+type UseUserTOTPStepParams struct {
+	TotpLastStep int64  `json:"totp_last_step"`
+	Username     string `json:"username"`
+}
+
+// Record the time step of a code that was used. Fails with no rows when a
+// code of that step or a later one was used already.
+func (q *Queries) UseUserTOTPStep(ctx context.Context, arg UseUserTOTPStepParams) (User, error) {
+	row := q.db.QueryRow(ctx, useUserTOTPStep, arg.TotpLastStep, arg.Username)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Role,
+		&i.KycExempt,
+		&i.BlockedAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
 	)
 	return i, err
 }
@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/stretchr/testify/require"
 	"github.com/vlone310/bss/testutil"
 	"github.com/vlone310/bss/util"
@@ -54,3 +55,32 @@ func TestGetUser(t *testing.T) {
 	require.WithinDuration(t, user1.PasswordChangedAt.Time, user2.PasswordChangedAt.Time, time.Second)
 	require.WithinDuration(t, user1.CreatedAt.Time, user2.CreatedAt.Time, time.Second)
 }
+
+func TestUserTOTP(t *testing.T) {
+	user := createRandomUser(t)
+
+	// a step cannot be used before the secret is enabled
+	_, err := testStore.UseUserTOTPStep(context.Background(), UseUserTOTPStepParams{Username: user.Username, TotpLastStep: 10})
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	enrolled, err := testStore.SetUserTOTPSecret(context.Background(), SetUserTOTPSecretParams{Username: user.Username, TotpSecret: "JBSWY3DPEHPK3PXP"})
+	require.NoError(t, err)
+	require.Equal(t, "JBSWY3DPEHPK3PXP", enrolled.TotpSecret.String)
+	require.False(t, enrolled.TotpEnabledAt.Valid)
+
+	enabled, err := testStore.EnableUserTOTP(context.Background(), EnableUserTOTPParams{Username: user.Username, TotpSecret: enrolled.TotpSecret.String, TotpLastStep: 10})
+	require.NoError(t, err)
+	require.True(t, enabled.TotpEnabledAt.Valid)
+
+	// an enabled secret is not replaced
+	_, err = testStore.SetUserTOTPSecret(context.Background(), SetUserTOTPSecretParams{Username: user.Username, TotpSecret: "KRSXG5DSN5XW4ZLP"})
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	// every step is used once, and never after a later one
+	_, err = testStore.UseUserTOTPStep(context.Background(), UseUserTOTPStepParams{Username: user.Username, TotpLastStep: 10})
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	used, err := testStore.UseUserTOTPStep(context.Background(), UseUserTOTPStepParams{Username: user.Username, TotpLastStep: 11})
+	require.NoError(t, err)
+	require.Equal(t, int64(11), used.TotpLastStep.Int64)
+}
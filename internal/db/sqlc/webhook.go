@@ -0,0 +1,35 @@
+package db
+
+import (
+	"context"
+)
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryDead      = "dead"
+)
+
+type RecordWebhookAttemptTxParams struct {
+	Attempt CreateWebhookDeliveryAttemptParams `json:"attempt"`
+	// Delivery is the state of the delivery after the attempt.
+	Delivery UpdateWebhookDeliveryParams `json:"delivery"`
+}
+
+// RecordWebhookAttemptTx logs an attempt to deliver a webhook together with
+// the state it left the delivery in.
+func (s *SQLStore) RecordWebhookAttemptTx(ctx context.Context, arg RecordWebhookAttemptTxParams) (WebhookDelivery, error) {
+	var result WebhookDelivery
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		_, err := q.CreateWebhookDeliveryAttempt(ctx, arg.Attempt)
+		if err != nil {
+			return err
+		}
+
+		result, err = q.UpdateWebhookDelivery(ctx, arg.Delivery)
+		return err
+	})
+
+	return result, err
+}
@@ -0,0 +1,143 @@
+package db
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"github.com/vlone310/bss/internal/event"
+	"github.com/vlone310/bss/testutil"
+)
+
+func createRandomWebhookEndpoint(t *testing.T) WebhookEndpoint {
+	t.Helper()
+
+	user := createRandomUser(t)
+	endpoint, err := testStore.CreateWebhookEndpoint(context.Background(), CreateWebhookEndpointParams{
+		Owner:      user.Username,
+		Url:        "https://example.com/hooks",
+		Secret:     testutil.RandomString(32),
+		EventTypes: []string{event.TypeTransferCompleted},
+	})
+	require.NoError(t, err)
+	return endpoint
+}
+
+func TestListWebhookEndpointsForEvent(t *testing.T) {
+	endpoint := createRandomWebhookEndpoint(t)
+	all, err := testStore.CreateWebhookEndpoint(context.Background(), CreateWebhookEndpointParams{
+		Owner:      endpoint.Owner,
+		Url:        "https://example.com/all",
+		Secret:     testutil.RandomString(32),
+		EventTypes: []string{},
+	})
+	require.NoError(t, err)
+
+	for eventType, want := range map[string][]int64{
+		event.TypeTransferCompleted: {endpoint.ID, all.ID},
+		event.TypeAccountCreated:    {all.ID},
+	} {
+		endpoints, err := testStore.ListWebhookEndpointsForEvent(context.Background(), ListWebhookEndpointsForEventParams{
+			Owners:    []string{endpoint.Owner},
+			EventType: eventType,
+		})
+		require.NoError(t, err)
+
+		var ids []int64
+		for _, e := range endpoints {
+			ids = append(ids, e.ID)
+		}
+		require.Equal(t, want, ids, eventType)
+	}
+}
+
+func TestWebhookDelivery(t *testing.T) {
+	endpoint := createRandomWebhookEndpoint(t)
+	ctx := context.Background()
+
+	// an event published twice is queued once
+	for range 2 {
+		err := testStore.CreateWebhookDelivery(ctx, CreateWebhookDeliveryParams{
+			EndpointID: endpoint.ID,
+			EventID:    testutil.RandomInt(1, 1_000_000),
+			EventType:  event.TypeTransferCompleted,
+			Payload:    []byte(`{"id":1}`),
+		})
+		require.NoError(t, err)
+	}
+	deliveries, err := testStore.ListWebhookDeliveries(ctx, ListWebhookDeliveriesParams{EndpointID: endpoint.ID, LimitCount: 10})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 2)
+
+	err = testStore.CreateWebhookDelivery(ctx, CreateWebhookDeliveryParams{
+		EndpointID: endpoint.ID,
+		EventID:    deliveries[0].EventID,
+		EventType:  event.TypeTransferCompleted,
+		Payload:    []byte(`{"id":1}`),
+	})
+	require.NoError(t, err)
+	deliveries, err = testStore.ListWebhookDeliveries(ctx, ListWebhookDeliveriesParams{EndpointID: endpoint.ID, LimitCount: 10})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 2)
+	delivery := deliveries[0]
+
+	claimUntil := time.Now().Add(time.Hour)
+	claimed, err := testStore.ClaimDueWebhookDeliveries(ctx, ClaimDueWebhookDeliveriesParams{
+		ClaimUntil: pgtype.Timestamptz{Time: claimUntil, Valid: true},
+		LimitCount: 1000,
+	})
+	require.NoError(t, err)
+	require.True(t, slices.ContainsFunc(claimed, func(d WebhookDelivery) bool { return d.ID == delivery.ID }))
+
+	// a claimed delivery is not due again until the claim expires
+	claimed, err = testStore.ClaimDueWebhookDeliveries(ctx, ClaimDueWebhookDeliveriesParams{
+		ClaimUntil: pgtype.Timestamptz{Time: claimUntil, Valid: true},
+		LimitCount: 1000,
+	})
+	require.NoError(t, err)
+	require.False(t, slices.ContainsFunc(claimed, func(d WebhookDelivery) bool { return d.ID == delivery.ID }))
+
+	failure := pgtype.Text{String: "endpoint responded 500", Valid: true}
+	updated, err := testStore.RecordWebhookAttemptTx(ctx, RecordWebhookAttemptTxParams{
+		Attempt: CreateWebhookDeliveryAttemptParams{
+			DeliveryID: delivery.ID,
+			StatusCode: pgtype.Int4{Int32: 500, Valid: true},
+			Error:      failure,
+			DurationMs: 12,
+		},
+		Delivery: UpdateWebhookDeliveryParams{
+			ID:            delivery.ID,
+			Status:        WebhookDeliveryDead,
+			Attempts:      1,
+			NextAttemptAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+			LastError:     failure,
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, WebhookDeliveryDead, updated.Status)
+	require.Equal(t, failure, updated.LastError)
+
+	attempts, err := testStore.ListWebhookDeliveryAttempts(ctx, delivery.ID)
+	require.NoError(t, err)
+	require.Len(t, attempts, 1)
+	require.Equal(t, int32(500), attempts[0].StatusCode.Int32)
+
+	redelivered, err := testStore.RedeliverWebhookDelivery(ctx, delivery.ID)
+	require.NoError(t, err)
+	require.Equal(t, WebhookDeliveryPending, redelivered.Status)
+	require.Zero(t, redelivered.Attempts)
+	require.False(t, redelivered.LastError.Valid)
+
+	// a pending delivery is not queued again
+	_, err = testStore.RedeliverWebhookDelivery(ctx, delivery.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	// deleting the endpoint deletes its deliveries
+	require.NoError(t, testStore.DeleteWebhookEndpoint(ctx, endpoint.ID))
+	_, err = testStore.GetWebhookDelivery(ctx, delivery.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
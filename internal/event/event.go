@@ -0,0 +1,84 @@
+// Package event defines the domain events the store writes to its outbox and
+// the publishers the outbox relay hands them to.
+//
+// Every event is an Event envelope whose Data follows the schema of its Type
+// and Version. A schema only ever gains optional fields; anything else is a
+// new version, published under the same type, so that consumers can tell the
+// two apart.
+package event
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/vlone310/bss/internal/money"
+)
+
+// Event types. The aggregate of an event is the resource it is about, the
+// events of an aggregate are published in the order they happened.
+const (
+	TypeAccountCreated    = "account.created"
+	TypeAccountFrozen     = "account.frozen"
+	TypeAccountUnfrozen   = "account.unfrozen"
+	TypeAccountClosed     = "account.closed"
+	TypeTransferCreated   = "transfer.created"
+	TypeTransferCompleted = "transfer.completed"
+	TypeTransferFailed    = "transfer.failed"
+)
+
+// Types lists every event type, for consumers that subscribe to some of them.
+var Types = []string{
+	TypeAccountCreated,
+	TypeAccountFrozen,
+	TypeAccountUnfrozen,
+	TypeAccountClosed,
+	TypeTransferCreated,
+	TypeTransferCompleted,
+	TypeTransferFailed,
+}
+
+const (
+	AggregateAccount  = "account"
+	AggregateTransfer = "transfer"
+)
+
+// Current schema versions of the event data.
+const (
+	AccountVersion  = 1
+	TransferVersion = 1
+)
+
+// Event is the envelope every event is published in.
+type Event struct {
+	// ID grows with every event, a consumer can use it to drop the
+	// duplicates of at-least-once delivery.
+	ID            int64           `json:"id"`
+	Type          string          `json:"type"`
+	Version       int32           `json:"version"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   string          `json:"aggregate_id"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	Data          json.RawMessage `json:"data"`
+}
+
+// AccountV1 is the data of the account events, version 1.
+type AccountV1 struct {
+	ID           int64  `json:"id"`
+	Owner        string `json:"owner"`
+	Currency     string `json:"currency"`
+	Product      string `json:"product"`
+	Status       string `json:"status"`
+	StatusReason string `json:"status_reason,omitempty"`
+}
+
+// TransferV1 is the data of the transfer events, version 1.
+type TransferV1 struct {
+	ID            int64      `json:"id"`
+	FromAccountID int64      `json:"from_account_id"`
+	ToAccountID   int64      `json:"to_account_id"`
+	Amount        money.JSON `json:"amount"`
+	// Status is pending for a transfer.created that waits for a review
+	// or confirmation, its transfer.completed or transfer.failed follows.
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,100 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"slices"
+	"sync"
+)
+
+// Publisher delivers events to their consumers. Publish returns once the
+// event is delivered; an event that fails is published again later, so a
+// publisher may see the same event more than once.
+type Publisher interface {
+	Publish(ctx context.Context, e Event) error
+}
+
+// WriterPublisher writes each event as a line of JSON, to stdout or a file
+// that other processes tail.
+type WriterPublisher struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+	sync    func() error
+}
+
+func NewWriterPublisher(w io.Writer) *WriterPublisher {
+	return &WriterPublisher{encoder: json.NewEncoder(w)}
+}
+
+// NewFilePublisher appends the events to the file at path, creating it when
+// needed. Every event is synced to disk before Publish returns.
+func NewFilePublisher(path string) (*WriterPublisher, io.Closer, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p := NewWriterPublisher(f)
+	p.sync = f.Sync
+	return p, f, nil
+}
+
+func (p *WriterPublisher) Publish(_ context.Context, e Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.encoder.Encode(e); err != nil {
+		return err
+	}
+	if p.sync != nil {
+		return p.sync()
+	}
+	return nil
+}
+
+// Publishers publishes each event to all of its publishers in turn. It fails
+// when one of them fails, and the event is then published to all of them
+// again.
+type Publishers []Publisher
+
+func (p Publishers) Publish(ctx context.Context, e Event) error {
+	for _, publisher := range p {
+		if err := publisher.Publish(ctx, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MemoryPublisher keeps the events it is given, for tests. Fail makes
+// Publish fail for the events it returns an error for.
+type MemoryPublisher struct {
+	Fail func(e Event) error
+
+	mu     sync.Mutex
+	events []Event
+}
+
+func (p *MemoryPublisher) Publish(_ context.Context, e Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.Fail != nil {
+		if err := p.Fail(e); err != nil {
+			return err
+		}
+	}
+
+	p.events = append(p.events, e)
+	return nil
+}
+
+// Events returns the events published so far, in order.
+func (p *MemoryPublisher) Events() []Event {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return slices.Clone(p.events)
+}
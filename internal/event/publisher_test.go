@@ -0,0 +1,101 @@
+package event
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testEvent(id int64, aggregateID string) Event {
+	return Event{
+		ID:            id,
+		Type:          TypeAccountCreated,
+		Version:       AccountVersion,
+		AggregateType: AggregateAccount,
+		AggregateID:   aggregateID,
+		OccurredAt:    time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC),
+		Data:          json.RawMessage(`{"id":` + aggregateID + `}`),
+	}
+}
+
+func readEvents(t *testing.T, data []byte) []Event {
+	t.Helper()
+
+	var events []Event
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		var e Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &e))
+		events = append(events, e)
+	}
+	require.NoError(t, scanner.Err())
+	return events
+}
+
+func TestWriterPublisher(t *testing.T) {
+	var buf bytes.Buffer
+	publisher := NewWriterPublisher(&buf)
+
+	require.NoError(t, publisher.Publish(context.Background(), testEvent(1, "7")))
+	require.NoError(t, publisher.Publish(context.Background(), testEvent(2, "8")))
+
+	require.Equal(t, []Event{testEvent(1, "7"), testEvent(2, "8")}, readEvents(t, buf.Bytes()))
+}
+
+func TestFilePublisher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+
+	for i := range 2 {
+		publisher, f, err := NewFilePublisher(path)
+		require.NoError(t, err)
+		require.NoError(t, publisher.Publish(context.Background(), testEvent(int64(i+1), "7")))
+		require.NoError(t, f.Close())
+	}
+
+	// a restart appends to the file
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, []Event{testEvent(1, "7"), testEvent(2, "7")}, readEvents(t, data))
+}
+
+func TestMemoryPublisher(t *testing.T) {
+	publisher := &MemoryPublisher{
+		Fail: func(e Event) error {
+			if e.AggregateID == "8" {
+				return errors.New("broker unavailable")
+			}
+			return nil
+		},
+	}
+
+	require.NoError(t, publisher.Publish(context.Background(), testEvent(1, "7")))
+	require.Error(t, publisher.Publish(context.Background(), testEvent(2, "8")))
+	require.Equal(t, []Event{testEvent(1, "7")}, publisher.Events())
+}
+
+func TestPublishers(t *testing.T) {
+	first := &MemoryPublisher{}
+	second := &MemoryPublisher{
+		Fail: func(e Event) error {
+			if e.AggregateID == "8" {
+				return errors.New("broker unavailable")
+			}
+			return nil
+		},
+	}
+	publishers := Publishers{first, second}
+
+	require.NoError(t, publishers.Publish(context.Background(), testEvent(1, "7")))
+	require.Error(t, publishers.Publish(context.Background(), testEvent(2, "8")))
+
+	require.Equal(t, []Event{testEvent(1, "7"), testEvent(2, "8")}, first.Events())
+	require.Equal(t, []Event{testEvent(1, "7")}, second.Events())
+}
@@ -0,0 +1,239 @@
+package export
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+const (
+	camt053Namespace   = "urn:iso:std:iso:20022:tech:xsd:camt.053.001.02"
+	camtDateTimeLayout = "2006-01-02T15:04:05Z"
+)
+
+type camtAmount struct {
+	Currency string `xml:"Ccy,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type camtDateTime struct {
+	DtTm string `xml:"DtTm"`
+}
+
+type camtAccountID struct {
+	ID string `xml:"Othr>Id"`
+}
+
+type camtGroupHeader struct {
+	XMLName xml.Name `xml:"GrpHdr"`
+	MsgID   string   `xml:"MsgId"`
+	CreDtTm string   `xml:"CreDtTm"`
+}
+
+type camtPeriod struct {
+	XMLName xml.Name `xml:"FrToDt"`
+	FrDtTm  string   `xml:"FrDtTm"`
+	ToDtTm  string   `xml:"ToDtTm"`
+}
+
+type camtAccount struct {
+	XMLName xml.Name      `xml:"Acct"`
+	ID      camtAccountID `xml:"Id"`
+	Ccy     string        `xml:"Ccy"`
+	Owner   string        `xml:"Ownr>Nm"`
+}
+
+type camtBalance struct {
+	XMLName   xml.Name     `xml:"Bal"`
+	Code      string       `xml:"Tp>CdOrPrtry>Cd"`
+	Amt       camtAmount   `xml:"Amt"`
+	CdtDbtInd string       `xml:"CdtDbtInd"`
+	Dt        camtDateTime `xml:"Dt"`
+}
+
+type camtNumberAndSum struct {
+	NbOfNtries int64  `xml:"NbOfNtries"`
+	Sum        string `xml:"Sum"`
+}
+
+type camtTransactionsSummary struct {
+	XMLName      xml.Name         `xml:"TxsSummry"`
+	NbOfNtries   int64            `xml:"TtlNtries>NbOfNtries"`
+	TtlCdtNtries camtNumberAndSum `xml:"TtlCdtNtries"`
+	TtlDbtNtries camtNumberAndSum `xml:"TtlDbtNtries"`
+}
+
+type camtParty struct {
+	Name string `xml:"Nm"`
+}
+
+type camtRelatedParties struct {
+	Dbtr     *camtParty     `xml:"Dbtr,omitempty"`
+	DbtrAcct *camtAccountID `xml:"DbtrAcct>Id,omitempty"`
+	Cdtr     *camtParty     `xml:"Cdtr,omitempty"`
+	CdtrAcct *camtAccountID `xml:"CdtrAcct>Id,omitempty"`
+}
+
+type camtEntry struct {
+	XMLName    xml.Name            `xml:"Ntry"`
+	NtryRef    string              `xml:"NtryRef"`
+	Amt        camtAmount          `xml:"Amt"`
+	CdtDbtInd  string              `xml:"CdtDbtInd"`
+	Sts        string              `xml:"Sts"`
+	BookgDt    camtDateTime        `xml:"BookgDt"`
+	ValDt      camtDateTime        `xml:"ValDt"`
+	Domain     string              `xml:"BkTxCd>Domn>Cd"`
+	Family     string              `xml:"BkTxCd>Domn>Fmly>Cd"`
+	SubFamily  string              `xml:"BkTxCd>Domn>Fmly>SubFmlyCd"`
+	EndToEndID string              `xml:"NtryDtls>TxDtls>Refs>EndToEndId"`
+	RltdPties  *camtRelatedParties `xml:"NtryDtls>TxDtls>RltdPties,omitempty"`
+}
+
+// camt053Encoder writes an ISO 20022 bank to customer statement
+// (camt.053.001.02) holding a single statement.
+type camt053Encoder struct {
+	w         io.Writer
+	enc       *xml.Encoder
+	statement Statement
+}
+
+func newCAMT053Encoder(w io.Writer) *camt053Encoder {
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return &camt053Encoder{w: w, enc: enc}
+}
+
+func (e *camt053Encoder) Begin(statement Statement) error {
+	e.statement = statement
+
+	messageID := fmt.Sprintf("STMT-%d-%s", statement.AccountID, statement.GeneratedAt.UTC().Format("20060102150405"))
+	created := camtTime(statement.GeneratedAt)
+
+	document := xml.StartElement{
+		Name: xml.Name{Local: "Document"},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: camt053Namespace}},
+	}
+	if _, err := io.WriteString(e.w, xml.Header); err != nil {
+		return err
+	}
+	if err := encodeTokens(e.enc, document, start("BkToCstmrStmt")); err != nil {
+		return err
+	}
+
+	if err := e.enc.Encode(camtGroupHeader{MsgID: messageID, CreDtTm: created}); err != nil {
+		return err
+	}
+
+	if err := encodeTokens(e.enc, start("Stmt")); err != nil {
+		return err
+	}
+
+	if err := e.enc.EncodeElement(messageID, start("Id")); err != nil {
+		return err
+	}
+	if err := e.enc.EncodeElement(created, start("CreDtTm")); err != nil {
+		return err
+	}
+	if err := e.enc.Encode(camtPeriod{
+		FrDtTm: camtTime(statement.From),
+		ToDtTm: camtTime(statement.To),
+	}); err != nil {
+		return err
+	}
+
+	if err := e.enc.Encode(camtAccount{
+		ID:    camtAccountID{ID: strconv.FormatInt(statement.AccountID, 10)},
+		Ccy:   statement.Currency,
+		Owner: statement.Owner,
+	}); err != nil {
+		return err
+	}
+
+	if err := e.enc.Encode(e.balance("OPBD", statement.OpeningBalance, statement.From)); err != nil {
+		return err
+	}
+	if err := e.enc.Encode(e.balance("CLBD", statement.ClosingBalance, statement.To)); err != nil {
+		return err
+	}
+
+	return e.enc.Encode(camtTransactionsSummary{
+		NbOfNtries: statement.CreditCount + statement.DebitCount,
+		TtlCdtNtries: camtNumberAndSum{
+			NbOfNtries: statement.CreditCount,
+			Sum:        formatAmount(statement.TotalCredits, statement.MinorUnits),
+		},
+		TtlDbtNtries: camtNumberAndSum{
+			NbOfNtries: statement.DebitCount,
+			Sum:        formatAmount(statement.TotalDebits, statement.MinorUnits),
+		},
+	})
+}
+
+func (e *camt053Encoder) Entry(entry Entry) error {
+	booked := camtDateTime{DtTm: camtTime(entry.BookedAt)}
+
+	ntry := camtEntry{
+		NtryRef:    strconv.FormatInt(entry.ID, 10),
+		Amt:        camtAmount{Currency: e.statement.Currency, Value: formatAmount(abs(entry.AmountCents), e.statement.MinorUnits)},
+		CdtDbtInd:  creditDebitIndicator(entry.AmountCents),
+		Sts:        "BOOK",
+		BookgDt:    booked,
+		ValDt:      booked,
+		Domain:     "PMNT",
+		Family:     "RCDT",
+		SubFamily:  "BOOK",
+		EndToEndID: "NOTPROVIDED",
+	}
+	if entry.AmountCents < 0 {
+		ntry.Family = "ICDT"
+	}
+
+	if entry.TransferID != 0 {
+		ntry.EndToEndID = strconv.FormatInt(entry.TransferID, 10)
+	}
+
+	if entry.CounterpartyAccountID != 0 {
+		party := &camtParty{Name: entry.CounterpartyOwner}
+		account := &camtAccountID{ID: strconv.FormatInt(entry.CounterpartyAccountID, 10)}
+		if entry.AmountCents < 0 {
+			ntry.RltdPties = &camtRelatedParties{Cdtr: party, CdtrAcct: account}
+		} else {
+			ntry.RltdPties = &camtRelatedParties{Dbtr: party, DbtrAcct: account}
+		}
+	}
+
+	return e.enc.Encode(ntry)
+}
+
+func (e *camt053Encoder) End() error {
+	if err := encodeTokens(e.enc, end("Stmt"), end("BkToCstmrStmt"), end("Document")); err != nil {
+		return err
+	}
+	if err := e.enc.Close(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(e.w, "\n")
+	return err
+}
+
+func (e *camt053Encoder) balance(code string, amount int64, at time.Time) camtBalance {
+	return camtBalance{
+		Code:      code,
+		Amt:       camtAmount{Currency: e.statement.Currency, Value: formatAmount(abs(amount), e.statement.MinorUnits)},
+		CdtDbtInd: creditDebitIndicator(amount),
+		Dt:        camtDateTime{DtTm: camtTime(at)},
+	}
+}
+
+func camtTime(t time.Time) string {
+	return t.UTC().Format(camtDateTimeLayout)
+}
+
+func creditDebitIndicator(amount int64) string {
+	if amount < 0 {
+		return "DBIT"
+	}
+	return "CRDT"
+}
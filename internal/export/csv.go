@@ -0,0 +1,60 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+)
+
+var csvHeader = []string{
+	"entry_id",
+	"booked_at",
+	"transfer_id",
+	"counterparty_account_id",
+	"counterparty_owner",
+	"amount",
+	"running_balance",
+	"currency",
+}
+
+type csvEncoder struct {
+	w          *csv.Writer
+	currency   string
+	minorUnits int32
+}
+
+func newCSVEncoder(w io.Writer) *csvEncoder {
+	return &csvEncoder{w: csv.NewWriter(w)}
+}
+
+func (e *csvEncoder) Begin(statement Statement) error {
+	e.currency = statement.Currency
+	e.minorUnits = statement.MinorUnits
+	return e.w.Write(csvHeader)
+}
+
+func (e *csvEncoder) Entry(entry Entry) error {
+	return e.w.Write([]string{
+		strconv.FormatInt(entry.ID, 10),
+		entry.BookedAt.UTC().Format(time.RFC3339),
+		optionalID(entry.TransferID),
+		optionalID(entry.CounterpartyAccountID),
+		entry.CounterpartyOwner,
+		formatAmount(entry.AmountCents, e.minorUnits),
+		formatAmount(entry.RunningBalance, e.minorUnits),
+		e.currency,
+	})
+}
+
+func (e *csvEncoder) End() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func optionalID(id int64) string {
+	if id == 0 {
+		return ""
+	}
+	return strconv.FormatInt(id, 10)
+}
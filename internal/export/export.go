@@ -0,0 +1,109 @@
+// Package export renders account statements in formats understood by
+// accounting software. Encoders write entries as they are handed over, so a
+// statement of any size can be streamed without being held in memory.
+package export
+
+import (
+	"errors"
+	"io"
+	"time"
+
+	"github.com/vlone310/bss/internal/money"
+)
+
+type Format string
+
+const (
+	FormatCSV     Format = "csv"
+	FormatOFX     Format = "ofx"
+	FormatCAMT053 Format = "camt053"
+)
+
+var ErrUnknownFormat = errors.New("unknown export format")
+
+// Statement describes the exported period. Amounts are in minor units of
+// the currency, which has MinorUnits decimals.
+type Statement struct {
+	AccountID      int64
+	Owner          string
+	Currency       string
+	MinorUnits     int32
+	From           time.Time
+	To             time.Time
+	OpeningBalance int64
+	ClosingBalance int64
+	TotalDebits    int64
+	TotalCredits   int64
+	DebitCount     int64
+	CreditCount    int64
+	GeneratedAt    time.Time
+}
+
+// Entry is a single booked entry of the statement. TransferID and
+// CounterpartyAccountID are zero when the entry is not linked to a transfer.
+type Entry struct {
+	ID                    int64
+	TransferID            int64
+	AmountCents           int64
+	RunningBalance        int64
+	BookedAt              time.Time
+	CounterpartyAccountID int64
+	CounterpartyOwner     string
+}
+
+// Encoder writes a statement. Begin must be called once before any Entry and
+// End once after the last one.
+type Encoder interface {
+	Begin(statement Statement) error
+	Entry(entry Entry) error
+	End() error
+}
+
+func NewEncoder(format Format, w io.Writer) (Encoder, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVEncoder(w), nil
+	case FormatOFX:
+		return newOFXEncoder(w), nil
+	case FormatCAMT053:
+		return newCAMT053Encoder(w), nil
+	}
+	return nil, ErrUnknownFormat
+}
+
+func (f Format) ContentType() string {
+	switch f {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatOFX:
+		return "application/x-ofx"
+	case FormatCAMT053:
+		return "application/xml"
+	}
+	return "application/octet-stream"
+}
+
+func (f Format) Extension() string {
+	switch f {
+	case FormatCSV:
+		return "csv"
+	case FormatOFX:
+		return "ofx"
+	case FormatCAMT053:
+		return "xml"
+	}
+	return "bin"
+}
+
+// formatAmount renders minor units as a decimal string with the currency's
+// number of fraction digits, e.g. -1234 becomes "-12.34" in EUR.
+func formatAmount(amount int64, minorUnits int32) string {
+	return money.FormatMinor(amount, minorUnits)
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,119 @@
+package export
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func testStatement() (Statement, []Entry) {
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	statement := Statement{
+		AccountID:      42,
+		Owner:          "alice",
+		Currency:       "USD",
+		MinorUnits:     2,
+		From:           from,
+		To:             from.AddDate(0, 1, 0),
+		OpeningBalance: 100_00,
+		ClosingBalance: 112_45,
+		TotalDebits:    40_05,
+		TotalCredits:   52_50,
+		DebitCount:     2,
+		CreditCount:    1,
+		GeneratedAt:    time.Date(2025, 4, 1, 9, 30, 0, 0, time.UTC),
+	}
+
+	entries := []Entry{
+		{
+			ID:                    1001,
+			TransferID:            501,
+			AmountCents:           52_50,
+			RunningBalance:        152_50,
+			BookedAt:              from.Add(26 * time.Hour),
+			CounterpartyAccountID: 7,
+			CounterpartyOwner:     "bob & sons",
+		},
+		{
+			ID:                    1002,
+			TransferID:            502,
+			AmountCents:           -40_00,
+			RunningBalance:        112_50,
+			BookedAt:              from.Add(72 * time.Hour),
+			CounterpartyAccountID: 8,
+			CounterpartyOwner:     "carol, \"the\" <shop>",
+		},
+		{
+			ID:             1003,
+			AmountCents:    -5,
+			RunningBalance: 112_45,
+			BookedAt:       from.Add(30 * 24 * time.Hour),
+		},
+	}
+
+	return statement, entries
+}
+
+func TestEncoders(t *testing.T) {
+	statement, entries := testStatement()
+
+	for _, format := range []Format{FormatCSV, FormatOFX, FormatCAMT053} {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+
+			enc, err := NewEncoder(format, &buf)
+			require.NoError(t, err)
+
+			require.NoError(t, enc.Begin(statement))
+			for _, entry := range entries {
+				require.NoError(t, enc.Entry(entry))
+			}
+			require.NoError(t, enc.End())
+
+			golden := filepath.Join("testdata", "statement."+format.Extension()+".golden")
+			if *update {
+				require.NoError(t, os.WriteFile(golden, buf.Bytes(), 0o644))
+			}
+
+			want, err := os.ReadFile(golden)
+			require.NoError(t, err)
+			require.Equal(t, string(want), buf.String())
+		})
+	}
+}
+
+func TestUnknownFormat(t *testing.T) {
+	enc, err := NewEncoder("pdf", &bytes.Buffer{})
+	require.ErrorIs(t, err, ErrUnknownFormat)
+	require.Nil(t, enc)
+}
+
+func TestFormatAmount(t *testing.T) {
+	testCases := []struct {
+		amount     int64
+		minorUnits int32
+		want       string
+	}{
+		{0, 2, "0.00"},
+		{5, 2, "0.05"},
+		{-5, 2, "-0.05"},
+		{1234, 2, "12.34"},
+		{-100_000, 2, "-1000.00"},
+		{1234, 0, "1234"},
+		{-1234, 0, "-1234"},
+		{1234, 3, "1.234"},
+		{5, 3, "0.005"},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.want, formatAmount(tc.amount, tc.minorUnits))
+	}
+}
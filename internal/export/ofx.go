@@ -0,0 +1,175 @@
+package export
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+const (
+	ofxHeader = `<?xml version="1.0" encoding="UTF-8" standalone="no"?>` + "\n" +
+		`<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>` + "\n"
+	ofxDateLayout = "20060102150405.000"
+	ofxBankID     = "BSS"
+)
+
+type ofxStatus struct {
+	Code     int    `xml:"CODE"`
+	Severity string `xml:"SEVERITY"`
+}
+
+type ofxSignOn struct {
+	XMLName  xml.Name  `xml:"SIGNONMSGSRSV1"`
+	Status   ofxStatus `xml:"SONRS>STATUS"`
+	DTServer string    `xml:"SONRS>DTSERVER"`
+	Language string    `xml:"SONRS>LANGUAGE"`
+}
+
+type ofxBankAccount struct {
+	XMLName  xml.Name `xml:"BANKACCTFROM"`
+	BankID   string   `xml:"BANKID"`
+	AcctID   string   `xml:"ACCTID"`
+	AcctType string   `xml:"ACCTTYPE"`
+}
+
+type ofxTransaction struct {
+	XMLName  xml.Name `xml:"STMTTRN"`
+	TrnType  string   `xml:"TRNTYPE"`
+	DTPosted string   `xml:"DTPOSTED"`
+	TrnAmt   string   `xml:"TRNAMT"`
+	FitID    string   `xml:"FITID"`
+	Name     string   `xml:"NAME,omitempty"`
+	Memo     string   `xml:"MEMO,omitempty"`
+}
+
+type ofxBalance struct {
+	BalAmt string `xml:"BALAMT"`
+	DTAsOf string `xml:"DTASOF"`
+}
+
+// ofxEncoder writes an OFX 2.2 bank statement response.
+type ofxEncoder struct {
+	w         io.Writer
+	enc       *xml.Encoder
+	statement Statement
+}
+
+func newOFXEncoder(w io.Writer) *ofxEncoder {
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return &ofxEncoder{w: w, enc: enc}
+}
+
+func (e *ofxEncoder) Begin(statement Statement) error {
+	e.statement = statement
+
+	if _, err := io.WriteString(e.w, ofxHeader); err != nil {
+		return err
+	}
+	if err := encodeTokens(e.enc, start("OFX")); err != nil {
+		return err
+	}
+
+	if err := e.enc.Encode(ofxSignOn{
+		Status:   ofxStatus{Code: 0, Severity: "INFO"},
+		DTServer: ofxDate(statement.GeneratedAt),
+		Language: "ENG",
+	}); err != nil {
+		return err
+	}
+
+	if err := encodeTokens(e.enc, start("BANKMSGSRSV1"), start("STMTTRNRS")); err != nil {
+		return err
+	}
+	if err := e.enc.EncodeElement("0", start("TRNUID")); err != nil {
+		return err
+	}
+	if err := e.enc.EncodeElement(ofxStatus{Code: 0, Severity: "INFO"}, start("STATUS")); err != nil {
+		return err
+	}
+	if err := encodeTokens(e.enc, start("STMTRS")); err != nil {
+		return err
+	}
+	if err := e.enc.EncodeElement(statement.Currency, start("CURDEF")); err != nil {
+		return err
+	}
+	if err := e.enc.Encode(ofxBankAccount{
+		BankID:   ofxBankID,
+		AcctID:   strconv.FormatInt(statement.AccountID, 10),
+		AcctType: "CHECKING",
+	}); err != nil {
+		return err
+	}
+	if err := encodeTokens(e.enc, start("BANKTRANLIST")); err != nil {
+		return err
+	}
+	if err := e.enc.EncodeElement(ofxDate(statement.From), start("DTSTART")); err != nil {
+		return err
+	}
+	return e.enc.EncodeElement(ofxDate(statement.To), start("DTEND"))
+}
+
+func (e *ofxEncoder) Entry(entry Entry) error {
+	trnType := "CREDIT"
+	if entry.AmountCents < 0 {
+		trnType = "DEBIT"
+	}
+
+	trn := ofxTransaction{
+		TrnType:  trnType,
+		DTPosted: ofxDate(entry.BookedAt),
+		TrnAmt:   formatAmount(entry.AmountCents, e.statement.MinorUnits),
+		FitID:    strconv.FormatInt(entry.ID, 10),
+		Name:     entry.CounterpartyOwner,
+	}
+	if entry.TransferID != 0 {
+		trn.Memo = fmt.Sprintf("Transfer %d", entry.TransferID)
+	}
+
+	return e.enc.Encode(trn)
+}
+
+func (e *ofxEncoder) End() error {
+	if err := encodeTokens(e.enc, end("BANKTRANLIST")); err != nil {
+		return err
+	}
+	if err := e.enc.EncodeElement(ofxBalance{
+		BalAmt: formatAmount(e.statement.ClosingBalance, e.statement.MinorUnits),
+		DTAsOf: ofxDate(e.statement.To),
+	}, start("LEDGERBAL")); err != nil {
+		return err
+	}
+	if err := encodeTokens(e.enc, end("STMTRS"), end("STMTTRNRS"), end("BANKMSGSRSV1"), end("OFX")); err != nil {
+		return err
+	}
+	if err := e.enc.Close(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(e.w, "\n")
+	return err
+}
+
+func ofxDate(t time.Time) string {
+	return t.UTC().Format(ofxDateLayout) + "[0:GMT]"
+}
+
+func start(name string) xml.StartElement {
+	return xml.StartElement{Name: xml.Name{Local: name}}
+}
+
+func end(name string) xml.EndElement {
+	return xml.EndElement{Name: xml.Name{Local: name}}
+}
+
+// encodeTokens writes the tokens and flushes them, so that output appears on
+// the wire as the document is being built.
+func encodeTokens(enc *xml.Encoder, tokens ...xml.Token) error {
+	for _, token := range tokens {
+		if err := enc.EncodeToken(token); err != nil {
+			return err
+		}
+	}
+	return enc.Flush()
+}
@@ -0,0 +1,154 @@
+package fraud
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	db "github.com/vlone310/bss/internal/db/sqlc"
+	"github.com/vlone310/bss/internal/money"
+)
+
+// Thresholds are the scores from which a transfer is challenged, held or
+// blocked. The highest one reached wins, zero disables an action.
+type Thresholds struct {
+	Challenge int32
+	Hold      int32
+	Block     int32
+}
+
+// Enabled reports whether any score changes what happens to a transfer.
+func (t Thresholds) Enabled() bool {
+	return t.Challenge > 0 || t.Hold > 0 || t.Block > 0
+}
+
+// Action returns the action for a score.
+func (t Thresholds) Action(score int32) string {
+	switch {
+	case t.Block > 0 && score >= t.Block:
+		return db.FraudActionBlock
+	case t.Hold > 0 && score >= t.Hold:
+		return db.FraudActionHold
+	case t.Challenge > 0 && score >= t.Challenge:
+		return db.FraudActionChallenge
+	}
+	return db.FraudActionAllow
+}
+
+// Engine scores transfers with its rules. It is safe for concurrent use and
+// the rules can be reloaded while the server runs. It is the FraudAssessor
+// of the store.
+type Engine struct {
+	thresholds Thresholds
+	now        func() time.Time
+
+	mu    sync.RWMutex
+	rules []Rule
+}
+
+func NewEngine(thresholds Thresholds, rules ...Rule) *Engine {
+	e := &Engine{thresholds: thresholds, now: time.Now}
+	e.Replace(rules...)
+	return e
+}
+
+// Lister is the part of the store the engine loads its rules from.
+type Lister interface {
+	ListFraudRules(ctx context.Context) ([]db.FraudRule, error)
+}
+
+// Load replaces the rules with the enabled ones stored in the database. The
+// rules stay in place when one of them is invalid.
+func (e *Engine) Load(ctx context.Context, store Lister) error {
+	rows, err := store.ListFraudRules(ctx)
+	if err != nil {
+		return err
+	}
+
+	rules := make([]Rule, 0, len(rows))
+	for _, row := range rows {
+		if !row.Enabled {
+			continue
+		}
+		rule, err := NewRule(row.Name, row.Kind, row.Params, row.Score)
+		if err != nil {
+			return err
+		}
+		rules = append(rules, rule)
+	}
+
+	e.Replace(rules...)
+	return nil
+}
+
+func (e *Engine) Replace(rules ...Rule) {
+	e.mu.Lock()
+	e.rules = slices.Clone(rules)
+	e.mu.Unlock()
+}
+
+// Set adds, updates or, when it is disabled, removes a single rule right
+// after an admin changed it, without waiting for the next Load.
+func (e *Engine) Set(row db.FraudRule) error {
+	var rule Rule
+	if row.Enabled {
+		var err error
+		if rule, err = NewRule(row.Name, row.Kind, row.Params, row.Score); err != nil {
+			return err
+		}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.rules = slices.DeleteFunc(slices.Clone(e.rules), func(r Rule) bool { return r.Name() == row.Name })
+	if rule != nil {
+		e.rules = append(e.rules, rule)
+	}
+	return nil
+}
+
+// Rules returns the rules in use, by name.
+func (e *Engine) Rules() []Rule {
+	e.mu.RLock()
+	rules := slices.Clone(e.rules)
+	e.mu.RUnlock()
+
+	slices.SortFunc(rules, func(a, b Rule) int { return strings.Compare(a.Name(), b.Name()) })
+	return rules
+}
+
+// AssessTransfer implements db.FraudAssessor.
+func (e *Engine) AssessTransfer(ctx context.Context, q *db.Queries, from, to db.Account, amount money.Money) (db.FraudAssessment, error) {
+	return e.Assess(ctx, q, Transfer{From: from, To: to, Amount: amount, At: e.now()})
+}
+
+// Assess runs every rule on the transfer and adds up the scores of those
+// it hit. A failing rule fails the assessment, so that a transfer is not
+// allowed unchecked.
+func (e *Engine) Assess(ctx context.Context, h History, t Transfer) (db.FraudAssessment, error) {
+	assessment := db.FraudAssessment{Hits: []db.FraudRuleMatch{}}
+
+	for _, rule := range e.Rules() {
+		detail, hit, err := rule.Evaluate(ctx, h, t)
+		if err != nil {
+			return assessment, fmt.Errorf("rule %s: %w", rule.Name(), err)
+		}
+		if !hit {
+			continue
+		}
+
+		assessment.Score += rule.Score()
+		assessment.Hits = append(assessment.Hits, db.FraudRuleMatch{
+			Rule:   rule.Name(),
+			Score:  rule.Score(),
+			Detail: detail,
+		})
+	}
+
+	assessment.Action = e.thresholds.Action(assessment.Score)
+	return assessment, nil
+}
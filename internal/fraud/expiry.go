@@ -0,0 +1,95 @@
+package fraud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+)
+
+// ExpiryAuditAgent is the user agent the audit events of expired decisions
+// are recorded with.
+const ExpiryAuditAgent = "fraud expiry"
+
+const expiryBatchSize = 100
+
+// ExpiryStore is the part of the store the Expirer works with.
+type ExpiryStore interface {
+	ListStaleFraudDecisions(ctx context.Context, arg db.ListStaleFraudDecisionsParams) ([]db.FraudDecision, error)
+	ReviewFraudDecisionTx(ctx context.Context, arg db.ReviewFraudDecisionTxParams) (db.ReviewFraudDecisionTxResult, error)
+}
+
+// Expirer fails the challenged and held transfers that were not decided on
+// in time, so that they do not stay pending forever. A zero TTL keeps the
+// transfers of that action pending until they are decided on.
+type Expirer struct {
+	store        ExpiryStore
+	challengeTTL time.Duration
+	holdTTL      time.Duration
+	now          func() time.Time
+}
+
+func NewExpirer(store ExpiryStore, challengeTTL, holdTTL time.Duration) *Expirer {
+	return &Expirer{store: store, challengeTTL: challengeTTL, holdTTL: holdTTL, now: time.Now}
+}
+
+// Run expires the stale decisions and returns how many it expired.
+func (e *Expirer) Run(ctx context.Context) (int, error) {
+	var expired int
+	for action, ttl := range map[string]time.Duration{
+		db.FraudActionChallenge: e.challengeTTL,
+		db.FraudActionHold:      e.holdTTL,
+	} {
+		if ttl <= 0 {
+			continue
+		}
+
+		n, err := e.expire(ctx, action, e.now().Add(-ttl))
+		expired += n
+		if err != nil {
+			return expired, err
+		}
+	}
+
+	return expired, nil
+}
+
+func (e *Expirer) expire(ctx context.Context, action string, before time.Time) (int, error) {
+	var expired int
+	for {
+		decisions, err := e.store.ListStaleFraudDecisions(ctx, db.ListStaleFraudDecisionsParams{
+			Action:        action,
+			CreatedBefore: pgtype.Timestamptz{Time: before, Valid: true},
+			LimitCount:    expiryBatchSize,
+		})
+		if err != nil {
+			return expired, err
+		}
+
+		for _, decision := range decisions {
+			auditCtx := db.WithAuditMeta(ctx, db.AuditMeta{
+				RequestID: fmt.Sprintf("fraud-expiry-%d", decision.ID),
+				UserAgent: ExpiryAuditAgent,
+			})
+			_, err := e.store.ReviewFraudDecisionTx(auditCtx, db.ReviewFraudDecisionTxParams{
+				DecisionID: decision.ID,
+				Outcome:    db.FraudReviewExpired,
+			})
+			// decided on since it was listed
+			if errors.Is(err, db.ErrFraudDecisionNotReviewable) {
+				continue
+			}
+			if err != nil {
+				return expired, fmt.Errorf("cannot expire fraud decision [%d]: %w", decision.ID, err)
+			}
+			expired++
+		}
+
+		if len(decisions) < expiryBatchSize {
+			return expired, nil
+		}
+	}
+}
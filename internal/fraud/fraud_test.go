@@ -0,0 +1,287 @@
+package fraud
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+	"github.com/vlone310/bss/internal/money"
+)
+
+// fakeHistory answers the rule queries with fixed values and records the
+// start of the windows asked for.
+type fakeHistory struct {
+	recentTransfers int64
+	payeeTransfers  int64
+	stats           db.GetOutgoingTransferStatsRow
+	credits         int64
+	err             error
+
+	since []time.Time
+}
+
+func (h *fakeHistory) CountTransfersFromSince(_ context.Context, arg db.CountTransfersFromSinceParams) (int64, error) {
+	h.since = append(h.since, arg.Since.Time)
+	return h.recentTransfers, h.err
+}
+
+func (h *fakeHistory) CountTransfersBetween(_ context.Context, _ db.CountTransfersBetweenParams) (int64, error) {
+	return h.payeeTransfers, h.err
+}
+
+func (h *fakeHistory) GetOutgoingTransferStats(_ context.Context, arg db.GetOutgoingTransferStatsParams) (db.GetOutgoingTransferStatsRow, error) {
+	h.since = append(h.since, arg.Since.Time)
+	return h.stats, h.err
+}
+
+func (h *fakeHistory) SumCreditsSince(_ context.Context, arg db.SumCreditsSinceParams) (int64, error) {
+	h.since = append(h.since, arg.Since.Time)
+	return h.credits, h.err
+}
+
+var testNow = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+func testTransfer(amount int64) Transfer {
+	return Transfer{
+		From:   db.Account{ID: 1, Owner: "alice", Currency: "EUR"},
+		To:     db.Account{ID: 2, Owner: "bob", Currency: "EUR"},
+		Amount: money.New(amount, "EUR"),
+		At:     testNow,
+	}
+}
+
+func mustRule(t *testing.T, name, kind, params string, score int32) Rule {
+	t.Helper()
+
+	rule, err := NewRule(name, kind, []byte(params), score)
+	require.NoError(t, err)
+	return rule
+}
+
+func TestRules(t *testing.T) {
+	velocity := mustRule(t, "velocity", KindVelocity, `{"count": 3, "window": "10m"}`, 40)
+	newPayee := mustRule(t, "new_payee", KindNewPayee, `{}`, 20)
+	unusual := mustRule(t, "unusual_amount", KindUnusualAmount, `{"factor": 5, "min_transfers": 3, "window": "720h"}`, 30)
+	rapid := mustRule(t, "rapid_in_out", KindRapidInOut, `{"ratio": 0.8, "window": "1h"}`, 40)
+
+	testCases := []struct {
+		name    string
+		rule    Rule
+		history fakeHistory
+		amount  int64
+		hit     bool
+	}{
+		{name: "VelocityBelow", rule: velocity, history: fakeHistory{recentTransfers: 2}, amount: 100},
+		{name: "VelocityReached", rule: velocity, history: fakeHistory{recentTransfers: 3}, amount: 100, hit: true},
+		{name: "NewPayee", rule: newPayee, history: fakeHistory{payeeTransfers: 0}, amount: 100, hit: true},
+		{name: "KnownPayee", rule: newPayee, history: fakeHistory{payeeTransfers: 4}, amount: 100},
+		{name: "UsualAmount", rule: unusual, history: fakeHistory{stats: db.GetOutgoingTransferStatsRow{TransferCount: 10, AverageAmount: 100}}, amount: 500},
+		{name: "UnusualAmount", rule: unusual, history: fakeHistory{stats: db.GetOutgoingTransferStatsRow{TransferCount: 10, AverageAmount: 100}}, amount: 501, hit: true},
+		{name: "TooFewTransfers", rule: unusual, history: fakeHistory{stats: db.GetOutgoingTransferStatsRow{TransferCount: 2, AverageAmount: 1}}, amount: 10_000},
+		{name: "NoRecentCredits", rule: rapid, history: fakeHistory{credits: 0}, amount: 100},
+		{name: "KeepsMostCredits", rule: rapid, history: fakeHistory{credits: 1000}, amount: 799},
+		{name: "SendsOnCredits", rule: rapid, history: fakeHistory{credits: 1000}, amount: 800, hit: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			detail, hit, err := tc.rule.Evaluate(context.Background(), &tc.history, testTransfer(tc.amount))
+			require.NoError(t, err)
+			require.Equal(t, tc.hit, hit)
+			if hit {
+				require.NotEmpty(t, detail)
+			}
+		})
+	}
+}
+
+func TestRuleWindow(t *testing.T) {
+	rule := mustRule(t, "velocity", KindVelocity, `{"count": 3, "window": "10m"}`, 40)
+
+	history := &fakeHistory{}
+	_, _, err := rule.Evaluate(context.Background(), history, testTransfer(100))
+	require.NoError(t, err)
+	require.Equal(t, []time.Time{testNow.Add(-10 * time.Minute)}, history.since)
+}
+
+func TestNewPayeeOwnAccount(t *testing.T) {
+	rule := mustRule(t, "new_payee", KindNewPayee, ``, 20)
+
+	transfer := testTransfer(100)
+	transfer.To.Owner = transfer.From.Owner
+
+	_, hit, err := rule.Evaluate(context.Background(), &fakeHistory{}, transfer)
+	require.NoError(t, err)
+	require.False(t, hit)
+}
+
+func TestNewRuleInvalid(t *testing.T) {
+	testCases := []struct {
+		name    string
+		kind    string
+		params  string
+		wantErr error
+	}{
+		{name: "UnknownKind", kind: "geo", params: `{}`, wantErr: ErrUnknownKind},
+		{name: "UnknownParam", kind: KindVelocity, params: `{"count": 3, "window": "10m", "limit": 1}`, wantErr: ErrInvalidParams},
+		{name: "NoWindow", kind: KindVelocity, params: `{"count": 3}`, wantErr: ErrInvalidParams},
+		{name: "BadWindow", kind: KindRapidInOut, params: `{"ratio": 0.5, "window": "1 hour"}`, wantErr: ErrInvalidParams},
+		{name: "NoCount", kind: KindVelocity, params: `{"window": "10m"}`, wantErr: ErrInvalidParams},
+		{name: "SmallFactor", kind: KindUnusualAmount, params: `{"factor": 1, "window": "24h"}`, wantErr: ErrInvalidParams},
+		{name: "BigRatio", kind: KindRapidInOut, params: `{"ratio": 1.5, "window": "1h"}`, wantErr: ErrInvalidParams},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewRule(tc.name, tc.kind, []byte(tc.params), 10)
+			require.ErrorIs(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestThresholds(t *testing.T) {
+	thresholds := Thresholds{Challenge: 30, Hold: 60, Block: 90}
+	require.Equal(t, db.FraudActionAllow, thresholds.Action(29))
+	require.Equal(t, db.FraudActionChallenge, thresholds.Action(30))
+	require.Equal(t, db.FraudActionHold, thresholds.Action(60))
+	require.Equal(t, db.FraudActionBlock, thresholds.Action(120))
+
+	// without a hold threshold a high score is challenged
+	thresholds.Hold, thresholds.Block = 0, 0
+	require.Equal(t, db.FraudActionChallenge, thresholds.Action(120))
+	require.False(t, Thresholds{}.Enabled())
+}
+
+func TestEngineAssess(t *testing.T) {
+	engine := NewEngine(Thresholds{Challenge: 30, Hold: 60, Block: 100},
+		mustRule(t, "velocity", KindVelocity, `{"count": 3, "window": "10m"}`, 40),
+		mustRule(t, "new_payee", KindNewPayee, `{}`, 20),
+	)
+
+	assessment, err := engine.Assess(context.Background(), &fakeHistory{recentTransfers: 5, payeeTransfers: 1}, testTransfer(100))
+	require.NoError(t, err)
+	require.Equal(t, int32(40), assessment.Score)
+	require.Equal(t, db.FraudActionChallenge, assessment.Action)
+	require.Len(t, assessment.Hits, 1)
+	require.Equal(t, "velocity", assessment.Hits[0].Rule)
+
+	assessment, err = engine.Assess(context.Background(), &fakeHistory{recentTransfers: 5}, testTransfer(100))
+	require.NoError(t, err)
+	require.Equal(t, int32(60), assessment.Score)
+	require.Equal(t, db.FraudActionHold, assessment.Action)
+
+	assessment, err = engine.Assess(context.Background(), &fakeHistory{payeeTransfers: 1}, testTransfer(100))
+	require.NoError(t, err)
+	require.Zero(t, assessment.Score)
+	require.Equal(t, db.FraudActionAllow, assessment.Action)
+	require.Empty(t, assessment.Hits)
+
+	// a rule that cannot be evaluated fails the assessment
+	_, err = engine.Assess(context.Background(), &fakeHistory{err: errors.New("connection reset")}, testTransfer(100))
+	require.Error(t, err)
+}
+
+type fakeLister []db.FraudRule
+
+func (l fakeLister) ListFraudRules(context.Context) ([]db.FraudRule, error) {
+	return l, nil
+}
+
+func TestEngineLoad(t *testing.T) {
+	engine := NewEngine(Thresholds{Hold: 50})
+
+	err := engine.Load(context.Background(), fakeLister{
+		{Name: "velocity", Kind: KindVelocity, Params: []byte(`{"count": 5, "window": "10m"}`), Score: 40, Enabled: true},
+		{Name: "new_payee", Kind: KindNewPayee, Params: []byte(`{}`), Score: 20, Enabled: false},
+	})
+	require.NoError(t, err)
+	require.Len(t, engine.Rules(), 1)
+
+	require.NoError(t, engine.Set(db.FraudRule{Name: "new_payee", Kind: KindNewPayee, Score: 20, Enabled: true}))
+	require.Len(t, engine.Rules(), 2)
+	require.Equal(t, "new_payee", engine.Rules()[0].Name())
+
+	require.NoError(t, engine.Set(db.FraudRule{Name: "velocity", Kind: KindVelocity, Enabled: false}))
+	require.Len(t, engine.Rules(), 1)
+
+	// an invalid rule keeps the rules in place
+	err = engine.Load(context.Background(), fakeLister{
+		{Name: "velocity", Kind: KindVelocity, Params: []byte(`{}`), Score: 40, Enabled: true},
+	})
+	require.ErrorIs(t, err, ErrInvalidParams)
+	require.Len(t, engine.Rules(), 1)
+}
+
+// fakeExpiryStore lists its pending decisions by action and records the
+// reviews.
+type fakeExpiryStore struct {
+	pending  map[string][]db.FraudDecision
+	reviewed map[int64]db.ReviewFraudDecisionTxParams
+	before   map[string]time.Time
+}
+
+func (s *fakeExpiryStore) ListStaleFraudDecisions(_ context.Context, arg db.ListStaleFraudDecisionsParams) ([]db.FraudDecision, error) {
+	s.before[arg.Action] = arg.CreatedBefore.Time
+
+	var stale []db.FraudDecision
+	for _, decision := range s.pending[arg.Action] {
+		if _, ok := s.reviewed[decision.ID]; !ok && decision.CreatedAt.Time.Before(arg.CreatedBefore.Time) {
+			stale = append(stale, decision)
+		}
+	}
+	return stale, nil
+}
+
+func (s *fakeExpiryStore) ReviewFraudDecisionTx(ctx context.Context, arg db.ReviewFraudDecisionTxParams) (db.ReviewFraudDecisionTxResult, error) {
+	if _, ok := db.AuditMetaFrom(ctx); !ok {
+		return db.ReviewFraudDecisionTxResult{}, errors.New("expiry is not audited")
+	}
+	if arg.DecisionID == 3 {
+		// confirmed by the sender since it was listed
+		s.reviewed[arg.DecisionID] = db.ReviewFraudDecisionTxParams{}
+		return db.ReviewFraudDecisionTxResult{}, db.ErrFraudDecisionNotReviewable
+	}
+	s.reviewed[arg.DecisionID] = arg
+	return db.ReviewFraudDecisionTxResult{}, nil
+}
+
+func TestExpirerRun(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	decision := func(id int64, action string, age time.Duration) db.FraudDecision {
+		return db.FraudDecision{ID: id, Action: action, CreatedAt: pgtype.Timestamptz{Time: now.Add(-age), Valid: true}}
+	}
+
+	store := &fakeExpiryStore{
+		pending: map[string][]db.FraudDecision{
+			db.FraudActionChallenge: {
+				decision(1, db.FraudActionChallenge, 2*time.Hour),
+				decision(2, db.FraudActionChallenge, time.Minute),
+				decision(3, db.FraudActionChallenge, 3*time.Hour),
+			},
+			db.FraudActionHold: {
+				decision(4, db.FraudActionHold, 30*24*time.Hour),
+			},
+		},
+		reviewed: map[int64]db.ReviewFraudDecisionTxParams{},
+		before:   map[string]time.Time{},
+	}
+
+	// holds wait for a reviewer however long it takes
+	expirer := NewExpirer(store, time.Hour, 0)
+	expirer.now = func() time.Time { return now }
+
+	expired, err := expirer.Run(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, expired)
+
+	require.Equal(t, now.Add(-time.Hour), store.before[db.FraudActionChallenge])
+	require.NotContains(t, store.before, db.FraudActionHold)
+
+	require.Equal(t, db.ReviewFraudDecisionTxParams{DecisionID: 1, Outcome: db.FraudReviewExpired}, store.reviewed[1])
+	require.NotContains(t, store.reviewed, int64(2))
+	require.NotContains(t, store.reviewed, int64(4))
+}
@@ -0,0 +1,224 @@
+// Package fraud scores transfers with configurable rules before they are
+// booked. Each rule that hits adds its points to the score of the transfer,
+// and the score picks what happens to it: allow, challenge the sender, hold
+// for a review, or block.
+package fraud
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+	"github.com/vlone310/bss/internal/money"
+)
+
+// Rule kinds as stored in the fraud_rules table.
+const (
+	KindVelocity      = "velocity"
+	KindNewPayee      = "new_payee"
+	KindUnusualAmount = "unusual_amount"
+	KindRapidInOut    = "rapid_in_out"
+)
+
+// Kinds lists the rule kinds.
+var Kinds = []string{KindVelocity, KindNewPayee, KindUnusualAmount, KindRapidInOut}
+
+var ErrUnknownKind = errors.New("unknown fraud rule kind")
+var ErrInvalidParams = errors.New("invalid fraud rule params")
+
+// History is the part of the store the rules read the past of an account
+// from.
+type History interface {
+	CountTransfersFromSince(ctx context.Context, arg db.CountTransfersFromSinceParams) (int64, error)
+	CountTransfersBetween(ctx context.Context, arg db.CountTransfersBetweenParams) (int64, error)
+	GetOutgoingTransferStats(ctx context.Context, arg db.GetOutgoingTransferStatsParams) (db.GetOutgoingTransferStatsRow, error)
+	SumCreditsSince(ctx context.Context, arg db.SumCreditsSinceParams) (int64, error)
+}
+
+// Transfer is the transfer being scored.
+type Transfer struct {
+	From   db.Account
+	To     db.Account
+	Amount money.Money
+	At     time.Time
+}
+
+// Rule is a single fraud check. Evaluate reports whether the transfer hit
+// the rule, with a detail for the reviewer.
+type Rule interface {
+	Name() string
+	Score() int32
+	Evaluate(ctx context.Context, h History, t Transfer) (detail string, hit bool, err error)
+}
+
+type ruleBase struct {
+	name  string
+	score int32
+}
+
+func (r ruleBase) Name() string { return r.name }
+func (r ruleBase) Score() int32 { return r.score }
+
+func since(t Transfer, window time.Duration) pgtype.Timestamptz {
+	return pgtype.Timestamptz{Time: t.At.Add(-window), Valid: true}
+}
+
+// Velocity hits when the sender already made Count transfers within Window.
+type Velocity struct {
+	ruleBase
+	Count  int64
+	Window time.Duration
+}
+
+func (r Velocity) Evaluate(ctx context.Context, h History, t Transfer) (string, bool, error) {
+	n, err := h.CountTransfersFromSince(ctx, db.CountTransfersFromSinceParams{
+		AccountID: t.From.ID,
+		Since:     since(t, r.Window),
+	})
+	if err != nil || n < r.Count {
+		return "", false, err
+	}
+
+	return fmt.Sprintf("%d transfers in the last %s", n+1, r.Window), true, nil
+}
+
+// NewPayee hits on the first transfer to an account of somebody else.
+type NewPayee struct {
+	ruleBase
+}
+
+func (r NewPayee) Evaluate(ctx context.Context, h History, t Transfer) (string, bool, error) {
+	if t.From.Owner == t.To.Owner {
+		return "", false, nil
+	}
+
+	n, err := h.CountTransfersBetween(ctx, db.CountTransfersBetweenParams{
+		FromAccountID: t.From.ID,
+		ToAccountID:   t.To.ID,
+	})
+	if err != nil || n > 0 {
+		return "", false, err
+	}
+
+	return fmt.Sprintf("first transfer to account [%d]", t.To.ID), true, nil
+}
+
+// UnusualAmount hits when the amount is more than Factor times the average
+// of the transfers the sender made within Window. Senders with fewer than
+// MinTransfers transfers have no usual amount yet.
+type UnusualAmount struct {
+	ruleBase
+	Factor       float64
+	MinTransfers int64
+	Window       time.Duration
+}
+
+func (r UnusualAmount) Evaluate(ctx context.Context, h History, t Transfer) (string, bool, error) {
+	stats, err := h.GetOutgoingTransferStats(ctx, db.GetOutgoingTransferStatsParams{
+		AccountID: t.From.ID,
+		Since:     since(t, r.Window),
+	})
+	if err != nil || stats.TransferCount < r.MinTransfers || stats.AverageAmount <= 0 {
+		return "", false, err
+	}
+
+	ratio := float64(t.Amount.Amount) / stats.AverageAmount
+	if ratio <= r.Factor {
+		return "", false, nil
+	}
+
+	return fmt.Sprintf("amount is %.1f times the average of %d transfers", ratio, stats.TransferCount), true, nil
+}
+
+// RapidInOut hits when the transfer sends on at least Ratio of the money
+// that arrived on the account within Window, the pattern of a mule account.
+type RapidInOut struct {
+	ruleBase
+	Ratio  float64
+	Window time.Duration
+}
+
+func (r RapidInOut) Evaluate(ctx context.Context, h History, t Transfer) (string, bool, error) {
+	credits, err := h.SumCreditsSince(ctx, db.SumCreditsSinceParams{
+		AccountID: t.From.ID,
+		Since:     since(t, r.Window),
+	})
+	if err != nil || credits <= 0 || float64(t.Amount.Amount) < r.Ratio*float64(credits) {
+		return "", false, err
+	}
+
+	share := 100 * float64(t.Amount.Amount) / float64(credits)
+	return fmt.Sprintf("sends on %.0f%% of what it received in the last %s", share, r.Window), true, nil
+}
+
+// duration reads a Go duration string such as "10m" from JSON.
+type duration time.Duration
+
+func (d *duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = duration(v)
+	return nil
+}
+
+type ruleParams struct {
+	Count        int64    `json:"count"`
+	Window       duration `json:"window"`
+	Factor       float64  `json:"factor"`
+	MinTransfers int64    `json:"min_transfers"`
+	Ratio        float64  `json:"ratio"`
+}
+
+// NewRule builds a rule of the kind from its JSON params, as stored in the
+// fraud_rules table.
+func NewRule(name, kind string, params []byte, score int32) (Rule, error) {
+	var p ruleParams
+	if len(bytes.TrimSpace(params)) > 0 {
+		decoder := json.NewDecoder(bytes.NewReader(params))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&p); err != nil {
+			return nil, fmt.Errorf("%w: rule %s: %v", ErrInvalidParams, name, err)
+		}
+	}
+
+	base := ruleBase{name: name, score: score}
+	window := time.Duration(p.Window)
+	if kind != KindNewPayee && window <= 0 && slices.Contains(Kinds, kind) {
+		return nil, fmt.Errorf("%w: rule %s needs a positive window", ErrInvalidParams, name)
+	}
+
+	switch kind {
+	case KindVelocity:
+		if p.Count < 1 {
+			return nil, fmt.Errorf("%w: rule %s needs a count of at least 1", ErrInvalidParams, name)
+		}
+		return Velocity{ruleBase: base, Count: p.Count, Window: window}, nil
+	case KindNewPayee:
+		return NewPayee{ruleBase: base}, nil
+	case KindUnusualAmount:
+		if p.Factor <= 1 {
+			return nil, fmt.Errorf("%w: rule %s needs a factor above 1", ErrInvalidParams, name)
+		}
+		return UnusualAmount{ruleBase: base, Factor: p.Factor, MinTransfers: max(p.MinTransfers, 1), Window: window}, nil
+	case KindRapidInOut:
+		if p.Ratio <= 0 || p.Ratio > 1 {
+			return nil, fmt.Errorf("%w: rule %s needs a ratio above 0 and at most 1", ErrInvalidParams, name)
+		}
+		return RapidInOut{ruleBase: base, Ratio: p.Ratio, Window: window}, nil
+	}
+
+	return nil, fmt.Errorf("%w: %q", ErrUnknownKind, kind)
+}
@@ -0,0 +1,160 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+	"github.com/vlone310/bss/internal/grpc/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	defaultPageSize    = 20
+	defaultMaxPageSize = 100
+)
+
+func convertAccount(account db.Account) *pb.Account {
+	return &pb.Account{
+		Id:           account.ID,
+		Owner:        account.Owner,
+		Balance:      &pb.Money{Minor: account.Balance, Currency: account.Currency},
+		Currency:     account.Currency,
+		Product:      account.Product,
+		MinBalance:   &pb.Money{Minor: account.MinBalance, Currency: account.Currency},
+		Status:       account.Status,
+		StatusReason: account.StatusReason.String,
+		CreatedAt:    timestamppb.New(account.CreatedAt.Time),
+	}
+}
+
+// CreateAccount opens an account for the authenticated user.
+func (s *Server) CreateAccount(ctx context.Context, req *pb.CreateAccountRequest) (*pb.CreateAccountResponse, error) {
+	var rules fieldRules
+	rules.check("currency", req.GetCurrency(), "required")
+	if req.GetCurrency() != "" && !s.currencies.Enabled(req.GetCurrency()) {
+		rules.add("currency", "is not an enabled currency")
+	}
+	rules.check("product", req.GetProduct(), "omitempty,oneof=checking savings credit_line")
+	if err := rules.err(); err != nil {
+		return nil, err
+	}
+
+	product := req.GetProduct()
+	if product == "" {
+		product = db.ProductChecking
+	}
+
+	account, err := s.store.CreateAccount(ctx, db.CreateAccountParams{
+		Owner:    authPayload(ctx).Username,
+		Currency: req.GetCurrency(),
+		Product:  product,
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case "23505":
+				return nil, status.Error(codes.AlreadyExists, "account already exists")
+			case "23503":
+				return nil, status.Error(codes.NotFound, "user not found")
+			}
+		}
+		if errors.Is(err, db.ErrUserBlocked) {
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		}
+		return nil, internalError(err)
+	}
+
+	return &pb.CreateAccountResponse{Account: convertAccount(account)}, nil
+}
+
+func (s *Server) GetAccount(ctx context.Context, req *pb.GetAccountRequest) (*pb.GetAccountResponse, error) {
+	var rules fieldRules
+	rules.check("id", req.GetId(), "min=1")
+	if err := rules.err(); err != nil {
+		return nil, err
+	}
+
+	account, err := s.ownedAccount(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.GetAccountResponse{Account: convertAccount(account)}, nil
+}
+
+// ownedAccount returns the account with id when the authenticated user owns
+// it. An account of somebody else is not found.
+func (s *Server) ownedAccount(ctx context.Context, id int64) (db.Account, error) {
+	account, err := s.store.GetAccount(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return account, status.Error(codes.NotFound, "account not found")
+		}
+		return account, internalError(err)
+	}
+
+	if account.Owner != authPayload(ctx).Username {
+		return account, status.Error(codes.NotFound, "account not found")
+	}
+
+	return account, nil
+}
+
+// ListAccounts lists the accounts of the authenticated user in the order
+// they were opened. The page token is the id of the last account of the
+// previous page.
+func (s *Server) ListAccounts(ctx context.Context, req *pb.ListAccountsRequest) (*pb.ListAccountsResponse, error) {
+	var rules fieldRules
+	rules.check("page_size", req.GetPageSize(), "min=0,max="+strconv.Itoa(int(s.maxPageSize())))
+	var afterID int64
+	if req.GetPageToken() != "" {
+		var err error
+		afterID, err = strconv.ParseInt(req.GetPageToken(), 10, 64)
+		if err != nil || afterID < 1 {
+			rules.add("page_token", "is not the token of a page")
+		}
+	}
+	if err := rules.err(); err != nil {
+		return nil, err
+	}
+
+	pageSize := req.GetPageSize()
+	if pageSize == 0 {
+		pageSize = min(defaultPageSize, s.maxPageSize())
+	}
+
+	// one more than asked for tells whether there is a next page
+	accounts, err := s.store.ListOwnerAccounts(ctx, db.ListOwnerAccountsParams{
+		Owner:      authPayload(ctx).Username,
+		AfterID:    afterID,
+		LimitCount: pageSize + 1,
+	})
+	if err != nil {
+		return nil, internalError(err)
+	}
+
+	res := &pb.ListAccountsResponse{}
+	if len(accounts) > int(pageSize) {
+		accounts = accounts[:pageSize]
+		res.NextPageToken = strconv.FormatInt(accounts[len(accounts)-1].ID, 10)
+	}
+	for _, account := range accounts {
+		res.Accounts = append(res.Accounts, convertAccount(account))
+	}
+
+	return res, nil
+}
+
+func (s *Server) maxPageSize() int32 {
+	if s.config.MaxPageSize > 0 {
+		return s.config.MaxPageSize
+	}
+	return defaultMaxPageSize
+}
@@ -0,0 +1,233 @@
+package grpc
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/vlone310/bss/internal/db/mock"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+	"github.com/vlone310/bss/internal/grpc/pb"
+	"github.com/vlone310/bss/testutil"
+	"google.golang.org/grpc/codes"
+)
+
+func TestCreateAccount(t *testing.T) {
+	account := randomAccount()
+
+	testCases := []struct {
+		name       string
+		req        *pb.CreateAccountRequest
+		buildStubs func(store *mockdb.MockStore)
+		check      func(t *testing.T, res *pb.CreateAccountResponse, err error)
+	}{
+		{
+			name: "DefaultProduct",
+			req:  &pb.CreateAccountRequest{Currency: account.Currency},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.CreateAccountParams{
+					Owner:    account.Owner,
+					Currency: account.Currency,
+					Product:  db.ProductChecking,
+				}
+				store.EXPECT().CreateAccount(gomock.Any(), gomock.Eq(arg)).Times(1).Return(account, nil)
+			},
+			check: func(t *testing.T, res *pb.CreateAccountResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, account.ID, res.GetAccount().GetId())
+				require.Equal(t, account.Balance, res.GetAccount().GetBalance().GetMinor())
+				require.Equal(t, account.Currency, res.GetAccount().GetBalance().GetCurrency())
+			},
+		},
+		{
+			name: "Savings",
+			req:  &pb.CreateAccountRequest{Currency: account.Currency, Product: db.ProductSavings},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.CreateAccountParams{
+					Owner:    account.Owner,
+					Currency: account.Currency,
+					Product:  db.ProductSavings,
+				}
+				store.EXPECT().CreateAccount(gomock.Any(), gomock.Eq(arg)).Times(1).Return(account, nil)
+			},
+			check: func(t *testing.T, res *pb.CreateAccountResponse, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "Exists",
+			req:  &pb.CreateAccountRequest{Currency: account.Currency},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAccount(gomock.Any(), gomock.Any()).Times(1).Return(db.Account{}, &pgconn.PgError{Code: "23505"})
+			},
+			check: func(t *testing.T, res *pb.CreateAccountResponse, err error) {
+				requireCode(t, err, codes.AlreadyExists)
+			},
+		},
+		{
+			name: "CurrencyNotEnabled",
+			req:  &pb.CreateAccountRequest{Currency: "GBP"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, res *pb.CreateAccountResponse, err error) {
+				requireCode(t, err, codes.InvalidArgument)
+			},
+		},
+		{
+			name: "InvalidProduct",
+			req:  &pb.CreateAccountRequest{Currency: account.Currency, Product: db.ProductHouse},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, res *pb.CreateAccountResponse, err error) {
+				requireCode(t, err, codes.InvalidArgument)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			client := pb.NewAccountServiceClient(dialTestServer(t, server))
+
+			ctx := withAuthorization(t, server, account.Owner, time.Minute)
+			res, err := client.CreateAccount(ctx, tc.req)
+			tc.check(t, res, err)
+		})
+	}
+}
+
+func TestGetAccount(t *testing.T) {
+	account := randomAccount()
+
+	testCases := []struct {
+		name       string
+		id         int64
+		username   string
+		buildStubs func(store *mockdb.MockStore)
+		code       codes.Code
+	}{
+		{
+			name:     "OK",
+			id:       account.ID,
+			username: account.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+			},
+			code: codes.OK,
+		},
+		{
+			name:     "NotOwner",
+			id:       account.ID,
+			username: "someoneelse",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+			},
+			code: codes.NotFound,
+		},
+		{
+			name:     "NotFound",
+			id:       account.ID,
+			username: account.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, pgx.ErrNoRows)
+			},
+			code: codes.NotFound,
+		},
+		{
+			name:     "InvalidID",
+			id:       0,
+			username: account.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.InvalidArgument,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			client := pb.NewAccountServiceClient(dialTestServer(t, server))
+
+			ctx := withAuthorization(t, server, tc.username, time.Minute)
+			res, err := client.GetAccount(ctx, &pb.GetAccountRequest{Id: tc.id})
+			if tc.code != codes.OK {
+				requireCode(t, err, tc.code)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, account.ID, res.GetAccount().GetId())
+		})
+	}
+}
+
+func TestListAccounts(t *testing.T) {
+	owner := testutil.RandomOwner()
+	accounts := make([]db.Account, 3)
+	for i := range accounts {
+		accounts[i] = randomAccount()
+		accounts[i].ID = int64(i + 1)
+		accounts[i].Owner = owner
+	}
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	gomock.InOrder(
+		store.EXPECT().
+			ListOwnerAccounts(gomock.Any(), gomock.Eq(db.ListOwnerAccountsParams{Owner: owner, LimitCount: 3})).
+			Times(1).
+			Return(accounts, nil),
+		store.EXPECT().
+			ListOwnerAccounts(gomock.Any(), gomock.Eq(db.ListOwnerAccountsParams{Owner: owner, AfterID: 2, LimitCount: 3})).
+			Times(1).
+			Return(accounts[2:], nil),
+	)
+
+	server := newTestServer(t, store)
+	client := pb.NewAccountServiceClient(dialTestServer(t, server))
+	ctx := withAuthorization(t, server, owner, time.Minute)
+
+	res, err := client.ListAccounts(ctx, &pb.ListAccountsRequest{PageSize: 2})
+	require.NoError(t, err)
+	require.Len(t, res.GetAccounts(), 2)
+	require.Equal(t, strconv.FormatInt(accounts[1].ID, 10), res.GetNextPageToken())
+
+	res, err = client.ListAccounts(ctx, &pb.ListAccountsRequest{PageSize: 2, PageToken: res.GetNextPageToken()})
+	require.NoError(t, err)
+	require.Len(t, res.GetAccounts(), 1)
+	require.Equal(t, accounts[2].ID, res.GetAccounts()[0].GetId())
+	require.Empty(t, res.GetNextPageToken())
+
+	_, err = client.ListAccounts(ctx, &pb.ListAccountsRequest{PageToken: "x"})
+	requireCode(t, err, codes.InvalidArgument)
+
+	_, err = client.ListAccounts(ctx, &pb.ListAccountsRequest{PageSize: defaultMaxPageSize + 1})
+	requireCode(t, err, codes.InvalidArgument)
+}
+
+func randomAccount() db.Account {
+	return db.Account{
+		ID:        testutil.RandomInt(1, 1000),
+		Owner:     testutil.RandomOwner(),
+		Balance:   testutil.RandomMoney(),
+		Currency:  testutil.RandomCurrency(),
+		Product:   db.ProductChecking,
+		Status:    db.AccountStatusActive,
+		CreatedAt: pgtype.Timestamptz{Valid: true},
+	}
+}
@@ -0,0 +1,190 @@
+package grpc
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net"
+	"strings"
+
+	"github.com/vlone310/bss/internal/adapter/token/maker"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+	"github.com/vlone310/bss/internal/grpc/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	authorizationHeaderKey  = "authorization"
+	authorizationTypeBearer = "bearer"
+	requestIDHeaderKey      = "x-request-id"
+	userAgentHeaderKey      = "user-agent"
+	// forwardedForHeaderKey and gatewayUserAgentHeaderKey are set by the
+	// gateway, to the address and user agent of the REST client.
+	forwardedForHeaderKey     = "x-forwarded-for"
+	gatewayUserAgentHeaderKey = "grpcgateway-user-agent"
+	maxRequestIDSize          = 64
+)
+
+// publicMethods can be called without an access token.
+var publicMethods = map[string]bool{
+	pb.UserService_CreateUser_FullMethodName: true,
+	pb.UserService_LoginUser_FullMethodName:  true,
+}
+
+type payloadKey struct{}
+
+// authPayload returns the payload of the access token of the call. It is
+// only set for methods that are not public.
+func authPayload(ctx context.Context) *maker.Payload {
+	return ctx.Value(payloadKey{}).(*maker.Payload)
+}
+
+func (s *Server) unaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := s.authorize(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (s *Server) streamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := s.authorize(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+}
+
+// serverStream replaces the context of a stream with the authorized one.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (ss *serverStream) Context() context.Context {
+	return ss.ctx
+}
+
+// authorize gives the call a request ID, returned in the x-request-id
+// header, and the audit details the store records changes with. Unless the
+// method is public it verifies the access token in the authorization header
+// and adds its payload to the context.
+func (s *Server) authorize(ctx context.Context, method string) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	id := firstValue(md, requestIDHeaderKey)
+	if !validRequestID(id) {
+		id = newRequestID()
+	}
+	// the header cannot be sent when the call already failed on the client
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDHeaderKey, id))
+
+	meta := db.AuditMeta{
+		RequestID: id,
+		IP:        peerIP(ctx),
+		UserAgent: firstValue(md, userAgentHeaderKey),
+	}
+	// the gateway dials in over loopback, on behalf of the REST client it
+	// passes along
+	if isLoopback(meta.IP) {
+		if ip := forwardedFor(md); ip != "" {
+			meta.IP = ip
+		}
+		if userAgent := firstValue(md, gatewayUserAgentHeaderKey); userAgent != "" {
+			meta.UserAgent = userAgent
+		}
+	}
+
+	if publicMethods[method] {
+		return db.WithAuditMeta(ctx, meta), nil
+	}
+
+	authorizationHeader := firstValue(md, authorizationHeaderKey)
+	if authorizationHeader == "" {
+		return nil, status.Error(codes.Unauthenticated, "authorization header is not provided")
+	}
+
+	fields := strings.Fields(authorizationHeader)
+	if len(fields) < 2 {
+		return nil, status.Error(codes.Unauthenticated, "invalid authorization header format")
+	}
+
+	authorizationType := strings.ToLower(fields[0])
+	if authorizationType != authorizationTypeBearer {
+		return nil, status.Errorf(codes.Unauthenticated, "unsupported authorization type %s", authorizationType)
+	}
+
+	payload, err := s.tokenMaker.VerifyToken(fields[1])
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	meta.Actor = payload.Username
+	ctx = db.WithAuditMeta(ctx, meta)
+	return context.WithValue(ctx, payloadKey{}, payload), nil
+}
+
+// peerIP returns the address the call came from.
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+
+	ip := p.Addr.String()
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+	return ip
+}
+
+func isLoopback(ip string) bool {
+	addr := net.ParseIP(ip)
+	return addr != nil && addr.IsLoopback()
+}
+
+// forwardedFor returns the client the gateway appended to x-forwarded-for,
+// empty when there is none.
+func forwardedFor(md metadata.MD) string {
+	values := md.Get(forwardedForHeaderKey)
+	if len(values) == 0 {
+		return ""
+	}
+
+	hops := strings.Split(values[len(values)-1], ",")
+	client := strings.TrimSpace(hops[len(hops)-1])
+	if net.ParseIP(client) == nil {
+		return ""
+	}
+	return client
+}
+
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDSize {
+		return false
+	}
+
+	for _, r := range id {
+		if r < '!' || r > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,171 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/vlone310/bss/internal/db/mock"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+	"github.com/vlone310/bss/internal/grpc/pb"
+	"github.com/vlone310/bss/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestAuthorization(t *testing.T) {
+	account := randomAccount()
+
+	testCases := []struct {
+		name       string
+		setupAuth  func(t *testing.T, server *Server) context.Context
+		buildStubs func(store *mockdb.MockStore)
+		code       codes.Code
+	}{
+		{
+			name: "OK",
+			setupAuth: func(t *testing.T, server *Server) context.Context {
+				return withAuthorization(t, server, account.Owner, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+			},
+			code: codes.OK,
+		},
+		{
+			name: "NoAuthorization",
+			setupAuth: func(t *testing.T, server *Server) context.Context {
+				return context.Background()
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.Unauthenticated,
+		},
+		{
+			name: "UnsupportedAuthorization",
+			setupAuth: func(t *testing.T, server *Server) context.Context {
+				token, err := server.tokenMaker.CreateToken(account.Owner, time.Minute)
+				require.NoError(t, err)
+				return metadata.AppendToOutgoingContext(context.Background(), authorizationHeaderKey, "basic "+token)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.Unauthenticated,
+		},
+		{
+			name: "InvalidAuthorizationFormat",
+			setupAuth: func(t *testing.T, server *Server) context.Context {
+				return metadata.AppendToOutgoingContext(context.Background(), authorizationHeaderKey, authorizationTypeBearer)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.Unauthenticated,
+		},
+		{
+			name: "ExpiredToken",
+			setupAuth: func(t *testing.T, server *Server) context.Context {
+				return withAuthorization(t, server, account.Owner, -time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.Unauthenticated,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			client := pb.NewAccountServiceClient(dialTestServer(t, server))
+
+			_, err := client.GetAccount(tc.setupAuth(t, server), &pb.GetAccountRequest{Id: account.ID})
+			if tc.code == codes.OK {
+				require.NoError(t, err)
+				return
+			}
+			requireCode(t, err, tc.code)
+		})
+	}
+}
+
+func TestAuditMeta(t *testing.T) {
+	account := randomAccount()
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	var meta db.AuditMeta
+	store.EXPECT().
+		GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+		Times(2).
+		DoAndReturn(func(ctx context.Context, _ int64) (db.Account, error) {
+			var ok bool
+			meta, ok = db.AuditMetaFrom(ctx)
+			require.True(t, ok)
+			return account, nil
+		})
+
+	server := newTestServer(t, store)
+	client := pb.NewAccountServiceClient(dialTestServer(t, server))
+
+	t.Run("RequestID", func(t *testing.T) {
+		requestID := testutil.RandomString(16)
+		ctx := withAuthorization(t, server, account.Owner, time.Minute)
+		ctx = metadata.AppendToOutgoingContext(ctx, requestIDHeaderKey, requestID)
+
+		var header metadata.MD
+		_, err := client.GetAccount(ctx, &pb.GetAccountRequest{Id: account.ID}, grpc.Header(&header))
+		require.NoError(t, err)
+
+		require.Equal(t, []string{requestID}, header.Get(requestIDHeaderKey))
+		require.Equal(t, requestID, meta.RequestID)
+		require.Equal(t, account.Owner, meta.Actor)
+		require.Contains(t, meta.UserAgent, "grpc-go")
+	})
+
+	t.Run("NewRequestID", func(t *testing.T) {
+		ctx := withAuthorization(t, server, account.Owner, time.Minute)
+		ctx = metadata.AppendToOutgoingContext(ctx, requestIDHeaderKey, "not a request id")
+
+		var header metadata.MD
+		_, err := client.GetAccount(ctx, &pb.GetAccountRequest{Id: account.ID}, grpc.Header(&header))
+		require.NoError(t, err)
+
+		require.Len(t, header.Get(requestIDHeaderKey), 1)
+		require.Equal(t, header.Get(requestIDHeaderKey)[0], meta.RequestID)
+		require.NotEqual(t, "not a request id", meta.RequestID)
+	})
+}
+
+func TestForwardedFor(t *testing.T) {
+	testCases := []struct {
+		name   string
+		values []string
+		want   string
+	}{
+		{name: "None", want: ""},
+		{name: "Client", values: []string{"203.0.113.7"}, want: "203.0.113.7"},
+		{name: "LastHop", values: []string{"198.51.100.1, 203.0.113.7"}, want: "203.0.113.7"},
+		{name: "NotAnIP", values: []string{"unknown"}, want: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			md := metadata.MD{}
+			for _, value := range tc.values {
+				md.Append(forwardedForHeaderKey, value)
+			}
+			require.Equal(t, tc.want, forwardedFor(md))
+		})
+	}
+}
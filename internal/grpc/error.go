@@ -0,0 +1,86 @@
+package grpc
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/go-playground/validator/v10"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+	"github.com/vlone310/bss/internal/transfer"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var validate = validator.New()
+
+// fieldRules collects the fields of a request that break their rules, the
+// same rules the REST handlers bind with.
+type fieldRules struct {
+	violations []*errdetails.BadRequest_FieldViolation
+}
+
+func (r *fieldRules) check(field string, value any, rules string) {
+	err := validate.Var(value, rules)
+	if err == nil {
+		return
+	}
+
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) {
+		r.add(field, err.Error())
+		return
+	}
+	for _, fe := range errs {
+		rule := fe.Tag()
+		if fe.Param() != "" {
+			rule += "=" + fe.Param()
+		}
+		r.add(field, fmt.Sprintf("failed on the %q rule", rule))
+	}
+}
+
+func (r *fieldRules) add(field string, description string) {
+	r.violations = append(r.violations, &errdetails.BadRequest_FieldViolation{
+		Field:       field,
+		Description: description,
+	})
+}
+
+// err returns an InvalidArgument status that details every violation, nil
+// when there is none.
+func (r *fieldRules) err() error {
+	if len(r.violations) == 0 {
+		return nil
+	}
+
+	st := status.New(codes.InvalidArgument, "invalid request")
+	detailed, err := st.WithDetails(&errdetails.BadRequest{FieldViolations: r.violations})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// internalError logs err and returns a status that does not reveal it.
+func internalError(err error) error {
+	log.Printf("grpc: %v", err)
+	return status.Error(codes.Internal, "internal error")
+}
+
+// transferError returns the status for an error of a transfer transaction.
+func transferError(err error) error {
+	switch {
+	case errors.Is(err, transfer.ErrAccountNotFound):
+		return status.Error(codes.NotFound, "account not found")
+	case errors.Is(err, db.ErrAccountNotActive), errors.Is(err, db.ErrInsufficientFunds), errors.Is(err, db.ErrWithdrawalLimitReached), errors.Is(err, db.ErrMaxBalanceExceeded), errors.Is(err, db.ErrUnverifiedBalanceLimit):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, db.ErrKYCRequired), errors.Is(err, db.ErrTransferBlocked):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, db.ErrCurrencyMismatch), errors.Is(err, db.ErrAmountNotPositive):
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		return internalError(err)
+	}
+}
@@ -0,0 +1,91 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/vlone310/bss/internal/grpc/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// Gateway serves the REST routes of the contract, the google.api.http
+// options in proto/bss/v1, by calling the gRPC server. The calls go through
+// the same interceptors as any other.
+type Gateway struct {
+	conn *grpc.ClientConn
+	mux  *runtime.ServeMux
+}
+
+// NewGateway returns a gateway to the gRPC server listening on grpcAddr.
+// The connection is made lazily, the gRPC server need not be up yet.
+func NewGateway(ctx context.Context, grpcAddr string) (*Gateway, error) {
+	conn, err := grpc.NewClient(dialAddr(grpcAddr), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, err
+	}
+
+	mux := runtime.NewServeMux(
+		runtime.WithIncomingHeaderMatcher(incomingHeaderMatcher),
+		runtime.WithOutgoingHeaderMatcher(outgoingHeaderMatcher),
+		// the same field names as the REST API
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
+			MarshalOptions:   protojson.MarshalOptions{UseProtoNames: true},
+			UnmarshalOptions: protojson.UnmarshalOptions{DiscardUnknown: true},
+		}),
+	)
+
+	for _, register := range []func(context.Context, *runtime.ServeMux, *grpc.ClientConn) error{
+		pb.RegisterUserServiceHandler,
+		pb.RegisterAccountServiceHandler,
+		pb.RegisterTransferServiceHandler,
+	} {
+		if err := register(ctx, mux, conn); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	return &Gateway{conn: conn, mux: mux}, nil
+}
+
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	g.mux.ServeHTTP(w, r)
+}
+
+// Close closes the connection to the gRPC server.
+func (g *Gateway) Close() error {
+	return g.conn.Close()
+}
+
+// incomingHeaderMatcher passes the request ID on as is, along with what the
+// gateway passes by default.
+func incomingHeaderMatcher(key string) (string, bool) {
+	if strings.EqualFold(key, requestIDHeaderKey) {
+		return requestIDHeaderKey, true
+	}
+	return runtime.DefaultHeaderMatcher(key)
+}
+
+// outgoingHeaderMatcher returns the request ID in the same header as the
+// REST API.
+func outgoingHeaderMatcher(key string) (string, bool) {
+	if key == requestIDHeaderKey {
+		return http.CanonicalHeaderKey(requestIDHeaderKey), true
+	}
+	return runtime.MetadataHeaderPrefix + key, true
+}
+
+// dialAddr turns a listen address without a host, such as ":9090", into one
+// that can be dialled.
+func dialAddr(addr string) string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil || (host != "" && host != "0.0.0.0" && host != "::") {
+		return addr
+	}
+	return net.JoinHostPort("localhost", port)
+}
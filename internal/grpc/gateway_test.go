@@ -0,0 +1,112 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/vlone310/bss/internal/db/mock"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+)
+
+// newTestGateway serves server on a loopback port and returns a gateway to
+// it.
+func newTestGateway(t *testing.T, server *Server) *Gateway {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go server.Serve(lis)
+	t.Cleanup(server.grpcServer.Stop)
+
+	gateway, err := NewGateway(context.Background(), lis.Addr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() { gateway.Close() })
+
+	return gateway
+}
+
+func TestGateway(t *testing.T) {
+	user, password := randomUser(t)
+	account := randomAccount()
+	account.Owner = user.Username
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	var meta db.AuditMeta
+	store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+	store.EXPECT().
+		GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+		Times(1).
+		DoAndReturn(func(ctx context.Context, _ int64) (db.Account, error) {
+			meta, _ = db.AuditMetaFrom(ctx)
+			return account, nil
+		})
+
+	server := newTestServer(t, store)
+	gateway := newTestGateway(t, server)
+
+	body := fmt.Sprintf(`{"username": %q, "password": %q}`, user.Username, password)
+	request := httptest.NewRequest(http.MethodPost, "/v1/users/login", strings.NewReader(body))
+	recorder := httptest.NewRecorder()
+	gateway.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	var login struct {
+		AccessToken string `json:"access_token"`
+	}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &login))
+	require.NotEmpty(t, login.AccessToken)
+
+	t.Run("Authorized", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/v1/accounts/%d", account.ID), nil)
+		request.RemoteAddr = "203.0.113.7:4321"
+		request.Header.Set("Authorization", "Bearer "+login.AccessToken)
+		request.Header.Set("X-Request-ID", "gateway-test")
+		request.Header.Set("User-Agent", "gateway-test-agent")
+
+		recorder := httptest.NewRecorder()
+		gateway.ServeHTTP(recorder, request)
+		require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+		require.Equal(t, "gateway-test", recorder.Header().Get("X-Request-ID"))
+
+		var res struct {
+			Account struct {
+				Owner      string `json:"owner"`
+				MinBalance struct {
+					Currency string `json:"currency"`
+				} `json:"min_balance"`
+			} `json:"account"`
+		}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+		require.Equal(t, account.Owner, res.Account.Owner)
+		require.Equal(t, account.Currency, res.Account.MinBalance.Currency)
+
+		// the changes are audited under the REST client
+		require.Equal(t, "gateway-test", meta.RequestID)
+		require.Equal(t, user.Username, meta.Actor)
+		require.Equal(t, "203.0.113.7", meta.IP)
+		require.Equal(t, "gateway-test-agent", meta.UserAgent)
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/v1/accounts/%d", account.ID), nil)
+		recorder := httptest.NewRecorder()
+		gateway.ServeHTTP(recorder, request)
+		require.Equal(t, http.StatusUnauthorized, recorder.Code)
+	})
+}
+
+func TestDialAddr(t *testing.T) {
+	require.Equal(t, "localhost:9090", dialAddr(":9090"))
+	require.Equal(t, "localhost:9090", dialAddr("0.0.0.0:9090"))
+	require.Equal(t, "grpc.internal:9090", dialAddr("grpc.internal:9090"))
+}
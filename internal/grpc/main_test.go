@@ -0,0 +1,84 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vlone310/bss/config"
+	"github.com/vlone310/bss/internal/currency"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+	"github.com/vlone310/bss/internal/screening"
+	"github.com/vlone310/bss/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newTestServer(t *testing.T, store db.Store) *Server {
+	t.Helper()
+
+	config := config.Config{
+		TokenSymmetricKey:   testutil.RandomString(32),
+		AccessTokenDuration: time.Minute,
+	}
+
+	server, err := NewServer(config, store, testCurrencies(), screening.NewScreener(0, 0))
+	require.NoError(t, err)
+
+	return server
+}
+
+// testCurrencies enables the currencies testutil.RandomCurrency picks from.
+func testCurrencies() *currency.Registry {
+	return currency.NewRegistry(
+		currency.Currency{Code: "CAD", Numeric: 124, MinorUnits: 2, Enabled: true},
+		currency.Currency{Code: "EUR", Numeric: 978, MinorUnits: 2, Enabled: true},
+		currency.Currency{Code: "GBP", Numeric: 826, MinorUnits: 2, Enabled: false},
+		currency.Currency{Code: "USD", Numeric: 840, MinorUnits: 2, Enabled: true},
+	)
+}
+
+// dialTestServer serves server in memory and returns a connection to it,
+// so that the calls go through the interceptors.
+func dialTestServer(t *testing.T, server *Server) *grpc.ClientConn {
+	t.Helper()
+
+	lis := bufconn.Listen(1 << 20)
+	go server.Serve(lis)
+	t.Cleanup(server.grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+// withAuthorization returns a context that sends a token for username.
+func withAuthorization(t *testing.T, server *Server, username string, duration time.Duration) context.Context {
+	t.Helper()
+
+	token, err := server.tokenMaker.CreateToken(username, duration)
+	require.NoError(t, err)
+
+	return metadata.AppendToOutgoingContext(context.Background(), authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, token))
+}
+
+func requireCode(t *testing.T, err error, code codes.Code) {
+	t.Helper()
+
+	require.Error(t, err)
+	require.Equal(t, code, status.Code(err), err)
+}
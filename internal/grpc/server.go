@@ -0,0 +1,94 @@
+// Package grpc serves the user, account and transfer APIs over gRPC, and
+// over REST through a gateway generated from the same contract, see
+// proto/bss/v1.
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/vlone310/bss/config"
+	"github.com/vlone310/bss/internal/adapter/token/maker"
+	"github.com/vlone310/bss/internal/adapter/token/paseto"
+	"github.com/vlone310/bss/internal/currency"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+	"github.com/vlone310/bss/internal/grpc/pb"
+	"github.com/vlone310/bss/internal/screening"
+	"github.com/vlone310/bss/internal/transfer"
+	"google.golang.org/grpc"
+)
+
+type Server struct {
+	pb.UnimplementedUserServiceServer
+	pb.UnimplementedAccountServiceServer
+	pb.UnimplementedTransferServiceServer
+
+	config     config.Config
+	store      db.Store
+	tokenMaker maker.Maker
+	currencies *currency.Registry
+	screener   *screening.Screener
+	transfers  *transfer.Service
+	grpcServer *grpc.Server
+}
+
+func NewServer(config config.Config, store db.Store, currencies *currency.Registry, screener *screening.Screener) (*Server, error) {
+	tokenMaker, err := paseto.NewPasetoMaker(config.TokenSymmetricKey)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create token maker: %w", err)
+	}
+
+	server := &Server{
+		config:     config,
+		store:      store,
+		tokenMaker: tokenMaker,
+		currencies: currencies,
+		screener:   screener,
+		transfers:  transfer.NewService(store, screener),
+	}
+
+	server.grpcServer = grpc.NewServer(
+		grpc.ChainUnaryInterceptor(server.unaryInterceptor),
+		grpc.ChainStreamInterceptor(server.streamInterceptor),
+	)
+	pb.RegisterUserServiceServer(server.grpcServer, server)
+	pb.RegisterAccountServiceServer(server.grpcServer, server)
+	pb.RegisterTransferServiceServer(server.grpcServer, server)
+
+	return server, nil
+}
+
+// Serve accepts connections on lis until the server is stopped.
+func (s *Server) Serve(lis net.Listener) error {
+	return s.grpcServer.Serve(lis)
+}
+
+func (s *Server) ServeGRPC(addr string) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("gRPC server is running on", addr)
+	return s.Serve(lis)
+}
+
+// Shutdown stops accepting connections and waits for the calls in flight to
+// finish. When ctx is done first the remaining calls are cancelled.
+func (s *Server) Shutdown(ctx context.Context) error {
+	stopped := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		s.grpcServer.Stop()
+		<-stopped
+		return ctx.Err()
+	}
+}
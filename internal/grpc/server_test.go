@@ -0,0 +1,85 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/vlone310/bss/internal/db/mock"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+	"github.com/vlone310/bss/internal/grpc/pb"
+)
+
+func TestShutdown(t *testing.T) {
+	account := randomAccount()
+
+	testCases := []struct {
+		name    string
+		timeout time.Duration
+		// release ends the call in flight, nil leaves it running until its
+		// context is cancelled.
+		release     chan struct{}
+		checkResult func(t *testing.T, callErr, shutdownErr error)
+	}{
+		{
+			name:    "Drained",
+			timeout: time.Second,
+			release: make(chan struct{}),
+			checkResult: func(t *testing.T, callErr, shutdownErr error) {
+				require.NoError(t, callErr)
+				require.NoError(t, shutdownErr)
+			},
+		},
+		{
+			name:    "Deadline",
+			timeout: 50 * time.Millisecond,
+			checkResult: func(t *testing.T, callErr, shutdownErr error) {
+				require.Error(t, callErr)
+				require.ErrorIs(t, shutdownErr, context.DeadlineExceeded)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+
+			started := make(chan struct{})
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).
+				DoAndReturn(func(ctx context.Context, _ int64) (db.Account, error) {
+					close(started)
+					select {
+					case <-tc.release:
+						return account, nil
+					case <-ctx.Done():
+						return db.Account{}, ctx.Err()
+					}
+				})
+
+			server := newTestServer(t, store)
+			client := pb.NewAccountServiceClient(dialTestServer(t, server))
+
+			callErrs := make(chan error, 1)
+			go func() {
+				ctx := withAuthorization(t, server, account.Owner, time.Minute)
+				_, err := client.GetAccount(ctx, &pb.GetAccountRequest{Id: account.ID})
+				callErrs <- err
+			}()
+			<-started
+
+			ctx, cancel := context.WithTimeout(context.Background(), tc.timeout)
+			defer cancel()
+
+			shutdownErrs := make(chan error, 1)
+			go func() { shutdownErrs <- server.Shutdown(ctx) }()
+
+			if tc.release != nil {
+				close(tc.release)
+			}
+			tc.checkResult(t, <-callErrs, <-shutdownErrs)
+		})
+	}
+}
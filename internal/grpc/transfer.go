@@ -0,0 +1,95 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+	"github.com/vlone310/bss/internal/grpc/pb"
+	"github.com/vlone310/bss/internal/money"
+	"github.com/vlone310/bss/internal/transfer"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// convertTransfer needs the currency of the accounts, which the transfer
+// itself does not record.
+func convertTransfer(transfer db.Transfer, currency string) *pb.Transfer {
+	return &pb.Transfer{
+		Id:            transfer.ID,
+		FromAccountId: transfer.FromAccountID,
+		ToAccountId:   transfer.ToAccountID,
+		Amount:        &pb.Money{Minor: transfer.AmountCents, Currency: currency},
+		Status:        transfer.Status,
+		CreatedAt:     timestamppb.New(transfer.CreatedAt.Time),
+	}
+}
+
+// CreateTransfer moves money from an account of the authenticated user. A
+// transfer between parties on a sanctions list, or one the fraud engine
+// challenged or held, is answered as pending.
+func (s *Server) CreateTransfer(ctx context.Context, req *pb.CreateTransferRequest) (*pb.CreateTransferResponse, error) {
+	amount := money.New(req.GetAmount().GetMinor(), req.GetAmount().GetCurrency())
+
+	var rules fieldRules
+	rules.check("from_account_id", req.GetFromAccountId(), "min=1")
+	rules.check("to_account_id", req.GetToAccountId(), "min=1")
+	if !s.currencies.Enabled(amount.Currency) {
+		rules.add("amount.currency", "is not an enabled currency")
+	}
+	if !amount.IsPositive() {
+		rules.add("amount.minor", db.ErrAmountNotPositive.Error())
+	}
+	if err := rules.err(); err != nil {
+		return nil, err
+	}
+
+	result, err := s.transfers.Create(ctx, transfer.Request{
+		Sender:        authPayload(ctx).Username,
+		FromAccountID: req.GetFromAccountId(),
+		ToAccountID:   req.GetToAccountId(),
+		Amount:        amount,
+	})
+	if err != nil {
+		return nil, transferError(err)
+	}
+
+	return &pb.CreateTransferResponse{
+		Transfer:             convertTransfer(result.Transfer, amount.Currency),
+		ConfirmationRequired: result.ConfirmationRequired,
+	}, nil
+}
+
+// GetTransfer returns a transfer the authenticated user owns a side of.
+func (s *Server) GetTransfer(ctx context.Context, req *pb.GetTransferRequest) (*pb.GetTransferResponse, error) {
+	var rules fieldRules
+	rules.check("id", req.GetId(), "min=1")
+	if err := rules.err(); err != nil {
+		return nil, err
+	}
+
+	transfer, err := s.store.GetTransfer(ctx, req.GetId())
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Error(codes.NotFound, "transfer not found")
+		}
+		return nil, internalError(err)
+	}
+
+	username := authPayload(ctx).Username
+	for _, accountID := range []int64{transfer.FromAccountID, transfer.ToAccountID} {
+		account, err := s.store.GetAccount(ctx, accountID)
+		if err != nil {
+			return nil, internalError(err)
+		}
+		// both sides of a transfer are in the same currency
+		if account.Owner == username {
+			return &pb.GetTransferResponse{Transfer: convertTransfer(transfer, account.Currency)}, nil
+		}
+	}
+
+	// do not reveal that the transfer exists
+	return nil, status.Error(codes.NotFound, "transfer not found")
+}
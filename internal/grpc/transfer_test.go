@@ -0,0 +1,288 @@
+package grpc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/vlone310/bss/internal/db/mock"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+	"github.com/vlone310/bss/internal/grpc/pb"
+	"github.com/vlone310/bss/internal/money"
+	"google.golang.org/grpc/codes"
+)
+
+func TestCreateTransfer(t *testing.T) {
+	amount := int64(10)
+
+	account1 := randomAccount()
+	account2 := randomAccount()
+	account2.ID = account1.ID + 1
+	account2.Currency = account1.Currency
+	account1.Balance = 100
+
+	transfer := db.Transfer{
+		ID:            1,
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		AmountCents:   amount,
+		Status:        db.TransferStatusCompleted,
+		CreatedAt:     pgtype.Timestamptz{Valid: true},
+	}
+
+	arg := db.TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        money.New(amount, account1.Currency),
+	}
+
+	testCases := []struct {
+		name       string
+		username   string
+		req        *pb.CreateTransferRequest
+		buildStubs func(store *mockdb.MockStore)
+		check      func(t *testing.T, res *pb.CreateTransferResponse, err error)
+	}{
+		{
+			name:     "OK",
+			username: account1.Owner,
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        &pb.Money{Minor: amount, Currency: account1.Currency},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.TransferTxResult{Transfer: transfer}, nil)
+			},
+			check: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, transfer.ID, res.GetTransfer().GetId())
+				require.Equal(t, amount, res.GetTransfer().GetAmount().GetMinor())
+				require.Equal(t, db.TransferStatusCompleted, res.GetTransfer().GetStatus())
+				require.False(t, res.GetConfirmationRequired())
+			},
+		},
+		{
+			name:     "Challenged",
+			username: account1.Owner,
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        &pb.Money{Minor: amount, Currency: account1.Currency},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				pending := transfer
+				pending.Status = db.TransferStatusPending
+
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.TransferTxResult{
+					Transfer:      pending,
+					FraudDecision: &db.FraudDecision{Action: db.FraudActionChallenge},
+				}, nil)
+			},
+			check: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, db.TransferStatusPending, res.GetTransfer().GetStatus())
+				require.True(t, res.GetConfirmationRequired())
+			},
+		},
+		{
+			name:     "NotOwner",
+			username: account2.Owner,
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        &pb.Money{Minor: amount, Currency: account1.Currency},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				requireCode(t, err, codes.NotFound)
+			},
+		},
+		{
+			name:     "ToAccountNotFound",
+			username: account1.Owner,
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        &pb.Money{Minor: amount, Currency: account1.Currency},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(db.Account{}, pgx.ErrNoRows)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				requireCode(t, err, codes.NotFound)
+			},
+		},
+		{
+			name:     "InsufficientFunds",
+			username: account1.Owner,
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        &pb.Money{Minor: account1.Balance + 1, Currency: account1.Currency},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, db.ErrInsufficientFunds)
+			},
+			check: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				requireCode(t, err, codes.FailedPrecondition)
+			},
+		},
+		{
+			name:     "KYCRequired",
+			username: account1.Owner,
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        &pb.Money{Minor: amount, Currency: account1.Currency},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.TransferTxResult{}, db.ErrKYCRequired)
+			},
+			check: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				requireCode(t, err, codes.PermissionDenied)
+			},
+		},
+		{
+			name:     "NegativeAmount",
+			username: account1.Owner,
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        &pb.Money{Minor: -amount, Currency: account1.Currency},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				requireCode(t, err, codes.InvalidArgument)
+			},
+		},
+		{
+			name:     "NoAmount",
+			username: account1.Owner,
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				requireCode(t, err, codes.InvalidArgument)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			client := pb.NewTransferServiceClient(dialTestServer(t, server))
+
+			ctx := withAuthorization(t, server, tc.username, time.Minute)
+			res, err := client.CreateTransfer(ctx, tc.req)
+			tc.check(t, res, err)
+		})
+	}
+}
+
+func TestGetTransfer(t *testing.T) {
+	account1 := randomAccount()
+	account2 := randomAccount()
+	account2.ID = account1.ID + 1
+	account2.Currency = account1.Currency
+
+	transfer := db.Transfer{
+		ID:            7,
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		AmountCents:   10,
+		Status:        db.TransferStatusCompleted,
+		CreatedAt:     pgtype.Timestamptz{Valid: true},
+	}
+
+	testCases := []struct {
+		name       string
+		username   string
+		buildStubs func(store *mockdb.MockStore)
+		code       codes.Code
+	}{
+		{
+			name:     "Sender",
+			username: account1.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+			},
+			code: codes.OK,
+		},
+		{
+			name:     "Recipient",
+			username: account2.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+			},
+			code: codes.OK,
+		},
+		{
+			name:     "Stranger",
+			username: "someoneelse",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(2).Return(account1, nil)
+			},
+			code: codes.NotFound,
+		},
+		{
+			name:     "NotFound",
+			username: account1.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(db.Transfer{}, pgx.ErrNoRows)
+			},
+			code: codes.NotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			client := pb.NewTransferServiceClient(dialTestServer(t, server))
+
+			ctx := withAuthorization(t, server, tc.username, time.Minute)
+			res, err := client.GetTransfer(ctx, &pb.GetTransferRequest{Id: transfer.ID})
+			if tc.code != codes.OK {
+				requireCode(t, err, tc.code)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, transfer.ID, res.GetTransfer().GetId())
+			require.Equal(t, account1.Currency, res.GetTransfer().GetAmount().GetCurrency())
+		})
+	}
+}
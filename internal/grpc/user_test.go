@@ -0,0 +1,211 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/vlone310/bss/internal/db/mock"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+	"github.com/vlone310/bss/internal/grpc/pb"
+	"github.com/vlone310/bss/testutil"
+	"github.com/vlone310/bss/util"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCreateUser(t *testing.T) {
+	user, password := randomUser(t)
+
+	testCases := []struct {
+		name       string
+		req        *pb.CreateUserRequest
+		buildStubs func(store *mockdb.MockStore)
+		check      func(t *testing.T, res *pb.CreateUserResponse, err error)
+	}{
+		{
+			name: "OK",
+			req: &pb.CreateUserRequest{
+				Username: user.Username,
+				Password: password,
+				FullName: user.FullName,
+				Email:    user.Email,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateUserTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateUserTxParams) (db.CreateUserTxResult, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.NoError(t, util.CheckPasswordHash(password, arg.HashedPassword))
+						return db.CreateUserTxResult{User: user}, nil
+					})
+			},
+			check: func(t *testing.T, res *pb.CreateUserResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, user.Username, res.GetUser().GetUsername())
+				require.Equal(t, user.Email, res.GetUser().GetEmail())
+			},
+		},
+		{
+			name: "Exists",
+			req: &pb.CreateUserRequest{
+				Username: user.Username,
+				Password: password,
+				FullName: user.FullName,
+				Email:    user.Email,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateUserTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CreateUserTxResult{}, &pgconn.PgError{Code: "23505"})
+			},
+			check: func(t *testing.T, res *pb.CreateUserResponse, err error) {
+				requireCode(t, err, codes.AlreadyExists)
+			},
+		},
+		{
+			name: "Internal",
+			req: &pb.CreateUserRequest{
+				Username: user.Username,
+				Password: password,
+				FullName: user.FullName,
+				Email:    user.Email,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateUserTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CreateUserTxResult{}, pgx.ErrTxClosed)
+			},
+			check: func(t *testing.T, res *pb.CreateUserResponse, err error) {
+				requireCode(t, err, codes.Internal)
+				// the cause is only logged
+				require.NotContains(t, status.Convert(err).Message(), pgx.ErrTxClosed.Error())
+			},
+		},
+		{
+			name: "Invalid",
+			req: &pb.CreateUserRequest{
+				Username: "in valid",
+				Password: "123",
+				FullName: user.FullName,
+				Email:    "not an email",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateUserTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, res *pb.CreateUserResponse, err error) {
+				requireCode(t, err, codes.InvalidArgument)
+
+				var fields []string
+				for _, detail := range status.Convert(err).Details() {
+					badRequest, ok := detail.(*errdetails.BadRequest)
+					require.True(t, ok)
+					for _, violation := range badRequest.GetFieldViolations() {
+						fields = append(fields, violation.GetField())
+					}
+				}
+				require.Equal(t, []string{"username", "password", "email"}, fields)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			client := pb.NewUserServiceClient(dialTestServer(t, server))
+
+			res, err := client.CreateUser(context.Background(), tc.req)
+			tc.check(t, res, err)
+		})
+	}
+}
+
+func TestLoginUser(t *testing.T) {
+	user, password := randomUser(t)
+
+	testCases := []struct {
+		name       string
+		req        *pb.LoginUserRequest
+		buildStubs func(store *mockdb.MockStore)
+		check      func(t *testing.T, server *Server, res *pb.LoginUserResponse, err error)
+	}{
+		{
+			name: "OK",
+			req:  &pb.LoginUserRequest{Username: user.Username, Password: password},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+			},
+			check: func(t *testing.T, server *Server, res *pb.LoginUserResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, user.Username, res.GetUser().GetUsername())
+
+				payload, err := server.tokenMaker.VerifyToken(res.GetAccessToken())
+				require.NoError(t, err)
+				require.Equal(t, user.Username, payload.Username)
+				require.WithinDuration(t, payload.ExpiredAt, res.GetAccessTokenExpiresAt().AsTime(), 0)
+			},
+		},
+		{
+			name: "WrongPassword",
+			req:  &pb.LoginUserRequest{Username: user.Username, Password: password + "x"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+			},
+			check: func(t *testing.T, server *Server, res *pb.LoginUserResponse, err error) {
+				requireCode(t, err, codes.Unauthenticated)
+			},
+		},
+		{
+			name: "UserNotFound",
+			req:  &pb.LoginUserRequest{Username: user.Username, Password: password},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(db.User{}, pgx.ErrNoRows)
+			},
+			check: func(t *testing.T, server *Server, res *pb.LoginUserResponse, err error) {
+				requireCode(t, err, codes.Unauthenticated)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			client := pb.NewUserServiceClient(dialTestServer(t, server))
+
+			res, err := client.LoginUser(context.Background(), tc.req)
+			tc.check(t, server, res, err)
+		})
+	}
+}
+
+func randomUser(t *testing.T) (user db.User, password string) {
+	t.Helper()
+
+	password = testutil.RandomString(6)
+	hashedPassword, err := util.HashPassword(password)
+	require.NoError(t, err)
+
+	user = db.User{
+		Username:       testutil.RandomOwner(),
+		HashedPassword: hashedPassword,
+		FullName:       testutil.RandomOwner(),
+		Email:          testutil.RandomEmail(),
+		Role:           db.UserRoleCustomer,
+	}
+	return
+}
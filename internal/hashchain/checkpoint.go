@@ -0,0 +1,118 @@
+package hashchain
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	db "github.com/vlone310/bss/internal/db/sqlc"
+)
+
+var ErrInvalidSigningKey = errors.New("checkpoint signing key must be a base64 ed25519 seed")
+var ErrInvalidSignature = errors.New("checkpoint signature is invalid")
+
+// Signer creates signed checkpoints of the chain heads.
+type Signer struct {
+	key   ed25519.PrivateKey
+	keyID string
+}
+
+// NewSigner returns a Signer for a base64 encoded ed25519 seed.
+func NewSigner(seed string, keyID string) (*Signer, error) {
+	raw, err := base64.StdEncoding.DecodeString(seed)
+	if err != nil || len(raw) != ed25519.SeedSize {
+		return nil, ErrInvalidSigningKey
+	}
+
+	return &Signer{key: ed25519.NewKeyFromSeed(raw), keyID: keyID}, nil
+}
+
+func (s *Signer) PublicKey() ed25519.PublicKey {
+	return s.key.Public().(ed25519.PublicKey)
+}
+
+// Checkpoint records and signs the current chain heads.
+func (s *Signer) Checkpoint(ctx context.Context, store db.Store) (db.LedgerCheckpoint, error) {
+	return store.CreateLedgerCheckpointTx(ctx, db.CreateLedgerCheckpointTxParams{
+		KeyID: s.keyID,
+		Sign: func(rows []db.ListEntryChainHeadsRow) ([]byte, []byte, error) {
+			heads := make([]Head, 0, len(rows))
+			for _, row := range rows {
+				heads = append(heads, Head{AccountID: row.AccountID, EntryID: row.EntryID, Hash: row.Hash})
+			}
+
+			root := CheckpointRoot(heads)
+			return root, ed25519.Sign(s.key, root), nil
+		},
+	})
+}
+
+// VerifyCheckpoint checks the signature of a checkpoint and that every head
+// it recorded is still in the ledger unchanged. Together with Verify this
+// proves that no entry up to the checkpoint was altered.
+func VerifyCheckpoint(ctx context.Context, store db.Store, publicKey ed25519.PublicKey, checkpointID int64) error {
+	checkpoint, err := store.GetLedgerCheckpoint(ctx, checkpointID)
+	if err != nil {
+		return err
+	}
+
+	if !ed25519.Verify(publicKey, checkpoint.RootHash, checkpoint.Signature) {
+		return ErrInvalidSignature
+	}
+
+	rows, err := store.ListLedgerCheckpointHeads(ctx, checkpoint.ID)
+	if err != nil {
+		return err
+	}
+
+	heads := make([]Head, 0, len(rows))
+	for _, row := range rows {
+		entry, err := store.GetEntry(ctx, row.EntryID)
+		if err != nil {
+			return fmt.Errorf("head of account [%d]: %w", row.AccountID, err)
+		}
+
+		if entry.AccountID != row.AccountID || !bytes.Equal(entry.Hash, row.Hash) {
+			return &Break{AccountID: row.AccountID, EntryID: row.EntryID, Reason: "entry differs from the checkpoint"}
+		}
+
+		heads = append(heads, Head{AccountID: row.AccountID, EntryID: row.EntryID, Hash: row.Hash})
+	}
+
+	if !bytes.Equal(CheckpointRoot(heads), checkpoint.RootHash) {
+		return fmt.Errorf("checkpoint [%d] heads do not match its root hash", checkpoint.ID)
+	}
+
+	return nil
+}
+
+// ExportedCheckpoint is the form handed to an external notary.
+type ExportedCheckpoint struct {
+	ID        int64     `json:"id"`
+	RootHash  string    `json:"root_hash"`
+	Signature string    `json:"signature"`
+	KeyID     string    `json:"key_id"`
+	PublicKey string    `json:"public_key,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func Export(checkpoint db.LedgerCheckpoint, publicKey ed25519.PublicKey) ExportedCheckpoint {
+	exported := ExportedCheckpoint{
+		ID:        checkpoint.ID,
+		RootHash:  hex.EncodeToString(checkpoint.RootHash),
+		Signature: base64.StdEncoding.EncodeToString(checkpoint.Signature),
+		KeyID:     checkpoint.KeyID,
+		CreatedAt: checkpoint.CreatedAt.Time.UTC(),
+	}
+
+	if publicKey != nil {
+		exported.PublicKey = base64.StdEncoding.EncodeToString(publicKey)
+	}
+
+	return exported
+}
@@ -0,0 +1,72 @@
+// Package hashchain makes the ledger tamper-evident. Every entry stores the
+// hash of its content and of the previous entry of the same account, so
+// editing any past entry breaks every later link. Signed checkpoints of the
+// chain heads can be handed to an external notary, which prevents rewriting
+// a whole chain unnoticed.
+package hashchain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+
+	db "github.com/vlone310/bss/internal/db/sqlc"
+)
+
+const (
+	entryVersion      = "bss-entry-v1"
+	checkpointVersion = "bss-checkpoint-v1"
+)
+
+// Genesis is the previous hash of the first entry of every account.
+var Genesis = make([]byte, sha256.Size)
+
+// EntryHash returns the hash of the entry linked to prev. It must produce
+// the same bytes as the entry_hash function in the database, which computes
+// the hash on insert.
+func EntryHash(prev []byte, entry db.Entry) []byte {
+	h := sha256.New()
+	h.Write([]byte(entryVersion))
+	h.Write(prev)
+
+	var transferID int64
+	if entry.TransferID.Valid {
+		transferID = entry.TransferID.Int64
+	}
+
+	for _, v := range []int64{
+		entry.ID,
+		entry.AccountID,
+		entry.JournalID,
+		entry.AmountCents,
+		transferID,
+		entry.CreatedAt.Time.UnixMicro(),
+	} {
+		binary.Write(h, binary.BigEndian, v)
+	}
+
+	return h.Sum(nil)
+}
+
+// Head is the latest entry of an account's chain.
+type Head struct {
+	AccountID int64
+	EntryID   int64
+	Hash      []byte
+}
+
+// CheckpointRoot returns the hash over the chain heads, which must be sorted
+// by account.
+func CheckpointRoot(heads []Head) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(checkpointVersion)
+
+	for _, head := range heads {
+		binary.Write(&buf, binary.BigEndian, head.AccountID)
+		binary.Write(&buf, binary.BigEndian, head.EntryID)
+		buf.Write(head.Hash)
+	}
+
+	root := sha256.Sum256(buf.Bytes())
+	return root[:]
+}
@@ -0,0 +1,170 @@
+package hashchain
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/vlone310/bss/internal/db/mock"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+)
+
+// chain returns linked entries alternating between two accounts, ordered the
+// way WalkEntryChain streams them.
+func chain(n int) []db.Entry {
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	var entries []db.Entry
+
+	for _, accountID := range []int64{1, 2} {
+		prev := Genesis
+		for i := 0; i < n; i++ {
+			entry := db.Entry{
+				ID:          int64(i*2) + accountID,
+				AccountID:   accountID,
+				JournalID:   int64(i + 1),
+				AmountCents: 100 * (3 - 2*accountID),
+				TransferID:  pgtype.Int8{Int64: int64(i + 1), Valid: true},
+				CreatedAt:   pgtype.Timestamptz{Time: now.Add(time.Duration(i) * time.Second), Valid: true},
+				PrevHash:    prev,
+			}
+			entry.Hash = EntryHash(prev, entry)
+			prev = entry.Hash
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries
+}
+
+func walk(entries []db.Entry) func(context.Context, pgtype.Int8, func(db.Entry) error) error {
+	return func(_ context.Context, _ pgtype.Int8, fn func(db.Entry) error) error {
+		for _, entry := range entries {
+			if err := fn(entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func TestVerify(t *testing.T) {
+	entries := chain(3)
+
+	testCases := []struct {
+		name    string
+		tamper  func(entries []db.Entry)
+		checked int64
+		broken  *Break
+	}{
+		{
+			name:    "Intact",
+			tamper:  func(entries []db.Entry) {},
+			checked: 6,
+		},
+		{
+			name: "AmountChanged",
+			tamper: func(entries []db.Entry) {
+				entries[1].AmountCents++
+			},
+			checked: 2,
+			broken:  &Break{AccountID: 1, EntryID: entries[1].ID, Reason: "hash does not match the entry content"},
+		},
+		{
+			name: "EntryRemoved",
+			tamper: func(entries []db.Entry) {
+				copy(entries[4:], entries[5:])
+				entries[5] = entries[4]
+			},
+			checked: 5,
+			broken:  &Break{AccountID: 2, EntryID: entries[5].ID, Reason: "previous hash does not match the previous entry"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			tampered := append([]db.Entry(nil), entries...)
+			tc.tamper(tampered)
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().WalkEntryChain(gomock.Any(), gomock.Any(), gomock.Any()).
+				Times(1).
+				DoAndReturn(walk(tampered))
+
+			checked, err := Verify(context.Background(), store, pgtype.Int8{})
+			require.Equal(t, tc.checked, checked)
+			if tc.broken == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.True(t, IsBreak(err))
+			require.Equal(t, tc.broken, err)
+		})
+	}
+}
+
+func TestCheckpoint(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	seed := make([]byte, ed25519.SeedSize)
+	seed[0] = 1
+	signer, err := NewSigner(base64.StdEncoding.EncodeToString(seed), "k1")
+	require.NoError(t, err)
+
+	entries := chain(2)
+	heads := []db.ListEntryChainHeadsRow{
+		{AccountID: 1, EntryID: entries[1].ID, Hash: entries[1].Hash},
+		{AccountID: 2, EntryID: entries[3].ID, Hash: entries[3].Hash},
+	}
+
+	var checkpoint db.LedgerCheckpoint
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().CreateLedgerCheckpointTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.CreateLedgerCheckpointTxParams) (db.LedgerCheckpoint, error) {
+			root, signature, err := arg.Sign(heads)
+			checkpoint = db.LedgerCheckpoint{ID: 1, RootHash: root, Signature: signature, KeyID: arg.KeyID}
+			return checkpoint, err
+		})
+
+	created, err := signer.Checkpoint(context.Background(), store)
+	require.NoError(t, err)
+	require.Equal(t, "k1", created.KeyID)
+	require.True(t, ed25519.Verify(signer.PublicKey(), created.RootHash, created.Signature))
+
+	store.EXPECT().GetLedgerCheckpoint(gomock.Any(), gomock.Eq(int64(1))).AnyTimes().Return(checkpoint, nil)
+	store.EXPECT().ListLedgerCheckpointHeads(gomock.Any(), gomock.Eq(int64(1))).AnyTimes().Return([]db.LedgerCheckpointHead{
+		{CheckpointID: 1, AccountID: 1, EntryID: entries[1].ID, Hash: entries[1].Hash},
+		{CheckpointID: 1, AccountID: 2, EntryID: entries[3].ID, Hash: entries[3].Hash},
+	}, nil)
+	store.EXPECT().GetEntry(gomock.Any(), gomock.Eq(entries[1].ID)).AnyTimes().Return(entries[1], nil)
+	store.EXPECT().GetEntry(gomock.Any(), gomock.Eq(entries[3].ID)).Times(1).Return(entries[3], nil)
+
+	require.NoError(t, VerifyCheckpoint(context.Background(), store, signer.PublicKey(), 1))
+
+	// a rewritten head no longer matches the checkpoint
+	rewritten := entries[3]
+	rewritten.Hash = EntryHash(rewritten.PrevHash, db.Entry{ID: rewritten.ID})
+	store.EXPECT().GetEntry(gomock.Any(), gomock.Eq(entries[3].ID)).Times(1).Return(rewritten, nil)
+	require.True(t, IsBreak(VerifyCheckpoint(context.Background(), store, signer.PublicKey(), 1)))
+
+	other, _, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	require.ErrorIs(t, VerifyCheckpoint(context.Background(), store, other, 1), ErrInvalidSignature)
+}
+
+func TestNewSignerInvalidKey(t *testing.T) {
+	_, err := NewSigner("not base64", "k1")
+	require.ErrorIs(t, err, ErrInvalidSigningKey)
+
+	_, err = NewSigner(base64.StdEncoding.EncodeToString([]byte("short")), "k1")
+	require.ErrorIs(t, err, ErrInvalidSigningKey)
+}
@@ -0,0 +1,60 @@
+package hashchain
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+)
+
+// Break describes the first entry whose link does not hold.
+type Break struct {
+	AccountID int64  `json:"account_id"`
+	EntryID   int64  `json:"entry_id"`
+	Reason    string `json:"reason"`
+}
+
+func (b *Break) Error() string {
+	return fmt.Sprintf("chain of account [%d] breaks at entry [%d]: %s", b.AccountID, b.EntryID, b.Reason)
+}
+
+// Verify walks the entry chains, of all accounts or only of accountID, and
+// returns the number of entries checked. The first broken link is returned
+// as a *Break error.
+func Verify(ctx context.Context, store db.Store, accountID pgtype.Int8) (int64, error) {
+	var checked int64
+	var account int64
+	prev := Genesis
+
+	err := store.WalkEntryChain(ctx, accountID, func(entry db.Entry) error {
+		if checked == 0 || entry.AccountID != account {
+			account = entry.AccountID
+			prev = Genesis
+		}
+		checked++
+
+		if !bytes.Equal(entry.PrevHash, prev) {
+			return &Break{AccountID: entry.AccountID, EntryID: entry.ID, Reason: "previous hash does not match the previous entry"}
+		}
+
+		hash := EntryHash(prev, entry)
+		if !bytes.Equal(entry.Hash, hash) {
+			return &Break{AccountID: entry.AccountID, EntryID: entry.ID, Reason: "hash does not match the entry content"}
+		}
+
+		prev = hash
+		return nil
+	})
+
+	return checked, err
+}
+
+// IsBreak reports whether err is a broken chain rather than a failure to
+// read it.
+func IsBreak(err error) bool {
+	var b *Break
+	return errors.As(err, &b)
+}
@@ -9,36 +9,48 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	db "github.com/vlone310/bss/internal/db/sqlc"
+	"github.com/vlone310/bss/internal/money"
 )
 
 var errAccountNotFound = errors.New("account not found")
 var errAccountExists = errors.New("account already exists")
 var errUserNotFound = errors.New("user not found")
 
+// createAccountRequest opens an account for the authenticated user.
 type createAccountRequest struct {
-	Owner    string `json:"owner" binding:"required,min=3,max=20"`
 	Currency string `json:"currency" binding:"required,currency"`
+	Product  string `json:"product" binding:"omitempty,oneof=checking savings credit_line"`
 }
 
 type accountResponse struct {
-	ID        int64     `json:"id"`
-	Owner     string    `json:"owner"`
-	Balance   int64     `json:"balance"`
-	Currency  string    `json:"currency"`
-	CreatedAt time.Time `json:"created_at"`
+	ID              int64      `json:"id"`
+	Owner           string     `json:"owner"`
+	Balance         money.JSON `json:"balance"`
+	Currency        string     `json:"currency"`
+	Product         string     `json:"product"`
+	MinBalance      money.JSON `json:"min_balance"`
+	Status          string     `json:"status"`
+	StatusReason    string     `json:"status_reason,omitempty"`
+	StatusChangedAt *time.Time `json:"status_changed_at,omitempty"`
+	ClosedAt        *time.Time `json:"closed_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
 }
 
 func (s *Server) createAccount(c *gin.Context) {
 	var req createAccountRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, errorResponse(err))
+		errorResponse(c, http.StatusBadRequest, err)
 		return
 	}
 
+	if req.Product == "" {
+		req.Product = db.ProductChecking
+	}
+
 	arg := db.CreateAccountParams{
-		Owner:    req.Owner,
-		Balance:  0,
+		Owner:    authPayload(c).Username,
 		Currency: req.Currency,
+		Product:  req.Product,
 	}
 
 	account, err := s.store.CreateAccount(c, arg)
@@ -46,24 +58,22 @@ func (s *Server) createAccount(c *gin.Context) {
 		if pgErr, ok := err.(*pgconn.PgError); ok {
 			switch pgErr.Code {
 			case "23505":
-				c.JSON(http.StatusForbidden, errorResponse(errAccountExists))
+				errorResponse(c, http.StatusForbidden, errAccountExists)
 				return
 			case "23503":
-				c.JSON(http.StatusForbidden, errorResponse(errUserNotFound))
+				errorResponse(c, http.StatusForbidden, errUserNotFound)
 				return
 			}
 		}
-		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		if errors.Is(err, db.ErrUserBlocked) {
+			errorResponse(c, http.StatusForbidden, err)
+			return
+		}
+		errorResponse(c, http.StatusInternalServerError, err)
 		return
 	}
 
-	res := accountResponse{
-		ID:        account.ID,
-		Owner:     account.Owner,
-		Balance:   account.Balance,
-		Currency:  account.Currency,
-		CreatedAt: account.CreatedAt.Time.UTC(),
-	}
+	res := s.newAccountResponse(account)
 
 	c.JSON(http.StatusCreated, res)
 }
@@ -75,67 +85,122 @@ type getAccountParams struct {
 func (s *Server) getAccountByID(c *gin.Context) {
 	var req getAccountParams
 	if err := c.ShouldBindUri(&req); err != nil {
-		c.JSON(http.StatusBadRequest, errorResponse(err))
+		errorResponse(c, http.StatusBadRequest, err)
 		return
 	}
 
-	account, err := s.store.GetAccount(c, req.ID)
-
-	if err != nil {
-		if err == pgx.ErrNoRows {
-			c.JSON(http.StatusNotFound, errorResponse(errAccountNotFound))
-			return
-		}
-		c.JSON(http.StatusInternalServerError, errorResponse(err))
+	account, ok := s.getOwnedAccount(c, req.ID)
+	if !ok {
 		return
 	}
 
-	res := accountResponse{
-		ID:        account.ID,
-		Owner:     account.Owner,
-		Balance:   account.Balance,
-		Currency:  account.Currency,
-		CreatedAt: account.CreatedAt.Time.UTC(),
-	}
+	res := s.newAccountResponse(account)
 
 	c.JSON(http.StatusOK, res)
 }
 
-type listAccountsQuery struct {
-	PageID   int32 `form:"page_id" binding:"required,min=1"`
-	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
-}
-
+// listAccounts lists the accounts of the authenticated user in the order
+// they were opened.
 func (s *Server) listAccounts(c *gin.Context) {
-	var req listAccountsQuery
+	var req pageQuery
 	if err := c.ShouldBindQuery(&req); err != nil {
-		c.JSON(http.StatusBadRequest, errorResponse(err))
+		errorResponse(c, http.StatusBadRequest, err)
 		return
 	}
 
-	arg := db.ListAccountsParams{
-		Limit:  req.PageSize,
-		Offset: (req.PageID - 1) * req.PageSize,
+	owner := authPayload(c).Username
+
+	if req.legacy() {
+		offset, ok := s.legacyPage(c, req)
+		if !ok {
+			return
+		}
+
+		accounts, err := s.store.ListAccounts(c, db.ListAccountsParams{
+			Owner:  owner,
+			Limit:  req.PageSize,
+			Offset: offset,
+		})
+		if err != nil {
+			errorResponse(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		res := make([]accountResponse, 0, len(accounts))
+		for _, account := range accounts {
+			res = append(res, s.newAccountResponse(account))
+		}
+
+		c.JSON(http.StatusOK, res)
+		return
 	}
 
-	accounts, err := s.store.ListAccounts(c, arg)
+	after, pageSize, ok := s.keysetPage(c, req, "accounts", 0)
+	if !ok {
+		return
+	}
 
+	accounts, err := s.store.ListOwnerAccounts(c, db.ListOwnerAccountsParams{
+		Owner:      owner,
+		AfterID:    after.ID,
+		LimitCount: pageSize + 1,
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		errorResponse(c, http.StatusInternalServerError, err)
 		return
 	}
 
-	res := make([]accountResponse, 0, len(accounts))
+	c.JSON(http.StatusOK, newListResponse(s, "accounts", 0, accounts, pageSize, accountKey, s.newAccountResponse))
+}
 
-	for _, account := range accounts {
-		res = append(res, accountResponse{
-			ID:        account.ID,
-			Owner:     account.Owner,
-			Balance:   account.Balance,
-			Currency:  account.Currency,
-			CreatedAt: account.CreatedAt.Time.UTC(),
-		})
+func (s *Server) newAccountResponse(account db.Account) accountResponse {
+	res := accountResponse{
+		ID:           account.ID,
+		Owner:        account.Owner,
+		Balance:      s.moneyJSON(account.Balance, account.Currency),
+		Currency:     account.Currency,
+		Product:      account.Product,
+		MinBalance:   s.moneyJSON(account.MinBalance, account.Currency),
+		Status:       account.Status,
+		StatusReason: account.StatusReason.String,
+		CreatedAt:    account.CreatedAt.Time.UTC(),
 	}
 
-	c.JSON(http.StatusOK, res)
+	if account.StatusChangedAt.Valid {
+		changedAt := account.StatusChangedAt.Time.UTC()
+		res.StatusChangedAt = &changedAt
+	}
+
+	if account.ClosedAt.Valid {
+		closedAt := account.ClosedAt.Time.UTC()
+		res.ClosedAt = &closedAt
+	}
+
+	return res
+}
+
+func accountKey(account db.Account) cursor {
+	return cursor{ID: account.ID}
+}
+
+// getOwnedAccount loads the account and checks that it belongs to the
+// authenticated user, writing the error response when it does not. An
+// account of somebody else is not found, so that its id reveals nothing.
+func (s *Server) getOwnedAccount(c *gin.Context, accountID int64) (db.Account, bool) {
+	account, err := s.store.GetAccount(c, accountID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			errorResponse(c, http.StatusNotFound, errAccountNotFound)
+			return account, false
+		}
+		errorResponse(c, http.StatusInternalServerError, err)
+		return account, false
+	}
+
+	if account.Owner != authPayload(c).Username {
+		errorResponse(c, http.StatusNotFound, errAccountNotFound)
+		return account, false
+	}
+
+	return account, true
 }
@@ -0,0 +1,79 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+	"github.com/vlone310/bss/internal/money"
+)
+
+var errLimitNotNegative = errors.New("min_balance must be zero or negative")
+
+// setAccountLimitRequest carries the approved overdraft or credit limit as a
+// minimum balance in the account's currency, so a limit of 500.00 is sent
+// as {"value":"-500.00","currency":"EUR"}.
+type setAccountLimitRequest struct {
+	MinBalance *money.JSON `json:"min_balance" binding:"required"`
+}
+
+func (s *Server) setAccountLimit(c *gin.Context) {
+	var params getAccountParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var req setAccountLimitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	minBalance, err := s.parseMoney(*req.MinBalance)
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+	if minBalance.IsPositive() {
+		errorResponse(c, http.StatusBadRequest, errLimitNotNegative)
+		return
+	}
+
+	account, err := s.store.GetAccount(c, params.ID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			errorResponse(c, http.StatusNotFound, errAccountNotFound)
+			return
+		}
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	if minBalance.Currency != account.Currency {
+		errorResponse(c, http.StatusBadRequest, fmt.Errorf("account [%d] is in %s, not %s: %w", account.ID, account.Currency, minBalance.Currency, db.ErrCurrencyMismatch))
+		return
+	}
+
+	account, err = s.store.SetAccountMinBalanceTx(c, db.SetAccountMinBalanceTxParams{
+		AccountID:  params.ID,
+		MinBalance: minBalance.Amount,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			errorResponse(c, http.StatusNotFound, errAccountNotFound)
+			return
+		case errors.Is(err, db.ErrNegativeBalanceNotAllowed):
+			errorResponse(c, http.StatusConflict, err)
+			return
+		}
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, s.newAccountResponse(account))
+}
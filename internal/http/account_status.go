@@ -0,0 +1,91 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+)
+
+type freezeAccountRequest struct {
+	Reason string `json:"reason" binding:"required,max=255"`
+}
+
+type changeAccountStatusRequest struct {
+	Reason string `json:"reason" binding:"max=255"`
+}
+
+func (s *Server) freezeAccount(c *gin.Context) {
+	var params getAccountParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var req freezeAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	s.changeAccountStatus(c, params.ID, db.AccountStatusFrozen, req.Reason)
+}
+
+func (s *Server) unfreezeAccount(c *gin.Context) {
+	var params getAccountParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var req changeAccountStatusRequest
+	if err := bindOptionalJSON(c, &req); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	s.changeAccountStatus(c, params.ID, db.AccountStatusActive, req.Reason)
+}
+
+func (s *Server) closeAccount(c *gin.Context) {
+	var params getAccountParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var req changeAccountStatusRequest
+	if err := bindOptionalJSON(c, &req); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if _, ok := s.getOwnedAccount(c, params.ID); !ok {
+		return
+	}
+
+	s.changeAccountStatus(c, params.ID, db.AccountStatusClosed, req.Reason)
+}
+
+func (s *Server) changeAccountStatus(c *gin.Context, accountID int64, status string, reason string) {
+	account, err := s.store.ChangeAccountStatusTx(c, db.ChangeAccountStatusTxParams{
+		AccountID: accountID,
+		Status:    status,
+		Reason:    reason,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			errorResponse(c, http.StatusNotFound, errAccountNotFound)
+		case errors.Is(err, db.ErrInvalidStatusTransition), errors.Is(err, db.ErrNonZeroBalance), errors.Is(err, db.ErrUserBlocked):
+			errorResponse(c, http.StatusConflict, err)
+		default:
+			errorResponse(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, s.newAccountResponse(account))
+}
@@ -0,0 +1,91 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+)
+
+type listAuditEventsQuery struct {
+	Actor        string     `form:"actor"`
+	Action       string     `form:"action"`
+	ResourceType string     `form:"resource_type"`
+	ResourceID   string     `form:"resource_id"`
+	RequestID    string     `form:"request_id"`
+	From         *time.Time `form:"from"`
+	To           *time.Time `form:"to"`
+	pageQuery
+}
+
+type auditEventResponse struct {
+	ID           int64           `json:"id"`
+	Actor        string          `json:"actor,omitempty"`
+	Action       string          `json:"action"`
+	ResourceType string          `json:"resource_type"`
+	ResourceID   string          `json:"resource_id"`
+	Before       json.RawMessage `json:"before,omitempty"`
+	After        json.RawMessage `json:"after,omitempty"`
+	RequestID    string          `json:"request_id"`
+	IP           string          `json:"ip"`
+	UserAgent    string          `json:"user_agent"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+func newAuditEventResponse(event db.AuditEvent) auditEventResponse {
+	return auditEventResponse{
+		ID:           event.ID,
+		Actor:        event.Actor.String,
+		Action:       event.Action,
+		ResourceType: event.ResourceType,
+		ResourceID:   event.ResourceID,
+		Before:       event.Before,
+		After:        event.After,
+		RequestID:    event.RequestID,
+		IP:           event.Ip,
+		UserAgent:    event.UserAgent,
+		CreatedAt:    event.CreatedAt.Time,
+	}
+}
+
+// listAuditEvents lists the audit log newest first. Every filter is optional,
+// from is inclusive and to exclusive.
+func (s *Server) listAuditEvents(c *gin.Context) {
+	var req listAuditEventsQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	after, pageSize, ok := s.keysetPage(c, req.pageQuery, "audit_events", 0)
+	if !ok {
+		return
+	}
+
+	events, err := s.store.ListAuditEvents(c, db.ListAuditEventsParams{
+		Actor:        optionalText(req.Actor),
+		Action:       optionalText(req.Action),
+		ResourceType: optionalText(req.ResourceType),
+		ResourceID:   optionalText(req.ResourceID),
+		RequestID:    optionalText(req.RequestID),
+		FromTime:     optionalTimestamptz(req.From),
+		ToTime:       optionalTimestamptz(req.To),
+		BeforeID:     pgtype.Int8{Int64: after.ID, Valid: after.ID != 0},
+		LimitCount:   pageSize + 1,
+	})
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newListResponse(s, "audit_events", 0, events, pageSize, auditEventKey, newAuditEventResponse))
+}
+
+// auditEventKey keys the events by id alone, the events of a transaction
+// share its created_at.
+func auditEventKey(event db.AuditEvent) cursor {
+	return cursor{ID: event.ID}
+}
@@ -0,0 +1,211 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/vlone310/bss/internal/db/mock"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+)
+
+func TestRequestMiddleware(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name      string
+		requestID string
+		username  string
+		checkMeta func(t *testing.T, meta db.AuditMeta, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "NewRequestID",
+			username: user.Username,
+			checkMeta: func(t *testing.T, meta db.AuditMeta, recorder *httptest.ResponseRecorder) {
+				require.Len(t, meta.RequestID, 32)
+				require.Equal(t, meta.RequestID, recorder.Header().Get(requestIDHeaderKey))
+				require.Equal(t, user.Username, meta.Actor)
+				require.Equal(t, "bss-test/1.0", meta.UserAgent)
+				require.Equal(t, "192.0.2.1", meta.IP)
+			},
+		},
+		{
+			name:      "CallerRequestID",
+			requestID: "req-42",
+			username:  user.Username,
+			checkMeta: func(t *testing.T, meta db.AuditMeta, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, "req-42", meta.RequestID)
+				require.Equal(t, "req-42", recorder.Header().Get(requestIDHeaderKey))
+			},
+		},
+		{
+			name:      "InvalidRequestID",
+			requestID: strings.Repeat("x", maxRequestIDSize+1),
+			username:  user.Username,
+			checkMeta: func(t *testing.T, meta db.AuditMeta, recorder *httptest.ResponseRecorder) {
+				require.Len(t, meta.RequestID, 32)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			var meta db.AuditMeta
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetCustomerProfile(gomock.Any(), gomock.Eq(user.Username)).Times(1).
+				DoAndReturn(func(ctx context.Context, _ string) (db.CustomerProfile, error) {
+					var ok bool
+					meta, ok = db.AuditMetaFrom(ctx)
+					require.True(t, ok)
+					return db.CustomerProfile{}, pgx.ErrNoRows
+				})
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/users/me/profile", nil)
+			require.NoError(t, err)
+			request.Header.Set("User-Agent", "bss-test/1.0")
+			request.RemoteAddr = "192.0.2.1:50000"
+			if tc.requestID != "" {
+				request.Header.Set(requestIDHeaderKey, tc.requestID)
+			}
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+			serve(t, server, recorder, request)
+			tc.checkMeta(t, meta, recorder)
+		})
+	}
+}
+
+func TestListAuditEventsAPI(t *testing.T) {
+	admin, _ := randomUser(t)
+	admin.Role = db.UserRoleAdmin
+	customer, _ := randomUser(t)
+
+	event := db.AuditEvent{
+		ID:           7,
+		Actor:        pgtype.Text{String: admin.Username, Valid: true},
+		Action:       db.AuditActionAccountFreeze,
+		ResourceType: "account",
+		ResourceID:   "12",
+		Before:       []byte(`{"id":12,"status":"active"}`),
+		After:        []byte(`{"id":12,"status":"frozen"}`),
+		RequestID:    "req-42",
+		Ip:           "192.0.2.1",
+		UserAgent:    "curl/8.0",
+		CreatedAt:    pgtype.Timestamptz{Time: time.Now(), Valid: true},
+	}
+
+	testCases := []struct {
+		name          string
+		user          db.User
+		query         func(server *Server) string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			user: admin,
+			query: func(server *Server) string {
+				return "?actor=" + admin.Username + "&resource_type=account&resource_id=12&from=2026-10-01T00:00:00Z&page_size=5"
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListAuditEventsParams{
+					Actor:        pgtype.Text{String: admin.Username, Valid: true},
+					ResourceType: pgtype.Text{String: "account", Valid: true},
+					ResourceID:   pgtype.Text{String: "12", Valid: true},
+					FromTime:     pgtype.Timestamptz{Time: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+					LimitCount:   6,
+				}
+				store.EXPECT().ListAuditEvents(gomock.Any(), gomock.Eq(arg)).Times(1).Return([]db.AuditEvent{event}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				res := requireBodyListResponse[auditEventResponse](t, recorder.Body)
+				require.Len(t, res.Items, 1)
+				require.False(t, res.HasMore)
+				require.Equal(t, db.AuditActionAccountFreeze, res.Items[0].Action)
+				require.JSONEq(t, `{"id":12,"status":"frozen"}`, string(res.Items[0].After))
+			},
+		},
+		{
+			name: "NextPage",
+			user: admin,
+			query: func(server *Server) string {
+				return "?page_size=1&cursor=" + server.encodeCursor(cursor{Kind: "audit_events", ID: 9})
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListAuditEventsParams{
+					BeforeID:   pgtype.Int8{Int64: 9, Valid: true},
+					LimitCount: 2,
+				}
+				older := event
+				older.ID = 6
+				store.EXPECT().ListAuditEvents(gomock.Any(), gomock.Eq(arg)).Times(1).Return([]db.AuditEvent{event, older}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				res := requireBodyListResponse[auditEventResponse](t, recorder.Body)
+				require.Len(t, res.Items, 1)
+				require.Equal(t, event.ID, res.Items[0].ID)
+				require.True(t, res.HasMore)
+			},
+		},
+		{
+			name:  "InvalidFrom",
+			user:  admin,
+			query: func(server *Server) string { return "?from=yesterday" },
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAuditEvents(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "NotAdmin",
+			user:  customer,
+			query: func(server *Server) string { return "" },
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAuditEvents(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetUser(gomock.Any(), gomock.Eq(tc.user.Username)).Times(1).Return(tc.user, nil)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/admin/audit-events"+tc.query(server), nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.user.Username, time.Minute)
+			serve(t, server, recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
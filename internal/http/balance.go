@@ -0,0 +1,70 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+	"github.com/vlone310/bss/internal/money"
+)
+
+type balanceQuery struct {
+	AsOf *time.Time `form:"as_of"`
+}
+
+type balanceResponse struct {
+	AccountID int64      `json:"account_id"`
+	Currency  string     `json:"currency"`
+	AsOf      time.Time  `json:"as_of"`
+	Balance   money.JSON `json:"balance"`
+	// SnapshotDate is the day whose closing balance the figure started from.
+	SnapshotDate *string `json:"snapshot_date,omitempty"`
+}
+
+func (s *Server) getAccountBalance(c *gin.Context) {
+	var params getAccountParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var req balanceQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	account, ok := s.getOwnedAccount(c, params.ID)
+	if !ok {
+		return
+	}
+
+	asOf := time.Now()
+	if req.AsOf != nil {
+		asOf = *req.AsOf
+	}
+
+	balance, err := s.store.GetAccountBalanceAsOf(c, db.GetAccountBalanceAsOfParams{
+		AccountID: account.ID,
+		AsOf:      pgtype.Timestamptz{Time: asOf, Valid: true},
+	})
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	res := balanceResponse{
+		AccountID: account.ID,
+		Currency:  account.Currency,
+		AsOf:      asOf.UTC(),
+		Balance:   s.moneyJSON(balance.Balance, account.Currency),
+	}
+	if balance.SnapshotDate.Valid {
+		date := balance.SnapshotDate.Time.Format(time.DateOnly)
+		res.SnapshotDate = &date
+	}
+
+	c.JSON(http.StatusOK, res)
+}
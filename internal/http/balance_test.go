@@ -0,0 +1,160 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"github.com/vlone310/bss/internal/adapter/token/maker"
+	mockdb "github.com/vlone310/bss/internal/db/mock"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+)
+
+func TestGetAccountBalanceAPI(t *testing.T) {
+	account := randomAccount()
+	other := randomAccount()
+
+	asOf := time.Date(2025, 3, 15, 12, 0, 0, 0, time.UTC)
+	snapshotDate := time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name          string
+		query         string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker maker.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			query: "as_of=" + asOf.Format(time.RFC3339),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker maker.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Owner, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.GetAccountBalanceAsOfParams{
+					AccountID: account.ID,
+					AsOf:      pgtype.Timestamptz{Time: asOf, Valid: true},
+				}
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountBalanceAsOf(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.GetAccountBalanceAsOfRow{
+					SnapshotDate: pgtype.Date{Time: snapshotDate, Valid: true},
+					Balance:      1234,
+				}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got balanceResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, account.ID, got.AccountID)
+				require.Equal(t, account.Currency, got.Currency)
+				require.Equal(t, formatter.moneyJSON(1234, account.Currency), got.Balance)
+				require.True(t, asOf.Equal(got.AsOf))
+				require.NotNil(t, got.SnapshotDate)
+				require.Equal(t, "2025-03-14", *got.SnapshotDate)
+			},
+		},
+		{
+			name:  "DefaultsToNow",
+			query: "",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker maker.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Owner, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountBalanceAsOf(gomock.Any(), gomock.Any()).Times(1).Return(db.GetAccountBalanceAsOfRow{
+					Balance: account.Balance,
+				}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got balanceResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, formatter.moneyJSON(account.Balance, account.Currency), got.Balance)
+				require.Nil(t, got.SnapshotDate)
+				require.WithinDuration(t, time.Now(), got.AsOf, time.Minute)
+			},
+		},
+		{
+			name:  "InvalidAsOf",
+			query: "as_of=yesterday",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker maker.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Owner, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "NotOwner",
+			query: "as_of=" + asOf.Format(time.RFC3339),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker maker.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, other.Owner, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountBalanceAsOf(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:      "NoAuthorization",
+			query:     "as_of=" + asOf.Format(time.RFC3339),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker maker.Maker) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:  "InternalError",
+			query: "as_of=" + asOf.Format(time.RFC3339),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker maker.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Owner, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountBalanceAsOf(gomock.Any(), gomock.Any()).Times(1).Return(db.GetAccountBalanceAsOfRow{}, pgx.ErrTxClosed)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/%d/balance?%s", account.ID, tc.query)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			serve(t, server, recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
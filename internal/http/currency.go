@@ -0,0 +1,151 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/vlone310/bss/internal/currency"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+	"github.com/vlone310/bss/internal/money"
+)
+
+var errCurrencyNotFound = errors.New("currency not found")
+var errCurrencyNotEnabled = errors.New("currency is not enabled")
+
+type currencyParams struct {
+	Code string `uri:"code" binding:"required,len=3,uppercase"`
+}
+
+type setCurrencyResponse struct {
+	currency.Currency
+	HouseAccounts []db.HouseAccount `json:"house_accounts"`
+}
+
+// defaultMinorUnits is assumed for a currency missing from the registry.
+// Accounts reference the currencies table, so it is a fallback only.
+const defaultMinorUnits = 2
+
+func (s *Server) minorUnits(code string) int32 {
+	if c, ok := s.currencies.Lookup(code); ok {
+		return c.MinorUnits
+	}
+	return defaultMinorUnits
+}
+
+// moneyJSON returns the wire format of an amount in minor units of a
+// currency.
+func (s *Server) moneyJSON(amount int64, currency string) money.JSON {
+	return money.New(amount, currency).JSON(s.minorUnits(currency))
+}
+
+// parseMoney reads an amount of a request with the minor units of its
+// currency, which must be in the registry.
+func (s *Server) parseMoney(j money.JSON) (money.Money, error) {
+	if j.Currency == "" {
+		return money.Money{}, money.ErrMissingCurrency
+	}
+
+	c, ok := s.currencies.Lookup(j.Currency)
+	if !ok {
+		return money.Money{}, fmt.Errorf("%w: %q", errCurrencyNotEnabled, j.Currency)
+	}
+
+	return j.Money(c.MinorUnits)
+}
+
+// checkAmount accepts a positive amount in an enabled currency.
+func (s *Server) checkAmount(amount money.Money) error {
+	if !s.currencies.Enabled(amount.Currency) {
+		return fmt.Errorf("%w: %q", errCurrencyNotEnabled, amount.Currency)
+	}
+	if !amount.IsPositive() {
+		return db.ErrAmountNotPositive
+	}
+	return nil
+}
+
+// parseOptionalAmount reads a positive decimal amount given as a query
+// parameter.
+func (s *Server) parseOptionalAmount(value *string, currency string) (pgtype.Int8, error) {
+	if value == nil {
+		return pgtype.Int8{}, nil
+	}
+
+	amount, err := money.Parse(*value, currency, s.minorUnits(currency))
+	if err != nil {
+		return pgtype.Int8{}, err
+	}
+	if !amount.IsPositive() {
+		return pgtype.Int8{}, db.ErrAmountNotPositive
+	}
+
+	return pgtype.Int8{Int64: amount.Amount, Valid: true}, nil
+}
+
+// listCurrencies returns the currencies accounts can be opened in.
+func (s *Server) listCurrencies(c *gin.Context) {
+	res := []currency.Currency{}
+	for _, cur := range s.currencies.List() {
+		if cur.Enabled {
+			res = append(res, cur)
+		}
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// listAllCurrencies returns every known currency, enabled or not, from the
+// database.
+func (s *Server) listAllCurrencies(c *gin.Context) {
+	currencies, err := s.store.ListCurrencies(c)
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	res := make([]currency.Currency, 0, len(currencies))
+	for _, cur := range currencies {
+		res = append(res, currency.FromDB(cur))
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+func (s *Server) enableCurrency(c *gin.Context) {
+	s.setCurrencyEnabled(c, true)
+}
+
+func (s *Server) disableCurrency(c *gin.Context) {
+	s.setCurrencyEnabled(c, false)
+}
+
+func (s *Server) setCurrencyEnabled(c *gin.Context, enabled bool) {
+	var params currencyParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	result, err := s.store.SetCurrencyEnabledTx(c, db.SetCurrencyEnabledTxParams{
+		Code:    params.Code,
+		Enabled: enabled,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			errorResponse(c, http.StatusNotFound, errCurrencyNotFound)
+			return
+		}
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	// other instances pick the change up on their next registry refresh
+	cur := currency.FromDB(result.Currency)
+	s.currencies.Set(cur)
+
+	c.JSON(http.StatusOK, setCurrencyResponse{Currency: cur, HouseAccounts: result.HouseAccounts})
+}
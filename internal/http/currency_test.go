@@ -0,0 +1,215 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+	"github.com/vlone310/bss/internal/currency"
+	mockdb "github.com/vlone310/bss/internal/db/mock"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+	"github.com/vlone310/bss/internal/money"
+)
+
+func TestListCurrenciesAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := newTestServer(t, mockdb.NewMockStore(ctrl))
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/currencies", nil)
+	require.NoError(t, err)
+
+	serve(t, server, recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var res []currency.Currency
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+	for _, c := range res {
+		require.True(t, c.Enabled)
+		require.NotEqual(t, "GBP", c.Code)
+	}
+}
+
+func TestSetCurrencyEnabledAPI(t *testing.T) {
+	admin, _ := randomUser(t)
+	admin.Role = db.UserRoleAdmin
+	customer, _ := randomUser(t)
+
+	gbp := db.Currency{Code: "GBP", NumericCode: 826, MinorUnits: 2, Name: "Pound Sterling", Enabled: true}
+
+	testCases := []struct {
+		name          string
+		url           string
+		user          db.User
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Enable",
+			url:  "/admin/currencies/GBP/enable",
+			user: admin,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.SetCurrencyEnabledTxParams{Code: "GBP", Enabled: true}
+				store.EXPECT().SetCurrencyEnabledTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.SetCurrencyEnabledTxResult{
+					Currency: gbp,
+					HouseAccounts: []db.HouseAccount{
+						{Purpose: db.HousePurposeSuspense, Currency: "GBP", AccountID: 1},
+					},
+				}, nil)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.True(t, server.currencies.Enabled("GBP"))
+
+				var res setCurrencyResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, "GBP", res.Code)
+				require.True(t, res.Enabled)
+				require.Len(t, res.HouseAccounts, 1)
+			},
+		},
+		{
+			name: "Disable",
+			url:  "/admin/currencies/JPY/disable",
+			user: admin,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.SetCurrencyEnabledTxParams{Code: "JPY", Enabled: false}
+				store.EXPECT().SetCurrencyEnabledTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.SetCurrencyEnabledTxResult{
+					Currency:      db.Currency{Code: "JPY", NumericCode: 392},
+					HouseAccounts: []db.HouseAccount{},
+				}, nil)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.False(t, server.currencies.Enabled("JPY"))
+			},
+		},
+		{
+			name: "NotFound",
+			url:  "/admin/currencies/XXX/enable",
+			user: admin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SetCurrencyEnabledTx(gomock.Any(), gomock.Any()).Times(1).Return(db.SetCurrencyEnabledTxResult{}, pgx.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidCode",
+			url:  "/admin/currencies/gbp/enable",
+			user: admin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SetCurrencyEnabledTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NotAdmin",
+			url:  "/admin/currencies/GBP/enable",
+			user: customer,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SetCurrencyEnabledTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetUser(gomock.Any(), gomock.Eq(tc.user.Username)).Times(1).Return(tc.user, nil)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodPost, tc.url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.user.Username, time.Minute)
+			serve(t, server, recorder, request)
+			tc.checkResponse(t, server, recorder)
+		})
+	}
+}
+
+func TestCreateTransferAmountAPI(t *testing.T) {
+	testCases := []struct {
+		name       string
+		currency   string
+		body       string
+		wantAmount int64
+		wantCode   int
+	}{
+		{name: "Minor", currency: "EUR", body: `{"minor": 1234, "currency": "EUR"}`, wantAmount: 1234, wantCode: http.StatusCreated},
+		{name: "DecimalEUR", currency: "EUR", body: `{"value": "12.34", "currency": "EUR"}`, wantAmount: 1234, wantCode: http.StatusCreated},
+		{name: "DecimalJPY", currency: "JPY", body: `{"value": "1234", "currency": "JPY"}`, wantAmount: 1234, wantCode: http.StatusCreated},
+		{name: "DecimalKWD", currency: "KWD", body: `{"value": "1.234", "currency": "KWD"}`, wantAmount: 1234, wantCode: http.StatusCreated},
+		{name: "BothAgree", currency: "EUR", body: `{"value": "12.34", "minor": 1234, "currency": "EUR"}`, wantAmount: 1234, wantCode: http.StatusCreated},
+		{name: "TooManyDecimals", currency: "JPY", body: `{"value": "12.5", "currency": "JPY"}`, wantCode: http.StatusBadRequest},
+		{name: "NotPositive", currency: "EUR", body: `{"value": "-1.00", "currency": "EUR"}`, wantCode: http.StatusBadRequest},
+		{name: "BothDisagree", currency: "EUR", body: `{"value": "12.34", "minor": 1235, "currency": "EUR"}`, wantCode: http.StatusBadRequest},
+		{name: "NoAmount", currency: "EUR", body: `{"value": "", "currency": "EUR"}`, wantCode: http.StatusBadRequest},
+		{name: "NoCurrency", currency: "EUR", body: `{"value": "12.34"}`, wantCode: http.StatusBadRequest},
+		{name: "Missing", currency: "EUR", body: `null`, wantCode: http.StatusBadRequest},
+		{name: "DisabledCurrency", currency: "GBP", body: `{"value": "12.34", "currency": "GBP"}`, wantCode: http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			from := randomAccount()
+			from.Currency = tc.currency
+			from.Balance = 1_000_000
+			to := randomAccount()
+			to.ID = from.ID + 1
+			to.Currency = tc.currency
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			if tc.wantCode == http.StatusCreated {
+				arg := db.TransferTxParams{FromAccountID: from.ID, ToAccountID: to.ID, Amount: money.New(tc.wantAmount, tc.currency)}
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(from.ID)).Times(1).Return(from, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(to.ID)).Times(1).Return(to, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(bookedTransfer(arg), nil)
+			} else {
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			}
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			body := fmt.Sprintf(`{"from_account_id": %d, "to_account_id": %d, "amount": %s}`, from.ID, to.ID, tc.body)
+			request, err := http.NewRequest(http.MethodPost, "/transfers", strings.NewReader(body))
+			require.NoError(t, err)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, from.Owner, time.Minute)
+
+			serve(t, server, recorder, request)
+			require.Equal(t, tc.wantCode, recorder.Code, recorder.Body.String())
+
+			if tc.wantCode == http.StatusCreated {
+				var res transferResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, formatter.moneyJSON(tc.wantAmount, tc.currency), res.Amount)
+			}
+		})
+	}
+}
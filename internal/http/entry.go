@@ -0,0 +1,105 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+	"github.com/vlone310/bss/internal/money"
+)
+
+type entryResponse struct {
+	ID         int64      `json:"id"`
+	AccountID  int64      `json:"account_id"`
+	JournalID  int64      `json:"journal_id"`
+	Amount     money.JSON `json:"amount"`
+	TransferID *int64     `json:"transfer_id,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func (s *Server) newEntryResponse(entry db.Entry, currency string) entryResponse {
+	res := entryResponse{
+		ID:        entry.ID,
+		AccountID: entry.AccountID,
+		JournalID: entry.JournalID,
+		Amount:    s.moneyJSON(entry.AmountCents, currency),
+		CreatedAt: entry.CreatedAt.Time.UTC(),
+	}
+
+	if entry.TransferID.Valid {
+		res.TransferID = &entry.TransferID.Int64
+	}
+
+	return res
+}
+
+func entryKey(entry db.Entry) cursor {
+	return cursor{CreatedAt: entry.CreatedAt.Time, ID: entry.ID}
+}
+
+func (s *Server) listAccountEntries(c *gin.Context) {
+	var params getAccountParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var req pageQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	account, ok := s.getOwnedAccount(c, params.ID)
+	if !ok {
+		return
+	}
+
+	newResponse := func(entry db.Entry) entryResponse {
+		return s.newEntryResponse(entry, account.Currency)
+	}
+
+	if req.legacy() {
+		offset, ok := s.legacyPage(c, req)
+		if !ok {
+			return
+		}
+
+		entries, err := s.store.ListEntries(c, db.ListEntriesParams{
+			AccountID: params.ID,
+			Limit:     req.PageSize,
+			Offset:    offset,
+		})
+		if err != nil {
+			errorResponse(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		res := make([]entryResponse, 0, len(entries))
+		for _, entry := range entries {
+			res = append(res, newResponse(entry))
+		}
+
+		c.JSON(http.StatusOK, res)
+		return
+	}
+
+	after, pageSize, ok := s.keysetPage(c, req, "entries", params.ID)
+	if !ok {
+		return
+	}
+
+	entries, err := s.store.ListEntriesAfter(c, db.ListEntriesAfterParams{
+		AccountID:      params.ID,
+		AfterCreatedAt: after.afterCreatedAt(),
+		AfterID:        after.ID,
+		LimitCount:     pageSize + 1,
+	})
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newListResponse(s, "entries", params.ID, entries, pageSize, entryKey, newResponse))
+}
@@ -0,0 +1,109 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+	"github.com/vlone310/bss/internal/export"
+)
+
+type exportQuery struct {
+	Format string    `form:"format" binding:"required,oneof=csv ofx camt053"`
+	From   time.Time `form:"from" binding:"required"`
+	To     time.Time `form:"to" binding:"required"`
+}
+
+func (s *Server) exportAccountStatement(c *gin.Context) {
+	var params getAccountParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var req exportQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if !req.To.After(req.From) {
+		errorResponse(c, http.StatusBadRequest, errInvalidTimeRange)
+		return
+	}
+
+	account, ok := s.getOwnedAccount(c, params.ID)
+	if !ok {
+		return
+	}
+
+	// a long statement takes longer than the write timeout of the server,
+	// every chunk the encoder flushes gets a deadline of its own instead
+	w := streamWriter{ResponseWriter: c.Writer, rc: http.NewResponseController(c.Writer)}
+
+	format := export.Format(req.Format)
+	enc, err := export.NewEncoder(format, w)
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	arg := db.AccountStatementParams{
+		AccountID: account.ID,
+		From:      req.From,
+		To:        req.To,
+	}
+
+	err = s.store.StreamAccountStatement(c, arg,
+		func(summary db.GetStatementSummaryRow) error {
+			filename := fmt.Sprintf("statement-%d-%s-%s.%s",
+				account.ID, req.From.UTC().Format("20060102"), req.To.UTC().Format("20060102"), format.Extension())
+			c.Header("Content-Type", format.ContentType())
+			c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+			c.Status(http.StatusOK)
+
+			return enc.Begin(export.Statement{
+				AccountID:      account.ID,
+				Owner:          account.Owner,
+				Currency:       account.Currency,
+				MinorUnits:     s.minorUnits(account.Currency),
+				From:           req.From,
+				To:             req.To,
+				OpeningBalance: summary.OpeningBalance,
+				ClosingBalance: summary.ClosingBalance,
+				TotalDebits:    summary.TotalDebits,
+				TotalCredits:   summary.TotalCredits,
+				DebitCount:     summary.DebitCount,
+				CreditCount:    summary.CreditCount,
+				GeneratedAt:    time.Now(),
+			})
+		},
+		func(line db.StatementLine) error {
+			return enc.Entry(export.Entry{
+				ID:                    line.ID,
+				TransferID:            line.TransferID.Int64,
+				AmountCents:           line.AmountCents,
+				RunningBalance:        line.RunningBalance,
+				BookedAt:              line.CreatedAt.Time,
+				CounterpartyAccountID: line.CounterpartyAccountID.Int64,
+				CounterpartyOwner:     line.CounterpartyOwner.String,
+			})
+		},
+	)
+	if err == nil {
+		err = enc.End()
+	}
+
+	if err != nil {
+		if !c.Writer.Written() {
+			errorResponse(c, http.StatusInternalServerError, err)
+			return
+		}
+		// the response is already on its way, the client is left with a
+		// truncated file that fails to parse
+		c.Error(err)
+		c.Abort()
+	}
+}
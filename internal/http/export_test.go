@@ -0,0 +1,236 @@
+package http
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"github.com/vlone310/bss/config"
+	"github.com/vlone310/bss/internal/adapter/token/maker"
+	mockdb "github.com/vlone310/bss/internal/db/mock"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+	"github.com/vlone310/bss/internal/fraud"
+	"github.com/vlone310/bss/internal/screening"
+	"github.com/vlone310/bss/internal/stream"
+	"github.com/vlone310/bss/testutil"
+)
+
+func TestExportAccountStatementAPI(t *testing.T) {
+	account := randomAccount()
+
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	period := fmt.Sprintf("from=%s&to=%s", from.Format(time.RFC3339), to.Format(time.RFC3339))
+
+	summary := db.GetStatementSummaryRow{
+		OpeningBalance: 1000,
+		ClosingBalance: 1500,
+		TotalCredits:   500,
+		CreditCount:    1,
+	}
+	line := db.StatementLine{
+		ListStatementEntriesRow: db.ListStatementEntriesRow{
+			ID:          1,
+			AmountCents: 500,
+			CreatedAt:   pgtype.Timestamptz{Time: from.Add(time.Hour), Valid: true},
+		},
+		RunningBalance: 1500,
+	}
+
+	stream := func(_ context.Context, _ db.AccountStatementParams, onSummary func(db.GetStatementSummaryRow) error, onLine func(db.StatementLine) error) error {
+		if err := onSummary(summary); err != nil {
+			return err
+		}
+		return onLine(line)
+	}
+
+	testCases := []struct {
+		name          string
+		query         string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker maker.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OKCSV",
+			query: "format=csv&" + period,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker maker.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Owner, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.AccountStatementParams{AccountID: account.ID, From: from, To: to}
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().StreamAccountStatement(gomock.Any(), gomock.Eq(arg), gomock.Any(), gomock.Any()).Times(1).DoAndReturn(stream)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "text/csv; charset=utf-8", recorder.Header().Get("Content-Type"))
+				require.Contains(t, recorder.Header().Get("Content-Disposition"), "statement-")
+
+				records, err := csv.NewReader(recorder.Body).ReadAll()
+				require.NoError(t, err)
+				require.Len(t, records, 2)
+				require.Equal(t, "5.00", records[1][5])
+				require.Equal(t, "15.00", records[1][6])
+			},
+		},
+		{
+			name:  "OKCAMT053",
+			query: "format=camt053&" + period,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker maker.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Owner, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().StreamAccountStatement(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).DoAndReturn(stream)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "application/xml", recorder.Header().Get("Content-Type"))
+				require.Contains(t, recorder.Body.String(), "camt.053.001.02")
+			},
+		},
+		{
+			name:  "UnknownFormat",
+			query: "format=pdf&" + period,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker maker.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Owner, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "NotOwner",
+			query: "format=ofx&" + period,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker maker.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "unauthorized", time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().StreamAccountStatement(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:  "InternalErrorBeforeStreaming",
+			query: "format=ofx&" + period,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker maker.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Owner, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().StreamAccountStatement(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(pgx.ErrTxClosed)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				require.Contains(t, recorder.Header().Get("Content-Type"), problemContentType)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/%d/export?%s", account.ID, tc.query)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			serve(t, server, recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestExportAccountStatementWriteTimeout(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount()
+	account.Owner = user.Username
+
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	config := config.Config{
+		TokenSymmetricKey:   testutil.RandomString(32),
+		CursorSecretKey:     testutil.RandomString(32),
+		AccessTokenDuration: time.Minute,
+		ServerWriteTimeout:  100 * time.Millisecond,
+	}
+
+	const lines = 1000
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+	store.EXPECT().StreamAccountStatement(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, _ db.AccountStatementParams, onSummary func(db.GetStatementSummaryRow) error, onLine func(db.StatementLine) error) error {
+			if err := onSummary(db.GetStatementSummaryRow{}); err != nil {
+				return err
+			}
+			for i := range lines {
+				// the statement takes several write timeouts to read
+				if i%100 == 0 {
+					time.Sleep(config.ServerWriteTimeout / 2)
+				}
+				err := onLine(db.StatementLine{
+					ListStatementEntriesRow: db.ListStatementEntriesRow{
+						ID:          int64(i + 1),
+						AmountCents: 100,
+						CreatedAt:   pgtype.Timestamptz{Time: from.Add(time.Minute), Valid: true},
+					},
+					RunningBalance: int64(i+1) * 100,
+				})
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+
+	server, err := NewServer(config, store, testCurrencies(), screening.NewScreener(0, 0), fraud.NewEngine(fraud.Thresholds{}), stream.NewHub(0))
+	require.NoError(t, err)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go server.Serve(lis)
+	defer server.Shutdown(context.Background())
+
+	url := fmt.Sprintf("http://%s/accounts/%d/export?format=csv&from=%s&to=%s",
+		lis.Addr(), account.ID, from.Format(time.RFC3339), to.Format(time.RFC3339))
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+
+	res, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	records, err := csv.NewReader(res.Body).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, lines+1)
+}
@@ -0,0 +1,434 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+	"github.com/vlone310/bss/internal/fraud"
+	"github.com/vlone310/bss/internal/money"
+	"github.com/vlone310/bss/util"
+)
+
+var errFraudDecisionNotFound = errors.New("fraud decision not found")
+var errTransferNotChallenged = errors.New("transfer is not waiting for confirmation")
+
+// maxConfirmAttempts is how many wrong codes fail a challenged transfer,
+// so that the codes cannot be guessed.
+const maxConfirmAttempts = 5
+
+// confirmTransfer lets the sender of a challenged transfer confirm it with a
+// one-time code of their authenticator app, after which it is booked. A
+// stolen session cannot confirm a transfer without the device.
+func (s *Server) confirmTransfer(c *gin.Context) {
+	var params getTransferParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var req totpCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	transfer, err := s.store.GetTransfer(c, params.ID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			errorResponse(c, http.StatusNotFound, errTransferNotFound)
+			return
+		}
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	from, err := s.store.GetAccount(c, transfer.FromAccountID)
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	// only the sender confirms, do not reveal the transfer to anybody else
+	payload := authPayload(c)
+	if from.Owner != payload.Username {
+		errorResponse(c, http.StatusNotFound, errTransferNotFound)
+		return
+	}
+
+	decision, err := s.store.GetFraudDecisionByTransfer(c, pgtype.Int8{Int64: transfer.ID, Valid: true})
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+	if err != nil || decision.Action != db.FraudActionChallenge || decision.ReviewedAt.Valid {
+		errorResponse(c, http.StatusConflict, errTransferNotChallenged)
+		return
+	}
+
+	user, err := s.store.GetUser(c, payload.Username)
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	err = s.checkTOTP(c, user, req.Code)
+	switch {
+	case errors.Is(err, errTOTPRequired):
+		errorResponse(c, http.StatusForbidden, err)
+		return
+	case errors.Is(err, util.ErrInvalidTOTP):
+		if err := s.failConfirmAttempt(c, decision); err != nil {
+			errorResponse(c, http.StatusInternalServerError, err)
+			return
+		}
+		errorResponse(c, http.StatusUnauthorized, err)
+		return
+	case err != nil:
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	result, err := s.store.ReviewFraudDecisionTx(c, db.ReviewFraudDecisionTxParams{
+		DecisionID: decision.ID,
+		Outcome:    db.FraudReviewApproved,
+		ReviewedBy: user.Username,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrFraudDecisionNotReviewable) {
+			errorResponse(c, http.StatusConflict, errTransferNotChallenged)
+			return
+		}
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	// the transfer fails when it no longer passes the transfer rules
+	c.JSON(http.StatusOK, s.newTransferResponse(result.Transfer, from.Currency))
+}
+
+// failConfirmAttempt counts a wrong code for a challenge and rejects the
+// transfer once there were maxConfirmAttempts of them.
+func (s *Server) failConfirmAttempt(c *gin.Context, decision db.FraudDecision) error {
+	decision, err := s.store.AddFraudConfirmAttempt(c, decision.ID)
+	if err != nil || decision.ConfirmAttempts < maxConfirmAttempts {
+		return err
+	}
+
+	_, err = s.store.ReviewFraudDecisionTx(c, db.ReviewFraudDecisionTxParams{
+		DecisionID: decision.ID,
+		Outcome:    db.FraudReviewRejected,
+	})
+	if errors.Is(err, db.ErrFraudDecisionNotReviewable) {
+		return nil
+	}
+	return err
+}
+
+type fraudRuleResponse struct {
+	Name      string          `json:"name"`
+	Kind      string          `json:"kind"`
+	Params    json.RawMessage `json:"params"`
+	Score     int32           `json:"score"`
+	Enabled   bool            `json:"enabled"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+func newFraudRuleResponse(rule db.FraudRule) fraudRuleResponse {
+	return fraudRuleResponse{
+		Name:      rule.Name,
+		Kind:      rule.Kind,
+		Params:    rule.Params,
+		Score:     rule.Score,
+		Enabled:   rule.Enabled,
+		UpdatedAt: rule.UpdatedAt.Time.UTC(),
+	}
+}
+
+// listFraudRules returns every rule, the disabled ones included.
+func (s *Server) listFraudRules(c *gin.Context) {
+	rules, err := s.store.ListFraudRules(c)
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	res := make([]fraudRuleResponse, 0, len(rules))
+	for _, rule := range rules {
+		res = append(res, newFraudRuleResponse(rule))
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+type fraudRuleParams struct {
+	Name string `uri:"name" binding:"required,max=64"`
+}
+
+type putFraudRuleRequest struct {
+	Kind    string          `json:"kind" binding:"required,oneof=velocity new_payee unusual_amount rapid_in_out"`
+	Params  json.RawMessage `json:"params"`
+	Score   int32           `json:"score" binding:"min=0"`
+	Enabled *bool           `json:"enabled" binding:"required"`
+}
+
+// putFraudRule creates or replaces a rule. The params are checked by
+// building the rule before it is stored.
+func (s *Server) putFraudRule(c *gin.Context) {
+	var params fraudRuleParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var req putFraudRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if len(req.Params) == 0 {
+		req.Params = json.RawMessage("{}")
+	}
+
+	if _, err := fraud.NewRule(params.Name, req.Kind, req.Params, req.Score); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	rule, err := s.store.UpsertFraudRule(c, db.UpsertFraudRuleParams{
+		Name:    params.Name,
+		Kind:    req.Kind,
+		Params:  req.Params,
+		Score:   req.Score,
+		Enabled: *req.Enabled,
+	})
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	// other instances pick the change up on their next rule refresh
+	if err := s.fraud.Set(rule); err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newFraudRuleResponse(rule))
+}
+
+type fraudRuleHitResponse struct {
+	Rule   string `json:"rule"`
+	Score  int32  `json:"score"`
+	Detail string `json:"detail"`
+}
+
+type fraudDecisionResponse struct {
+	ID            int64                  `json:"id"`
+	FromAccountID int64                  `json:"from_account_id"`
+	ToAccountID   int64                  `json:"to_account_id"`
+	Amount        money.JSON             `json:"amount"`
+	TransferID    *int64                 `json:"transfer_id,omitempty"`
+	Score         int32                  `json:"score"`
+	Action        string                 `json:"action"`
+	ReviewOutcome string                 `json:"review_outcome,omitempty"`
+	ReviewedBy    string                 `json:"reviewed_by,omitempty"`
+	ReviewedAt    *time.Time             `json:"reviewed_at,omitempty"`
+	CreatedAt     time.Time              `json:"created_at"`
+	Hits          []fraudRuleHitResponse `json:"hits,omitempty"`
+}
+
+// s.newFraudDecisionResponse needs the currency of the accounts, which the
+// decision itself does not record.
+func (s *Server) newFraudDecisionResponse(decision db.FraudDecision, currency string) fraudDecisionResponse {
+	res := fraudDecisionResponse{
+		ID:            decision.ID,
+		FromAccountID: decision.FromAccountID,
+		ToAccountID:   decision.ToAccountID,
+		Amount:        s.moneyJSON(decision.AmountCents, currency),
+		Score:         decision.Score,
+		Action:        decision.Action,
+		ReviewOutcome: decision.ReviewOutcome.String,
+		ReviewedBy:    decision.ReviewedBy.String,
+		CreatedAt:     decision.CreatedAt.Time.UTC(),
+	}
+	if decision.TransferID.Valid {
+		res.TransferID = &decision.TransferID.Int64
+	}
+	if decision.ReviewedAt.Valid {
+		reviewedAt := decision.ReviewedAt.Time.UTC()
+		res.ReviewedAt = &reviewedAt
+	}
+	return res
+}
+
+type listFraudDecisionsQuery struct {
+	Action     string `form:"action" binding:"omitempty,oneof=allow challenge hold block"`
+	Unreviewed bool   `form:"unreviewed"`
+	pageQuery
+}
+
+// accountCurrencies looks up the currency of accounts, once per account.
+type accountCurrencies map[int64]string
+
+func (m accountCurrencies) lookup(c *gin.Context, store db.Store, accountID int64) (string, error) {
+	if currency, ok := m[accountID]; ok {
+		return currency, nil
+	}
+
+	account, err := store.GetAccount(c, accountID)
+	if err != nil {
+		return "", err
+	}
+	m[accountID] = account.Currency
+	return account.Currency, nil
+}
+
+// listFraudDecisions lists decisions newest first. unreviewed=true is the
+// queue of challenged and held transfers nobody decided on yet.
+func (s *Server) listFraudDecisions(c *gin.Context) {
+	var req listFraudDecisionsQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	after, pageSize, ok := s.keysetPage(c, req.pageQuery, "fraud_decisions", 0)
+	if !ok {
+		return
+	}
+
+	decisions, err := s.store.ListFraudDecisions(c, db.ListFraudDecisionsParams{
+		Action:          pgtype.Text{String: req.Action, Valid: req.Action != ""},
+		Unreviewed:      req.Unreviewed,
+		BeforeCreatedAt: after.beforeCreatedAt(),
+		BeforeID:        after.ID,
+		LimitCount:      pageSize + 1,
+	})
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	currencies := accountCurrencies{}
+	for _, decision := range decisions {
+		if _, err := currencies.lookup(c, s.store, decision.FromAccountID); err != nil {
+			errorResponse(c, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, newListResponse(s, "fraud_decisions", 0, decisions, pageSize, fraudDecisionKey,
+		func(decision db.FraudDecision) fraudDecisionResponse {
+			return s.newFraudDecisionResponse(decision, currencies[decision.FromAccountID])
+		}))
+}
+
+func fraudDecisionKey(decision db.FraudDecision) cursor {
+	return cursor{CreatedAt: decision.CreatedAt.Time, ID: decision.ID}
+}
+
+type fraudDecisionParams struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// getFraudDecision returns a decision with the rules the transfer hit.
+func (s *Server) getFraudDecision(c *gin.Context) {
+	var params fraudDecisionParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	decision, err := s.store.GetFraudDecision(c, params.ID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			errorResponse(c, http.StatusNotFound, errFraudDecisionNotFound)
+			return
+		}
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	hits, err := s.store.ListFraudRuleHits(c, decision.ID)
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	account, err := s.store.GetAccount(c, decision.FromAccountID)
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	res := s.newFraudDecisionResponse(decision, account.Currency)
+	for _, hit := range hits {
+		res.Hits = append(res.Hits, fraudRuleHitResponse{
+			Rule:   hit.RuleName,
+			Score:  hit.Score,
+			Detail: hit.Detail,
+		})
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+func (s *Server) approveFraudDecision(c *gin.Context) {
+	s.reviewFraudDecision(c, db.FraudReviewApproved)
+}
+
+func (s *Server) rejectFraudDecision(c *gin.Context) {
+	s.reviewFraudDecision(c, db.FraudReviewRejected)
+}
+
+type reviewFraudDecisionResponse struct {
+	Decision fraudDecisionResponse `json:"decision"`
+	Transfer transferResponse      `json:"transfer"`
+	// FailureReason tells why an approved transfer could not be booked.
+	FailureReason string `json:"failure_reason,omitempty"`
+}
+
+// reviewFraudDecision settles a challenged or held transfer. A reviewer may
+// decide on a challenge the sender did not confirm.
+func (s *Server) reviewFraudDecision(c *gin.Context, outcome string) {
+	var params fraudDecisionParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	result, err := s.store.ReviewFraudDecisionTx(c, db.ReviewFraudDecisionTxParams{
+		DecisionID: params.ID,
+		Outcome:    outcome,
+		ReviewedBy: authPayload(c).Username,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			errorResponse(c, http.StatusNotFound, errFraudDecisionNotFound)
+		case errors.Is(err, db.ErrFraudDecisionNotReviewable):
+			errorResponse(c, http.StatusConflict, err)
+		default:
+			errorResponse(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	account, err := s.store.GetAccount(c, result.Transfer.FromAccountID)
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, reviewFraudDecisionResponse{
+		Decision:      s.newFraudDecisionResponse(result.Decision, account.Currency),
+		Transfer:      s.newTransferResponse(result.Transfer, account.Currency),
+		FailureReason: result.FailureReason,
+	})
+}
@@ -0,0 +1,544 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/vlone310/bss/internal/db/mock"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+	"github.com/vlone310/bss/internal/fraud"
+	"github.com/vlone310/bss/util"
+)
+
+func randomFraudDecision(transfer db.Transfer, action string) db.FraudDecision {
+	return db.FraudDecision{
+		ID:            11,
+		FromAccountID: transfer.FromAccountID,
+		ToAccountID:   transfer.ToAccountID,
+		AmountCents:   transfer.AmountCents,
+		TransferID:    pgtype.Int8{Int64: transfer.ID, Valid: true},
+		Score:         60,
+		Action:        action,
+		CreatedAt:     pgtype.Timestamptz{Time: time.Now(), Valid: true},
+	}
+}
+
+func TestCreateTransferFraudAPI(t *testing.T) {
+	from := randomAccount()
+	from.Balance = 1_000_000
+	to := randomAccount()
+	to.ID = from.ID + 1
+	to.Currency = from.Currency
+
+	pending := randomTransfer(from, to)
+	pending.Status = db.TransferStatusPending
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Challenged",
+			buildStubs: func(store *mockdb.MockStore) {
+				decision := randomFraudDecision(pending, db.FraudActionChallenge)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.TransferTxResult{Transfer: pending, FraudDecision: &decision}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+
+				var res pendingTransferResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, db.TransferStatusPending, res.Status)
+				require.Equal(t, fmt.Sprintf("/transfers/%d/confirm", pending.ID), res.ConfirmURL)
+
+				// the score is not disclosed
+				require.NotContains(t, recorder.Body.String(), "score")
+			},
+		},
+		{
+			name: "Held",
+			buildStubs: func(store *mockdb.MockStore) {
+				decision := randomFraudDecision(pending, db.FraudActionHold)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.TransferTxResult{Transfer: pending, FraudDecision: &decision}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+
+				var res pendingTransferResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Empty(t, res.ConfirmURL)
+			},
+		},
+		{
+			name: "Blocked",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.TransferTxResult{}, db.ErrTransferBlocked)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(from.ID)).Times(1).Return(from, nil)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(to.ID)).Times(1).Return(to, nil)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			body := fmt.Sprintf(`{"from_account_id": %d, "to_account_id": %d, "amount": {"minor": 100, "currency": %q}}`,
+				from.ID, to.ID, from.Currency)
+			request, err := http.NewRequest(http.MethodPost, "/transfers", strings.NewReader(body))
+			require.NoError(t, err)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, from.Owner, time.Minute)
+
+			serve(t, server, recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+// randomTOTPUser returns a user with one-time codes enabled and a code of
+// theirs that is valid now.
+func randomTOTPUser(t *testing.T) (user db.User, code string) {
+	t.Helper()
+
+	secret, err := util.NewTOTPSecret()
+	require.NoError(t, err)
+
+	user, _ = randomUser(t)
+	user.TotpSecret = pgtype.Text{String: secret, Valid: true}
+	user.TotpEnabledAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+
+	code, err = util.TOTPCode(secret, util.TOTPStep(time.Now()))
+	require.NoError(t, err)
+	return
+}
+
+func TestConfirmTransferAPI(t *testing.T) {
+	user, code := randomTOTPUser(t)
+	from := randomAccount()
+	from.Owner = user.Username
+	to := randomAccount()
+
+	pending := randomTransfer(from, to)
+	pending.Status = db.TransferStatusPending
+	challenge := randomFraudDecision(pending, db.FraudActionChallenge)
+
+	staleCode, err := util.TOTPCode(user.TotpSecret.String, util.TOTPStep(time.Now())-10)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name          string
+		username      string
+		code          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: user.Username,
+			code:     code,
+			buildStubs: func(store *mockdb.MockStore) {
+				completed := pending
+				completed.Status = db.TransferStatusCompleted
+
+				store.EXPECT().GetFraudDecisionByTransfer(gomock.Any(), gomock.Eq(pgtype.Int8{Int64: pending.ID, Valid: true})).Times(1).Return(challenge, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().UseUserTOTPStep(gomock.Any(), gomock.Any()).Times(1).Return(user, nil)
+				arg := db.ReviewFraudDecisionTxParams{
+					DecisionID: challenge.ID,
+					Outcome:    db.FraudReviewApproved,
+					ReviewedBy: user.Username,
+				}
+				store.EXPECT().ReviewFraudDecisionTx(gomock.Any(), gomock.Eq(arg)).Times(1).
+					Return(db.ReviewFraudDecisionTxResult{Transfer: completed}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res transferResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, db.TransferStatusCompleted, res.Status)
+				require.Equal(t, formatter.moneyJSON(pending.AmountCents, from.Currency), res.Amount)
+			},
+		},
+		{
+			name:     "WrongCode",
+			username: user.Username,
+			code:     staleCode,
+			buildStubs: func(store *mockdb.MockStore) {
+				attempted := challenge
+				attempted.ConfirmAttempts = 1
+
+				store.EXPECT().GetFraudDecisionByTransfer(gomock.Any(), gomock.Any()).Times(1).Return(challenge, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().UseUserTOTPStep(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().AddFraudConfirmAttempt(gomock.Any(), gomock.Eq(challenge.ID)).Times(1).Return(attempted, nil)
+				store.EXPECT().ReviewFraudDecisionTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Contains(t, recorder.Body.String(), "invalid_otp")
+			},
+		},
+		{
+			name:     "TooManyWrongCodes",
+			username: user.Username,
+			code:     staleCode,
+			buildStubs: func(store *mockdb.MockStore) {
+				attempted := challenge
+				attempted.ConfirmAttempts = maxConfirmAttempts
+
+				store.EXPECT().GetFraudDecisionByTransfer(gomock.Any(), gomock.Any()).Times(1).Return(challenge, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().AddFraudConfirmAttempt(gomock.Any(), gomock.Eq(challenge.ID)).Times(1).Return(attempted, nil)
+				arg := db.ReviewFraudDecisionTxParams{
+					DecisionID: challenge.ID,
+					Outcome:    db.FraudReviewRejected,
+				}
+				store.EXPECT().ReviewFraudDecisionTx(gomock.Any(), gomock.Eq(arg)).Times(1).
+					Return(db.ReviewFraudDecisionTxResult{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "UsedCode",
+			username: user.Username,
+			code:     code,
+			buildStubs: func(store *mockdb.MockStore) {
+				attempted := challenge
+				attempted.ConfirmAttempts = 1
+
+				store.EXPECT().GetFraudDecisionByTransfer(gomock.Any(), gomock.Any()).Times(1).Return(challenge, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().UseUserTOTPStep(gomock.Any(), gomock.Any()).Times(1).Return(db.User{}, pgx.ErrNoRows)
+				store.EXPECT().AddFraudConfirmAttempt(gomock.Any(), gomock.Eq(challenge.ID)).Times(1).Return(attempted, nil)
+				store.EXPECT().ReviewFraudDecisionTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Contains(t, recorder.Body.String(), "invalid_otp")
+			},
+		},
+		{
+			name:     "TOTPNotEnabled",
+			username: user.Username,
+			code:     code,
+			buildStubs: func(store *mockdb.MockStore) {
+				withoutTOTP := user
+				withoutTOTP.TotpEnabledAt = pgtype.Timestamptz{}
+
+				store.EXPECT().GetFraudDecisionByTransfer(gomock.Any(), gomock.Any()).Times(1).Return(challenge, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(withoutTOTP, nil)
+				store.EXPECT().AddFraudConfirmAttempt(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ReviewFraudDecisionTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				require.Contains(t, recorder.Body.String(), "totp_required")
+			},
+		},
+		{
+			name:     "NotSender",
+			username: to.Owner,
+			code:     code,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetFraudDecisionByTransfer(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ReviewFraudDecisionTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:     "Held",
+			username: user.Username,
+			code:     code,
+			buildStubs: func(store *mockdb.MockStore) {
+				hold := randomFraudDecision(pending, db.FraudActionHold)
+				store.EXPECT().GetFraudDecisionByTransfer(gomock.Any(), gomock.Any()).Times(1).Return(hold, nil)
+				store.EXPECT().ReviewFraudDecisionTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(pending, nil)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(from.ID)).Times(1).Return(from, nil)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			var body bytes.Buffer
+			require.NoError(t, json.NewEncoder(&body).Encode(gin.H{"code": tc.code}))
+
+			request, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/transfers/%d/confirm", pending.ID), &body)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+			serve(t, server, recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestPutFraudRuleAPI(t *testing.T) {
+	admin, _ := randomUser(t)
+	admin.Role = db.UserRoleAdmin
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, engine *fraud.Engine)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"kind": fraud.KindVelocity, "params": gin.H{"count": 3, "window": "5m"}, "score": 50, "enabled": true},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpsertFraudRuleParams{
+					Name:    "velocity",
+					Kind:    fraud.KindVelocity,
+					Params:  []byte(`{"count":3,"window":"5m"}`),
+					Score:   50,
+					Enabled: true,
+				}
+				store.EXPECT().UpsertFraudRule(gomock.Any(), gomock.Eq(arg)).Times(1).
+					Return(db.FraudRule{Name: arg.Name, Kind: arg.Kind, Params: arg.Params, Score: arg.Score, Enabled: true}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, engine *fraud.Engine) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				// the rule is used right away
+				rules := engine.Rules()
+				require.Len(t, rules, 1)
+				require.Equal(t, "velocity", rules[0].Name())
+				require.Equal(t, int32(50), rules[0].Score())
+			},
+		},
+		{
+			name: "InvalidParams",
+			body: gin.H{"kind": fraud.KindVelocity, "params": gin.H{"count": 0, "window": "5m"}, "score": 50, "enabled": true},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertFraudRule(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, engine *fraud.Engine) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Empty(t, engine.Rules())
+			},
+		},
+		{
+			name: "UnknownKind",
+			body: gin.H{"kind": "geo", "score": 50, "enabled": true},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertFraudRule(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, engine *fraud.Engine) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetUser(gomock.Any(), gomock.Eq(admin.Username)).Times(1).Return(admin, nil)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			var body bytes.Buffer
+			require.NoError(t, json.NewEncoder(&body).Encode(tc.body))
+
+			request, err := http.NewRequest(http.MethodPut, "/admin/fraud/rules/velocity", &body)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, admin.Username, time.Minute)
+			serve(t, server, recorder, request)
+			tc.checkResponse(t, recorder, server.fraud)
+		})
+	}
+}
+
+func TestReviewFraudDecisionAPI(t *testing.T) {
+	admin, _ := randomUser(t)
+	admin.Role = db.UserRoleAdmin
+	from := randomAccount()
+	to := randomAccount()
+	pending := randomTransfer(from, to)
+	pending.Status = db.TransferStatusPending
+	hold := randomFraudDecision(pending, db.FraudActionHold)
+
+	testCases := []struct {
+		name          string
+		action        string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "Reject",
+			action: "reject",
+			buildStubs: func(store *mockdb.MockStore) {
+				rejected := hold
+				rejected.ReviewOutcome = pgtype.Text{String: db.FraudReviewRejected, Valid: true}
+				rejected.ReviewedBy = pgtype.Text{String: admin.Username, Valid: true}
+				failed := pending
+				failed.Status = db.TransferStatusFailed
+
+				arg := db.ReviewFraudDecisionTxParams{
+					DecisionID: hold.ID,
+					Outcome:    db.FraudReviewRejected,
+					ReviewedBy: admin.Username,
+				}
+				store.EXPECT().ReviewFraudDecisionTx(gomock.Any(), gomock.Eq(arg)).Times(1).
+					Return(db.ReviewFraudDecisionTxResult{Decision: rejected, Transfer: failed}, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(from.ID)).Times(1).Return(from, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res reviewFraudDecisionResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, db.FraudReviewRejected, res.Decision.ReviewOutcome)
+				require.Equal(t, db.TransferStatusFailed, res.Transfer.Status)
+			},
+		},
+		{
+			name:   "AlreadyReviewed",
+			action: "approve",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ReviewFraudDecisionTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.ReviewFraudDecisionTxResult{}, fmt.Errorf("decision [11] to hold: %w", db.ErrFraudDecisionNotReviewable))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetUser(gomock.Any(), gomock.Eq(admin.Username)).Times(1).Return(admin, nil)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/admin/fraud/decisions/%d/%s", hold.ID, tc.action)
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, admin.Username, time.Minute)
+			serve(t, server, recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListFraudDecisionsAPI(t *testing.T) {
+	admin, _ := randomUser(t)
+	admin.Role = db.UserRoleAdmin
+	from := randomAccount()
+	to := randomAccount()
+
+	// newest first
+	decisions := make([]db.FraudDecision, 2)
+	base := time.Now().UTC().Truncate(time.Second)
+	for i := range decisions {
+		decisions[i] = randomFraudDecision(randomTransfer(from, to), db.FraudActionHold)
+		decisions[i].ID = int64(len(decisions) - i)
+		decisions[i].CreatedAt = pgtype.Timestamptz{Time: base.Add(-time.Duration(i) * time.Second), Valid: true}
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+
+	first := db.ListFraudDecisionsParams{
+		Unreviewed:      true,
+		BeforeCreatedAt: pgtype.Timestamptz{InfinityModifier: pgtype.Infinity, Valid: true},
+		LimitCount:      2,
+	}
+	next := db.ListFraudDecisionsParams{
+		Unreviewed:      true,
+		BeforeCreatedAt: decisions[0].CreatedAt,
+		BeforeID:        decisions[0].ID,
+		LimitCount:      2,
+	}
+	store.EXPECT().GetUser(gomock.Any(), gomock.Eq(admin.Username)).Times(2).Return(admin, nil)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(from.ID)).Times(2).Return(from, nil)
+	gomock.InOrder(
+		store.EXPECT().ListFraudDecisions(gomock.Any(), gomock.Eq(first)).Times(1).Return(decisions, nil),
+		store.EXPECT().ListFraudDecisions(gomock.Any(), gomock.Eq(next)).Times(1).Return(decisions[1:], nil),
+	)
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/admin/fraud/decisions?unreviewed=true&page_size=1", nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, admin.Username, time.Minute)
+	serve(t, server, recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	res := requireBodyListResponse[fraudDecisionResponse](t, recorder.Body)
+	require.Len(t, res.Items, 1)
+	require.Equal(t, decisions[0].ID, res.Items[0].ID)
+	require.True(t, res.HasMore)
+
+	recorder = httptest.NewRecorder()
+	request, err = http.NewRequest(http.MethodGet, "/admin/fraud/decisions?unreviewed=true&page_size=1&cursor="+res.NextCursor, nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, admin.Username, time.Minute)
+	serve(t, server, recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	res = requireBodyListResponse[fraudDecisionResponse](t, recorder.Body)
+	require.Len(t, res.Items, 1)
+	require.Equal(t, decisions[1].ID, res.Items[0].ID)
+	require.False(t, res.HasMore)
+}
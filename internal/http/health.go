@@ -0,0 +1,174 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vlone310/bss/internal/db/migration"
+	"github.com/vlone310/bss/internal/worker"
+)
+
+// readyCheckTimeout bounds the database queries of a readiness check, a
+// probe that hangs is as bad as one that fails.
+const readyCheckTimeout = 2 * time.Second
+
+const (
+	checkOK     = "ok"
+	checkFailed = "failed"
+)
+
+type healthResponse struct {
+	Status string        `json:"status"`
+	Checks []checkResult `json:"checks,omitempty"`
+}
+
+type checkResult struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+	// Details depend on the check, such as the versions of the migrations
+	// check.
+	Details any `json:"details,omitempty"`
+}
+
+type migrationDetails struct {
+	Version  int64 `json:"version"`
+	Expected int64 `json:"expected"`
+	Dirty    bool  `json:"dirty"`
+}
+
+// workerDetails is the state of a worker. Status is failed when its last
+// run failed, the error itself is only logged.
+type workerDetails struct {
+	Name    string    `json:"name"`
+	Status  string    `json:"status"`
+	Running bool      `json:"running"`
+	LastRun time.Time `json:"last_run"`
+}
+
+// SetWorkers adds the state of the background workers to the readiness
+// check. It has to be called before serving.
+func (s *Server) SetWorkers(workers ...*worker.Periodic) {
+	s.workers = workers
+}
+
+// getHealthz answers as long as the process does, it checks nothing else.
+func (s *Server) getHealthz(c *gin.Context) {
+	c.JSON(http.StatusOK, healthResponse{Status: checkOK})
+}
+
+// getReadyz answers 503 while a dependency is down or the server is
+// draining, so that no new requests are sent to it.
+func (s *Server) getReadyz(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c, readyCheckTimeout)
+	defer cancel()
+
+	res := healthResponse{
+		Status: checkOK,
+		Checks: []checkResult{
+			s.checkShutdown(),
+			s.checkDatabase(ctx),
+			s.checkMigrations(ctx),
+			s.checkWorkers(),
+		},
+	}
+
+	status := http.StatusOK
+	for _, check := range res.Checks {
+		if check.Status != checkOK {
+			res.Status = checkFailed
+			status = http.StatusServiceUnavailable
+		}
+	}
+
+	c.JSON(status, res)
+}
+
+func (s *Server) checkShutdown() checkResult {
+	res := checkResult{Name: "shutdown", Status: checkOK}
+
+	select {
+	case <-s.closing:
+		res.Status = checkFailed
+		res.Error = "draining"
+	default:
+	}
+
+	return res
+}
+
+func (s *Server) checkDatabase(ctx context.Context) checkResult {
+	res := checkResult{Name: "database", Status: checkOK}
+
+	if err := s.store.Ping(ctx); err != nil {
+		log.Printf("http: readiness database: %v", err)
+		res.Status = checkFailed
+		res.Error = "database unreachable"
+	}
+
+	return res
+}
+
+// checkMigrations fails until the migrations the server was built with are
+// applied. A newer schema passes, migrations are backward compatible so
+// that a rolling deploy can migrate before the old servers are gone.
+func (s *Server) checkMigrations(ctx context.Context) checkResult {
+	res := checkResult{Name: "migrations", Status: checkOK}
+
+	expected, err := migration.Latest()
+	if err != nil {
+		log.Printf("http: readiness migrations: %v", err)
+		res.Status = checkFailed
+		res.Error = "embedded migrations unreadable"
+		return res
+	}
+
+	version, dirty, err := s.store.MigrationVersion(ctx)
+	if err != nil {
+		log.Printf("http: readiness migrations: %v", err)
+		res.Status = checkFailed
+		res.Error = "migration version unknown"
+		return res
+	}
+	res.Details = migrationDetails{Version: version, Expected: expected, Dirty: dirty}
+
+	switch {
+	case dirty:
+		res.Status = checkFailed
+		res.Error = fmt.Sprintf("migration %d is dirty", version)
+	case version < expected:
+		res.Status = checkFailed
+		res.Error = fmt.Sprintf("schema is at version %d, want %d", version, expected)
+	}
+
+	return res
+}
+
+// checkWorkers reports the state of the workers and never fails: the
+// workers do not serve requests, and taking the server out of the load
+// balancer because a job failed would not make the job succeed. The errors
+// of the jobs can name queries or webhook endpoints, they are in the log of
+// the worker only.
+func (s *Server) checkWorkers() checkResult {
+	details := make([]workerDetails, 0, len(s.workers))
+	for _, w := range s.workers {
+		status := w.Status()
+
+		state := workerDetails{
+			Name:    status.Name,
+			Status:  checkOK,
+			Running: status.Running,
+			LastRun: status.LastRun,
+		}
+		if status.LastError != "" {
+			state.Status = checkFailed
+		}
+		details = append(details, state)
+	}
+
+	return checkResult{Name: "workers", Status: checkOK, Details: details}
+}
@@ -0,0 +1,191 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"github.com/vlone310/bss/internal/db/migration"
+	mockdb "github.com/vlone310/bss/internal/db/mock"
+	"github.com/vlone310/bss/internal/worker"
+)
+
+// ranWorker returns a worker that ran its job once.
+func ranWorker(name string, err error) *worker.Periodic {
+	w := worker.NewPeriodic(name, time.Hour, func(ctx context.Context) error { return err })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	w.Run(ctx)
+
+	return w
+}
+
+func TestGetHealthzAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/healthz", nil)
+	require.NoError(t, err)
+
+	serve(t, server, recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.JSONEq(t, `{"status": "ok"}`, recorder.Body.String())
+}
+
+func TestGetReadyzAPI(t *testing.T) {
+	latest, err := migration.Latest()
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name       string
+		buildStubs func(store *mockdb.MockStore)
+		setup      func(t *testing.T, server *Server)
+		status     int
+		// failed are the checks expected to fail, with their errors
+		failed map[string]string
+		check  func(t *testing.T, res healthResponse)
+	}{
+		{
+			name: "Ready",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().MigrationVersion(gomock.Any()).Times(1).Return(latest, false, nil)
+			},
+			setup: func(t *testing.T, server *Server) {
+				server.SetWorkers(ranWorker("reconciliation", nil))
+			},
+			status: http.StatusOK,
+		},
+		{
+			name: "Draining",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().MigrationVersion(gomock.Any()).Times(1).Return(latest, false, nil)
+			},
+			setup: func(t *testing.T, server *Server) {
+				server.Drain()
+			},
+			status: http.StatusServiceUnavailable,
+			failed: map[string]string{"shutdown": "draining"},
+		},
+		{
+			name: "DatabaseDown",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(errors.New("dial tcp 127.0.0.1:5432: connect: connection refused"))
+				store.EXPECT().MigrationVersion(gomock.Any()).Times(1).Return(int64(0), false, errors.New("dial tcp 127.0.0.1:5432: connect: connection refused"))
+			},
+			status: http.StatusServiceUnavailable,
+			failed: map[string]string{"database": "database unreachable", "migrations": "migration version unknown"},
+		},
+		{
+			name: "MigrationsBehind",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().MigrationVersion(gomock.Any()).Times(1).Return(latest-1, false, nil)
+			},
+			status: http.StatusServiceUnavailable,
+			failed: map[string]string{"migrations": fmt.Sprintf("schema is at version %d, want %d", latest-1, latest)},
+		},
+		{
+			name: "MigrationsAhead",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().MigrationVersion(gomock.Any()).Times(1).Return(latest+1, false, nil)
+			},
+			status: http.StatusOK,
+		},
+		{
+			name: "MigrationDirty",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().MigrationVersion(gomock.Any()).Times(1).Return(latest, true, nil)
+			},
+			status: http.StatusServiceUnavailable,
+			failed: map[string]string{"migrations": fmt.Sprintf("migration %d is dirty", latest)},
+		},
+		{
+			name: "WorkerFailing",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().MigrationVersion(gomock.Any()).Times(1).Return(latest, false, nil)
+			},
+			setup: func(t *testing.T, server *Server) {
+				server.SetWorkers(ranWorker("reconciliation", nil), ranWorker("outbox relay", errors.New("write outbox.jsonl: no space left on device")))
+			},
+			// a failing job does not take the server out of the load balancer
+			status: http.StatusOK,
+			check: func(t *testing.T, res healthResponse) {
+				data, err := json.Marshal(res.Checks[3].Details)
+				require.NoError(t, err)
+
+				var workers []workerDetails
+				require.NoError(t, json.Unmarshal(data, &workers))
+				require.Len(t, workers, 2)
+				require.Equal(t, checkOK, workers[0].Status)
+				require.Equal(t, "outbox relay", workers[1].Name)
+				require.Equal(t, checkFailed, workers[1].Status)
+				// the error of the job is not public
+				require.NotContains(t, string(data), "outbox.jsonl")
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			if tc.setup != nil {
+				tc.setup(t, server)
+			}
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/readyz", nil)
+			require.NoError(t, err)
+
+			serve(t, server, recorder, request)
+			require.Equal(t, tc.status, recorder.Code)
+
+			var res healthResponse
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+			if tc.status == http.StatusOK {
+				require.Equal(t, checkOK, res.Status)
+			} else {
+				require.Equal(t, checkFailed, res.Status)
+			}
+
+			if tc.check != nil {
+				tc.check(t, res)
+			}
+
+			names := make([]string, 0, len(res.Checks))
+			for _, check := range res.Checks {
+				names = append(names, check.Name)
+				if msg, ok := tc.failed[check.Name]; ok {
+					require.Equal(t, checkFailed, check.Status, check.Name)
+					require.Equal(t, msg, check.Error)
+				} else {
+					require.Equal(t, checkOK, check.Status, check.Name)
+					require.Empty(t, check.Error)
+				}
+			}
+			require.Equal(t, []string{"shutdown", "database", "migrations", "workers"}, names)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+var errJournalNotFound = errors.New("journal not found")
+
+type houseAccountResponse struct {
+	Purpose string `json:"purpose"`
+	accountResponse
+}
+
+func (s *Server) listHouseAccounts(c *gin.Context) {
+	accounts, err := s.store.ListHouseAccounts(c)
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	res := make([]houseAccountResponse, 0, len(accounts))
+	for _, account := range accounts {
+		res = append(res, houseAccountResponse{
+			Purpose:         account.Purpose,
+			accountResponse: s.newAccountResponse(account.Account),
+		})
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+type journalResponse struct {
+	ID          int64           `json:"id"`
+	Kind        string          `json:"kind"`
+	Description string          `json:"description,omitempty"`
+	Entries     []entryResponse `json:"entries"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+type getJournalParams struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (s *Server) getJournal(c *gin.Context) {
+	var req getJournalParams
+	if err := c.ShouldBindUri(&req); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	journal, err := s.store.GetJournal(c, req.ID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			errorResponse(c, http.StatusNotFound, errJournalNotFound)
+			return
+		}
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	entries, err := s.store.ListJournalEntries(c, journal.ID)
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	res := journalResponse{
+		ID:          journal.ID,
+		Kind:        journal.Kind,
+		Description: journal.Description.String,
+		Entries:     make([]entryResponse, 0, len(entries)),
+		CreatedAt:   journal.CreatedAt.Time.UTC(),
+	}
+	// a journal can span currencies, each entry is in its account's
+	currencies := map[int64]string{}
+	for _, entry := range entries {
+		currency, ok := currencies[entry.AccountID]
+		if !ok {
+			account, err := s.store.GetAccount(c, entry.AccountID)
+			if err != nil {
+				errorResponse(c, http.StatusInternalServerError, err)
+				return
+			}
+			currency = account.Currency
+			currencies[entry.AccountID] = currency
+		}
+
+		res.Entries = append(res.Entries, s.newEntryResponse(entry, currency))
+	}
+
+	c.JSON(http.StatusOK, res)
+}
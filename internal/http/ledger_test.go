@@ -0,0 +1,129 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/vlone310/bss/internal/db/mock"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+	"github.com/vlone310/bss/testutil"
+)
+
+func TestListHouseAccountsAPI(t *testing.T) {
+	admin, _ := randomUser(t)
+	admin.Role = db.UserRoleAdmin
+
+	house := randomAccount()
+	house.Owner = "house"
+	house.Product = "house"
+	house.Balance = -house.Balance
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetUser(gomock.Any(), gomock.Eq(admin.Username)).Times(1).Return(admin, nil)
+	store.EXPECT().ListHouseAccounts(gomock.Any()).Times(1).Return([]db.ListHouseAccountsRow{
+		{Purpose: db.HousePurposeSuspense, Account: house},
+	}, nil)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/admin/house-accounts", nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, admin.Username, time.Minute)
+	serve(t, server, recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var res []houseAccountResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+	require.Len(t, res, 1)
+	require.Equal(t, db.HousePurposeSuspense, res[0].Purpose)
+	require.Equal(t, house.ID, res[0].ID)
+	require.Equal(t, formatter.moneyJSON(house.Balance, house.Currency), res[0].Balance)
+}
+
+func TestGetJournalAPI(t *testing.T) {
+	admin, _ := randomUser(t)
+	admin.Role = db.UserRoleAdmin
+
+	from := randomAccount()
+	to := randomAccount()
+	journal := db.Journal{
+		ID:        testutil.RandomInt(1, 1000),
+		Kind:      db.JournalKindTransfer,
+		CreatedAt: pgtype.Timestamptz{Time: time.Now().UTC(), Valid: true},
+	}
+	entries := []db.Entry{
+		{ID: 1, JournalID: journal.ID, AccountID: from.ID, AmountCents: -10},
+		{ID: 2, JournalID: journal.ID, AccountID: to.ID, AmountCents: 10},
+	}
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetJournal(gomock.Any(), gomock.Eq(journal.ID)).Times(1).Return(journal, nil)
+				store.EXPECT().ListJournalEntries(gomock.Any(), gomock.Eq(journal.ID)).Times(1).Return(entries, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(from.ID)).Times(1).Return(from, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(to.ID)).Times(1).Return(to, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res journalResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, journal.ID, res.ID)
+				require.Len(t, res.Entries, 2)
+				require.Equal(t, formatter.moneyJSON(-10, from.Currency), res.Entries[0].Amount)
+				require.Equal(t, formatter.moneyJSON(10, to.Currency), res.Entries[1].Amount)
+			},
+		},
+		{
+			name: "NotFound",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetJournal(gomock.Any(), gomock.Eq(journal.ID)).Times(1).Return(db.Journal{}, pgx.ErrNoRows)
+				store.EXPECT().ListJournalEntries(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetUser(gomock.Any(), gomock.Eq(admin.Username)).Times(1).Return(admin, nil)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/admin/journals/%d", journal.ID)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, admin.Username, time.Minute)
+			serve(t, server, recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
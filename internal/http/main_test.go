@@ -1,12 +1,83 @@
 package http
 
 import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/vlone310/bss/config"
+	"github.com/vlone310/bss/internal/adapter/token/maker"
+	"github.com/vlone310/bss/internal/currency"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+	"github.com/vlone310/bss/internal/fraud"
+	"github.com/vlone310/bss/internal/screening"
+	"github.com/vlone310/bss/internal/stream"
+	"github.com/vlone310/bss/testutil"
 )
 
+func newTestServer(t *testing.T, store db.Store) *Server {
+	t.Helper()
+
+	config := config.Config{
+		TokenSymmetricKey:   testutil.RandomString(32),
+		CursorSecretKey:     testutil.RandomString(32),
+		AccessTokenDuration: time.Minute,
+	}
+
+	server, err := NewServer(config, store, testCurrencies(), screening.NewScreener(0, 0), fraud.NewEngine(fraud.Thresholds{}), stream.NewHub(0))
+	require.NoError(t, err)
+
+	return server
+}
+
+// testCurrencies enables the currencies testutil.RandomCurrency picks from,
+// plus JPY and KWD for their uncommon minor units.
+func testCurrencies() *currency.Registry {
+	return currency.NewRegistry(
+		currency.Currency{Code: "CAD", Numeric: 124, MinorUnits: 2, Enabled: true},
+		currency.Currency{Code: "EUR", Numeric: 978, MinorUnits: 2, Enabled: true},
+		currency.Currency{Code: "GBP", Numeric: 826, MinorUnits: 2, Enabled: false},
+		currency.Currency{Code: "JPY", Numeric: 392, MinorUnits: 0, Enabled: true},
+		currency.Currency{Code: "KWD", Numeric: 414, MinorUnits: 3, Enabled: true},
+		currency.Currency{Code: "USD", Numeric: 840, MinorUnits: 2, Enabled: true},
+	)
+}
+
+// formatter builds responses with the test currencies, for the tests that
+// compare a response with the one they expect.
+var formatter = &Server{currencies: testCurrencies()}
+
+func addAuthorization(
+	t *testing.T,
+	request *http.Request,
+	tokenMaker maker.Maker,
+	authorizationType string,
+	username string,
+	duration time.Duration,
+) {
+	t.Helper()
+
+	token, err := tokenMaker.CreateToken(username, duration)
+	require.NoError(t, err)
+
+	authorizationHeader := fmt.Sprintf("%s %s", authorizationType, token)
+	request.Header.Set(authorizationHeaderKey, authorizationHeader)
+}
+
+// serve runs the request through the router and checks the response
+// against the OpenAPI spec.
+func serve(t *testing.T, server *Server, recorder *httptest.ResponseRecorder, request *http.Request) {
+	t.Helper()
+
+	server.router.ServeHTTP(recorder, request)
+	checkContract(t, request, recorder)
+}
+
 func TestMain(m *testing.M) {
 	gin.SetMode(gin.TestMode)
 	os.Exit(m.Run())
@@ -0,0 +1,132 @@
+package http
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/vlone310/bss/internal/adapter/token/maker"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+)
+
+const (
+	authorizationHeaderKey  = "authorization"
+	authorizationTypeBearer = "bearer"
+	authorizationPayloadKey = "authorization_payload"
+	requestIDHeaderKey      = "X-Request-ID"
+	requestIDKey            = "request_id"
+	maxRequestIDSize        = 64
+)
+
+var errMissingAuthHeader = errors.New("authorization header is not provided")
+var errInvalidAuthHeader = errors.New("invalid authorization header format")
+var errAdminRequired = errors.New("admin role required")
+
+func authMiddleware(tokenMaker maker.Maker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authorizationHeader := c.GetHeader(authorizationHeaderKey)
+		if len(authorizationHeader) == 0 {
+			errorResponse(c, http.StatusUnauthorized, errMissingAuthHeader)
+			return
+		}
+
+		fields := strings.Fields(authorizationHeader)
+		if len(fields) < 2 {
+			errorResponse(c, http.StatusUnauthorized, errInvalidAuthHeader)
+			return
+		}
+
+		authorizationType := strings.ToLower(fields[0])
+		if authorizationType != authorizationTypeBearer {
+			errorResponse(c, http.StatusUnauthorized, fmt.Errorf("unsupported authorization type %s", authorizationType))
+			return
+		}
+
+		payload, err := tokenMaker.VerifyToken(fields[1])
+		if err != nil {
+			errorResponse(c, http.StatusUnauthorized, err)
+			return
+		}
+
+		c.Set(authorizationPayloadKey, payload)
+		if meta, ok := db.AuditMetaFrom(c.Request.Context()); ok {
+			meta.Actor = payload.Username
+			c.Request = c.Request.WithContext(db.WithAuditMeta(c.Request.Context(), meta))
+		}
+		c.Next()
+	}
+}
+
+// adminMiddleware must run after authMiddleware. The role is read from the
+// database rather than the token so that revoking it takes effect at once.
+func adminMiddleware(store db.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, err := store.GetUser(c, authPayload(c).Username)
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				errorResponse(c, http.StatusForbidden, errAdminRequired)
+				return
+			}
+			errorResponse(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		if user.Role != db.UserRoleAdmin {
+			errorResponse(c, http.StatusForbidden, errAdminRequired)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// requestMiddleware gives every request an ID, the one in the X-Request-ID
+// header when the caller sent a usable one, and returns it in the same
+// header. The changes the request makes are audited under that ID, see
+// db.WithAuditMeta.
+func requestMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestIDHeaderKey)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+
+		c.Set(requestIDKey, id)
+		c.Header(requestIDHeaderKey, id)
+		c.Request = c.Request.WithContext(db.WithAuditMeta(c.Request.Context(), db.AuditMeta{
+			RequestID: id,
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		}))
+
+		c.Next()
+	}
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDSize {
+		return false
+	}
+
+	for _, r := range id {
+		if r < '!' || r > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func authPayload(c *gin.Context) *maker.Payload {
+	return c.MustGet(authorizationPayloadKey).(*maker.Payload)
+}
@@ -0,0 +1,49 @@
+package http
+
+import (
+	"cmp"
+	_ "embed"
+	"html/template"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/render"
+)
+
+// openAPISpec describes every route of the server. It is written by hand,
+// the contract tests check the handlers against it.
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+// swaggerUIVersion is the swagger-ui-dist release the docs page loads
+// unless the config points it at a copy of its own.
+const swaggerUIVersion = "5.17.14"
+
+const defaultSwaggerUIURL = "https://unpkg.com/swagger-ui-dist@" + swaggerUIVersion
+
+var swaggerUIPage = template.Must(template.New("docs").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>BSS API</title>
+  <link rel="stylesheet" href="{{.}}/swagger-ui.css" crossorigin="anonymous">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="{{.}}/swagger-ui-bundle.js" crossorigin="anonymous"></script>
+  <script>
+    window.ui = SwaggerUIBundle({url: "/openapi.json", dom_id: "#swagger-ui"});
+  </script>
+</body>
+</html>
+`))
+
+func (s *Server) getOpenAPI(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json", openAPISpec)
+}
+
+// getDocs serves Swagger UI for the spec.
+func (s *Server) getDocs(c *gin.Context) {
+	c.Render(http.StatusOK, render.HTML{Template: swaggerUIPage, Data: cmp.Or(s.config.SwaggerUIURL, defaultSwaggerUIURL)})
+}
@@ -0,0 +1,172 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/vlone310/bss/internal/db/mock"
+)
+
+var (
+	specOnce   sync.Once
+	specDoc    *openapi3.T
+	specRouter routers.Router
+	specErr    error
+)
+
+// loadSpec loads the embedded spec and its router once for all tests.
+func loadSpec(t *testing.T) (*openapi3.T, routers.Router) {
+	t.Helper()
+
+	specOnce.Do(func() {
+		// the exports, the event stream and the docs page are checked as
+		// plain strings
+		for _, contentType := range []string{"application/x-ofx", "application/xml", "text/event-stream", "text/html"} {
+			openapi3filter.RegisterBodyDecoder(contentType, openapi3filter.PlainBodyDecoder)
+		}
+
+		specDoc, specErr = openapi3.NewLoader().LoadFromData(openAPISpec)
+		if specErr != nil {
+			return
+		}
+		specRouter, specErr = gorillamux.NewRouter(specDoc)
+	})
+	require.NoError(t, specErr)
+	return specDoc, specRouter
+}
+
+// checkContract fails the test when the response is not one the spec
+// documents for the request: an unknown route or status code, another
+// content type or a body that breaks the schema.
+func checkContract(t *testing.T, request *http.Request, recorder *httptest.ResponseRecorder) {
+	t.Helper()
+
+	_, router := loadSpec(t)
+	route, pathParams, err := router.FindRoute(request)
+	if errors.Is(err, routers.ErrPathNotFound) || errors.Is(err, routers.ErrMethodNotAllowed) {
+		// unknown routes answer 404 without a body
+		require.Equal(t, http.StatusNotFound, recorder.Code, "%s %s is not in the spec", request.Method, request.URL.Path)
+		return
+	}
+	require.NoError(t, err)
+
+	response := route.Operation.Responses.Status(recorder.Code)
+	require.NotNil(t, response, "%s %s: status %d is not in the spec", request.Method, request.URL.Path, recorder.Code)
+	if len(response.Value.Content) == 0 {
+		require.Zero(t, recorder.Body.Len(), "%s %s: %d must not have a body", request.Method, request.URL.Path, recorder.Code)
+		return
+	}
+
+	input := &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: &openapi3filter.RequestValidationInput{
+			Request:    request,
+			PathParams: pathParams,
+			Route:      route,
+		},
+		Status:  recorder.Code,
+		Header:  recorder.Header(),
+		Options: &openapi3filter.Options{IncludeResponseStatus: true, MultiError: true},
+	}
+	input.SetBodyBytes(recorder.Body.Bytes())
+	err = openapi3filter.ValidateResponse(request.Context(), input)
+	require.NoError(t, err, "%s %s: %d response does not match the spec", request.Method, request.URL.Path, recorder.Code)
+}
+
+// TestOpenAPIRoutes keeps the spec and the router in step.
+func TestOpenAPIRoutes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	server := newTestServer(t, mockdb.NewMockStore(ctrl))
+	doc, _ := loadSpec(t)
+
+	routeParam := regexp.MustCompile(`:([a-z_]+)`)
+	routes := map[string]bool{}
+	for _, route := range server.router.Routes() {
+		path := routeParam.ReplaceAllString(route.Path, "{$1}")
+		routes[strings.ToLower(route.Method)+" "+path] = true
+	}
+
+	documented := map[string]bool{}
+	for path, item := range doc.Paths.Map() {
+		for method := range item.Operations() {
+			documented[strings.ToLower(method)+" "+path] = true
+		}
+	}
+
+	for route := range routes {
+		require.True(t, documented[route], "%s is not in the spec", route)
+	}
+	for route := range documented {
+		require.True(t, routes[route], "%s is in the spec but not routed", route)
+	}
+}
+
+// TestOpenAPIRefs checks that every $ref in the spec resolves, the loader
+// fails on one that does not.
+func TestOpenAPIRefs(t *testing.T) {
+	_, err := openapi3.NewLoader().LoadFromData(openAPISpec)
+	require.NoError(t, err)
+}
+
+func TestOpenAPIDocs(t *testing.T) {
+	testCases := []struct {
+		name          string
+		url           string
+		swaggerUIURL  string
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Spec",
+			url:  "/openapi.json",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, openAPISpec, recorder.Body.Bytes())
+			},
+		},
+		{
+			name: "SwaggerUI",
+			url:  "/docs",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Contains(t, recorder.Body.String(), `url: "/openapi.json"`)
+				require.Contains(t, recorder.Body.String(), `src="`+defaultSwaggerUIURL+`/swagger-ui-bundle.js"`)
+			},
+		},
+		{
+			name:         "SelfHostedSwaggerUI",
+			url:          "/docs",
+			swaggerUIURL: "/assets/swagger-ui",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Contains(t, recorder.Body.String(), `href="/assets/swagger-ui/swagger-ui.css"`)
+				require.Contains(t, recorder.Body.String(), `src="/assets/swagger-ui/swagger-ui-bundle.js"`)
+				require.NotContains(t, recorder.Body.String(), "unpkg.com")
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			server := newTestServer(t, mockdb.NewMockStore(ctrl))
+			server.config.SwaggerUIURL = tc.swaggerUIURL
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, tc.url, nil)
+			require.NoError(t, err)
+
+			serve(t, server, recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
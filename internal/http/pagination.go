@@ -0,0 +1,199 @@
+package http
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	defaultPageSize    = 20
+	defaultMaxPageSize = 100
+	minCursorKeySize   = 32
+
+	// page_id/page_size limits of the deprecated offset pagination
+	legacyMinPageSize = 5
+	legacyMaxPageSize = 10
+)
+
+var errInvalidCursor = errors.New("invalid cursor")
+var errInvalidPageSize = errors.New("invalid page_size")
+var errInvalidCursorKey = fmt.Errorf("cursor secret key must be at least %d characters", minCursorKeySize)
+
+// pageQuery accepts both the cursor based pagination and the deprecated
+// page_id/page_size offset pagination. The latter is used whenever page_id
+// is present.
+type pageQuery struct {
+	Cursor   string `form:"cursor"`
+	PageSize int32  `form:"page_size" binding:"omitempty,min=1"`
+	PageID   int32  `form:"page_id" binding:"omitempty,min=1"`
+}
+
+func (q pageQuery) legacy() bool {
+	return q.PageID > 0
+}
+
+// cursor points at the last row of a page. Kind and Scope tie it to the list
+// it was issued for, so it cannot be replayed against another account. Rows
+// are ordered by CreatedAt, or the time the list is sorted by, and then by
+// ID, or Username for the rows keyed by user.
+type cursor struct {
+	Kind      string    `json:"k"`
+	Scope     int64     `json:"s,omitempty"`
+	CreatedAt time.Time `json:"t"`
+	ID        int64     `json:"i,omitempty"`
+	Username  string    `json:"u,omitempty"`
+}
+
+type listResponse[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
+}
+
+func (s *Server) encodeCursor(cur cursor) string {
+	payload, _ := json.Marshal(cur)
+	mac := hmac.New(sha256.New, []byte(s.config.CursorSecretKey))
+	mac.Write(payload)
+
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (s *Server) decodeCursor(token string, kind string, scope int64) (cursor, error) {
+	var cur cursor
+
+	encodedPayload, encodedSignature, ok := strings.Cut(token, ".")
+	if !ok {
+		return cur, errInvalidCursor
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return cur, errInvalidCursor
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil {
+		return cur, errInvalidCursor
+	}
+
+	mac := hmac.New(sha256.New, []byte(s.config.CursorSecretKey))
+	mac.Write(payload)
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return cur, errInvalidCursor
+	}
+
+	if err := json.Unmarshal(payload, &cur); err != nil {
+		return cur, errInvalidCursor
+	}
+
+	if cur.Kind != kind || cur.Scope != scope {
+		return cur, errInvalidCursor
+	}
+
+	return cur, nil
+}
+
+func (s *Server) maxPageSize() int32 {
+	if s.config.MaxPageSize > 0 {
+		return s.config.MaxPageSize
+	}
+	return defaultMaxPageSize
+}
+
+// legacyPage validates the deprecated offset pagination and returns the
+// offset to use. It writes the error response and returns false when the
+// query is invalid.
+func (s *Server) legacyPage(c *gin.Context, q pageQuery) (offset int32, ok bool) {
+	if q.PageSize < legacyMinPageSize || q.PageSize > legacyMaxPageSize {
+		errorResponse(c, http.StatusBadRequest, fmt.Errorf("%w: must be between %d and %d", errInvalidPageSize, legacyMinPageSize, legacyMaxPageSize))
+		return 0, false
+	}
+
+	c.Header("Deprecation", "true")
+	return (q.PageID - 1) * q.PageSize, true
+}
+
+// keysetPage resolves the cursor and page size of a cursor paginated
+// request. It writes the error response and returns false when the query is
+// invalid.
+func (s *Server) keysetPage(c *gin.Context, q pageQuery, kind string, scope int64) (after cursor, pageSize int32, ok bool) {
+	pageSize = q.PageSize
+	if pageSize == 0 {
+		pageSize = min(defaultPageSize, s.maxPageSize())
+	}
+
+	if pageSize > s.maxPageSize() {
+		errorResponse(c, http.StatusBadRequest, fmt.Errorf("%w: must not be greater than %d", errInvalidPageSize, s.maxPageSize()))
+		return after, 0, false
+	}
+
+	if q.Cursor != "" {
+		var err error
+		after, err = s.decodeCursor(q.Cursor, kind, scope)
+		if err != nil {
+			errorResponse(c, http.StatusBadRequest, err)
+			return after, 0, false
+		}
+	}
+
+	return after, pageSize, true
+}
+
+// afterCreatedAt is the created_at bound of the keyset query. The first page
+// starts at -infinity.
+func (cur cursor) afterCreatedAt() pgtype.Timestamptz {
+	if cur.CreatedAt.IsZero() {
+		return pgtype.Timestamptz{InfinityModifier: pgtype.NegativeInfinity, Valid: true}
+	}
+	return pgtype.Timestamptz{Time: cur.CreatedAt, Valid: true}
+}
+
+// beforeCreatedAt is the created_at bound of the keyset query of a list
+// sorted newest first. The first page starts at infinity.
+func (cur cursor) beforeCreatedAt() pgtype.Timestamptz {
+	if cur.CreatedAt.IsZero() {
+		return pgtype.Timestamptz{InfinityModifier: pgtype.Infinity, Valid: true}
+	}
+	return pgtype.Timestamptz{Time: cur.CreatedAt, Valid: true}
+}
+
+// newListResponse builds a page out of rows fetched with a limit of
+// pageSize+1, the extra row only telling whether there is a next page.
+func newListResponse[T, R any](
+	s *Server,
+	kind string,
+	scope int64,
+	rows []T,
+	pageSize int32,
+	key func(T) cursor,
+	convert func(T) R,
+) listResponse[R] {
+	res := listResponse[R]{Items: make([]R, 0, min(len(rows), int(pageSize)))}
+
+	if len(rows) > int(pageSize) {
+		rows = rows[:pageSize]
+		res.HasMore = true
+	}
+
+	for _, row := range rows {
+		res.Items = append(res.Items, convert(row))
+	}
+
+	if res.HasMore {
+		next := key(rows[len(rows)-1])
+		next.Kind, next.Scope = kind, scope
+		res.NextCursor = s.encodeCursor(next)
+	}
+
+	return res
+}
@@ -0,0 +1,236 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"github.com/vlone310/bss/internal/adapter/token/maker"
+	mockdb "github.com/vlone310/bss/internal/db/mock"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+	"github.com/vlone310/bss/testutil"
+)
+
+func TestCursor(t *testing.T) {
+	server := newTestServer(t, nil)
+
+	cur := cursor{Kind: "transfers", Scope: 7, CreatedAt: time.Now().UTC().Truncate(time.Microsecond), ID: 42}
+	token := server.encodeCursor(cur)
+
+	got, err := server.decodeCursor(token, "transfers", 7)
+	require.NoError(t, err)
+	require.Equal(t, cur.ID, got.ID)
+	require.True(t, cur.CreatedAt.Equal(got.CreatedAt))
+
+	// issued for another list
+	_, err = server.decodeCursor(token, "entries", 7)
+	require.ErrorIs(t, err, errInvalidCursor)
+
+	_, err = server.decodeCursor(token, "transfers", 8)
+	require.ErrorIs(t, err, errInvalidCursor)
+
+	// signed with another key
+	other := newTestServer(t, nil)
+	_, err = other.decodeCursor(token, "transfers", 7)
+	require.ErrorIs(t, err, errInvalidCursor)
+
+	// tampered payload
+	forged := other.encodeCursor(cursor{Kind: "transfers", Scope: 7, ID: 1})
+	_, err = server.decodeCursor(forged, "transfers", 7)
+	require.ErrorIs(t, err, errInvalidCursor)
+
+	for _, token := range []string{"", "garbage", "a.b", "!!!.???"} {
+		_, err = server.decodeCursor(token, "transfers", 7)
+		require.ErrorIs(t, err, errInvalidCursor)
+	}
+}
+
+func TestListAccountsAPI(t *testing.T) {
+	n := 6
+	owner := testutil.RandomOwner()
+	accounts := make([]db.Account, n)
+	base := time.Now().UTC().Truncate(time.Second)
+	for i := range n {
+		accounts[i] = randomAccount()
+		accounts[i].ID = int64(i + 1)
+		accounts[i].Owner = owner
+		accounts[i].CreatedAt = pgtype.Timestamptz{Time: base.Add(time.Duration(i) * time.Second), Valid: true}
+	}
+
+	testCases := []struct {
+		name          string
+		query         func(server *Server) string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker maker.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "FirstPage",
+			query: func(server *Server) string { return "page_size=5" },
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListOwnerAccountsParams{
+					Owner:      owner,
+					AfterID:    0,
+					LimitCount: 6,
+				}
+				store.EXPECT().ListOwnerAccounts(gomock.Any(), gomock.Eq(arg)).Times(1).Return(accounts, nil)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				res := requireBodyListResponse[accountResponse](t, recorder.Body)
+				require.Len(t, res.Items, 5)
+				require.True(t, res.HasMore)
+
+				cur, err := server.decodeCursor(res.NextCursor, "accounts", 0)
+				require.NoError(t, err)
+				require.Equal(t, accounts[4].ID, cur.ID)
+			},
+		},
+		{
+			name: "NextPage",
+			query: func(server *Server) string {
+				token := server.encodeCursor(cursor{Kind: "accounts", ID: accounts[4].ID})
+				return "page_size=5&cursor=" + token
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListOwnerAccountsParams{
+					Owner:      owner,
+					AfterID:    accounts[4].ID,
+					LimitCount: 6,
+				}
+				store.EXPECT().ListOwnerAccounts(gomock.Any(), gomock.Eq(arg)).Times(1).Return(accounts[5:], nil)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				res := requireBodyListResponse[accountResponse](t, recorder.Body)
+				require.Len(t, res.Items, 1)
+				require.False(t, res.HasMore)
+				require.Empty(t, res.NextCursor)
+			},
+		},
+		{
+			name:  "DefaultPageSize",
+			query: func(server *Server) string { return "" },
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListOwnerAccounts(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.ListOwnerAccountsParams) ([]db.Account, error) {
+						require.Equal(t, int32(defaultPageSize+1), arg.LimitCount)
+						return accounts, nil
+					})
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				res := requireBodyListResponse[accountResponse](t, recorder.Body)
+				require.Len(t, res.Items, n)
+				require.False(t, res.HasMore)
+			},
+		},
+		{
+			name:  "PageSizeTooLarge",
+			query: func(server *Server) string { return fmt.Sprintf("page_size=%d", defaultMaxPageSize+1) },
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListOwnerAccounts(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "CursorOfAnotherList",
+			query: func(server *Server) string {
+				return "cursor=" + server.encodeCursor(cursor{Kind: "entries", Scope: 1, ID: 1})
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListOwnerAccounts(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "LegacyPagination",
+			query: func(server *Server) string { return "page_id=2&page_size=5" },
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListAccountsParams{Owner: owner, Limit: 5, Offset: 5}
+				store.EXPECT().ListAccounts(gomock.Any(), gomock.Eq(arg)).Times(1).Return(accounts[5:], nil)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "true", recorder.Header().Get("Deprecation"))
+				testutil.RequireBodyMatch(t, recorder.Body, []accountResponse{formatter.newAccountResponse(accounts[5])})
+			},
+		},
+		{
+			name:  "LegacyPageSizeTooLarge",
+			query: func(server *Server) string { return "page_id=1&page_size=20" },
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccounts(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "NoAuthorization",
+			query:     func(server *Server) string { return "" },
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker maker.Maker) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListOwnerAccounts(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/accounts?"+tc.query(server), nil)
+			require.NoError(t, err)
+
+			if tc.setupAuth != nil {
+				tc.setupAuth(t, request, server.tokenMaker)
+			} else {
+				addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, owner, time.Minute)
+			}
+
+			serve(t, server, recorder, request)
+			tc.checkResponse(t, server, recorder)
+		})
+	}
+}
+
+func requireBodyListResponse[T any](t *testing.T, body *bytes.Buffer) listResponse[T] {
+	t.Helper()
+
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+
+	var res listResponse[T]
+	err = json.Unmarshal(data, &res)
+	require.NoError(t, err)
+
+	return res
+}
@@ -0,0 +1,318 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/vlone310/bss/internal/adapter/token/maker"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+	"github.com/vlone310/bss/internal/money"
+	"github.com/vlone310/bss/internal/webhook"
+	"github.com/vlone310/bss/util"
+)
+
+const (
+	problemContentType = "application/problem+json"
+	problemTypePrefix  = "urn:bss:problem:"
+)
+
+// problem is an RFC 7807 error response. Code is stable for clients to act
+// on, Title is the same for every response with the code and Detail
+// explains this one.
+type problem struct {
+	Type      string         `json:"type"`
+	Title     string         `json:"title"`
+	Status    int            `json:"status"`
+	Detail    string         `json:"detail,omitempty"`
+	Instance  string         `json:"instance"`
+	Code      string         `json:"code"`
+	RequestID string         `json:"request_id"`
+	Errors    []fieldProblem `json:"errors,omitempty"`
+}
+
+// fieldProblem is a field of the request that broke a rule, named as the
+// client sent it.
+type fieldProblem struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+type errorCode struct {
+	code  string
+	title string
+}
+
+// errorCodes gives the errors clients can tell apart their code. Other
+// errors get the code of the status they are sent with.
+var errorCodes = []struct {
+	err error
+	errorCode
+}{
+	{errMissingAuthHeader, errorCode{"missing_authorization", "Authorization header missing"}},
+	{errInvalidAuthHeader, errorCode{"invalid_authorization", "Invalid authorization header"}},
+	{maker.ErrExpiredToken, errorCode{"token_expired", "Access token expired"}},
+	{maker.ErrInvalidToken, errorCode{"invalid_token", "Invalid access token"}},
+	{errAdminRequired, errorCode{"admin_required", "Admin role required"}},
+	{errInvalidCredentials, errorCode{"invalid_credentials", "Invalid credentials"}},
+	{errUserExists, errorCode{"user_exists", "User already exists"}},
+	{errUserNotFound, errorCode{"user_not_found", "User not found"}},
+	{errAccountNotFound, errorCode{"account_not_found", "Account not found"}},
+	{errAccountExists, errorCode{"account_exists", "Account already exists"}},
+	{errLimitNotNegative, errorCode{"invalid_limit", "Invalid limit"}},
+	{errCurrencyNotFound, errorCode{"currency_not_found", "Currency not found"}},
+	{errCurrencyNotEnabled, errorCode{"currency_not_enabled", "Currency not enabled"}},
+	{errTransferNotFound, errorCode{"transfer_not_found", "Transfer not found"}},
+	{errTransferNotChallenged, errorCode{"transfer_not_challenged", "Transfer not waiting for confirmation"}},
+	{errTOTPEnabled, errorCode{"totp_enabled", "One-time codes already enabled"}},
+	{errTOTPNotEnrolled, errorCode{"totp_not_enrolled", "One-time codes not enrolled"}},
+	{errTOTPRequired, errorCode{"totp_required", "One-time codes required"}},
+	{util.ErrInvalidTOTP, errorCode{"invalid_otp", "Invalid one-time code"}},
+	{errFraudDecisionNotFound, errorCode{"fraud_decision_not_found", "Fraud decision not found"}},
+	{errJournalNotFound, errorCode{"journal_not_found", "Journal not found"}},
+	{errNoReconciliation, errorCode{"reconciliation_not_found", "No reconciliation yet"}},
+	{errProfileNotFound, errorCode{"profile_not_found", "Profile not found"}},
+	{errProfileNotPending, errorCode{"profile_not_pending", "Profile not pending review"}},
+	{errUnknownCountry, errorCode{"unknown_country", "Unknown country"}},
+	{errCustomerTooYoung, errorCode{"customer_too_young", "Customer too young"}},
+	{errScreeningCaseNotFound, errorCode{"screening_case_not_found", "Screening case not found"}},
+	{errWebhookNotFound, errorCode{"webhook_not_found", "Webhook not found"}},
+	{errWebhookDeliveryNotFound, errorCode{"webhook_delivery_not_found", "Webhook delivery not found"}},
+	{errWebhookDeliveryPending, errorCode{"webhook_delivery_pending", "Webhook delivery pending"}},
+	{errWebhookInsecureURL, errorCode{"insecure_webhook_url", "Insecure webhook URL"}},
+	{webhook.ErrForbiddenAddress, errorCode{"forbidden_webhook_address", "Webhook address not public"}},
+	{errInvalidCursor, errorCode{"invalid_cursor", "Invalid cursor"}},
+	{errInvalidPageSize, errorCode{"invalid_page_size", "Invalid page size"}},
+	{errInvalidTimeRange, errorCode{"invalid_time_range", "Invalid time range"}},
+	{errInvalidAmountRange, errorCode{"invalid_amount_range", "Invalid amount range"}},
+	{errInvalidLastEventID, errorCode{"invalid_last_event_id", "Invalid Last-Event-ID"}},
+	{money.ErrInvalidAmount, errorCode{"invalid_amount", "Invalid amount"}},
+	{money.ErrTooManyDecimals, errorCode{"too_many_decimals", "Too many decimals"}},
+	{money.ErrAmountOutOfRange, errorCode{"amount_out_of_range", "Amount out of range"}},
+	{money.ErrOverflow, errorCode{"amount_out_of_range", "Amount out of range"}},
+	{money.ErrMissingCurrency, errorCode{"missing_currency", "Currency missing"}},
+	{money.ErrAmountConflict, errorCode{"amount_conflict", "Amounts disagree"}},
+	{db.ErrCurrencyMismatch, errorCode{"currency_mismatch", "Currency mismatch"}},
+	{db.ErrAmountNotPositive, errorCode{"amount_not_positive", "Amount not positive"}},
+	{db.ErrInsufficientFunds, errorCode{"insufficient_funds", "Insufficient funds"}},
+	{db.ErrWithdrawalLimitReached, errorCode{"withdrawal_limit_reached", "Withdrawal limit reached"}},
+	{db.ErrMaxBalanceExceeded, errorCode{"max_balance_exceeded", "Maximum balance exceeded"}},
+	{db.ErrUnverifiedBalanceLimit, errorCode{"unverified_balance_limit", "Unverified balance limit reached"}},
+	{db.ErrNegativeBalanceNotAllowed, errorCode{"negative_balance_not_allowed", "Negative balance not allowed"}},
+	{db.ErrAccountNotActive, errorCode{"account_not_active", "Account not active"}},
+	{db.ErrInvalidStatusTransition, errorCode{"invalid_status_transition", "Invalid account status transition"}},
+	{db.ErrNonZeroBalance, errorCode{"non_zero_balance", "Balance not zero"}},
+	{db.ErrUserBlocked, errorCode{"user_blocked", "User blocked"}},
+	{db.ErrKYCRequired, errorCode{"kyc_required", "Identity verification required"}},
+	{db.ErrTransferBlocked, errorCode{"transfer_blocked", "Transfer declined"}},
+	{db.ErrFraudDecisionNotReviewable, errorCode{"fraud_decision_not_reviewable", "Fraud decision not reviewable"}},
+	{db.ErrScreeningCaseResolved, errorCode{"screening_case_resolved", "Screening case already resolved"}},
+}
+
+var (
+	validationFailed = errorCode{"validation_failed", "Invalid request"}
+	malformedJSON    = errorCode{"malformed_json", "Malformed JSON"}
+	invalidParameter = errorCode{"invalid_parameter", "Invalid parameter"}
+)
+
+// statusCodes are the codes of errors without one of their own.
+var statusCodes = map[int]errorCode{
+	http.StatusBadRequest:          {"bad_request", "Bad request"},
+	http.StatusUnauthorized:        {"unauthenticated", "Authentication required"},
+	http.StatusForbidden:           {"forbidden", "Forbidden"},
+	http.StatusNotFound:            {"not_found", "Not found"},
+	http.StatusConflict:            {"conflict", "Conflict"},
+	http.StatusInternalServerError: {"internal_error", "Internal server error"},
+}
+
+// errorResponse aborts the request with a problem for err. The details of a
+// server error are logged instead of sent.
+func errorResponse(c *gin.Context, status int, err error) {
+	res := problem{
+		Status:    status,
+		Instance:  c.Request.URL.Path,
+		RequestID: c.GetString(requestIDKey),
+	}
+
+	var code errorCode
+	if status >= http.StatusInternalServerError {
+		log.Printf("http: %s %s (request %s): %v", c.Request.Method, c.Request.URL.Path, res.RequestID, err)
+		code = statusCode(status)
+	} else {
+		code, res.Detail, res.Errors = describeError(status, err)
+	}
+
+	res.Type = problemTypePrefix + code.code
+	res.Code = code.code
+	res.Title = code.title
+
+	c.Abort()
+	c.Render(status, problemRender{res})
+}
+
+// describeError finds the code of a client error and explains it.
+func describeError(status int, err error) (errorCode, string, []fieldProblem) {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		fields := make([]fieldProblem, 0, len(validationErrs))
+		details := make([]string, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			field := fieldProblem{Field: fieldPath(fe), Rule: fe.Tag(), Message: fieldMessage(fe)}
+			fields = append(fields, field)
+			details = append(details, field.Field+" "+field.Message)
+		}
+		return validationFailed, strings.Join(details, "; "), fields
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		field := fieldProblem{Field: typeErr.Field, Rule: "type", Message: "must be " + jsonType(typeErr.Type)}
+		return validationFailed, field.Field + " " + field.Message, []fieldProblem{field}
+	}
+
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.As(err, &syntaxErr):
+		return malformedJSON, err.Error(), nil
+	case errors.Is(err, io.EOF):
+		return malformedJSON, "the request body is empty", nil
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return malformedJSON, "the request body ends early", nil
+	}
+
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		return invalidParameter, fmt.Sprintf("%q is not a valid number", numErr.Num), nil
+	}
+	var timeErr *time.ParseError
+	if errors.As(err, &timeErr) {
+		return invalidParameter, fmt.Sprintf("%q is not an RFC 3339 time", timeErr.Value), nil
+	}
+
+	for _, known := range errorCodes {
+		if errors.Is(err, known.err) {
+			return known.errorCode, err.Error(), nil
+		}
+	}
+	return statusCode(status), err.Error(), nil
+}
+
+func statusCode(status int) errorCode {
+	if code, ok := statusCodes[status]; ok {
+		return code
+	}
+	text := http.StatusText(status)
+	return errorCode{strings.ReplaceAll(strings.ToLower(text), " ", "_"), text}
+}
+
+// fieldPath is the path of the field as the client sent it, without the
+// request struct and the structs embedded in it.
+func fieldPath(fe validator.FieldError) string {
+	names := strings.Split(fe.Namespace(), ".")[1:]
+	goNames := strings.Split(fe.StructNamespace(), ".")[1:]
+
+	path := make([]string, 0, len(names))
+	for i, name := range names {
+		// a field without a name tag is an embedded struct
+		if i < len(names)-1 && name == goNames[i] {
+			continue
+		}
+		path = append(path, name)
+	}
+	return strings.Join(path, ".")
+}
+
+// fieldMessage explains the rule a field broke.
+func fieldMessage(fe validator.FieldError) string {
+	unit := ""
+	switch fe.Kind() {
+	case reflect.String:
+		unit = " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		unit = " items"
+	}
+
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "min":
+		return "must be at least " + fe.Param() + unit
+	case "max":
+		return "must be at most " + fe.Param() + unit
+	case "len":
+		return "must be exactly " + fe.Param() + unit
+	case "oneof":
+		return "must be one of " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	case "alphanum":
+		return "must contain only letters and digits"
+	case "uppercase":
+		return "must be uppercase"
+	case "email":
+		return "must be an email address"
+	case "url":
+		return "must be a URL"
+	case "e164":
+		return "must be a phone number in E.164 format"
+	case "datetime":
+		return "must be formatted as " + fe.Param()
+	case "currency":
+		return "must be an enabled currency"
+	case "event_type":
+		return "must be a known event type"
+	}
+	return "is invalid"
+}
+
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	}
+	return "an object"
+}
+
+// fieldName names the fields of requests in validation errors by the name
+// the client uses for them.
+func fieldName(field reflect.StructField) string {
+	for _, key := range []string{"json", "form", "uri"} {
+		name, _, _ := strings.Cut(field.Tag.Get(key), ",")
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return ""
+}
+
+// problemRender writes a problem with its own content type, gin.JSON
+// would send application/json.
+type problemRender struct {
+	problem problem
+}
+
+func (r problemRender) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	return json.NewEncoder(w).Encode(r.problem)
+}
+
+func (r problemRender) WriteContentType(w http.ResponseWriter) {
+	w.Header()["Content-Type"] = []string{problemContentType}
+}
@@ -0,0 +1,218 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+	"github.com/vlone310/bss/internal/adapter/token/maker"
+	mockdb "github.com/vlone310/bss/internal/db/mock"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+)
+
+func TestErrorResponse(t *testing.T) {
+	account := randomAccount()
+
+	testCases := []struct {
+		name         string
+		method       string
+		url          string
+		body         string
+		setupAuth    func(t *testing.T, request *http.Request, tokenMaker maker.Maker)
+		buildStubs   func(store *mockdb.MockStore)
+		status       int
+		checkProblem func(t *testing.T, res problem)
+	}{
+		{
+			name:   "ValidationFailed",
+			method: http.MethodPost,
+			url:    "/users",
+			body:   `{"username": "invalid-user#1", "password": "123", "full_name": "Jane Doe", "email": "jane@example.com"}`,
+			status: http.StatusBadRequest,
+			checkProblem: func(t *testing.T, res problem) {
+				require.Equal(t, "validation_failed", res.Code)
+				require.Equal(t, []fieldProblem{
+					{Field: "username", Rule: "alphanum", Message: "must contain only letters and digits"},
+					{Field: "password", Rule: "min", Message: "must be at least 6 characters"},
+				}, res.Errors)
+			},
+		},
+		{
+			name:   "NestedField",
+			method: http.MethodPut,
+			url:    "/users/me/profile",
+			body:   `{"date_of_birth": "1990-05-17", "address": {"line1": "Invalidenstraße 1", "city": "Berlin", "postal_code": "10115"}, "phone": "+4915112345678"}`,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker maker.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Owner, time.Minute)
+			},
+			status: http.StatusBadRequest,
+			checkProblem: func(t *testing.T, res problem) {
+				require.Equal(t, "validation_failed", res.Code)
+				require.Equal(t, []fieldProblem{{Field: "address.country", Rule: "required", Message: "is required"}}, res.Errors)
+			},
+		},
+		{
+			name:   "EmbeddedField",
+			method: http.MethodGet,
+			url:    "/accounts?page_size=-1",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker maker.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Owner, time.Minute)
+			},
+			status: http.StatusBadRequest,
+			checkProblem: func(t *testing.T, res problem) {
+				require.Equal(t, "validation_failed", res.Code)
+				require.Equal(t, []fieldProblem{{Field: "page_size", Rule: "min", Message: "must be at least 1"}}, res.Errors)
+			},
+		},
+		{
+			name:   "WrongType",
+			method: http.MethodPost,
+			url:    "/transfers",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker maker.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Owner, time.Minute)
+			},
+			body:   `{"from_account_id": "1", "to_account_id": 2}`,
+			status: http.StatusBadRequest,
+			checkProblem: func(t *testing.T, res problem) {
+				require.Equal(t, "validation_failed", res.Code)
+				require.Equal(t, []fieldProblem{{Field: "from_account_id", Rule: "type", Message: "must be an integer"}}, res.Errors)
+			},
+		},
+		{
+			name:   "MalformedJSON",
+			method: http.MethodPost,
+			url:    "/transfers",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker maker.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Owner, time.Minute)
+			},
+			body:   `{"from_account_id": 1,`,
+			status: http.StatusBadRequest,
+			checkProblem: func(t *testing.T, res problem) {
+				require.Equal(t, "malformed_json", res.Code)
+				require.Empty(t, res.Errors)
+			},
+		},
+		{
+			name:   "InvalidParameter",
+			method: http.MethodGet,
+			url:    "/accounts/abc",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker maker.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Owner, time.Minute)
+			},
+			status: http.StatusBadRequest,
+			checkProblem: func(t *testing.T, res problem) {
+				require.Equal(t, "invalid_parameter", res.Code)
+				require.Equal(t, `"abc" is not a valid number`, res.Detail)
+			},
+		},
+		{
+			name:   "KnownError",
+			method: http.MethodGet,
+			url:    "/accounts/1",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker maker.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Owner, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(int64(1))).Times(1).Return(db.Account{}, pgx.ErrNoRows)
+			},
+			status: http.StatusNotFound,
+			checkProblem: func(t *testing.T, res problem) {
+				require.Equal(t, "account_not_found", res.Code)
+				require.Equal(t, "Account not found", res.Title)
+				require.Equal(t, errAccountNotFound.Error(), res.Detail)
+			},
+		},
+		{
+			name:   "Middleware",
+			method: http.MethodGet,
+			url:    "/accounts/1/balance",
+			status: http.StatusUnauthorized,
+			checkProblem: func(t *testing.T, res problem) {
+				require.Equal(t, "missing_authorization", res.Code)
+			},
+		},
+		{
+			name:   "InternalError",
+			method: http.MethodGet,
+			url:    "/accounts/1",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker maker.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Owner, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.Account{}, errors.New(`ERROR: relation "accounts" does not exist (SQLSTATE 42P01)`))
+			},
+			status: http.StatusInternalServerError,
+			checkProblem: func(t *testing.T, res problem) {
+				require.Equal(t, "internal_error", res.Code)
+				require.Equal(t, "Internal server error", res.Title)
+				require.Empty(t, res.Detail)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			if tc.buildStubs != nil {
+				tc.buildStubs(store)
+			}
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(tc.method, tc.url, strings.NewReader(tc.body))
+			require.NoError(t, err)
+			request.Header.Set(requestIDHeaderKey, "req-1")
+			if tc.setupAuth != nil {
+				tc.setupAuth(t, request, server.tokenMaker)
+			}
+
+			serve(t, server, recorder, request)
+			require.Equal(t, tc.status, recorder.Code)
+			require.Equal(t, problemContentType, recorder.Header().Get("Content-Type"))
+			require.NotContains(t, recorder.Body.String(), "SQLSTATE")
+
+			var res problem
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+			require.Equal(t, tc.status, res.Status)
+			require.Equal(t, problemTypePrefix+res.Code, res.Type)
+			require.Equal(t, request.URL.Path, res.Instance)
+			require.Equal(t, "req-1", res.RequestID)
+			tc.checkProblem(t, res)
+		})
+	}
+}
+
+// TestErrorCodesDocumented keeps the codes in the spec complete.
+func TestErrorCodesDocumented(t *testing.T) {
+	doc, _ := loadSpec(t)
+	schema := doc.Components.Schemas["Problem"].Value.Properties["code"].Value
+
+	documented := map[string]bool{}
+	for _, code := range schema.Enum {
+		documented[code.(string)] = true
+	}
+
+	codes := []errorCode{validationFailed, malformedJSON, invalidParameter}
+	for _, known := range errorCodes {
+		codes = append(codes, known.errorCode)
+	}
+	for _, code := range statusCodes {
+		codes = append(codes, code)
+	}
+
+	for _, code := range codes {
+		require.True(t, documented[code.code], "%s is not in the spec", code.code)
+	}
+}
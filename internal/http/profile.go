@@ -0,0 +1,278 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+)
+
+// minCustomerAge is the age in years a customer must have reached.
+const minCustomerAge = 18
+
+var errProfileNotFound = errors.New("profile not found")
+var errProfileNotPending = errors.New("profile is not pending review")
+var errUnknownCountry = errors.New("unknown country")
+var errCustomerTooYoung = errors.New("customer must be at least 18 years old")
+
+type addressRequest struct {
+	Line1      string `json:"line1" binding:"required,max=255"`
+	Line2      string `json:"line2" binding:"max=255"`
+	City       string `json:"city" binding:"required,max=100"`
+	PostalCode string `json:"postal_code" binding:"required,max=20"`
+	// Country is the ISO 3166-1 numeric code, see GET /countries.
+	Country int32 `json:"country" binding:"required,min=1"`
+}
+
+type submitProfileRequest struct {
+	DateOfBirth string         `json:"date_of_birth" binding:"required,datetime=2006-01-02"`
+	Address     addressRequest `json:"address" binding:"required"`
+	Phone       string         `json:"phone" binding:"required,e164"`
+}
+
+type addressResponse struct {
+	Line1      string `json:"line1"`
+	Line2      string `json:"line2,omitempty"`
+	City       string `json:"city"`
+	PostalCode string `json:"postal_code"`
+	Country    int32  `json:"country"`
+}
+
+type profileResponse struct {
+	Username    string          `json:"username"`
+	DateOfBirth string          `json:"date_of_birth"`
+	Address     addressResponse `json:"address"`
+	Phone       string          `json:"phone"`
+	KYCStatus   string          `json:"kyc_status"`
+	KYCReason   string          `json:"kyc_reason,omitempty"`
+	SubmittedAt time.Time       `json:"submitted_at"`
+	ReviewedBy  string          `json:"reviewed_by,omitempty"`
+	ReviewedAt  *time.Time      `json:"reviewed_at,omitempty"`
+}
+
+func newProfileResponse(profile db.CustomerProfile) profileResponse {
+	res := profileResponse{
+		Username:    profile.Username,
+		DateOfBirth: profile.DateOfBirth.Time.Format(time.DateOnly),
+		Address: addressResponse{
+			Line1:      profile.AddressLine1,
+			Line2:      profile.AddressLine2.String,
+			City:       profile.City,
+			PostalCode: profile.PostalCode,
+			Country:    profile.CountryCode,
+		},
+		Phone:       profile.Phone,
+		KYCStatus:   profile.KycStatus,
+		KYCReason:   profile.KycReason.String,
+		SubmittedAt: profile.SubmittedAt.Time.UTC(),
+		ReviewedBy:  profile.ReviewedBy.String,
+	}
+
+	if profile.ReviewedAt.Valid {
+		reviewedAt := profile.ReviewedAt.Time.UTC()
+		res.ReviewedAt = &reviewedAt
+	}
+
+	return res
+}
+
+// submitProfile stores the caller's profile and queues it for KYC review.
+// Submitting again replaces the details and resets the review.
+func (s *Server) submitProfile(c *gin.Context) {
+	var req submitProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	// the binding already checked the format
+	dateOfBirth, _ := time.Parse(time.DateOnly, req.DateOfBirth)
+	if dateOfBirth.AddDate(minCustomerAge, 0, 0).After(time.Now()) {
+		errorResponse(c, http.StatusBadRequest, errCustomerTooYoung)
+		return
+	}
+
+	profile, err := s.store.SubmitCustomerProfile(c, db.SubmitCustomerProfileParams{
+		Username:     authPayload(c).Username,
+		DateOfBirth:  pgtype.Date{Time: dateOfBirth, Valid: true},
+		AddressLine1: req.Address.Line1,
+		AddressLine2: pgtype.Text{String: req.Address.Line2, Valid: req.Address.Line2 != ""},
+		City:         req.Address.City,
+		PostalCode:   req.Address.PostalCode,
+		CountryCode:  req.Address.Country,
+		Phone:        req.Phone,
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" && pgErr.ConstraintName == "customer_profiles_country_code_fkey" {
+			errorResponse(c, http.StatusBadRequest, errUnknownCountry)
+			return
+		}
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newProfileResponse(profile))
+}
+
+func (s *Server) getOwnProfile(c *gin.Context) {
+	s.getProfile(c, authPayload(c).Username)
+}
+
+type profileParams struct {
+	Username string `uri:"username" binding:"required,min=3,max=20,alphanum"`
+}
+
+func (s *Server) getCustomerProfile(c *gin.Context) {
+	var params profileParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	s.getProfile(c, params.Username)
+}
+
+func (s *Server) getProfile(c *gin.Context, username string) {
+	profile, err := s.store.GetCustomerProfile(c, username)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			errorResponse(c, http.StatusNotFound, errProfileNotFound)
+			return
+		}
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newProfileResponse(profile))
+}
+
+type listProfilesQuery struct {
+	Status string `form:"status" binding:"omitempty,oneof=pending verified rejected"`
+	pageQuery
+}
+
+// listCustomerProfiles is the review queue, oldest submission first. It
+// lists pending profiles unless another status is asked for.
+func (s *Server) listCustomerProfiles(c *gin.Context) {
+	var req listProfilesQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if req.Status == "" {
+		req.Status = db.KYCStatusPending
+	}
+
+	after, pageSize, ok := s.keysetPage(c, req.pageQuery, "profiles", 0)
+	if !ok {
+		return
+	}
+
+	profiles, err := s.store.ListCustomerProfiles(c, db.ListCustomerProfilesParams{
+		KycStatus:        req.Status,
+		AfterSubmittedAt: after.afterCreatedAt(),
+		AfterUsername:    after.Username,
+		LimitCount:       pageSize + 1,
+	})
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newListResponse(s, "profiles", 0, profiles, pageSize, profileKey, newProfileResponse))
+}
+
+func profileKey(profile db.CustomerProfile) cursor {
+	return cursor{CreatedAt: profile.SubmittedAt.Time, Username: profile.Username}
+}
+
+type rejectProfileRequest struct {
+	Reason string `json:"reason" binding:"required,max=255"`
+}
+
+func (s *Server) verifyProfile(c *gin.Context) {
+	var params profileParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	s.reviewProfile(c, params.Username, db.KYCStatusVerified, "")
+}
+
+func (s *Server) rejectProfile(c *gin.Context) {
+	var params profileParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var req rejectProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	s.reviewProfile(c, params.Username, db.KYCStatusRejected, req.Reason)
+}
+
+func (s *Server) reviewProfile(c *gin.Context, username string, status string, reason string) {
+	profile, err := s.store.ReviewCustomerProfile(c, db.ReviewCustomerProfileParams{
+		Username:   username,
+		KycStatus:  status,
+		KycReason:  pgtype.Text{String: reason, Valid: reason != ""},
+		ReviewedBy: pgtype.Text{String: authPayload(c).Username, Valid: true},
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// tell a missing profile apart from one already decided
+			_, err = s.store.GetCustomerProfile(c, username)
+			switch {
+			case errors.Is(err, pgx.ErrNoRows):
+				errorResponse(c, http.StatusNotFound, errProfileNotFound)
+			case err != nil:
+				errorResponse(c, http.StatusInternalServerError, err)
+			default:
+				errorResponse(c, http.StatusConflict, errProfileNotPending)
+			}
+			return
+		}
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newProfileResponse(profile))
+}
+
+type countryResponse struct {
+	Code      int32  `json:"code"`
+	Name      string `json:"name"`
+	Continent string `json:"continent"`
+}
+
+// listCountries returns the countries a customer address can be in.
+func (s *Server) listCountries(c *gin.Context) {
+	countries, err := s.store.ListCountries(c)
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	res := make([]countryResponse, 0, len(countries))
+	for _, country := range countries {
+		res = append(res, countryResponse{
+			Code:      country.Code,
+			Name:      country.Name,
+			Continent: country.ContinentName,
+		})
+	}
+
+	c.JSON(http.StatusOK, res)
+}
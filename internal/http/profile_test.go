@@ -0,0 +1,408 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"github.com/vlone310/bss/internal/adapter/token/maker"
+	mockdb "github.com/vlone310/bss/internal/db/mock"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+)
+
+func randomProfile(username string) db.CustomerProfile {
+	return db.CustomerProfile{
+		Username:     username,
+		DateOfBirth:  pgtype.Date{Time: time.Date(1990, 5, 17, 0, 0, 0, 0, time.UTC), Valid: true},
+		AddressLine1: "Invalidenstraße 1",
+		City:         "Berlin",
+		PostalCode:   "10115",
+		CountryCode:  276,
+		Phone:        "+4915112345678",
+		KycStatus:    db.KYCStatusPending,
+		SubmittedAt:  pgtype.Timestamptz{Time: time.Now(), Valid: true},
+	}
+}
+
+func TestSubmitProfileAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	profile := randomProfile(user.Username)
+
+	validBody := gin.H{
+		"date_of_birth": "1990-05-17",
+		"address": gin.H{
+			"line1":       profile.AddressLine1,
+			"city":        profile.City,
+			"postal_code": profile.PostalCode,
+			"country":     profile.CountryCode,
+		},
+		"phone": profile.Phone,
+	}
+
+	withField := func(key string, value any) gin.H {
+		body := gin.H{}
+		for k, v := range validBody {
+			body[k] = v
+		}
+		body[key] = value
+		return body
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: validBody,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.SubmitCustomerProfileParams{
+					Username:     user.Username,
+					DateOfBirth:  profile.DateOfBirth,
+					AddressLine1: profile.AddressLine1,
+					City:         profile.City,
+					PostalCode:   profile.PostalCode,
+					CountryCode:  profile.CountryCode,
+					Phone:        profile.Phone,
+				}
+				store.EXPECT().SubmitCustomerProfile(gomock.Any(), gomock.Eq(arg)).Times(1).Return(profile, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res profileResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, "1990-05-17", res.DateOfBirth)
+				require.Equal(t, db.KYCStatusPending, res.KYCStatus)
+				require.Equal(t, profile.CountryCode, res.Address.Country)
+			},
+		},
+		{
+			name: "TooYoung",
+			body: withField("date_of_birth", time.Now().AddDate(-17, 0, 0).Format(time.DateOnly)),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SubmitCustomerProfile(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidDate",
+			body: withField("date_of_birth", "17/05/1990"),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SubmitCustomerProfile(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidPhone",
+			body: withField("phone", "0151 12345678"),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SubmitCustomerProfile(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "UnknownCountry",
+			body: validBody,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SubmitCustomerProfile(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.CustomerProfile{}, &pgconn.PgError{Code: "23503", ConstraintName: "customer_profiles_country_code_fkey"})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			var body bytes.Buffer
+			require.NoError(t, json.NewEncoder(&body).Encode(tc.body))
+
+			request, err := http.NewRequest(http.MethodPut, "/users/me/profile", &body)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			serve(t, server, recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestReviewProfileAPI(t *testing.T) {
+	admin, _ := randomUser(t)
+	admin.Role = db.UserRoleAdmin
+	customer, _ := randomUser(t)
+	profile := randomProfile(customer.Username)
+
+	testCases := []struct {
+		name          string
+		action        string
+		body          gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker maker.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "Verify",
+			action: "verify",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker maker.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				verified := profile
+				verified.KycStatus = db.KYCStatusVerified
+				verified.ReviewedBy = pgtype.Text{String: admin.Username, Valid: true}
+				verified.ReviewedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+
+				arg := db.ReviewCustomerProfileParams{
+					Username:   customer.Username,
+					KycStatus:  db.KYCStatusVerified,
+					ReviewedBy: pgtype.Text{String: admin.Username, Valid: true},
+				}
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(admin.Username)).Times(1).Return(admin, nil)
+				store.EXPECT().ReviewCustomerProfile(gomock.Any(), gomock.Eq(arg)).Times(1).Return(verified, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res profileResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, db.KYCStatusVerified, res.KYCStatus)
+				require.Equal(t, admin.Username, res.ReviewedBy)
+				require.NotNil(t, res.ReviewedAt)
+			},
+		},
+		{
+			name:   "Reject",
+			action: "reject",
+			body:   gin.H{"reason": "document expired"},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker maker.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				rejected := profile
+				rejected.KycStatus = db.KYCStatusRejected
+				rejected.KycReason = pgtype.Text{String: "document expired", Valid: true}
+
+				arg := db.ReviewCustomerProfileParams{
+					Username:   customer.Username,
+					KycStatus:  db.KYCStatusRejected,
+					KycReason:  pgtype.Text{String: "document expired", Valid: true},
+					ReviewedBy: pgtype.Text{String: admin.Username, Valid: true},
+				}
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(admin.Username)).Times(1).Return(admin, nil)
+				store.EXPECT().ReviewCustomerProfile(gomock.Any(), gomock.Eq(arg)).Times(1).Return(rejected, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res profileResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, db.KYCStatusRejected, res.KYCStatus)
+				require.Equal(t, "document expired", res.KYCReason)
+			},
+		},
+		{
+			name:   "RejectWithoutReason",
+			action: "reject",
+			body:   gin.H{},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker maker.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(admin.Username)).Times(1).Return(admin, nil)
+				store.EXPECT().ReviewCustomerProfile(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "AlreadyReviewed",
+			action: "verify",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker maker.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(admin.Username)).Times(1).Return(admin, nil)
+				store.EXPECT().ReviewCustomerProfile(gomock.Any(), gomock.Any()).Times(1).Return(db.CustomerProfile{}, pgx.ErrNoRows)
+				store.EXPECT().GetCustomerProfile(gomock.Any(), gomock.Eq(customer.Username)).Times(1).Return(profile, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:   "NotFound",
+			action: "verify",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker maker.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(admin.Username)).Times(1).Return(admin, nil)
+				store.EXPECT().ReviewCustomerProfile(gomock.Any(), gomock.Any()).Times(1).Return(db.CustomerProfile{}, pgx.ErrNoRows)
+				store.EXPECT().GetCustomerProfile(gomock.Any(), gomock.Eq(customer.Username)).Times(1).Return(db.CustomerProfile{}, pgx.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "NotAdmin",
+			action: "verify",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker maker.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, customer.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(customer.Username)).Times(1).Return(customer, nil)
+				store.EXPECT().ReviewCustomerProfile(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			var body bytes.Buffer
+			if tc.body != nil {
+				require.NoError(t, json.NewEncoder(&body).Encode(tc.body))
+			}
+
+			url := fmt.Sprintf("/admin/profiles/%s/%s", customer.Username, tc.action)
+			request, err := http.NewRequest(http.MethodPost, url, &body)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			serve(t, server, recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListCustomerProfilesAPI(t *testing.T) {
+	admin, _ := randomUser(t)
+	admin.Role = db.UserRoleAdmin
+
+	profiles := make([]db.CustomerProfile, 2)
+	base := time.Now().UTC().Truncate(time.Second)
+	for i := range profiles {
+		customer, _ := randomUser(t)
+		profiles[i] = randomProfile(customer.Username)
+		profiles[i].SubmittedAt = pgtype.Timestamptz{Time: base.Add(time.Duration(i) * time.Second), Valid: true}
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+
+	first := db.ListCustomerProfilesParams{
+		KycStatus:        db.KYCStatusPending,
+		AfterSubmittedAt: pgtype.Timestamptz{InfinityModifier: pgtype.NegativeInfinity, Valid: true},
+		LimitCount:       2,
+	}
+	next := db.ListCustomerProfilesParams{
+		KycStatus:        db.KYCStatusPending,
+		AfterSubmittedAt: profiles[0].SubmittedAt,
+		AfterUsername:    profiles[0].Username,
+		LimitCount:       2,
+	}
+	store.EXPECT().GetUser(gomock.Any(), gomock.Eq(admin.Username)).Times(2).Return(admin, nil)
+	gomock.InOrder(
+		store.EXPECT().ListCustomerProfiles(gomock.Any(), gomock.Eq(first)).Times(1).Return(profiles, nil),
+		store.EXPECT().ListCustomerProfiles(gomock.Any(), gomock.Eq(next)).Times(1).Return(profiles[1:], nil),
+	)
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/admin/profiles?page_size=1", nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, admin.Username, time.Minute)
+	serve(t, server, recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	res := requireBodyListResponse[profileResponse](t, recorder.Body)
+	require.Len(t, res.Items, 1)
+	require.Equal(t, profiles[0].Username, res.Items[0].Username)
+	require.True(t, res.HasMore)
+
+	recorder = httptest.NewRecorder()
+	request, err = http.NewRequest(http.MethodGet, "/admin/profiles?page_size=1&cursor="+res.NextCursor, nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, admin.Username, time.Minute)
+	serve(t, server, recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	res = requireBodyListResponse[profileResponse](t, recorder.Body)
+	require.Len(t, res.Items, 1)
+	require.Equal(t, profiles[1].Username, res.Items[0].Username)
+	require.False(t, res.HasMore)
+}
+
+func TestListCountriesAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().ListCountries(gomock.Any()).Times(1).Return([]db.Country{
+		{Code: 276, Name: "Germany", ContinentName: "Europe"},
+		{Code: 392, Name: "Japan", ContinentName: "Asia"},
+	}, nil)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/countries", nil)
+	require.NoError(t, err)
+
+	serve(t, server, recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var res []countryResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+	require.Equal(t, []countryResponse{
+		{Code: 276, Name: "Germany", Continent: "Europe"},
+		{Code: 392, Name: "Japan", Continent: "Asia"},
+	}, res)
+}
@@ -0,0 +1,40 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+)
+
+var errNoReconciliation = errors.New("no reconciliation has run yet")
+
+type reconciliationResponse struct {
+	Run           db.ReconciliationRun           `json:"run"`
+	Discrepancies []db.ReconciliationDiscrepancy `json:"discrepancies"`
+}
+
+func (s *Server) getLatestReconciliation(c *gin.Context) {
+	run, err := s.store.GetLatestReconciliationRun(c)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			errorResponse(c, http.StatusNotFound, errNoReconciliation)
+			return
+		}
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	discrepancies, err := s.store.ListReconciliationDiscrepancies(c, run.ID)
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, reconciliationResponse{
+		Run:           run,
+		Discrepancies: discrepancies,
+	})
+}
@@ -0,0 +1,225 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+)
+
+var errScreeningCaseNotFound = errors.New("screening case not found")
+
+type screeningHitResponse struct {
+	ListSource  string  `json:"list_source"`
+	EntryUID    string  `json:"entry_uid"`
+	ListedName  string  `json:"listed_name"`
+	MatchedName string  `json:"matched_name"`
+	Score       float64 `json:"score"`
+}
+
+type screeningCaseResponse struct {
+	ID             int64                  `json:"id"`
+	Subject        string                 `json:"subject"`
+	Username       string                 `json:"username"`
+	TransferID     *int64                 `json:"transfer_id,omitempty"`
+	ScreenedName   string                 `json:"screened_name"`
+	Status         string                 `json:"status"`
+	ResolutionNote string                 `json:"resolution_note,omitempty"`
+	ResolvedBy     string                 `json:"resolved_by,omitempty"`
+	ResolvedAt     *time.Time             `json:"resolved_at,omitempty"`
+	CreatedAt      time.Time              `json:"created_at"`
+	Hits           []screeningHitResponse `json:"hits,omitempty"`
+}
+
+func newScreeningCaseResponse(screeningCase db.ScreeningCase) screeningCaseResponse {
+	res := screeningCaseResponse{
+		ID:             screeningCase.ID,
+		Subject:        screeningCase.Subject,
+		Username:       screeningCase.Username,
+		ScreenedName:   screeningCase.ScreenedName,
+		Status:         screeningCase.Status,
+		ResolutionNote: screeningCase.ResolutionNote.String,
+		ResolvedBy:     screeningCase.ResolvedBy.String,
+		CreatedAt:      screeningCase.CreatedAt.Time.UTC(),
+	}
+	if screeningCase.TransferID.Valid {
+		res.TransferID = &screeningCase.TransferID.Int64
+	}
+	if screeningCase.ResolvedAt.Valid {
+		resolvedAt := screeningCase.ResolvedAt.Time.UTC()
+		res.ResolvedAt = &resolvedAt
+	}
+	return res
+}
+
+type listScreeningCasesQuery struct {
+	Status string `form:"status" binding:"omitempty,oneof=open cleared confirmed"`
+	pageQuery
+}
+
+// listScreeningCases is the review queue, oldest case first. It lists open
+// cases unless another status is asked for.
+func (s *Server) listScreeningCases(c *gin.Context) {
+	var req listScreeningCasesQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if req.Status == "" {
+		req.Status = db.ScreeningCaseOpen
+	}
+
+	after, pageSize, ok := s.keysetPage(c, req.pageQuery, "screening_cases", 0)
+	if !ok {
+		return
+	}
+
+	cases, err := s.store.ListScreeningCases(c, db.ListScreeningCasesParams{
+		Status:         req.Status,
+		AfterCreatedAt: after.afterCreatedAt(),
+		AfterID:        after.ID,
+		LimitCount:     pageSize + 1,
+	})
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newListResponse(s, "screening_cases", 0, cases, pageSize, screeningCaseKey, newScreeningCaseResponse))
+}
+
+func screeningCaseKey(screeningCase db.ScreeningCase) cursor {
+	return cursor{CreatedAt: screeningCase.CreatedAt.Time, ID: screeningCase.ID}
+}
+
+type screeningCaseParams struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// getScreeningCase returns a case with the list entries the name matched.
+func (s *Server) getScreeningCase(c *gin.Context) {
+	var params screeningCaseParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	screeningCase, err := s.store.GetScreeningCase(c, params.ID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			errorResponse(c, http.StatusNotFound, errScreeningCaseNotFound)
+			return
+		}
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	hits, err := s.store.ListScreeningHits(c, screeningCase.ID)
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	res := newScreeningCaseResponse(screeningCase)
+	for _, hit := range hits {
+		res.Hits = append(res.Hits, screeningHitResponse{
+			ListSource:  hit.ListSource,
+			EntryUID:    hit.EntryUid,
+			ListedName:  hit.ListedName,
+			MatchedName: hit.MatchedName,
+			Score:       hit.Score,
+		})
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+type clearScreeningCaseRequest struct {
+	Note string `json:"note" binding:"max=255"`
+}
+
+type confirmScreeningCaseRequest struct {
+	Note string `json:"note" binding:"required,max=255"`
+}
+
+type resolveScreeningCaseResponse struct {
+	Case     screeningCaseResponse `json:"case"`
+	Transfer *transferResponse     `json:"transfer,omitempty"`
+	// FailureReason tells why a cleared transfer could not be booked.
+	FailureReason string `json:"failure_reason,omitempty"`
+}
+
+// clearScreeningCase marks a match as a false positive. A held transfer is
+// booked once all of its cases are cleared.
+func (s *Server) clearScreeningCase(c *gin.Context) {
+	var params screeningCaseParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var req clearScreeningCaseRequest
+	if err := bindOptionalJSON(c, &req); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	s.resolveScreeningCase(c, params.ID, db.ScreeningCaseCleared, req.Note)
+}
+
+// confirmScreeningCase marks a match as true, which fails a held transfer.
+func (s *Server) confirmScreeningCase(c *gin.Context) {
+	var params screeningCaseParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var req confirmScreeningCaseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	s.resolveScreeningCase(c, params.ID, db.ScreeningCaseConfirmed, req.Note)
+}
+
+func (s *Server) resolveScreeningCase(c *gin.Context, id int64, status string, note string) {
+	result, err := s.store.ResolveScreeningCaseTx(c, db.ResolveScreeningCaseTxParams{
+		CaseID:     id,
+		Status:     status,
+		Note:       note,
+		ResolvedBy: authPayload(c).Username,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			errorResponse(c, http.StatusNotFound, errScreeningCaseNotFound)
+		case errors.Is(err, db.ErrScreeningCaseResolved):
+			errorResponse(c, http.StatusConflict, err)
+		default:
+			errorResponse(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	res := resolveScreeningCaseResponse{
+		Case:          newScreeningCaseResponse(result.Case),
+		FailureReason: result.FailureReason,
+	}
+	if result.Transfer != nil {
+		account, err := s.store.GetAccount(c, result.Transfer.FromAccountID)
+		if err != nil {
+			errorResponse(c, http.StatusInternalServerError, err)
+			return
+		}
+		transfer := s.newTransferResponse(*result.Transfer, account.Currency)
+		res.Transfer = &transfer
+	}
+
+	c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,433 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/vlone310/bss/internal/db/mock"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+	"github.com/vlone310/bss/internal/money"
+	"github.com/vlone310/bss/internal/screening"
+	"github.com/vlone310/bss/testutil"
+	"github.com/vlone310/bss/util"
+)
+
+const sanctionedName = "Ivan Petrov"
+
+func testSanctionsList() screening.List {
+	return screening.List{
+		Source: "sdn.csv",
+		Entries: []screening.Entry{
+			{UID: "2674", Name: "PETROV, Ivan Sergeyevich", Type: "individual"},
+		},
+	}
+}
+
+func TestCreateTransferScreeningAPI(t *testing.T) {
+	from := randomAccount()
+	from.Balance = 1_000_000
+	to := randomAccount()
+	to.ID = from.ID + 1
+	to.Currency = from.Currency
+
+	sender, _ := randomUser(t)
+	sender.Username = from.Owner
+	sender.FullName = sanctionedName
+	recipient, _ := randomUser(t)
+	recipient.Username = to.Owner
+
+	amount := money.New(100, from.Currency)
+	arg := db.TransferTxParams{FromAccountID: from.ID, ToAccountID: to.ID, Amount: amount}
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Held",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(sender.Username)).Times(1).Return(sender, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(recipient.Username)).Times(1).Return(recipient, nil)
+				store.EXPECT().ListClearedScreeningEntries(gomock.Any(), gomock.Eq(sender.Username)).Times(1).
+					Return([]db.ListClearedScreeningEntriesRow{}, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().HoldTransferTx(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, hold db.HoldTransferTxParams) (db.HoldTransferTxResult, error) {
+						require.Equal(t, arg, hold.TransferTxParams)
+						require.Len(t, hold.Cases, 1)
+						require.Equal(t, sender.Username, hold.Cases[0].Username)
+						require.Equal(t, sanctionedName, hold.Cases[0].ScreenedName)
+						require.Len(t, hold.Cases[0].Matches, 1)
+						require.Equal(t, "2674", hold.Cases[0].Matches[0].EntryUID)
+
+						transfer := randomTransfer(from, to)
+						transfer.Status = db.TransferStatusPending
+						return db.HoldTransferTxResult{Transfer: transfer}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+
+				var res transferResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, db.TransferStatusPending, res.Status)
+			},
+		},
+		{
+			name: "ClearedBefore",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(sender.Username)).Times(1).Return(sender, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(recipient.Username)).Times(1).Return(recipient, nil)
+				store.EXPECT().ListClearedScreeningEntries(gomock.Any(), gomock.Eq(sender.Username)).Times(1).
+					Return([]db.ListClearedScreeningEntriesRow{{ListSource: "sdn.csv", EntryUid: "2674"}}, nil)
+				store.EXPECT().HoldTransferTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(bookedTransfer(arg), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name: "HoldRejected",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(sender.Username)).Times(1).Return(sender, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(recipient.Username)).Times(1).Return(recipient, nil)
+				store.EXPECT().ListClearedScreeningEntries(gomock.Any(), gomock.Any()).Times(1).Return(nil, nil)
+				store.EXPECT().HoldTransferTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.HoldTransferTxResult{}, db.ErrKYCRequired)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "UserError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(sender.Username)).Times(1).Return(db.User{}, pgx.ErrTxClosed)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().HoldTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(from.ID)).Times(1).Return(from, nil)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(to.ID)).Times(1).Return(to, nil)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			server.screener.Replace(testSanctionsList())
+			recorder := httptest.NewRecorder()
+
+			body := fmt.Sprintf(`{"from_account_id": %d, "to_account_id": %d, "amount": {"minor": %d, "currency": %q}}`,
+				from.ID, to.ID, amount.Amount, amount.Currency)
+			request, err := http.NewRequest(http.MethodPost, "/transfers", strings.NewReader(body))
+			require.NoError(t, err)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, from.Owner, time.Minute)
+
+			serve(t, server, recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestCreateUserScreeningAPI(t *testing.T) {
+	user, password := randomUser(t)
+	user.FullName = sanctionedName
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().CreateUserTx(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, arg db.CreateUserTxParams) (db.CreateUserTxResult, error) {
+			require.Equal(t, user.Username, arg.Username)
+			require.NoError(t, util.CheckPasswordHash(password, arg.HashedPassword))
+			// the case is opened with the user
+			require.Len(t, arg.Screening, 1)
+			require.Equal(t, "sdn.csv", arg.Screening[0].ListSource)
+			return db.CreateUserTxResult{
+				User:          user,
+				ScreeningCase: &db.ScreeningCase{ID: 1, Username: user.Username, Status: db.ScreeningCaseOpen},
+			}, nil
+		})
+
+	server := newTestServer(t, store)
+	server.screener.Replace(testSanctionsList())
+	recorder := httptest.NewRecorder()
+
+	var body bytes.Buffer
+	require.NoError(t, json.NewEncoder(&body).Encode(gin.H{
+		"username":  user.Username,
+		"password":  password,
+		"full_name": user.FullName,
+		"email":     user.Email,
+	}))
+
+	request, err := http.NewRequest(http.MethodPost, "/users", &body)
+	require.NoError(t, err)
+
+	serve(t, server, recorder, request)
+
+	// the match is not disclosed to the new user
+	require.Equal(t, http.StatusCreated, recorder.Code)
+	requireBodyMatchUser(t, recorder.Body, user)
+}
+
+func randomScreeningCase(username string, transferID int64) db.ScreeningCase {
+	return db.ScreeningCase{
+		ID:           7,
+		Subject:      db.ScreeningSubjectTransfer,
+		Username:     username,
+		TransferID:   pgtype.Int8{Int64: transferID, Valid: true},
+		ScreenedName: sanctionedName,
+		Status:       db.ScreeningCaseOpen,
+		CreatedAt:    pgtype.Timestamptz{Time: time.Now(), Valid: true},
+	}
+}
+
+func TestResolveScreeningCaseAPI(t *testing.T) {
+	admin, _ := randomUser(t)
+	admin.Role = db.UserRoleAdmin
+	from := randomAccount()
+	to := randomAccount()
+	transfer := randomTransfer(from, to)
+	screeningCase := randomScreeningCase(from.Owner, transfer.ID)
+
+	testCases := []struct {
+		name          string
+		action        string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "Clear",
+			action: "clear",
+			buildStubs: func(store *mockdb.MockStore) {
+				cleared := screeningCase
+				cleared.Status = db.ScreeningCaseCleared
+				cleared.ResolvedBy = pgtype.Text{String: admin.Username, Valid: true}
+				cleared.ResolvedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+				completed := transfer
+				completed.Status = db.TransferStatusCompleted
+
+				arg := db.ResolveScreeningCaseTxParams{
+					CaseID:     screeningCase.ID,
+					Status:     db.ScreeningCaseCleared,
+					ResolvedBy: admin.Username,
+				}
+				store.EXPECT().ResolveScreeningCaseTx(gomock.Any(), gomock.Eq(arg)).Times(1).
+					Return(db.ResolveScreeningCaseTxResult{Case: cleared, Transfer: &completed}, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(from.ID)).Times(1).Return(from, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res resolveScreeningCaseResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, db.ScreeningCaseCleared, res.Case.Status)
+				require.Equal(t, admin.Username, res.Case.ResolvedBy)
+				require.NotNil(t, res.Transfer)
+				require.Equal(t, db.TransferStatusCompleted, res.Transfer.Status)
+				require.Equal(t, from.Currency, res.Transfer.Amount.Currency)
+			},
+		},
+		{
+			name:   "Confirm",
+			action: "confirm",
+			body:   gin.H{"note": "same date of birth"},
+			buildStubs: func(store *mockdb.MockStore) {
+				confirmed := screeningCase
+				confirmed.Status = db.ScreeningCaseConfirmed
+				confirmed.ResolutionNote = pgtype.Text{String: "same date of birth", Valid: true}
+				failed := transfer
+				failed.Status = db.TransferStatusFailed
+
+				arg := db.ResolveScreeningCaseTxParams{
+					CaseID:     screeningCase.ID,
+					Status:     db.ScreeningCaseConfirmed,
+					Note:       "same date of birth",
+					ResolvedBy: admin.Username,
+				}
+				store.EXPECT().ResolveScreeningCaseTx(gomock.Any(), gomock.Eq(arg)).Times(1).
+					Return(db.ResolveScreeningCaseTxResult{Case: confirmed, Transfer: &failed}, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(from.ID)).Times(1).Return(from, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res resolveScreeningCaseResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, db.ScreeningCaseConfirmed, res.Case.Status)
+				require.Equal(t, "same date of birth", res.Case.ResolutionNote)
+				require.Equal(t, db.TransferStatusFailed, res.Transfer.Status)
+			},
+		},
+		{
+			name:   "ConfirmWithoutNote",
+			action: "confirm",
+			body:   gin.H{},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ResolveScreeningCaseTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "AlreadyResolved",
+			action: "clear",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ResolveScreeningCaseTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.ResolveScreeningCaseTxResult{}, fmt.Errorf("case [7] is cleared: %w", db.ErrScreeningCaseResolved))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:   "NotFound",
+			action: "clear",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ResolveScreeningCaseTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.ResolveScreeningCaseTxResult{}, pgx.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetUser(gomock.Any(), gomock.Eq(admin.Username)).Times(1).Return(admin, nil)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			var body bytes.Buffer
+			if tc.body != nil {
+				require.NoError(t, json.NewEncoder(&body).Encode(tc.body))
+			}
+
+			url := fmt.Sprintf("/admin/screening/cases/%d/%s", screeningCase.ID, tc.action)
+			request, err := http.NewRequest(http.MethodPost, url, &body)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, admin.Username, time.Minute)
+			serve(t, server, recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestGetScreeningCaseAPI(t *testing.T) {
+	admin, _ := randomUser(t)
+	admin.Role = db.UserRoleAdmin
+	screeningCase := randomScreeningCase(testutil.RandomOwner(), 1)
+	hit := db.ScreeningHit{
+		CaseID:      screeningCase.ID,
+		ListSource:  "sdn.csv",
+		EntryUid:    "2674",
+		ListedName:  "PETROV, Ivan Sergeyevich",
+		MatchedName: "PETROV, Ivan Sergeyevich",
+		Score:       0.95,
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetUser(gomock.Any(), gomock.Eq(admin.Username)).Times(1).Return(admin, nil)
+	store.EXPECT().GetScreeningCase(gomock.Any(), gomock.Eq(screeningCase.ID)).Times(1).Return(screeningCase, nil)
+	store.EXPECT().ListScreeningHits(gomock.Any(), gomock.Eq(screeningCase.ID)).Times(1).Return([]db.ScreeningHit{hit}, nil)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/admin/screening/cases/%d", screeningCase.ID), nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, admin.Username, time.Minute)
+	serve(t, server, recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var res screeningCaseResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+	require.Equal(t, db.ScreeningCaseOpen, res.Status)
+	require.Equal(t, int64(1), *res.TransferID)
+	require.Len(t, res.Hits, 1)
+	require.Equal(t, hit.EntryUid, res.Hits[0].EntryUID)
+}
+
+func TestListScreeningCasesAPI(t *testing.T) {
+	admin, _ := randomUser(t)
+	admin.Role = db.UserRoleAdmin
+
+	cases := make([]db.ScreeningCase, 2)
+	base := time.Now().UTC().Truncate(time.Second)
+	for i := range cases {
+		cases[i] = randomScreeningCase(testutil.RandomOwner(), int64(i+1))
+		cases[i].ID = int64(i + 1)
+		cases[i].CreatedAt = pgtype.Timestamptz{Time: base.Add(time.Duration(i) * time.Second), Valid: true}
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	arg := db.ListScreeningCasesParams{
+		Status:         db.ScreeningCaseOpen,
+		AfterCreatedAt: pgtype.Timestamptz{InfinityModifier: pgtype.NegativeInfinity, Valid: true},
+		LimitCount:     2,
+	}
+	store.EXPECT().GetUser(gomock.Any(), gomock.Eq(admin.Username)).Times(1).Return(admin, nil)
+	store.EXPECT().ListScreeningCases(gomock.Any(), gomock.Eq(arg)).Times(1).Return(cases, nil)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/admin/screening/cases?page_size=1", nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, admin.Username, time.Minute)
+	serve(t, server, recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	res := requireBodyListResponse[screeningCaseResponse](t, recorder.Body)
+	require.Len(t, res.Items, 1)
+	require.Equal(t, cases[0].ID, res.Items[0].ID)
+	require.True(t, res.HasMore)
+
+	cur, err := server.decodeCursor(res.NextCursor, "screening_cases", 0)
+	require.NoError(t, err)
+	require.Equal(t, cases[0].ID, cur.ID)
+	require.True(t, cases[0].CreatedAt.Time.Equal(cur.CreatedAt))
+}
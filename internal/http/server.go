@@ -1,40 +1,222 @@
 package http
 
 import (
+	"cmp"
+	"context"
+	"errors"
+	"expvar"
 	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
+	"github.com/vlone310/bss/config"
+	"github.com/vlone310/bss/internal/adapter/token/maker"
+	"github.com/vlone310/bss/internal/adapter/token/paseto"
+	"github.com/vlone310/bss/internal/currency"
 	db "github.com/vlone310/bss/internal/db/sqlc"
+	"github.com/vlone310/bss/internal/fraud"
+	"github.com/vlone310/bss/internal/screening"
+	"github.com/vlone310/bss/internal/stream"
+	"github.com/vlone310/bss/internal/transfer"
+	"github.com/vlone310/bss/internal/worker"
 )
 
 type Server struct {
-	store  db.Store
-	router *gin.Engine
+	config     config.Config
+	store      db.Store
+	tokenMaker maker.Maker
+	currencies *currency.Registry
+	screener   *screening.Screener
+	transfers  *transfer.Service
+	fraud      *fraud.Engine
+	hub        *stream.Hub
+	workers    []*worker.Periodic
+	router     *gin.Engine
+	httpServer *http.Server
+
+	// closing is closed once the server shuts down
+	closing   chan struct{}
+	closeOnce sync.Once
 }
 
-func NewServer(store db.Store) *Server {
-	server := &Server{store: store}
-	r := gin.Default()
+const (
+	defaultReadTimeout  = 15 * time.Second
+	defaultWriteTimeout = 30 * time.Second
+	defaultIdleTimeout  = 2 * time.Minute
+)
+
+var errIncompleteTLS = errors.New("TLS needs both a certificate and a key file")
+
+func NewServer(config config.Config, store db.Store, currencies *currency.Registry, screener *screening.Screener, fraudEngine *fraud.Engine, hub *stream.Hub) (*Server, error) {
+	tokenMaker, err := paseto.NewPasetoMaker(config.TokenSymmetricKey)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create token maker: %w", err)
+	}
+
+	if len(config.CursorSecretKey) < minCursorKeySize {
+		return nil, errInvalidCursorKey
+	}
+
+	if (config.TLSCertFile == "") != (config.TLSKeyFile == "") {
+		return nil, errIncompleteTLS
+	}
+
+	server := &Server{
+		config:     config,
+		store:      store,
+		tokenMaker: tokenMaker,
+		currencies: currencies,
+		screener:   screener,
+		transfers:  transfer.NewService(store, screener),
+		fraud:      fraudEngine,
+		hub:        hub,
+		closing:    make(chan struct{}),
+	}
+	r := gin.New()
+	// the probes would drown the request log
+	r.Use(gin.LoggerWithConfig(gin.LoggerConfig{SkipPaths: []string{"/healthz", "/readyz"}}), gin.Recovery())
+	// the store reads the audit details of a call from the request context
+	r.ContextWithFallback = true
+	r.Use(requestMiddleware())
 
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
-		v.RegisterValidation("currency", validCurrency)
+		v.RegisterValidation("currency", validCurrency(currencies))
+		v.RegisterValidation("event_type", validEventType)
+		v.RegisterTagNameFunc(fieldName)
 	}
 
 	r.POST("/users", server.createUser)
+	r.POST("/users/login", server.loginUser)
+
+	r.GET("/currencies", server.listCurrencies)
+	r.GET("/countries", server.listCountries)
 
-	// adding routes
-	r.POST("/accounts", server.createAccount)
-	r.GET("/accounts/:id", server.getAccountByID)
-	r.GET("/accounts", server.listAccounts)
+	r.GET("/openapi.json", server.getOpenAPI)
+	r.GET("/docs", server.getDocs)
+
+	r.GET("/healthz", server.getHealthz)
+	r.GET("/readyz", server.getReadyz)
+
+	authRoutes := r.Group("/").Use(authMiddleware(server.tokenMaker))
+	authRoutes.POST("/accounts", server.createAccount)
+	authRoutes.GET("/accounts/:id", server.getAccountByID)
+	authRoutes.GET("/accounts", server.listAccounts)
+	authRoutes.GET("/accounts/:id/entries", server.listAccountEntries)
+	authRoutes.GET("/accounts/:id/transfers", server.listAccountTransfers)
+	authRoutes.GET("/accounts/:id/balance", server.getAccountBalance)
+	authRoutes.GET("/accounts/:id/statement", server.getAccountStatement)
+	authRoutes.GET("/accounts/:id/export", server.exportAccountStatement)
+	authRoutes.GET("/accounts/:id/events", server.streamAccountEvents)
+	authRoutes.POST("/accounts/:id/close", server.closeAccount)
+	authRoutes.POST("/transfers", server.createTransfer)
+	authRoutes.GET("/transfers/:id", server.getTransfer)
+	authRoutes.POST("/transfers/:id/confirm", server.confirmTransfer)
+	authRoutes.GET("/users/me/profile", server.getOwnProfile)
+	authRoutes.PUT("/users/me/profile", server.submitProfile)
+	authRoutes.POST("/users/me/totp", server.enrollTOTP)
+	authRoutes.POST("/users/me/totp/verify", server.verifyTOTP)
+	authRoutes.POST("/webhooks", server.createWebhook)
+	authRoutes.GET("/webhooks", server.listWebhooks)
+	authRoutes.DELETE("/webhooks/:id", server.deleteWebhook)
+	authRoutes.GET("/webhooks/:id/deliveries", server.listWebhookDeliveries)
+	authRoutes.GET("/webhooks/:id/deliveries/:delivery_id", server.getWebhookDelivery)
+	authRoutes.POST("/webhooks/:id/deliveries/:delivery_id/redeliver", server.redeliverWebhook)
+
+	adminRoutes := r.Group("/admin").Use(authMiddleware(server.tokenMaker), adminMiddleware(server.store))
+	adminRoutes.POST("/accounts/:id/freeze", server.freezeAccount)
+	adminRoutes.POST("/accounts/:id/unfreeze", server.unfreezeAccount)
+	adminRoutes.POST("/accounts/:id/limit", server.setAccountLimit)
+	adminRoutes.GET("/house-accounts", server.listHouseAccounts)
+	adminRoutes.GET("/audit-events", server.listAuditEvents)
+	adminRoutes.GET("/journals/:id", server.getJournal)
+	adminRoutes.GET("/reconciliation/latest", server.getLatestReconciliation)
+	adminRoutes.GET("/currencies", server.listAllCurrencies)
+	adminRoutes.POST("/currencies/:code/enable", server.enableCurrency)
+	adminRoutes.POST("/currencies/:code/disable", server.disableCurrency)
+	adminRoutes.GET("/profiles", server.listCustomerProfiles)
+	adminRoutes.GET("/profiles/:username", server.getCustomerProfile)
+	adminRoutes.POST("/profiles/:username/verify", server.verifyProfile)
+	adminRoutes.POST("/profiles/:username/reject", server.rejectProfile)
+	adminRoutes.GET("/screening/cases", server.listScreeningCases)
+	adminRoutes.GET("/screening/cases/:id", server.getScreeningCase)
+	adminRoutes.POST("/screening/cases/:id/clear", server.clearScreeningCase)
+	adminRoutes.POST("/screening/cases/:id/confirm", server.confirmScreeningCase)
+	adminRoutes.GET("/fraud/rules", server.listFraudRules)
+	adminRoutes.PUT("/fraud/rules/:name", server.putFraudRule)
+	adminRoutes.GET("/fraud/decisions", server.listFraudDecisions)
+	adminRoutes.GET("/fraud/decisions/:id", server.getFraudDecision)
+	adminRoutes.POST("/fraud/decisions/:id/approve", server.approveFraudDecision)
+	adminRoutes.POST("/fraud/decisions/:id/reject", server.rejectFraudDecision)
+	// expvar metrics, such as the reconciliation results, with the command
+	// line and memory stats of the process
+	adminRoutes.GET("/debug/vars", gin.WrapH(expvar.Handler()))
 
-	r.POST("/transfers", server.createTransfer)
 	server.router = r
-	return server
+	server.httpServer = NewHTTPServer(config, config.ServerAddr, r)
+	return server, nil
+}
+
+// Serve serves the API on lis until the server is shut down, over TLS when
+// the config has a certificate. It returns http.ErrServerClosed after
+// Shutdown.
+func (s *Server) Serve(lis net.Listener) error {
+	return serveOn(s.httpServer, lis, s.config)
 }
 
 func (s *Server) ServeHTTP(addr string) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
 	fmt.Println("Server is running on", addr)
-	return s.router.Run(addr)
+	return s.Serve(lis)
+}
+
+// Drain fails the readiness check from now on, so that the load balancer
+// stops sending requests before Shutdown stops taking them. Event streams
+// never finish, they are ended right away.
+func (s *Server) Drain() {
+	s.closeOnce.Do(func() { close(s.closing) })
+}
+
+// Shutdown drains the server, stops taking requests and waits until the
+// requests in flight are done or ctx is.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.Drain()
+	return s.httpServer.Shutdown(ctx)
+}
+
+// NewHTTPServer returns a server for handler with the timeouts and the
+// header limit of the config.
+func NewHTTPServer(config config.Config, addr string, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:           addr,
+		Handler:        handler,
+		ReadTimeout:    cmp.Or(config.ServerReadTimeout, defaultReadTimeout),
+		WriteTimeout:   cmp.Or(config.ServerWriteTimeout, defaultWriteTimeout),
+		IdleTimeout:    cmp.Or(config.ServerIdleTimeout, defaultIdleTimeout),
+		MaxHeaderBytes: cmp.Or(config.ServerMaxHeaderBytes, http.DefaultMaxHeaderBytes),
+	}
+}
+
+// ListenAndServe serves srv on its address like Server.Serve does.
+func ListenAndServe(srv *http.Server, config config.Config) error {
+	lis, err := net.Listen("tcp", srv.Addr)
+	if err != nil {
+		return err
+	}
+	return serveOn(srv, lis, config)
+}
+
+func serveOn(srv *http.Server, lis net.Listener, config config.Config) error {
+	if config.TLSCertFile != "" {
+		return srv.ServeTLS(lis, config.TLSCertFile, config.TLSKeyFile)
+	}
+	return srv.Serve(lis)
 }
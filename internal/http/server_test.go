@@ -0,0 +1,84 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"github.com/vlone310/bss/config"
+	mockdb "github.com/vlone310/bss/internal/db/mock"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+	"github.com/vlone310/bss/internal/fraud"
+	"github.com/vlone310/bss/internal/screening"
+	"github.com/vlone310/bss/internal/stream"
+	"github.com/vlone310/bss/testutil"
+)
+
+func TestServerShutdown(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount()
+	account.Owner = user.Username
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+
+	config := config.Config{
+		TokenSymmetricKey:   testutil.RandomString(32),
+		CursorSecretKey:     testutil.RandomString(32),
+		AccessTokenDuration: time.Minute,
+		ServerWriteTimeout:  100 * time.Millisecond,
+	}
+	server, err := NewServer(config, store, testCurrencies(), screening.NewScreener(0, 0), fraud.NewEngine(fraud.Thresholds{}), stream.NewHub(0))
+	require.NoError(t, err)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	serveErrs := make(chan error, 1)
+	go func() { serveErrs <- server.Serve(lis) }()
+
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("http://%s/accounts/%d/events", lis.Addr(), account.ID), nil)
+	require.NoError(t, err)
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+
+	res, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	r := bufio.NewReader(res.Body)
+	requireBalanceEvent(t, readEvent(t, r), account, account.Balance)
+
+	// the write timeout of the server does not end the stream
+	time.Sleep(3 * config.ServerWriteTimeout)
+	server.hub.Publish(db.EntryNotification{Entry: streamEntry(account, 7), Balance: account.Balance + 100})
+	requireEntryEvent(t, readEvent(t, r), 7)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, server.Shutdown(ctx))
+	require.ErrorIs(t, <-serveErrs, http.ErrServerClosed)
+
+	_, err = io.ReadAll(r)
+	require.NoError(t, err)
+}
+
+func TestNewServerIncompleteTLS(t *testing.T) {
+	config := config.Config{
+		TokenSymmetricKey: testutil.RandomString(32),
+		CursorSecretKey:   testutil.RandomString(32),
+		TLSCertFile:       "server.crt",
+	}
+
+	_, err := NewServer(config, nil, testCurrencies(), screening.NewScreener(0, 0), fraud.NewEngine(fraud.Thresholds{}), stream.NewHub(0))
+	require.ErrorIs(t, err, errIncompleteTLS)
+}
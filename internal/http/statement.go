@@ -0,0 +1,115 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+	"github.com/vlone310/bss/internal/money"
+)
+
+type statementQuery struct {
+	From time.Time `form:"from" binding:"required"`
+	To   time.Time `form:"to" binding:"required"`
+}
+
+type statementCounterparty struct {
+	AccountID int64  `json:"account_id"`
+	Owner     string `json:"owner"`
+}
+
+type statementLineResponse struct {
+	EntryID        int64                  `json:"entry_id"`
+	TransferID     *int64                 `json:"transfer_id,omitempty"`
+	Amount         money.JSON             `json:"amount"`
+	RunningBalance money.JSON             `json:"running_balance"`
+	Counterparty   *statementCounterparty `json:"counterparty,omitempty"`
+	CreatedAt      time.Time              `json:"created_at"`
+}
+
+type statementResponse struct {
+	AccountID      int64                   `json:"account_id"`
+	Currency       string                  `json:"currency"`
+	From           time.Time               `json:"from"`
+	To             time.Time               `json:"to"`
+	OpeningBalance money.JSON              `json:"opening_balance"`
+	ClosingBalance money.JSON              `json:"closing_balance"`
+	TotalDebits    money.JSON              `json:"total_debits"`
+	TotalCredits   money.JSON              `json:"total_credits"`
+	Entries        []statementLineResponse `json:"entries"`
+}
+
+func (s *Server) getAccountStatement(c *gin.Context) {
+	var params getAccountParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var req statementQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if !req.To.After(req.From) {
+		errorResponse(c, http.StatusBadRequest, errInvalidTimeRange)
+		return
+	}
+
+	account, ok := s.getOwnedAccount(c, params.ID)
+	if !ok {
+		return
+	}
+
+	statement, err := s.store.AccountStatement(c, db.AccountStatementParams{
+		AccountID: account.ID,
+		From:      req.From,
+		To:        req.To,
+	})
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	res := statementResponse{
+		AccountID:      account.ID,
+		Currency:       account.Currency,
+		From:           req.From.UTC(),
+		To:             req.To.UTC(),
+		OpeningBalance: s.moneyJSON(statement.OpeningBalance, account.Currency),
+		ClosingBalance: s.moneyJSON(statement.ClosingBalance, account.Currency),
+		TotalDebits:    s.moneyJSON(statement.TotalDebits, account.Currency),
+		TotalCredits:   s.moneyJSON(statement.TotalCredits, account.Currency),
+		Entries:        make([]statementLineResponse, 0, len(statement.Lines)),
+	}
+
+	for _, line := range statement.Lines {
+		res.Entries = append(res.Entries, s.newStatementLineResponse(line, account.Currency))
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+func (s *Server) newStatementLineResponse(line db.StatementLine, currency string) statementLineResponse {
+	res := statementLineResponse{
+		EntryID:        line.ID,
+		Amount:         s.moneyJSON(line.AmountCents, currency),
+		RunningBalance: s.moneyJSON(line.RunningBalance, currency),
+		CreatedAt:      line.CreatedAt.Time.UTC(),
+	}
+
+	if line.TransferID.Valid {
+		res.TransferID = &line.TransferID.Int64
+	}
+
+	if line.CounterpartyAccountID.Valid {
+		res.Counterparty = &statementCounterparty{
+			AccountID: line.CounterpartyAccountID.Int64,
+			Owner:     line.CounterpartyOwner.String,
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,187 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+	"github.com/vlone310/bss/internal/money"
+)
+
+const (
+	lastEventIDHeaderKey = "Last-Event-ID"
+	// streamHeartbeat keeps idle streams open through proxies.
+	streamHeartbeat = 15 * time.Second
+	// streamBackfillSize is how many entries a resumed stream reads at once.
+	streamBackfillSize = 100
+	// streamWriteTimeout bounds each write to a stream, in place of the write
+	// timeout of the server, which would end every stream.
+	streamWriteTimeout = 2 * streamHeartbeat
+)
+
+var errInvalidLastEventID = errors.New("Last-Event-ID must be the id of an entry")
+
+type balanceEventResponse struct {
+	AccountID int64      `json:"account_id"`
+	Balance   money.JSON `json:"balance"`
+}
+
+// streamAccountEvents streams the entries of an account as Server-Sent
+// Events. Every "entry" event has the id of the entry and is followed by a
+// "balance" event. A client that reconnects with Last-Event-ID first gets
+// the entries it missed, then the current balance.
+func (s *Server) streamAccountEvents(c *gin.Context) {
+	var params getAccountParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var lastID int64
+	resume := c.GetHeader(lastEventIDHeaderKey)
+	if resume != "" {
+		var err error
+		lastID, err = strconv.ParseInt(resume, 10, 64)
+		if err != nil || lastID < 0 {
+			errorResponse(c, http.StatusBadRequest, errInvalidLastEventID)
+			return
+		}
+	}
+
+	// subscribe before reading the account and the backfill, so that no
+	// entry falls in between; an entry the balance or the backfill already
+	// has is sent again at most, with its balance
+	sub := s.hub.Subscribe(params.ID)
+	defer sub.Close()
+
+	account, ok := s.getOwnedAccount(c, params.ID)
+	if !ok {
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	w := streamWriter{ResponseWriter: c.Writer, rc: http.NewResponseController(c.Writer)}
+	balance := account.Balance
+	if resume != "" {
+		for {
+			entries, err := s.store.ListEntriesAfterID(c, db.ListEntriesAfterIDParams{
+				AccountID:  account.ID,
+				AfterID:    lastID,
+				LimitCount: streamBackfillSize,
+			})
+			if err != nil {
+				// the stream has started, the client retries with the last id
+				return
+			}
+			for _, entry := range entries {
+				if err := writeEvent(w, "entry", strconv.FormatInt(entry.ID, 10), s.newEntryResponse(entry, account.Currency)); err != nil {
+					return
+				}
+				lastID = entry.ID
+			}
+			if len(entries) < streamBackfillSize {
+				break
+			}
+		}
+
+		// the balance has moved since the account was read
+		current, err := s.store.GetAccount(c, account.ID)
+		if err != nil {
+			return
+		}
+		balance = current.Balance
+	}
+
+	if err := writeEvent(w, "balance", "", balanceEventResponse{
+		AccountID: account.ID,
+		Balance:   s.moneyJSON(balance, account.Currency),
+	}); err != nil {
+		return
+	}
+	w.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+
+		case <-s.closing:
+			// the client reconnects to another instance with its last id
+			return
+
+		case <-heartbeat.C:
+			if _, err := io.WriteString(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			w.Flush()
+
+		case n, ok := <-sub.C:
+			if !ok {
+				// dropped, the client resumes from lastID
+				return
+			}
+			if n.Entry.ID <= lastID {
+				continue
+			}
+
+			if err := writeEvent(w, "entry", strconv.FormatInt(n.Entry.ID, 10), s.newEntryResponse(n.Entry, account.Currency)); err != nil {
+				return
+			}
+			if err := writeEvent(w, "balance", "", balanceEventResponse{
+				AccountID: account.ID,
+				Balance:   s.moneyJSON(n.Balance, account.Currency),
+			}); err != nil {
+				return
+			}
+			lastID = n.Entry.ID
+			w.Flush()
+		}
+	}
+}
+
+// writeEvent writes a Server-Sent Event. An event without an id leaves the
+// Last-Event-ID of the client as it was.
+func writeEvent(w io.Writer, name string, id string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	if id != "" {
+		if _, err := fmt.Fprintf(w, "id: %s\n", id); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", name, payload)
+	return err
+}
+
+// streamWriter gives every write to a stream a deadline of its own.
+type streamWriter struct {
+	gin.ResponseWriter
+	rc *http.ResponseController
+}
+
+func (w streamWriter) Write(p []byte) (int, error) {
+	w.rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+	return w.ResponseWriter.Write(p)
+}
+
+func (w streamWriter) WriteString(s string) (int, error) {
+	w.rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,241 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/vlone310/bss/internal/db/mock"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+)
+
+type sseEvent struct {
+	ID   string
+	Name string
+	Data string
+}
+
+// readEvent reads the next event of a stream, skipping comments.
+func readEvent(t *testing.T, r *bufio.Reader) sseEvent {
+	t.Helper()
+
+	var e sseEvent
+	for {
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+
+		switch {
+		case line == "" && e.Name != "":
+			return e
+		case strings.HasPrefix(line, "id: "):
+			e.ID = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			e.Name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			e.Data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func requireBalanceEvent(t *testing.T, e sseEvent, account db.Account, balance int64) {
+	t.Helper()
+
+	require.Equal(t, "balance", e.Name)
+	require.Empty(t, e.ID)
+
+	var res balanceEventResponse
+	require.NoError(t, json.Unmarshal([]byte(e.Data), &res))
+	require.Equal(t, account.ID, res.AccountID)
+	require.Equal(t, formatter.moneyJSON(balance, account.Currency), res.Balance)
+}
+
+func requireEntryEvent(t *testing.T, e sseEvent, entryID int64) {
+	t.Helper()
+
+	require.Equal(t, "entry", e.Name)
+	require.Equal(t, fmt.Sprint(entryID), e.ID)
+
+	var res entryResponse
+	require.NoError(t, json.Unmarshal([]byte(e.Data), &res))
+	require.Equal(t, entryID, res.ID)
+}
+
+func streamEntry(account db.Account, id int64) db.Entry {
+	return db.Entry{
+		ID:          id,
+		AccountID:   account.ID,
+		AmountCents: 100,
+		JournalID:   id,
+		CreatedAt:   pgtype.Timestamptz{Time: time.Now(), Valid: true},
+	}
+}
+
+func TestStreamAccountEventsAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount()
+	account.Owner = user.Username
+
+	testCases := []struct {
+		name        string
+		lastEventID string
+		buildStubs  func(store *mockdb.MockStore, server *Server)
+		checkStream func(t *testing.T, server *Server, r *bufio.Reader)
+	}{
+		{
+			name: "Live",
+			buildStubs: func(store *mockdb.MockStore, server *Server) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ListEntriesAfterID(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkStream: func(t *testing.T, server *Server, r *bufio.Reader) {
+				requireBalanceEvent(t, readEvent(t, r), account, account.Balance)
+
+				server.hub.Publish(db.EntryNotification{Entry: streamEntry(account, 7), Balance: account.Balance + 100})
+				requireEntryEvent(t, readEvent(t, r), 7)
+				requireBalanceEvent(t, readEvent(t, r), account, account.Balance+100)
+			},
+		},
+		{
+			name: "EntryWhileReading",
+			buildStubs: func(store *mockdb.MockStore, server *Server) {
+				current := account
+				current.Balance += 100
+
+				// the entry is posted after the stream subscribed, but before
+				// it read the balance
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).
+					DoAndReturn(func(_ context.Context, _ int64) (db.Account, error) {
+						server.hub.Publish(db.EntryNotification{Entry: streamEntry(account, 7), Balance: current.Balance})
+						return current, nil
+					})
+			},
+			checkStream: func(t *testing.T, server *Server, r *bufio.Reader) {
+				requireBalanceEvent(t, readEvent(t, r), account, account.Balance+100)
+				requireEntryEvent(t, readEvent(t, r), 7)
+				requireBalanceEvent(t, readEvent(t, r), account, account.Balance+100)
+			},
+		},
+		{
+			name:        "Resume",
+			lastEventID: "3",
+			buildStubs: func(store *mockdb.MockStore, server *Server) {
+				current := account
+				current.Balance += 200
+
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				arg := db.ListEntriesAfterIDParams{AccountID: account.ID, AfterID: 3, LimitCount: streamBackfillSize}
+				store.EXPECT().ListEntriesAfterID(gomock.Any(), gomock.Eq(arg)).Times(1).
+					Return([]db.Entry{streamEntry(account, 4), streamEntry(account, 5)}, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(current, nil)
+			},
+			checkStream: func(t *testing.T, server *Server, r *bufio.Reader) {
+				requireEntryEvent(t, readEvent(t, r), 4)
+				requireEntryEvent(t, readEvent(t, r), 5)
+				requireBalanceEvent(t, readEvent(t, r), account, account.Balance+200)
+
+				// an entry the backfill sent is not sent again
+				server.hub.Publish(db.EntryNotification{Entry: streamEntry(account, 5), Balance: account.Balance + 200})
+				server.hub.Publish(db.EntryNotification{Entry: streamEntry(account, 6), Balance: account.Balance + 300})
+				requireEntryEvent(t, readEvent(t, r), 6)
+				requireBalanceEvent(t, readEvent(t, r), account, account.Balance+300)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			server := newTestServer(t, store)
+			tc.buildStubs(store, server)
+
+			ts := httptest.NewServer(server.router)
+			defer ts.Close()
+
+			request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/accounts/%d/events", ts.URL, account.ID), nil)
+			require.NoError(t, err)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			if tc.lastEventID != "" {
+				request.Header.Set(lastEventIDHeaderKey, tc.lastEventID)
+			}
+
+			res, err := ts.Client().Do(request)
+			require.NoError(t, err)
+			defer res.Body.Close()
+
+			require.Equal(t, http.StatusOK, res.StatusCode)
+			require.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+
+			r := bufio.NewReader(res.Body)
+			tc.checkStream(t, server, r)
+		})
+	}
+}
+
+func TestStreamAccountEventsRejectedAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount()
+	account.Owner = user.Username
+
+	testCases := []struct {
+		name         string
+		username     string
+		lastEventID  string
+		buildStubs   func(store *mockdb.MockStore)
+		expectedCode int
+	}{
+		{
+			name:        "InvalidLastEventID",
+			username:    user.Username,
+			lastEventID: "abc",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:     "NotOwner",
+			username: "someone-else",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/accounts/%d/events", account.ID), nil)
+			require.NoError(t, err)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+			if tc.lastEventID != "" {
+				request.Header.Set(lastEventIDHeaderKey, tc.lastEventID)
+			}
+
+			serve(t, server, recorder, request)
+			require.Equal(t, tc.expectedCode, recorder.Code)
+			require.Zero(t, server.hub.Subscribers(account.ID))
+		})
+	}
+}
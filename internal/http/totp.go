@@ -0,0 +1,133 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+	"github.com/vlone310/bss/util"
+)
+
+// totpIssuer names the service in the authenticator apps.
+const totpIssuer = "bss"
+
+var errTOTPEnabled = errors.New("one-time codes are already enabled")
+var errTOTPNotEnrolled = errors.New("no one-time code secret to verify, enroll first")
+var errTOTPRequired = errors.New("one-time codes are not enabled")
+
+type enrollTOTPResponse struct {
+	Secret string `json:"secret"`
+	// URL is the otpauth URL to show as a QR code.
+	URL string `json:"url"`
+}
+
+// enrollTOTP gives the user a new secret for an authenticator app. It is
+// not used before verifyTOTP, enrolling again replaces a secret that was
+// not verified yet.
+func (s *Server) enrollTOTP(c *gin.Context) {
+	secret, err := util.NewTOTPSecret()
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	username := authPayload(c).Username
+	_, err = s.store.SetUserTOTPSecret(c, db.SetUserTOTPSecretParams{
+		Username:   username,
+		TotpSecret: secret,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			errorResponse(c, http.StatusConflict, errTOTPEnabled)
+			return
+		}
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, enrollTOTPResponse{
+		Secret: secret,
+		URL:    util.TOTPURL(totpIssuer, username, secret),
+	})
+}
+
+type totpCodeRequest struct {
+	Code string `json:"code" binding:"required,len=6,numeric"`
+}
+
+type verifyTOTPResponse struct {
+	EnabledAt time.Time `json:"enabled_at"`
+}
+
+// verifyTOTP enables the secret of the user once they entered a code of it,
+// which proves their app was set up.
+func (s *Server) verifyTOTP(c *gin.Context) {
+	var req totpCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	user, err := s.store.GetUser(c, authPayload(c).Username)
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+	if user.TotpEnabledAt.Valid {
+		errorResponse(c, http.StatusConflict, errTOTPEnabled)
+		return
+	}
+	if !user.TotpSecret.Valid {
+		errorResponse(c, http.StatusConflict, errTOTPNotEnrolled)
+		return
+	}
+
+	step, err := util.CheckTOTP(user.TotpSecret.String, req.Code, time.Now())
+	if err != nil {
+		errorResponse(c, http.StatusUnauthorized, err)
+		return
+	}
+
+	user, err = s.store.EnableUserTOTP(c, db.EnableUserTOTPParams{
+		Username:     user.Username,
+		TotpSecret:   user.TotpSecret.String,
+		TotpLastStep: step,
+	})
+	if err != nil {
+		// enabled or enrolled again by a concurrent request
+		if errors.Is(err, pgx.ErrNoRows) {
+			errorResponse(c, http.StatusConflict, errTOTPEnabled)
+			return
+		}
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, verifyTOTPResponse{EnabledAt: user.TotpEnabledAt.Time.UTC()})
+}
+
+// checkTOTP checks a one-time code of the user and uses it up, so that the
+// code cannot be replayed. It fails with util.ErrInvalidTOTP for a wrong or
+// used code.
+func (s *Server) checkTOTP(c *gin.Context, user db.User, code string) error {
+	if !user.TotpEnabledAt.Valid {
+		return errTOTPRequired
+	}
+
+	step, err := util.CheckTOTP(user.TotpSecret.String, code, time.Now())
+	if err != nil {
+		return err
+	}
+
+	_, err = s.store.UseUserTOTPStep(c, db.UseUserTOTPStepParams{
+		Username:     user.Username,
+		TotpLastStep: step,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return util.ErrInvalidTOTP
+	}
+	return err
+}
@@ -1,72 +1,279 @@
 package http
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	db "github.com/vlone310/bss/internal/db/sqlc"
+	"github.com/vlone310/bss/internal/money"
+	"github.com/vlone310/bss/internal/transfer"
 )
 
+var errTransferNotFound = errors.New("transfer not found")
+var errInvalidTimeRange = errors.New("to must be after from")
+var errInvalidAmountRange = errors.New("max_amount must not be less than min_amount")
+
 type createTransferRequest struct {
-	FromAccountID int64  `json:"from_account_id" binding:"required,min=1"`
-	ToAccountID   int64  `json:"to_account_id" binding:"required,min=1"`
-	AmountCents   int64  `json:"amount_cents" binding:"required,gt=0"`
-	Currency      string `json:"currency" binding:"required,currency"`
+	FromAccountID int64      `json:"from_account_id" binding:"required,min=1"`
+	ToAccountID   int64      `json:"to_account_id" binding:"required,min=1"`
+	Amount        money.JSON `json:"amount"`
+}
+
+type transferResponse struct {
+	ID            int64      `json:"id"`
+	FromAccountID int64      `json:"from_account_id"`
+	ToAccountID   int64      `json:"to_account_id"`
+	Amount        money.JSON `json:"amount"`
+	Status        string     `json:"status"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// newTransferResponse needs the currency of the accounts, which the
+// transfer itself does not record.
+func (s *Server) newTransferResponse(transfer db.Transfer, currency string) transferResponse {
+	return transferResponse{
+		ID:            transfer.ID,
+		FromAccountID: transfer.FromAccountID,
+		ToAccountID:   transfer.ToAccountID,
+		Amount:        s.moneyJSON(transfer.AmountCents, currency),
+		Status:        transfer.Status,
+		CreatedAt:     transfer.CreatedAt.Time.UTC(),
+	}
 }
 
 func (s *Server) createTransfer(c *gin.Context) {
 	var req createTransferRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, errorResponse(err))
+		errorResponse(c, http.StatusBadRequest, err)
 		return
 	}
 
-	if !s.validAccount(c, req.FromAccountID, req.Currency, -req.AmountCents) {
-		return
+	amount, err := s.parseMoney(req.Amount)
+	if err == nil {
+		err = s.checkAmount(amount)
 	}
-
-	if !s.validAccount(c, req.ToAccountID, req.Currency, req.AmountCents) {
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
 		return
 	}
 
-	arg := db.TransferTxParams{
+	result, err := s.transfers.Create(c, transfer.Request{
+		Sender:        authPayload(c).Username,
 		FromAccountID: req.FromAccountID,
 		ToAccountID:   req.ToAccountID,
-		AmountCents:   req.AmountCents,
+		Amount:        amount,
+	})
+	if err != nil {
+		transferErrorResponse(c, err)
+		return
 	}
 
-	transferResult, err := s.store.TransferTx(c, arg)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, errorResponse(err))
+	// held for screening, or challenged or held by the fraud engine
+	if result.Transfer.Status == db.TransferStatusPending {
+		res := pendingTransferResponse{transferResponse: s.newTransferResponse(result.Transfer, amount.Currency)}
+		if result.ConfirmationRequired {
+			res.ConfirmURL = fmt.Sprintf("/transfers/%d/confirm", result.Transfer.ID)
+		}
+		c.JSON(http.StatusAccepted, res)
 		return
 	}
 
-	c.JSON(http.StatusCreated, transferResult)
+	c.JSON(http.StatusCreated, s.newTransferResponse(result.Transfer, amount.Currency))
+}
+
+// pendingTransferResponse is the answer to a transfer that was not booked
+// right away. ConfirmURL is set when the sender has to confirm it.
+type pendingTransferResponse struct {
+	transferResponse
+	ConfirmURL string `json:"confirm_url,omitempty"`
+}
+
+// transferErrorResponse writes the response for an error of creating a
+// transfer.
+func transferErrorResponse(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, transfer.ErrAccountNotFound):
+		errorResponse(c, http.StatusNotFound, errAccountNotFound)
+	case errors.Is(err, db.ErrAccountNotActive):
+		errorResponse(c, http.StatusConflict, err)
+	case errors.Is(err, db.ErrKYCRequired), errors.Is(err, db.ErrTransferBlocked):
+		errorResponse(c, http.StatusForbidden, err)
+	case errors.Is(err, db.ErrInsufficientFunds), errors.Is(err, db.ErrWithdrawalLimitReached), errors.Is(err, db.ErrMaxBalanceExceeded), errors.Is(err, db.ErrCurrencyMismatch), errors.Is(err, db.ErrAmountNotPositive), errors.Is(err, db.ErrUnverifiedBalanceLimit):
+		errorResponse(c, http.StatusBadRequest, err)
+	default:
+		errorResponse(c, http.StatusInternalServerError, err)
+	}
+}
+
+type getTransferParams struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
 }
 
-func (s *Server) validAccount(c *gin.Context, accountID int64, currency string, amountCents int64) bool {
-	account, err := s.store.GetAccount(c, accountID)
+func (s *Server) getTransfer(c *gin.Context) {
+	var req getTransferParams
+	if err := c.ShouldBindUri(&req); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	transfer, err := s.store.GetTransfer(c, req.ID)
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			c.JSON(http.StatusNotFound, errorResponse(errAccountNotFound))
-			return false
+			errorResponse(c, http.StatusNotFound, errTransferNotFound)
+			return
 		}
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
 
-		c.JSON(http.StatusInternalServerError, errorResponse(err))
-		return false
+	// the caller must own at least one side of the transfer
+	payload := authPayload(c)
+	owned := false
+	var currency string
+	for _, accountID := range []int64{transfer.FromAccountID, transfer.ToAccountID} {
+		account, err := s.store.GetAccount(c, accountID)
+		if err != nil {
+			errorResponse(c, http.StatusInternalServerError, err)
+			return
+		}
+		// both sides of a transfer are in the same currency
+		currency = account.Currency
+		if account.Owner == payload.Username {
+			owned = true
+			break
+		}
 	}
 
-	if account.Currency != currency {
-		c.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("account [%d] currency missmatch: %s and %s", account.ID, account.Currency, currency)))
-		return false
+	if !owned {
+		// do not reveal that the transfer exists
+		errorResponse(c, http.StatusNotFound, errTransferNotFound)
+		return
 	}
 
-	if account.Balance+amountCents < 0 {
-		c.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("account [%d] balance not enough: %d", account.ID, account.Balance)))
-		return false
+	c.JSON(http.StatusOK, s.newTransferResponse(transfer, currency))
+}
+
+type listTransfersQuery struct {
+	Direction string     `form:"direction" binding:"omitempty,oneof=in out both"`
+	From      *time.Time `form:"from"`
+	To        *time.Time `form:"to"`
+	// MinAmount and MaxAmount are decimal amounts in the account currency.
+	MinAmount *string `form:"min_amount" binding:"omitempty,max=32"`
+	MaxAmount *string `form:"max_amount" binding:"omitempty,max=32"`
+	Status    string  `form:"status" binding:"omitempty,oneof=pending completed failed"`
+	pageQuery
+}
+
+func (s *Server) listAccountTransfers(c *gin.Context) {
+	var params getAccountParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
 	}
 
-	return true
+	var req listTransfersQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if req.From != nil && req.To != nil && !req.To.After(*req.From) {
+		errorResponse(c, http.StatusBadRequest, errInvalidTimeRange)
+		return
+	}
+
+	account, ok := s.getOwnedAccount(c, params.ID)
+	if !ok {
+		return
+	}
+
+	minAmount, err := s.parseOptionalAmount(req.MinAmount, account.Currency)
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	maxAmount, err := s.parseOptionalAmount(req.MaxAmount, account.Currency)
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if minAmount.Valid && maxAmount.Valid && maxAmount.Int64 < minAmount.Int64 {
+		errorResponse(c, http.StatusBadRequest, errInvalidAmountRange)
+		return
+	}
+
+	newResponse := func(transfer db.Transfer) transferResponse {
+		return s.newTransferResponse(transfer, account.Currency)
+	}
+
+	direction := req.Direction
+	if direction == "" {
+		direction = "both"
+	}
+
+	if req.legacy() {
+		offset, ok := s.legacyPage(c, req.pageQuery)
+		if !ok {
+			return
+		}
+
+		transfers, err := s.store.ListTransfers(c, db.ListTransfersParams{
+			AccountID:   params.ID,
+			Direction:   direction,
+			FromTime:    optionalTimestamptz(req.From),
+			ToTime:      optionalTimestamptz(req.To),
+			MinAmount:   minAmount,
+			MaxAmount:   maxAmount,
+			Status:      pgtype.Text{String: req.Status, Valid: req.Status != ""},
+			LimitCount:  req.PageSize,
+			OffsetCount: offset,
+		})
+		if err != nil {
+			errorResponse(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		res := make([]transferResponse, 0, len(transfers))
+		for _, transfer := range transfers {
+			res = append(res, newResponse(transfer))
+		}
+
+		c.JSON(http.StatusOK, res)
+		return
+	}
+
+	after, pageSize, ok := s.keysetPage(c, req.pageQuery, "transfers", params.ID)
+	if !ok {
+		return
+	}
+
+	transfers, err := s.store.ListTransfersAfter(c, db.ListTransfersAfterParams{
+		AccountID:      params.ID,
+		Direction:      direction,
+		AfterCreatedAt: after.afterCreatedAt(),
+		AfterID:        after.ID,
+		FromTime:       optionalTimestamptz(req.From),
+		ToTime:         optionalTimestamptz(req.To),
+		MinAmount:      minAmount,
+		MaxAmount:      maxAmount,
+		Status:         pgtype.Text{String: req.Status, Valid: req.Status != ""},
+		LimitCount:     pageSize + 1,
+	})
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newListResponse(s, "transfers", params.ID, transfers, pageSize, transferKey, newResponse))
+}
+
+func transferKey(transfer db.Transfer) cursor {
+	return cursor{CreatedAt: transfer.CreatedAt.Time, ID: transfer.ID}
 }
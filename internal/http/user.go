@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	db "github.com/vlone310/bss/internal/db/sqlc"
 	"github.com/vlone310/bss/util"
@@ -31,36 +32,40 @@ type CreateUserResponse struct {
 func (s *Server) createUser(c *gin.Context) {
 	var req createUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, errorResponse(err))
+		errorResponse(c, http.StatusBadRequest, err)
 		return
 	}
 
 	hashedPassword, err := util.HashPassword(req.Password)
-
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
 	}
 
-	arg := db.CreateUserParams{
-		Username:       req.Username,
-		FullName:       req.FullName,
-		Email:          req.Email,
-		HashedPassword: hashedPassword,
+	arg := db.CreateUserTxParams{
+		CreateUserParams: db.CreateUserParams{
+			Username:       req.Username,
+			FullName:       req.FullName,
+			Email:          req.Email,
+			HashedPassword: hashedPassword,
+		},
+		Screening: s.screener.ScreenUser(req.FullName),
 	}
 
-	user, err := s.store.CreateUser(c, arg)
+	result, err := s.store.CreateUserTx(c, arg)
 	if err != nil {
 		if pgErr, ok := err.(*pgconn.PgError); ok {
 			switch pgErr.Code {
 			case "23505":
-				c.JSON(http.StatusForbidden, errorResponse(errUserExists))
+				errorResponse(c, http.StatusForbidden, errUserExists)
 				return
 			}
 		}
-		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		errorResponse(c, http.StatusInternalServerError, err)
 		return
 	}
 
+	user := result.User
 	res := CreateUserResponse{
 		Username:          user.Username,
 		FullName:          user.FullName,
@@ -71,3 +76,57 @@ func (s *Server) createUser(c *gin.Context) {
 
 	c.JSON(http.StatusCreated, res)
 }
+
+var errInvalidCredentials = errors.New("invalid username or password")
+
+type loginUserRequest struct {
+	Username string `json:"username" binding:"required,alphanum"`
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+type loginUserResponse struct {
+	AccessToken string             `json:"access_token"`
+	User        CreateUserResponse `json:"user"`
+}
+
+func (s *Server) loginUser(c *gin.Context) {
+	var req loginUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	user, err := s.store.GetUser(c, req.Username)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			errorResponse(c, http.StatusUnauthorized, errInvalidCredentials)
+			return
+		}
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	if err := util.CheckPasswordHash(req.Password, user.HashedPassword); err != nil {
+		errorResponse(c, http.StatusUnauthorized, errInvalidCredentials)
+		return
+	}
+
+	accessToken, err := s.tokenMaker.CreateToken(user.Username, s.config.AccessTokenDuration)
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	res := loginUserResponse{
+		AccessToken: accessToken,
+		User: CreateUserResponse{
+			Username:          user.Username,
+			FullName:          user.FullName,
+			Email:             user.Email,
+			PasswordChangedAt: user.PasswordChangedAt.Time.UTC(),
+			CreatedAt:         user.CreatedAt.Time.UTC(),
+		},
+	}
+
+	c.JSON(http.StatusOK, res)
+}
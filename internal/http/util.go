@@ -1,9 +1,38 @@
 package http
 
 import (
+	"errors"
+	"io"
+	"time"
+
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
-func errorResponse(err error) gin.H {
-	return gin.H{"error": err.Error()}
+// bindOptionalJSON binds the request body when there is one. An empty body
+// leaves obj untouched instead of failing with io.EOF.
+func bindOptionalJSON(c *gin.Context, obj any) error {
+	err := c.ShouldBindJSON(obj)
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	return err
+}
+
+func optionalTimestamptz(t *time.Time) pgtype.Timestamptz {
+	if t == nil {
+		return pgtype.Timestamptz{}
+	}
+	return pgtype.Timestamptz{Time: *t, Valid: true}
+}
+
+func optionalText(s string) pgtype.Text {
+	return pgtype.Text{String: s, Valid: s != ""}
+}
+
+func optionalInt8(n *int64) pgtype.Int8 {
+	if n == nil {
+		return pgtype.Int8{}
+	}
+	return pgtype.Int8{Int64: *n, Valid: true}
 }
@@ -1,13 +1,22 @@
 package http
 
-import "github.com/go-playground/validator/v10"
-
-var validCurrency validator.Func = func(fl validator.FieldLevel) bool {
-	currency := fl.Field().String()
-	switch currency {
-	// TODO: consider moving to the config
-	case "EUR", "USD", "CAD":
-		return true
+import (
+	"slices"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/vlone310/bss/internal/currency"
+	"github.com/vlone310/bss/internal/event"
+)
+
+// validCurrency accepts the currencies enabled in the registry, so that
+// enabling one takes effect without a restart.
+func validCurrency(currencies *currency.Registry) validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		return currencies.Enabled(fl.Field().String())
 	}
-	return false
+}
+
+// validEventType accepts the types of the domain events.
+func validEventType(fl validator.FieldLevel) bool {
+	return slices.Contains(event.Types, fl.Field().String())
 }
@@ -0,0 +1,323 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+	"github.com/vlone310/bss/internal/webhook"
+)
+
+var errWebhookNotFound = errors.New("webhook not found")
+var errWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+var errWebhookDeliveryPending = errors.New("webhook delivery is still pending")
+var errWebhookInsecureURL = errors.New("webhook url must use https")
+
+type createWebhookRequest struct {
+	URL string `json:"url" binding:"required,url,max=2048"`
+	// EventTypes subscribes the endpoint to some events, all of them when
+	// empty.
+	EventTypes []string `json:"event_types" binding:"omitempty,dive,event_type"`
+}
+
+type webhookResponse struct {
+	ID         int64     `json:"id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type createWebhookResponse struct {
+	webhookResponse
+	// Secret signs the deliveries, it is only shown once.
+	Secret string `json:"secret"`
+}
+
+func newWebhookResponse(endpoint db.WebhookEndpoint) webhookResponse {
+	return webhookResponse{
+		ID:         endpoint.ID,
+		URL:        endpoint.Url,
+		EventTypes: endpoint.EventTypes,
+		CreatedAt:  endpoint.CreatedAt.Time,
+	}
+}
+
+// createWebhook registers an endpoint for the events of the accounts of the
+// user.
+func (s *Server) createWebhook(c *gin.Context) {
+	var req createWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	u, err := url.Parse(req.URL)
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+	if u.Scheme != "https" && !(u.Scheme == "http" && s.config.WebhookAllowHTTP) {
+		errorResponse(c, http.StatusBadRequest, errWebhookInsecureURL)
+		return
+	}
+	if !s.config.WebhookAllowPrivate {
+		if err := webhook.CheckHost(u.Hostname()); err != nil {
+			errorResponse(c, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	eventTypes := req.EventTypes
+	if eventTypes == nil {
+		eventTypes = []string{}
+	}
+
+	endpoint, err := s.store.CreateWebhookEndpoint(c, db.CreateWebhookEndpointParams{
+		Owner:      authPayload(c).Username,
+		Url:        req.URL,
+		Secret:     webhook.NewSecret(),
+		EventTypes: eventTypes,
+	})
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, createWebhookResponse{
+		webhookResponse: newWebhookResponse(endpoint),
+		Secret:          endpoint.Secret,
+	})
+}
+
+func (s *Server) listWebhooks(c *gin.Context) {
+	endpoints, err := s.store.ListWebhookEndpoints(c, authPayload(c).Username)
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	res := make([]webhookResponse, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		res = append(res, newWebhookResponse(endpoint))
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+type webhookParams struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// ownWebhook returns the endpoint with id when the user owns it. It has
+// responded when it returns false.
+func (s *Server) ownWebhook(c *gin.Context, id int64) (db.WebhookEndpoint, bool) {
+	endpoint, err := s.store.GetWebhookEndpoint(c, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			errorResponse(c, http.StatusNotFound, errWebhookNotFound)
+			return endpoint, false
+		}
+		errorResponse(c, http.StatusInternalServerError, err)
+		return endpoint, false
+	}
+
+	// do not reveal the endpoints of other users
+	if endpoint.Owner != authPayload(c).Username {
+		errorResponse(c, http.StatusNotFound, errWebhookNotFound)
+		return endpoint, false
+	}
+
+	return endpoint, true
+}
+
+// deleteWebhook removes an endpoint along with its deliveries.
+func (s *Server) deleteWebhook(c *gin.Context) {
+	var params webhookParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if _, ok := s.ownWebhook(c, params.ID); !ok {
+		return
+	}
+
+	if err := s.store.DeleteWebhookEndpoint(c, params.ID); err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+type webhookDeliveryResponse struct {
+	ID            int64                            `json:"id"`
+	EventID       int64                            `json:"event_id"`
+	EventType     string                           `json:"event_type"`
+	Status        string                           `json:"status"`
+	Attempts      int32                            `json:"attempts"`
+	NextAttemptAt *time.Time                       `json:"next_attempt_at,omitempty"`
+	LastError     string                           `json:"last_error,omitempty"`
+	DeliveredAt   *time.Time                       `json:"delivered_at,omitempty"`
+	CreatedAt     time.Time                        `json:"created_at"`
+	Payload       json.RawMessage                  `json:"payload,omitempty"`
+	AttemptLog    []webhookDeliveryAttemptResponse `json:"attempt_log,omitempty"`
+}
+
+type webhookDeliveryAttemptResponse struct {
+	StatusCode int32     `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int32     `json:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func newWebhookDeliveryResponse(delivery db.WebhookDelivery) webhookDeliveryResponse {
+	res := webhookDeliveryResponse{
+		ID:        delivery.ID,
+		EventID:   delivery.EventID,
+		EventType: delivery.EventType,
+		Status:    delivery.Status,
+		Attempts:  delivery.Attempts,
+		LastError: delivery.LastError.String,
+		CreatedAt: delivery.CreatedAt.Time,
+	}
+	// a delivery that is done has no next attempt
+	if delivery.Status == db.WebhookDeliveryPending {
+		res.NextAttemptAt = &delivery.NextAttemptAt.Time
+	}
+	if delivery.DeliveredAt.Valid {
+		res.DeliveredAt = &delivery.DeliveredAt.Time
+	}
+	return res
+}
+
+// listWebhookDeliveries lists the deliveries of an endpoint newest first.
+func (s *Server) listWebhookDeliveries(c *gin.Context) {
+	var params webhookParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var req pageQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	after, pageSize, ok := s.keysetPage(c, req, "webhook_deliveries", params.ID)
+	if !ok {
+		return
+	}
+
+	if _, ok := s.ownWebhook(c, params.ID); !ok {
+		return
+	}
+
+	deliveries, err := s.store.ListWebhookDeliveries(c, db.ListWebhookDeliveriesParams{
+		EndpointID: params.ID,
+		BeforeID:   pgtype.Int8{Int64: after.ID, Valid: after.ID != 0},
+		LimitCount: pageSize + 1,
+	})
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newListResponse(s, "webhook_deliveries", params.ID, deliveries, pageSize, webhookDeliveryKey, newWebhookDeliveryResponse))
+}
+
+func webhookDeliveryKey(delivery db.WebhookDelivery) cursor {
+	return cursor{ID: delivery.ID}
+}
+
+type webhookDeliveryParams struct {
+	ID         int64 `uri:"id" binding:"required,min=1"`
+	DeliveryID int64 `uri:"delivery_id" binding:"required,min=1"`
+}
+
+// ownWebhookDelivery returns the delivery in the uri when the user owns its
+// endpoint. It has responded when it returns false.
+func (s *Server) ownWebhookDelivery(c *gin.Context) (db.WebhookDelivery, bool) {
+	var params webhookDeliveryParams
+	if err := c.ShouldBindUri(&params); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return db.WebhookDelivery{}, false
+	}
+
+	if _, ok := s.ownWebhook(c, params.ID); !ok {
+		return db.WebhookDelivery{}, false
+	}
+
+	delivery, err := s.store.GetWebhookDelivery(c, params.DeliveryID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			errorResponse(c, http.StatusNotFound, errWebhookDeliveryNotFound)
+			return delivery, false
+		}
+		errorResponse(c, http.StatusInternalServerError, err)
+		return delivery, false
+	}
+	if delivery.EndpointID != params.ID {
+		errorResponse(c, http.StatusNotFound, errWebhookDeliveryNotFound)
+		return delivery, false
+	}
+
+	return delivery, true
+}
+
+// getWebhookDelivery returns a delivery with its payload and every attempt
+// made.
+func (s *Server) getWebhookDelivery(c *gin.Context) {
+	delivery, ok := s.ownWebhookDelivery(c)
+	if !ok {
+		return
+	}
+
+	attempts, err := s.store.ListWebhookDeliveryAttempts(c, delivery.ID)
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	res := newWebhookDeliveryResponse(delivery)
+	res.Payload = delivery.Payload
+	res.AttemptLog = make([]webhookDeliveryAttemptResponse, 0, len(attempts))
+	for _, attempt := range attempts {
+		res.AttemptLog = append(res.AttemptLog, webhookDeliveryAttemptResponse{
+			StatusCode: attempt.StatusCode.Int32,
+			Error:      attempt.Error.String,
+			DurationMs: attempt.DurationMs,
+			CreatedAt:  attempt.CreatedAt.Time,
+		})
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// redeliverWebhook queues a delivery that succeeded or is dead once more,
+// with a fresh set of attempts.
+func (s *Server) redeliverWebhook(c *gin.Context) {
+	delivery, ok := s.ownWebhookDelivery(c)
+	if !ok {
+		return
+	}
+
+	delivery, err := s.store.RedeliverWebhookDelivery(c, delivery.ID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			errorResponse(c, http.StatusConflict, errWebhookDeliveryPending)
+			return
+		}
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newWebhookDeliveryResponse(delivery))
+}
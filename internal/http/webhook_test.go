@@ -0,0 +1,355 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/vlone310/bss/internal/db/mock"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+	"github.com/vlone310/bss/internal/event"
+	"github.com/vlone310/bss/testutil"
+)
+
+func randomWebhookEndpoint(owner string) db.WebhookEndpoint {
+	return db.WebhookEndpoint{
+		ID:         testutil.RandomInt(1, 1000),
+		Owner:      owner,
+		Url:        "https://example.com/hooks",
+		Secret:     "whsec_" + testutil.RandomString(64),
+		EventTypes: []string{event.TypeTransferCompleted},
+		CreatedAt:  pgtype.Timestamptz{Time: time.Now(), Valid: true},
+	}
+}
+
+func TestCreateWebhookAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"url": "https://example.com/hooks", "event_types": []string{event.TypeTransferCompleted}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhookEndpoint(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ any, arg db.CreateWebhookEndpointParams) (db.WebhookEndpoint, error) {
+						require.Equal(t, user.Username, arg.Owner)
+						require.Equal(t, []string{event.TypeTransferCompleted}, arg.EventTypes)
+						require.True(t, strings.HasPrefix(arg.Secret, "whsec_"))
+
+						endpoint := randomWebhookEndpoint(arg.Owner)
+						endpoint.Secret = arg.Secret
+						return endpoint, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var res createWebhookResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.True(t, strings.HasPrefix(res.Secret, "whsec_"))
+				require.Equal(t, []string{event.TypeTransferCompleted}, res.EventTypes)
+			},
+		},
+		{
+			name: "AllEvents",
+			body: gin.H{"url": "https://example.com/hooks"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhookEndpoint(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ any, arg db.CreateWebhookEndpointParams) (db.WebhookEndpoint, error) {
+						require.Equal(t, []string{}, arg.EventTypes)
+						return randomWebhookEndpoint(arg.Owner), nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name: "InsecureURL",
+			body: gin.H{"url": "http://example.com/hooks"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhookEndpoint(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Contains(t, recorder.Body.String(), errWebhookInsecureURL.Error())
+			},
+		},
+		{
+			name: "PrivateAddress",
+			body: gin.H{"url": "https://169.254.169.254/latest/meta-data"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhookEndpoint(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Contains(t, recorder.Body.String(), "forbidden_webhook_address")
+			},
+		},
+		{
+			name: "Localhost",
+			body: gin.H{"url": "https://localhost:8080/hooks"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhookEndpoint(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Contains(t, recorder.Body.String(), "forbidden_webhook_address")
+			},
+		},
+		{
+			name: "UnknownEventType",
+			body: gin.H{"url": "https://example.com/hooks", "event_types": []string{"account.deleted"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhookEndpoint(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			var body bytes.Buffer
+			require.NoError(t, json.NewEncoder(&body).Encode(tc.body))
+
+			request, err := http.NewRequest(http.MethodPost, "/webhooks", &body)
+			require.NoError(t, err)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+
+			serve(t, server, recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestDeleteWebhookAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	endpoint := randomWebhookEndpoint(user.Username)
+
+	testCases := []struct {
+		name          string
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetWebhookEndpoint(gomock.Any(), gomock.Eq(endpoint.ID)).Times(1).Return(endpoint, nil)
+				store.EXPECT().DeleteWebhookEndpoint(gomock.Any(), gomock.Eq(endpoint.ID)).Times(1).Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name:     "OtherOwner",
+			username: testutil.RandomOwner(),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetWebhookEndpoint(gomock.Any(), gomock.Eq(endpoint.ID)).Times(1).Return(endpoint, nil)
+				store.EXPECT().DeleteWebhookEndpoint(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:     "NotFound",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetWebhookEndpoint(gomock.Any(), gomock.Eq(endpoint.ID)).Times(1).Return(db.WebhookEndpoint{}, pgx.ErrNoRows)
+				store.EXPECT().DeleteWebhookEndpoint(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/webhooks/%d", endpoint.ID), nil)
+			require.NoError(t, err)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+
+			serve(t, server, recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestWebhookDeliveryAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	endpoint := randomWebhookEndpoint(user.Username)
+
+	dead := db.WebhookDelivery{
+		ID:            testutil.RandomInt(1, 1000),
+		EndpointID:    endpoint.ID,
+		EventID:       42,
+		EventType:     event.TypeTransferCompleted,
+		Payload:       []byte(`{"id":42}`),
+		Status:        db.WebhookDeliveryDead,
+		Attempts:      8,
+		NextAttemptAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+		LastError:     pgtype.Text{String: "endpoint responded 500", Valid: true},
+		CreatedAt:     pgtype.Timestamptz{Time: time.Now(), Valid: true},
+	}
+	pending := dead
+	pending.Status = db.WebhookDeliveryPending
+	pending.Attempts = 0
+	pending.LastError = pgtype.Text{}
+
+	testCases := []struct {
+		name          string
+		method        string
+		url           string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "List",
+			method: http.MethodGet,
+			url:    fmt.Sprintf("/webhooks/%d/deliveries?page_size=5", endpoint.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListWebhookDeliveriesParams{EndpointID: endpoint.ID, LimitCount: 6}
+				store.EXPECT().ListWebhookDeliveries(gomock.Any(), gomock.Eq(arg)).Times(1).
+					Return([]db.WebhookDelivery{dead}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				res := requireBodyListResponse[webhookDeliveryResponse](t, recorder.Body)
+				require.Len(t, res.Items, 1)
+				require.False(t, res.HasMore)
+				require.Equal(t, db.WebhookDeliveryDead, res.Items[0].Status)
+				require.Nil(t, res.Items[0].NextAttemptAt)
+				require.Empty(t, res.Items[0].Payload)
+			},
+		},
+		{
+			name:   "Get",
+			method: http.MethodGet,
+			url:    fmt.Sprintf("/webhooks/%d/deliveries/%d", endpoint.ID, dead.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetWebhookDelivery(gomock.Any(), gomock.Eq(dead.ID)).Times(1).Return(dead, nil)
+				store.EXPECT().ListWebhookDeliveryAttempts(gomock.Any(), gomock.Eq(dead.ID)).Times(1).
+					Return([]db.WebhookDeliveryAttempt{{
+						DeliveryID: dead.ID,
+						StatusCode: pgtype.Int4{Int32: 500, Valid: true},
+						Error:      dead.LastError,
+						DurationMs: 12,
+					}}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res webhookDeliveryResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.JSONEq(t, string(dead.Payload), string(res.Payload))
+				require.Len(t, res.AttemptLog, 1)
+				require.Equal(t, int32(500), res.AttemptLog[0].StatusCode)
+			},
+		},
+		{
+			name:   "GetOtherEndpoint",
+			method: http.MethodGet,
+			url:    fmt.Sprintf("/webhooks/%d/deliveries/%d", endpoint.ID, dead.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				other := dead
+				other.EndpointID = endpoint.ID + 1
+				store.EXPECT().GetWebhookDelivery(gomock.Any(), gomock.Eq(dead.ID)).Times(1).Return(other, nil)
+				store.EXPECT().ListWebhookDeliveryAttempts(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "Redeliver",
+			method: http.MethodPost,
+			url:    fmt.Sprintf("/webhooks/%d/deliveries/%d/redeliver", endpoint.ID, dead.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetWebhookDelivery(gomock.Any(), gomock.Eq(dead.ID)).Times(1).Return(dead, nil)
+				store.EXPECT().RedeliverWebhookDelivery(gomock.Any(), gomock.Eq(dead.ID)).Times(1).Return(pending, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res webhookDeliveryResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, db.WebhookDeliveryPending, res.Status)
+				require.NotNil(t, res.NextAttemptAt)
+			},
+		},
+		{
+			name:   "RedeliverPending",
+			method: http.MethodPost,
+			url:    fmt.Sprintf("/webhooks/%d/deliveries/%d/redeliver", endpoint.ID, dead.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetWebhookDelivery(gomock.Any(), gomock.Eq(dead.ID)).Times(1).Return(pending, nil)
+				store.EXPECT().RedeliverWebhookDelivery(gomock.Any(), gomock.Eq(dead.ID)).Times(1).
+					Return(db.WebhookDelivery{}, pgx.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetWebhookEndpoint(gomock.Any(), gomock.Eq(endpoint.ID)).Times(1).Return(endpoint, nil)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(tc.method, tc.url, nil)
+			require.NoError(t, err)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+
+			serve(t, server, recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
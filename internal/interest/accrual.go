@@ -0,0 +1,31 @@
+package interest
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/vlone310/bss/internal/money"
+)
+
+const bpsPerUnit = 10_000
+
+// DailyAccrual returns the interest, in micro units, earned
+// on the end-of-day balance of the given date. The result is rounded half to
+// even. Balances at or below zero earn nothing.
+func DailyAccrual(balance int64, rateBps int32, dc DayCount, date time.Time) int64 {
+	if balance <= 0 || rateBps <= 0 {
+		return 0
+	}
+
+	date = dateOf(date)
+	days := dc.Days(date, date.AddDate(0, 0, 1))
+
+	num := new(big.Int).SetInt64(balance)
+	num.Mul(num, big.NewInt(int64(rateBps)))
+	num.Mul(num, big.NewInt(days))
+	num.Mul(num, big.NewInt(money.MicrosPerUnit))
+
+	den := big.NewInt(bpsPerUnit * dc.Basis())
+
+	return money.RoundHalfEven(num, den)
+}
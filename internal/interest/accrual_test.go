@@ -0,0 +1,34 @@
+package interest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDailyAccrual(t *testing.T) {
+	testCases := []struct {
+		name    string
+		balance int64
+		rateBps int32
+		dc      DayCount
+		date    string
+		micros  int64
+	}{
+		// 100000 * 1.5% / 365 = 4.109589041... cents
+		{"Actual365", 100_000, 150, Actual365, "2024-05-10", 4_109_589},
+		// 100000 * 1.5% / 360 = 4.1666... cents
+		{"Thirty360", 100_000, 150, Thirty360, "2024-05-10", 4_166_667},
+		{"Thirty360On30thOfLongMonth", 100_000, 150, Thirty360, "2024-05-30", 0},
+		{"Thirty360EndOfFebruary", 100_000, 150, Thirty360, "2023-02-28", 12_500_000},
+		{"ZeroBalance", 0, 150, Actual365, "2024-05-10", 0},
+		{"NegativeBalance", -100_000, 150, Actual365, "2024-05-10", 0},
+		{"LargeBalance", 1 << 50, 10_000, Actual365, "2024-05-10", 3_084_657_279_020_887_671},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.micros, DailyAccrual(tc.balance, tc.rateBps, tc.dc, date(tc.date)))
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package interest
+
+import (
+	"fmt"
+	"time"
+)
+
+// DayCount is a day-count convention. It decides how many days of interest
+// a period earns and how many days make up a year.
+type DayCount string
+
+const (
+	// Actual365 counts calendar days over a fixed 365-day year.
+	Actual365 DayCount = "ACT/365"
+	// Thirty360 counts every month as 30 days over a 360-day year, using the
+	// 30E/360 rule: the 31st is treated as the 30th.
+	Thirty360 DayCount = "30/360"
+)
+
+func ParseDayCount(s string) (DayCount, error) {
+	switch dc := DayCount(s); dc {
+	case Actual365, Thirty360:
+		return dc, nil
+	default:
+		return "", fmt.Errorf("unknown day-count convention %q", s)
+	}
+}
+
+// Days returns the number of days between two dates under the convention.
+func (dc DayCount) Days(from, to time.Time) int64 {
+	if dc == Thirty360 {
+		y1, m1, d1 := from.Date()
+		y2, m2, d2 := to.Date()
+
+		d1 = min(d1, 30)
+		d2 = min(d2, 30)
+
+		return int64(360*(y2-y1) + 30*(int(m2)-int(m1)) + (d2 - d1))
+	}
+
+	return int64(dateOf(to).Sub(dateOf(from)) / (24 * time.Hour))
+}
+
+// Basis returns the number of days in a year under the convention.
+func (dc DayCount) Basis() int64 {
+	if dc == Thirty360 {
+		return 360
+	}
+
+	return 365
+}
+
+// dateOf truncates t to midnight UTC of the same calendar date.
+func dateOf(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,60 @@
+package interest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func date(s string) time.Time {
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestDayCountDays(t *testing.T) {
+	testCases := []struct {
+		dc   DayCount
+		from string
+		to   string
+		days int64
+	}{
+		{Actual365, "2024-01-01", "2024-01-02", 1},
+		{Actual365, "2024-01-01", "2025-01-01", 366},
+		{Actual365, "2024-02-28", "2024-03-01", 2},
+		{Thirty360, "2024-01-01", "2024-01-02", 1},
+		{Thirty360, "2024-01-30", "2024-01-31", 0},
+		{Thirty360, "2024-01-31", "2024-02-01", 1},
+		{Thirty360, "2023-02-28", "2023-03-01", 3},
+		{Thirty360, "2024-02-29", "2024-03-01", 2},
+		{Thirty360, "2024-01-01", "2025-01-01", 360},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.dc)+" "+tc.from+" "+tc.to, func(t *testing.T) {
+			require.Equal(t, tc.days, tc.dc.Days(date(tc.from), date(tc.to)))
+		})
+	}
+}
+
+func TestThirty360MonthIsThirtyDays(t *testing.T) {
+	for day := date("2023-01-01"); day.Year() < 2025; day = day.AddDate(0, 1, 0) {
+		var days int64
+		for d := day; d.Month() == day.Month(); d = d.AddDate(0, 0, 1) {
+			days += Thirty360.Days(d, d.AddDate(0, 0, 1))
+		}
+		require.Equal(t, int64(30), days, day.Format("2006-01"))
+	}
+}
+
+func TestParseDayCount(t *testing.T) {
+	dc, err := ParseDayCount("30/360")
+	require.NoError(t, err)
+	require.Equal(t, Thirty360, dc)
+
+	_, err = ParseDayCount("ACT/ACT")
+	require.Error(t, err)
+}
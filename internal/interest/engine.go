@@ -0,0 +1,131 @@
+package interest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+)
+
+// Engine accrues interest on end-of-day balances and posts it monthly.
+// Every run continues from what is already stored, so running it twice, or
+// after a few missed days, accrues each day exactly once.
+type Engine struct {
+	store db.Store
+	now   func() time.Time
+}
+
+func NewEngine(store db.Store) *Engine {
+	return &Engine{store: store, now: time.Now}
+}
+
+// Accrue stores the interest for every interest-bearing account and every
+// day up to and including through that has not been accrued yet. The
+// current day is never accrued because its closing balance is not final.
+// An account that fails does not hold up the others, the errors are
+// returned together. It returns the number of accruals written.
+func (e *Engine) Accrue(ctx context.Context, through time.Time) (int, error) {
+	yesterday := dateOf(e.now()).AddDate(0, 0, -1)
+	through = dateOf(through)
+	if through.After(yesterday) {
+		through = yesterday
+	}
+
+	accounts, err := e.store.ListInterestBearingAccounts(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var written int
+	var errs []error
+	for _, account := range accounts {
+		n, err := e.accrueAccount(ctx, account, through)
+		written += n
+		if err != nil {
+			errs = append(errs, fmt.Errorf("accrue account [%d]: %w", account.ID, err))
+		}
+	}
+
+	return written, errors.Join(errs...)
+}
+
+func (e *Engine) accrueAccount(ctx context.Context, account db.ListInterestBearingAccountsRow, through time.Time) (int, error) {
+	dc, err := ParseDayCount(account.DayCount)
+	if err != nil {
+		return 0, err
+	}
+
+	day := dateOf(account.CreatedAt.Time.UTC())
+	if account.LastAccrualDate.Valid {
+		day = dateOf(account.LastAccrualDate.Time).AddDate(0, 0, 1)
+	}
+
+	var written int
+	for ; !day.After(through); day = day.AddDate(0, 0, 1) {
+		balance, err := e.store.GetAccountBalanceAt(ctx, db.GetAccountBalanceAtParams{
+			AccountID: account.ID,
+			At:        pgtype.Timestamptz{Time: day.AddDate(0, 0, 1), Valid: true},
+		})
+		if err != nil {
+			return written, err
+		}
+
+		n, err := e.store.CreateInterestAccrual(ctx, db.CreateInterestAccrualParams{
+			AccountID:    account.ID,
+			AccrualDate:  pgtype.Date{Time: day, Valid: true},
+			Balance:      balance,
+			RateBps:      account.InterestRateBps,
+			DayCount:     string(dc),
+			AmountMicros: DailyAccrual(balance, account.InterestRateBps, dc, day),
+		})
+		if err != nil {
+			return written, err
+		}
+		written += int(n)
+	}
+
+	return written, nil
+}
+
+// Post pays out the interest of every calendar month that ended before the
+// month containing asOf. The interest of an account that is not active
+// stays accrued until it is, and an account that fails does not hold up the
+// others, the errors are returned together. It returns the number of
+// postings made.
+func (e *Engine) Post(ctx context.Context, asOf time.Time) (int, error) {
+	y, m, _ := asOf.UTC().Date()
+	before := time.Date(y, m, 1, 0, 0, 0, 0, time.UTC)
+
+	periods, err := e.store.ListUnpostedInterestPeriods(ctx, pgtype.Date{Time: before, Valid: true})
+	if err != nil {
+		return 0, err
+	}
+
+	var posted int
+	var errs []error
+	for _, period := range periods {
+		start := period.PeriodStart.Time
+		result, err := e.store.PostInterestTx(ctx, db.PostInterestTxParams{
+			AccountID:   period.AccountID,
+			PeriodStart: start,
+			PeriodEnd:   start.AddDate(0, 1, 0),
+		})
+		// frozen after the periods were listed, it is posted once unfrozen
+		if errors.Is(err, db.ErrAccountNotActive) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("post interest for account [%d] %s: %w", period.AccountID, start.Format("2006-01"), err))
+			continue
+		}
+
+		if result.Transfer != nil {
+			posted++
+		}
+	}
+
+	return posted, errors.Join(errs...)
+}
@@ -0,0 +1,166 @@
+package interest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/vlone310/bss/internal/db/mock"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+)
+
+func newTestEngine(store db.Store, now time.Time) *Engine {
+	engine := NewEngine(store)
+	engine.now = func() time.Time { return now }
+	return engine
+}
+
+func TestEngineAccrueBackfillsMissedDays(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	account := db.ListInterestBearingAccountsRow{
+		ID:              1,
+		Currency:        "EUR",
+		CreatedAt:       pgtype.Timestamptz{Time: date("2024-01-01"), Valid: true},
+		InterestRateBps: 150,
+		DayCount:        string(Actual365),
+		LastAccrualDate: pgtype.Date{Time: date("2024-05-07"), Valid: true},
+	}
+	store.EXPECT().ListInterestBearingAccounts(gomock.Any()).Times(1).Return([]db.ListInterestBearingAccountsRow{account}, nil)
+
+	// 8th and 9th are missing, the 10th is still in progress
+	for _, day := range []string{"2024-05-08", "2024-05-09"} {
+		store.EXPECT().GetAccountBalanceAt(gomock.Any(), gomock.Eq(db.GetAccountBalanceAtParams{
+			AccountID: account.ID,
+			At:        pgtype.Timestamptz{Time: date(day).AddDate(0, 0, 1), Valid: true},
+		})).Times(1).Return(int64(100_000), nil)
+
+		store.EXPECT().CreateInterestAccrual(gomock.Any(), gomock.Eq(db.CreateInterestAccrualParams{
+			AccountID:    account.ID,
+			AccrualDate:  pgtype.Date{Time: date(day), Valid: true},
+			Balance:      100_000,
+			RateBps:      150,
+			DayCount:     string(Actual365),
+			AmountMicros: 4_109_589,
+		})).Times(1).Return(int64(1), nil)
+	}
+
+	engine := newTestEngine(store, date("2024-05-10").Add(9*time.Hour))
+	written, err := engine.Accrue(context.Background(), date("2024-05-10"))
+	require.NoError(t, err)
+	require.Equal(t, 2, written)
+}
+
+func TestEngineAccrueUpToDate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().ListInterestBearingAccounts(gomock.Any()).Times(1).Return([]db.ListInterestBearingAccountsRow{{
+		ID:              1,
+		InterestRateBps: 150,
+		DayCount:        string(Thirty360),
+		LastAccrualDate: pgtype.Date{Time: date("2024-05-09"), Valid: true},
+	}}, nil)
+	store.EXPECT().GetAccountBalanceAt(gomock.Any(), gomock.Any()).Times(0)
+	store.EXPECT().CreateInterestAccrual(gomock.Any(), gomock.Any()).Times(0)
+
+	engine := newTestEngine(store, date("2024-05-10"))
+	written, err := engine.Accrue(context.Background(), date("2024-05-09"))
+	require.NoError(t, err)
+	require.Zero(t, written)
+}
+
+func TestEnginePostCompletedMonths(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().ListUnpostedInterestPeriods(gomock.Any(), gomock.Eq(pgtype.Date{Time: date("2024-05-01"), Valid: true})).
+		Times(1).
+		Return([]db.ListUnpostedInterestPeriodsRow{
+			{AccountID: 1, PeriodStart: pgtype.Date{Time: date("2024-03-01"), Valid: true}},
+			{AccountID: 1, PeriodStart: pgtype.Date{Time: date("2024-04-01"), Valid: true}},
+		}, nil)
+
+	store.EXPECT().PostInterestTx(gomock.Any(), gomock.Eq(db.PostInterestTxParams{
+		AccountID:   1,
+		PeriodStart: date("2024-03-01"),
+		PeriodEnd:   date("2024-04-01"),
+	})).Times(1).Return(db.PostInterestTxResult{AmountCents: 127, Transfer: &db.Transfer{ID: 1}}, nil)
+
+	// the April accruals round to zero, so nothing is booked
+	store.EXPECT().PostInterestTx(gomock.Any(), gomock.Eq(db.PostInterestTxParams{
+		AccountID:   1,
+		PeriodStart: date("2024-04-01"),
+		PeriodEnd:   date("2024-05-01"),
+	})).Times(1).Return(db.PostInterestTxResult{}, nil)
+
+	engine := newTestEngine(store, date("2024-05-10"))
+	posted, err := engine.Post(context.Background(), date("2024-05-10"))
+	require.NoError(t, err)
+	require.Equal(t, 1, posted)
+}
+
+func TestEnginePostContinuesPastFailures(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().ListUnpostedInterestPeriods(gomock.Any(), gomock.Any()).Times(1).
+		Return([]db.ListUnpostedInterestPeriodsRow{
+			{AccountID: 1, PeriodStart: pgtype.Date{Time: date("2024-04-01"), Valid: true}},
+			{AccountID: 2, PeriodStart: pgtype.Date{Time: date("2024-04-01"), Valid: true}},
+			{AccountID: 3, PeriodStart: pgtype.Date{Time: date("2024-04-01"), Valid: true}},
+		}, nil)
+
+	// frozen since the periods were listed, posted once it is active again
+	store.EXPECT().PostInterestTx(gomock.Any(), gomock.Eq(db.PostInterestTxParams{
+		AccountID:   1,
+		PeriodStart: date("2024-04-01"),
+		PeriodEnd:   date("2024-05-01"),
+	})).Times(1).Return(db.PostInterestTxResult{}, fmt.Errorf("account [1] is frozen: %w", db.ErrAccountNotActive))
+
+	store.EXPECT().PostInterestTx(gomock.Any(), gomock.Eq(db.PostInterestTxParams{
+		AccountID:   2,
+		PeriodStart: date("2024-04-01"),
+		PeriodEnd:   date("2024-05-01"),
+	})).Times(1).Return(db.PostInterestTxResult{}, pgx.ErrTxClosed)
+
+	store.EXPECT().PostInterestTx(gomock.Any(), gomock.Eq(db.PostInterestTxParams{
+		AccountID:   3,
+		PeriodStart: date("2024-04-01"),
+		PeriodEnd:   date("2024-05-01"),
+	})).Times(1).Return(db.PostInterestTxResult{AmountCents: 127, Transfer: &db.Transfer{ID: 1}}, nil)
+
+	engine := newTestEngine(store, date("2024-05-10"))
+	posted, err := engine.Post(context.Background(), date("2024-05-10"))
+	require.ErrorIs(t, err, pgx.ErrTxClosed)
+	require.NotErrorIs(t, err, db.ErrAccountNotActive)
+	require.Equal(t, 1, posted)
+}
+
+func TestEngineAccrueContinuesPastFailures(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().ListInterestBearingAccounts(gomock.Any()).Times(1).Return([]db.ListInterestBearingAccountsRow{
+		{ID: 1, InterestRateBps: 150, DayCount: "bogus", LastAccrualDate: pgtype.Date{Time: date("2024-05-08"), Valid: true}},
+		{ID: 2, InterestRateBps: 150, DayCount: string(Actual365), LastAccrualDate: pgtype.Date{Time: date("2024-05-08"), Valid: true}},
+	}, nil)
+	store.EXPECT().GetAccountBalanceAt(gomock.Any(), gomock.Any()).Times(1).Return(int64(100_000), nil)
+	store.EXPECT().CreateInterestAccrual(gomock.Any(), gomock.Any()).Times(1).Return(int64(1), nil)
+
+	engine := newTestEngine(store, date("2024-05-10"))
+	written, err := engine.Accrue(context.Background(), date("2024-05-09"))
+	require.ErrorContains(t, err, "accrue account [1]")
+	require.Equal(t, 1, written)
+}
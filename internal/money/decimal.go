@@ -0,0 +1,94 @@
+package money
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidAmount = errors.New("amount must be a decimal number")
+var ErrTooManyDecimals = errors.New("amount has more decimals than the currency allows")
+var ErrAmountOutOfRange = errors.New("amount is out of range")
+
+// maxMinorUnits is the largest number of decimals ISO 4217 uses.
+const maxMinorUnits = 4
+
+// FormatMinor renders an amount in minor units as a decimal string with
+// minorUnits decimals, e.g. 1234 becomes "12.34" with 2, "1234" with 0 and
+// "1.234" with 3.
+func FormatMinor(amount int64, minorUnits int32) string {
+	digits := strconv.FormatUint(absUint(amount), 10)
+
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+	}
+
+	if minorUnits <= 0 {
+		return sign + digits
+	}
+
+	n := int(minorUnits)
+	if len(digits) <= n {
+		digits = strings.Repeat("0", n-len(digits)+1) + digits
+	}
+
+	return sign + digits[:len(digits)-n] + "." + digits[len(digits)-n:]
+}
+
+// ParseMinor reads a decimal string with an optional sign and at most
+// minorUnits decimals. Missing decimals are zero, so "12.3" is 1230 cents.
+func ParseMinor(s string, minorUnits int32) (int64, error) {
+	if minorUnits < 0 || minorUnits > maxMinorUnits {
+		return 0, ErrInvalidAmount
+	}
+
+	negative := false
+	switch {
+	case strings.HasPrefix(s, "-"):
+		negative = true
+		s = s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+
+	whole, fraction, hasPoint := strings.Cut(s, ".")
+	if whole == "" || (hasPoint && fraction == "") || !isDigits(whole) || !isDigits(fraction) {
+		return 0, ErrInvalidAmount
+	}
+
+	if len(fraction) > int(minorUnits) {
+		return 0, ErrTooManyDecimals
+	}
+	fraction += strings.Repeat("0", int(minorUnits)-len(fraction))
+
+	amount, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil {
+		return 0, ErrAmountOutOfRange
+	}
+
+	if negative {
+		amount = -amount
+	}
+	return amount, nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func absUint(n int64) uint64 {
+	if n == math.MinInt64 {
+		return uint64(math.MaxInt64) + 1
+	}
+	if n < 0 {
+		return uint64(-n)
+	}
+	return uint64(n)
+}
@@ -0,0 +1,66 @@
+package money
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatMinor(t *testing.T) {
+	testCases := []struct {
+		amount     int64
+		minorUnits int32
+		want       string
+	}{
+		{0, 2, "0.00"},
+		{7, 2, "0.07"},
+		{-1234, 2, "-12.34"},
+		{1234, 0, "1234"},
+		{-5, 0, "-5"},
+		{1234, 3, "1.234"},
+		{-1, 3, "-0.001"},
+		{math.MinInt64, 2, "-92233720368547758.08"},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.want, FormatMinor(tc.amount, tc.minorUnits))
+	}
+}
+
+func TestParseMinor(t *testing.T) {
+	testCases := []struct {
+		s          string
+		minorUnits int32
+		want       int64
+		err        error
+	}{
+		{"12.34", 2, 1234, nil},
+		{"12.3", 2, 1230, nil},
+		{"12", 2, 1200, nil},
+		{"-0.05", 2, -5, nil},
+		{"+1", 2, 100, nil},
+		{"1234", 0, 1234, nil},
+		{"1.234", 3, 1234, nil},
+		{"0.5", 3, 500, nil},
+		{"12.345", 2, 0, ErrTooManyDecimals},
+		{"1.5", 0, 0, ErrTooManyDecimals},
+		{"", 2, 0, ErrInvalidAmount},
+		{".5", 2, 0, ErrInvalidAmount},
+		{"5.", 2, 0, ErrInvalidAmount},
+		{"1e3", 2, 0, ErrInvalidAmount},
+		{"1,000.00", 2, 0, ErrInvalidAmount},
+		{"--1", 2, 0, ErrInvalidAmount},
+		{"92233720368547758.08", 2, 0, ErrAmountOutOfRange},
+	}
+
+	for _, tc := range testCases {
+		got, err := ParseMinor(tc.s, tc.minorUnits)
+		if tc.err != nil {
+			require.ErrorIs(t, err, tc.err, tc.s)
+			continue
+		}
+		require.NoError(t, err, tc.s)
+		require.Equal(t, tc.want, got, tc.s)
+	}
+}
@@ -0,0 +1,136 @@
+// Package money represents amounts exactly, as an integer number of minor
+// units of a currency, and converts them to and from the decimal strings
+// the API speaks.
+package money
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrOverflow = errors.New("amount overflows")
+var ErrMissingCurrency = errors.New("money needs a currency")
+var ErrAmountConflict = errors.New("value and minor disagree")
+var ErrNoMinorUnits = errors.New("money cannot encode without the minor units of its currency, encode its JSON instead")
+
+// Money is an amount in minor units of a currency, e.g. 1234 EUR is 12.34
+// euros and 1234 JPY is 1234 yen. It does not know the decimals of its
+// currency, so it cannot encode itself; whatever leaves the service carries
+// JSON instead.
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+func New(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// Parse reads a decimal string such as "12.34" in a currency with
+// minorUnits decimals.
+func Parse(value string, currency string, minorUnits int32) (Money, error) {
+	amount, err := ParseMinor(value, minorUnits)
+	if err != nil {
+		return Money{}, err
+	}
+	return New(amount, currency), nil
+}
+
+// Add returns m + o. Both must be in the same currency, the callers check
+// that before they get here.
+func (m Money) Add(o Money) (Money, error) {
+	if m.Currency != o.Currency {
+		return Money{}, fmt.Errorf("cannot add %s to %s", o.Currency, m.Currency)
+	}
+
+	sum := m.Amount + o.Amount
+	// overflow when both operands have the same sign and the sum does not
+	if (m.Amount >= 0) == (o.Amount >= 0) && (sum >= 0) != (m.Amount >= 0) {
+		return Money{}, ErrOverflow
+	}
+
+	return New(sum, m.Currency), nil
+}
+
+// Sub returns m - o. Both must be in the same currency, like for Add.
+func (m Money) Sub(o Money) (Money, error) {
+	if m.Currency != o.Currency {
+		return Money{}, fmt.Errorf("cannot subtract %s from %s", o.Currency, m.Currency)
+	}
+
+	diff := m.Amount - o.Amount
+	// overflow when the operands have different signs and the difference
+	// does not have the sign of m
+	if (m.Amount >= 0) != (o.Amount >= 0) && (diff >= 0) != (m.Amount >= 0) {
+		return Money{}, ErrOverflow
+	}
+
+	return New(diff, m.Currency), nil
+}
+
+// Neg returns -m.
+func (m Money) Neg() (Money, error) {
+	return New(0, m.Currency).Sub(m)
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+func (m Money) IsPositive() bool {
+	return m.Amount > 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+// MarshalJSON fails with ErrNoMinorUnits, so that an amount never goes out
+// in minor units where the API promises a decimal.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return nil, ErrNoMinorUnits
+}
+
+// Decimal renders the amount with the minorUnits decimals of its currency.
+func (m Money) Decimal(minorUnits int32) string {
+	return FormatMinor(m.Amount, minorUnits)
+}
+
+// JSON is the wire format of Money. Value carries the decimal string, which
+// keeps the amount exact for clients that read JSON numbers as floats, and
+// Minor the same amount in minor units; a request may use either.
+type JSON struct {
+	Value    string `json:"value,omitempty"`
+	Minor    *int64 `json:"minor,omitempty"`
+	Currency string `json:"currency"`
+}
+
+// JSON returns the wire format of the amount, in a currency with
+// minorUnits decimals.
+func (m Money) JSON(minorUnits int32) JSON {
+	return JSON{Value: m.Decimal(minorUnits), Currency: m.Currency}
+}
+
+// Money reads the amount of a request in a currency with minorUnits
+// decimals. When both are given, Value and Minor must agree.
+func (j JSON) Money(minorUnits int32) (Money, error) {
+	if j.Currency == "" {
+		return Money{}, ErrMissingCurrency
+	}
+
+	switch {
+	case j.Value != "":
+		parsed, err := Parse(j.Value, j.Currency, minorUnits)
+		if err != nil {
+			return Money{}, err
+		}
+		if j.Minor != nil && *j.Minor != parsed.Amount {
+			return Money{}, ErrAmountConflict
+		}
+		return parsed, nil
+	case j.Minor != nil:
+		return New(*j.Minor, j.Currency), nil
+	default:
+		return Money{}, fmt.Errorf("%w: value or minor is required", ErrInvalidAmount)
+	}
+}
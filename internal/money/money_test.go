@@ -0,0 +1,113 @@
+package money
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdd(t *testing.T) {
+	sum, err := New(1234, "EUR").Add(New(66, "EUR"))
+	require.NoError(t, err)
+	require.Equal(t, New(1300, "EUR"), sum)
+
+	_, err = New(1, "EUR").Add(New(1, "USD"))
+	require.Error(t, err)
+
+	_, err = New(math.MaxInt64, "EUR").Add(New(1, "EUR"))
+	require.ErrorIs(t, err, ErrOverflow)
+
+	_, err = New(math.MinInt64, "EUR").Add(New(-1, "EUR"))
+	require.ErrorIs(t, err, ErrOverflow)
+}
+
+func TestSub(t *testing.T) {
+	diff, err := New(100, "EUR").Sub(New(250, "EUR"))
+	require.NoError(t, err)
+	require.Equal(t, New(-150, "EUR"), diff)
+
+	_, err = New(1, "EUR").Sub(New(1, "USD"))
+	require.Error(t, err)
+
+	_, err = New(math.MinInt64, "EUR").Sub(New(1, "EUR"))
+	require.ErrorIs(t, err, ErrOverflow)
+
+	_, err = New(0, "EUR").Neg()
+	require.NoError(t, err)
+
+	_, err = New(math.MinInt64, "EUR").Neg()
+	require.ErrorIs(t, err, ErrOverflow)
+}
+
+func TestJSON(t *testing.T) {
+	testCases := []struct {
+		money      Money
+		minorUnits int32
+		want       string
+	}{
+		{New(1234, "EUR"), 2, `{"value":"12.34","currency":"EUR"}`},
+		{New(-5, "EUR"), 2, `{"value":"-0.05","currency":"EUR"}`},
+		{New(1234, "JPY"), 0, `{"value":"1234","currency":"JPY"}`},
+		{New(1234, "KWD"), 3, `{"value":"1.234","currency":"KWD"}`},
+	}
+
+	for _, tc := range testCases {
+		data, err := json.Marshal(tc.money.JSON(tc.minorUnits))
+		require.NoError(t, err)
+		require.JSONEq(t, tc.want, string(data))
+
+		var j JSON
+		require.NoError(t, json.Unmarshal(data, &j))
+		got, err := j.Money(tc.minorUnits)
+		require.NoError(t, err)
+		require.Equal(t, tc.money, got)
+	}
+}
+
+func TestJSONMoney(t *testing.T) {
+	testCases := []struct {
+		data       string
+		minorUnits int32
+		want       Money
+		err        error
+	}{
+		{`{"value":"12.34","currency":"EUR"}`, 2, New(1234, "EUR"), nil},
+		{`{"minor":1234,"currency":"EUR"}`, 2, New(1234, "EUR"), nil},
+		{`{"value":"12.34","minor":1234,"currency":"EUR"}`, 2, New(1234, "EUR"), nil},
+		{`{"value":"1234","currency":"JPY"}`, 0, New(1234, "JPY"), nil},
+		{`{"value":"12.34","minor":1235,"currency":"EUR"}`, 2, Money{}, ErrAmountConflict},
+		{`{"value":"12.5","currency":"JPY"}`, 0, Money{}, ErrTooManyDecimals},
+		{`{"value":"12.34"}`, 2, Money{}, ErrMissingCurrency},
+		{`{"currency":"EUR"}`, 2, Money{}, ErrInvalidAmount},
+	}
+
+	for _, tc := range testCases {
+		var j JSON
+		require.NoError(t, json.Unmarshal([]byte(tc.data), &j), tc.data)
+
+		got, err := j.Money(tc.minorUnits)
+		if tc.err != nil {
+			require.ErrorIs(t, err, tc.err, tc.data)
+			continue
+		}
+		require.NoError(t, err, tc.data)
+		require.Equal(t, tc.want, got, tc.data)
+	}
+}
+
+func TestMarshalMoney(t *testing.T) {
+	_, err := json.Marshal(New(1234, "JPY"))
+	require.ErrorIs(t, err, ErrNoMinorUnits)
+
+	// nor inside another value
+	_, err = json.Marshal(struct {
+		Amount Money `json:"amount"`
+	}{New(1234, "EUR")})
+	require.ErrorIs(t, err, ErrNoMinorUnits)
+
+	data, err := json.Marshal(New(1234, "JPY").JSON(0))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"value":"1234","currency":"JPY"}`, string(data))
+}
@@ -0,0 +1,33 @@
+package money
+
+import "math/big"
+
+// MicrosPerUnit is the number of micro units in one minor unit. Amounts
+// that are summed before they are paid, such as daily interest, are kept at
+// this precision and rounded to minor units once.
+const MicrosPerUnit = 1_000_000
+
+// RoundHalfEven divides num by a positive den and rounds the quotient to the
+// nearest integer, ties to even.
+func RoundHalfEven(num, den *big.Int) int64 {
+	quo, rem := new(big.Int).QuoRem(new(big.Int).Abs(num), den, new(big.Int))
+
+	switch rem.Lsh(rem, 1).Cmp(den) {
+	case 1:
+		quo.Add(quo, big.NewInt(1))
+	case 0:
+		if quo.Bit(0) == 1 {
+			quo.Add(quo, big.NewInt(1))
+		}
+	}
+
+	if num.Sign() < 0 {
+		quo.Neg(quo)
+	}
+	return quo.Int64()
+}
+
+// RoundMicros converts micro units to minor units, rounding half to even.
+func RoundMicros(micros int64) int64 {
+	return RoundHalfEven(big.NewInt(micros), big.NewInt(MicrosPerUnit))
+}
@@ -0,0 +1,39 @@
+package money
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoundHalfEven(t *testing.T) {
+	testCases := []struct {
+		num, den int64
+		want     int64
+	}{
+		{5, 2, 2},
+		{7, 2, 4},
+		{10, 4, 2},
+		{14, 4, 4},
+		{11, 4, 3},
+		{9, 4, 2},
+		{0, 3, 0},
+		{-5, 2, -2},
+		{-7, 2, -4},
+		{-11, 4, -3},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.want, RoundHalfEven(big.NewInt(tc.num), big.NewInt(tc.den)), "%d/%d", tc.num, tc.den)
+	}
+}
+
+func TestRoundMicros(t *testing.T) {
+	require.Equal(t, int64(0), RoundMicros(499_999))
+	require.Equal(t, int64(0), RoundMicros(500_000))
+	require.Equal(t, int64(1), RoundMicros(500_001))
+	require.Equal(t, int64(2), RoundMicros(1_500_000))
+	require.Equal(t, int64(2), RoundMicros(2_500_000))
+	require.Equal(t, int64(-2), RoundMicros(-2_500_000))
+}
@@ -0,0 +1,66 @@
+// Package outbox relays the events the store writes to its outbox table to
+// an event.Publisher.
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	db "github.com/vlone310/bss/internal/db/sqlc"
+	"github.com/vlone310/bss/internal/event"
+)
+
+const defaultBatchSize = 100
+
+type Relay struct {
+	store     db.Store
+	publisher event.Publisher
+	batchSize int32
+}
+
+// NewRelay returns a relay that publishes batchSize events per transaction,
+// or a default batch for zero.
+func NewRelay(store db.Store, publisher event.Publisher, batchSize int32) *Relay {
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+	return &Relay{store: store, publisher: publisher, batchSize: batchSize}
+}
+
+// Run publishes batches until the outbox is drained, a batch has events that
+// failed, or another relay holds the outbox. Failed events are retried by
+// the next run.
+func (r *Relay) Run(ctx context.Context) (int, error) {
+	var published int
+
+	for {
+		result, err := r.store.RelayOutboxTx(ctx, db.RelayOutboxTxParams{
+			Limit:   r.batchSize,
+			Publish: r.publisher.Publish,
+		})
+		published += result.Published
+		if err != nil || !result.Locked || result.Failed > 0 || result.Published < int(r.batchSize) {
+			return published, err
+		}
+	}
+}
+
+// Prune deletes the events published before the time, a batch per
+// statement so that no single delete holds the table for long. Unpublished
+// events are never deleted.
+func (r *Relay) Prune(ctx context.Context, publishedBefore time.Time) (int64, error) {
+	var deleted int64
+
+	for {
+		n, err := r.store.DeletePublishedOutboxEvents(ctx, db.DeletePublishedOutboxEventsParams{
+			PublishedBefore: pgtype.Timestamptz{Time: publishedBefore, Valid: true},
+			LimitCount:      r.batchSize,
+		})
+		deleted += n
+		if err != nil || n < int64(r.batchSize) {
+			return deleted, err
+		}
+	}
+}
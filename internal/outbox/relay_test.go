@@ -0,0 +1,120 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/vlone310/bss/internal/db/mock"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+	"github.com/vlone310/bss/internal/event"
+)
+
+func TestRelayRun(t *testing.T) {
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		wantPublished int
+		wantErr       bool
+	}{
+		{
+			name: "DrainsOutbox",
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					store.EXPECT().RelayOutboxTx(gomock.Any(), gomock.Any()).Times(2).
+						Return(db.RelayOutboxTxResult{Published: 3, Locked: true}, nil),
+					store.EXPECT().RelayOutboxTx(gomock.Any(), gomock.Any()).Times(1).
+						Return(db.RelayOutboxTxResult{Published: 1, Locked: true}, nil),
+				)
+			},
+			wantPublished: 7,
+		},
+		{
+			name: "StopsOnFailedEvents",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().RelayOutboxTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.RelayOutboxTxResult{Published: 2, Failed: 1, Locked: true}, nil)
+			},
+			wantPublished: 2,
+		},
+		{
+			name: "AnotherRelayRuns",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().RelayOutboxTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.RelayOutboxTxResult{}, nil)
+			},
+		},
+		{
+			name: "StoreError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().RelayOutboxTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.RelayOutboxTxResult{}, errors.New("connection reset"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			relay := NewRelay(store, &event.MemoryPublisher{}, 3)
+			published, err := relay.Run(context.Background())
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.wantPublished, published)
+		})
+	}
+}
+
+func TestRelayPublishes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	publisher := &event.MemoryPublisher{}
+	e := event.Event{ID: 1, Type: event.TypeAccountCreated, AggregateType: event.AggregateAccount, AggregateID: "7"}
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().RelayOutboxTx(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(ctx context.Context, arg db.RelayOutboxTxParams) (db.RelayOutboxTxResult, error) {
+			require.Equal(t, int32(defaultBatchSize), arg.Limit)
+			require.NoError(t, arg.Publish(ctx, e))
+			return db.RelayOutboxTxResult{Published: 1, Locked: true}, nil
+		})
+
+	_, err := NewRelay(store, publisher, 0).Run(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []event.Event{e}, publisher.Events())
+}
+
+func TestRelayPrune(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	before := time.Now().Add(-time.Hour)
+
+	store := mockdb.NewMockStore(ctrl)
+	gomock.InOrder(
+		store.EXPECT().DeletePublishedOutboxEvents(gomock.Any(), db.DeletePublishedOutboxEventsParams{
+			PublishedBefore: pgtype.Timestamptz{Time: before, Valid: true},
+			LimitCount:      3,
+		}).Times(1).Return(int64(3), nil),
+		store.EXPECT().DeletePublishedOutboxEvents(gomock.Any(), gomock.Any()).Times(1).
+			Return(int64(1), nil),
+	)
+
+	deleted, err := NewRelay(store, &event.MemoryPublisher{}, 3).Prune(context.Background(), before)
+	require.NoError(t, err)
+	require.Equal(t, int64(4), deleted)
+}
@@ -0,0 +1,47 @@
+package reconcile
+
+import (
+	"context"
+	"expvar"
+	"log"
+
+	db "github.com/vlone310/bss/internal/db/sqlc"
+)
+
+// Metrics are published through expvar at /admin/debug/vars.
+var (
+	runsTotal     = expvar.NewInt("reconciliation_runs_total")
+	discrepancies = expvar.NewInt("reconciliation_discrepancies")
+	lastRunUnix   = expvar.NewInt("reconciliation_last_run_unix")
+)
+
+// Reconciler checks the ledger against the cached balances and records what
+// it finds.
+type Reconciler struct {
+	store  db.Store
+	freeze bool
+}
+
+// New returns a Reconciler. With freeze set, active customer accounts with a
+// discrepancy are frozen.
+func New(store db.Store, freeze bool) *Reconciler {
+	return &Reconciler{store: store, freeze: freeze}
+}
+
+func (r *Reconciler) Run(ctx context.Context) (db.ReconcileTxResult, error) {
+	result, err := r.store.ReconcileTx(ctx, db.ReconcileTxParams{FreezeAccounts: r.freeze})
+	if err != nil {
+		return result, err
+	}
+
+	runsTotal.Add(1)
+	discrepancies.Set(result.Run.DiscrepancyCount)
+	lastRunUnix.Set(result.Run.CreatedAt.Time.Unix())
+
+	if result.Run.DiscrepancyCount > 0 {
+		log.Printf("reconciliation run %d found %d discrepancies, froze %d accounts",
+			result.Run.ID, result.Run.DiscrepancyCount, len(result.FrozenAccountIDs))
+	}
+
+	return result, nil
+}
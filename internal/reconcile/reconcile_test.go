@@ -0,0 +1,41 @@
+package reconcile
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/vlone310/bss/internal/db/mock"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+)
+
+func TestReconcilerRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Now().UTC()
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().ReconcileTx(gomock.Any(), gomock.Eq(db.ReconcileTxParams{FreezeAccounts: true})).
+		Times(1).
+		Return(db.ReconcileTxResult{
+			Run: db.ReconciliationRun{
+				ID:               1,
+				DiscrepancyCount: 3,
+				CreatedAt:        pgtype.Timestamptz{Time: now, Valid: true},
+			},
+			FrozenAccountIDs: []int64{7},
+		}, nil)
+
+	before := runsTotal.Value()
+
+	result, err := New(store, true).Run(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []int64{7}, result.FrozenAccountIDs)
+
+	require.Equal(t, before+1, runsTotal.Value())
+	require.Equal(t, int64(3), discrepancies.Value())
+	require.Equal(t, now.Unix(), lastRunUnix.Value())
+}
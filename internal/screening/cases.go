@@ -0,0 +1,100 @@
+package screening
+
+import (
+	"context"
+
+	db "github.com/vlone310/bss/internal/db/sqlc"
+)
+
+// Matches converts hits for the store.
+func Matches(hits []Hit) []db.ScreeningMatch {
+	matches := make([]db.ScreeningMatch, 0, len(hits))
+	for _, hit := range hits {
+		matches = append(matches, db.ScreeningMatch{
+			ListSource:  hit.Source,
+			EntryUID:    hit.UID,
+			ListedName:  hit.ListedName,
+			MatchedName: hit.MatchedName,
+			Score:       hit.Score,
+		})
+	}
+	return matches
+}
+
+// ScreenUser returns the list entries the full name of a new user matched,
+// for CreateUserTx to open a case with. The outcome is not disclosed to the
+// user.
+func (s *Screener) ScreenUser(fullName string) []db.ScreeningMatch {
+	hits := s.Screen(fullName)
+	if len(hits) == 0 {
+		return nil
+	}
+	return Matches(hits)
+}
+
+// ScreenParties screens the owners of both sides of a transfer and returns
+// a case for each owner whose name matched. List entries an owner was
+// cleared of before are left out, so a false positive does not hold every
+// transfer they make.
+func (s *Screener) ScreenParties(ctx context.Context, store db.Store, owners ...string) ([]db.OpenScreeningCaseTxParams, error) {
+	if s.Len() == 0 {
+		return nil, nil
+	}
+
+	var cases []db.OpenScreeningCaseTxParams
+	screened := make(map[string]bool, len(owners))
+	for _, owner := range owners {
+		if screened[owner] {
+			continue
+		}
+		screened[owner] = true
+
+		user, err := store.GetUser(ctx, owner)
+		if err != nil {
+			return nil, err
+		}
+
+		hits := s.Screen(user.FullName)
+		if len(hits) == 0 {
+			continue
+		}
+
+		cleared, err := store.ListClearedScreeningEntries(ctx, owner)
+		if err != nil {
+			return nil, err
+		}
+
+		hits = withoutCleared(hits, cleared)
+		if len(hits) == 0 {
+			continue
+		}
+
+		cases = append(cases, db.OpenScreeningCaseTxParams{
+			Username:     user.Username,
+			ScreenedName: user.FullName,
+			Matches:      Matches(hits),
+		})
+	}
+
+	return cases, nil
+}
+
+func withoutCleared(hits []Hit, cleared []db.ListClearedScreeningEntriesRow) []Hit {
+	if len(cleared) == 0 {
+		return hits
+	}
+
+	type entryKey struct{ source, uid string }
+	skip := make(map[entryKey]bool, len(cleared))
+	for _, entry := range cleared {
+		skip[entryKey{entry.ListSource, entry.EntryUid}] = true
+	}
+
+	kept := hits[:0]
+	for _, hit := range hits {
+		if !skip[entryKey{hit.Source, hit.UID}] {
+			kept = append(kept, hit)
+		}
+	}
+	return kept
+}
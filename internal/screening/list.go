@@ -0,0 +1,183 @@
+// Package screening matches names against sanctions lists, such as the
+// OFAC Specially Designated Nationals (SDN) list.
+package screening
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var ErrUnknownFormat = errors.New("unknown sanctions list format")
+
+// Entry is a sanctioned party.
+type Entry struct {
+	UID      string
+	Name     string
+	Aliases  []string
+	Type     string
+	Programs []string
+}
+
+// List is a sanctions list loaded from one file. Source names the file and
+// is stored with every match.
+type List struct {
+	Source  string
+	Entries []Entry
+}
+
+// LoadFile reads a list in the format given by the file extension, .csv or
+// .xml.
+func LoadFile(path string) (List, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return List{}, err
+	}
+	defer f.Close()
+
+	source := filepath.Base(path)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return ParseCSV(f, source)
+	case ".xml":
+		return ParseXML(f, source)
+	}
+
+	return List{}, fmt.Errorf("%w: %s", ErrUnknownFormat, path)
+}
+
+// ofacNull is how the OFAC CSV files spell an empty field.
+const ofacNull = "-0-"
+
+// ParseCSV reads the layout of the OFAC sdn.csv file: ent_num, SDN_Name,
+// SDN_Type, Program, followed by columns that are ignored. A header row is
+// skipped when the first field is not a number. Individuals are listed as
+// "LAST, First", which the matcher handles since token order is ignored.
+func ParseCSV(r io.Reader, source string) (List, error) {
+	list := List{Source: source}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return list, err
+		}
+
+		if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+		if len(record) < 2 {
+			return list, fmt.Errorf("%s line %d: expected at least 2 fields, got %d", source, line, len(record))
+		}
+		if line == 1 && !isNumber(strings.TrimSpace(record[0])) {
+			continue
+		}
+
+		entry := Entry{
+			UID:  strings.TrimSpace(record[0]),
+			Name: csvField(record, 1),
+			Type: csvField(record, 2),
+		}
+		if program := csvField(record, 3); program != "" {
+			entry.Programs = strings.Fields(strings.NewReplacer("[", "", "]", "").Replace(program))
+		}
+		if entry.Name == "" {
+			continue
+		}
+
+		list.Entries = append(list.Entries, entry)
+	}
+
+	return list, nil
+}
+
+func csvField(record []string, i int) string {
+	if i >= len(record) {
+		return ""
+	}
+	value := strings.TrimSpace(record[i])
+	if value == ofacNull {
+		return ""
+	}
+	return value
+}
+
+func isNumber(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// sdnList is the part of the OFAC sdn.xml schema the matcher needs. The
+// elements are matched by local name, so the namespace of the published
+// file does not matter.
+type sdnList struct {
+	Entries []sdnEntry `xml:"sdnEntry"`
+}
+
+type sdnEntry struct {
+	UID       string   `xml:"uid"`
+	FirstName string   `xml:"firstName"`
+	LastName  string   `xml:"lastName"`
+	Type      string   `xml:"sdnType"`
+	Programs  []string `xml:"programList>program"`
+	Akas      []sdnAka `xml:"akaList>aka"`
+}
+
+type sdnAka struct {
+	FirstName string `xml:"firstName"`
+	LastName  string `xml:"lastName"`
+}
+
+// ParseXML reads the OFAC sdn.xml format, including the aliases.
+func ParseXML(r io.Reader, source string) (List, error) {
+	list := List{Source: source}
+
+	var doc sdnList
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return list, err
+	}
+
+	for _, e := range doc.Entries {
+		entry := Entry{
+			UID:      strings.TrimSpace(e.UID),
+			Name:     joinName(e.FirstName, e.LastName),
+			Type:     strings.TrimSpace(e.Type),
+			Programs: e.Programs,
+		}
+		for _, aka := range e.Akas {
+			if alias := joinName(aka.FirstName, aka.LastName); alias != "" {
+				entry.Aliases = append(entry.Aliases, alias)
+			}
+		}
+		if entry.Name == "" {
+			continue
+		}
+
+		list.Entries = append(list.Entries, entry)
+	}
+
+	return list, nil
+}
+
+func joinName(first, last string) string {
+	return strings.TrimSpace(strings.TrimSpace(first) + " " + strings.TrimSpace(last))
+}
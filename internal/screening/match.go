@@ -0,0 +1,153 @@
+package screening
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// tokenize normalizes a name into lower case ASCII words. Diacritics are
+// dropped, so "José" and "JOSE" compare equal, and punctuation separates
+// words, so "PETROV, Ivan" gives the same words as "Ivan Petrov".
+func tokenize(name string) []string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(name) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// combining mark left over from the decomposition
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			b.WriteRune(' ')
+		}
+	}
+
+	return strings.Fields(b.String())
+}
+
+// nameScore is the similarity of two tokenized names between 0 and 1. Words
+// are compared with Jaro-Winkler and paired one to one, best pairs first;
+// pairs below tokenThreshold do not count and word order is ignored.
+//
+// The score is the average coverage of both names, except that a name of at
+// least two words found entirely in the other one scores its full coverage,
+// so a listed "Ivan Petrov" matches the customer "Ivan Sergeyevich Petrov".
+// A single common word alone never does.
+func nameScore(a, b []string, tokenThreshold float64) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	sum := pairedSimilarity(a, b, tokenThreshold)
+	ab := sum / float64(len(a))
+	ba := sum / float64(len(b))
+
+	score := (ab + ba) / 2
+	if len(a) >= 2 && ab > score {
+		score = ab
+	}
+	if len(b) >= 2 && ba > score {
+		score = ba
+	}
+
+	return score
+}
+
+// pairedSimilarity pairs the words of a and b greedily, most similar first,
+// so that one word cannot stand in for several, and sums the similarity of
+// the pairs that reach tokenThreshold.
+func pairedSimilarity(a, b []string, tokenThreshold float64) float64 {
+	type pair struct {
+		i, j int
+		sim  float64
+	}
+
+	var pairs []pair
+	for i, x := range a {
+		for j, y := range b {
+			if sim := jaroWinkler(x, y); sim >= tokenThreshold {
+				pairs = append(pairs, pair{i, j, sim})
+			}
+		}
+	}
+	slices.SortStableFunc(pairs, func(p, q pair) int {
+		return cmp.Compare(q.sim, p.sim)
+	})
+
+	usedA := make([]bool, len(a))
+	usedB := make([]bool, len(b))
+
+	var sum float64
+	for _, p := range pairs {
+		if usedA[p.i] || usedB[p.j] {
+			continue
+		}
+		usedA[p.i], usedB[p.j] = true, true
+		sum += p.sim
+	}
+
+	return sum
+}
+
+// jaroWinkler returns the Jaro-Winkler similarity of two words.
+func jaroWinkler(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+
+	s, t := []rune(a), []rune(b)
+	if len(s) == 0 || len(t) == 0 {
+		return 0
+	}
+
+	window := max(len(s), len(t))/2 - 1
+	window = max(window, 0)
+
+	sMatched := make([]bool, len(s))
+	tMatched := make([]bool, len(t))
+
+	matches := 0
+	for i := range s {
+		lo, hi := max(0, i-window), min(len(t), i+window+1)
+		for j := lo; j < hi; j++ {
+			if tMatched[j] || s[i] != t[j] {
+				continue
+			}
+			sMatched[i], tMatched[j] = true, true
+			matches++
+			break
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	transpositions := 0
+	j := 0
+	for i := range s {
+		if !sMatched[i] {
+			continue
+		}
+		for !tMatched[j] {
+			j++
+		}
+		if s[i] != t[j] {
+			transpositions++
+		}
+		j++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(len(s)) + m/float64(len(t)) + (m-float64(transpositions)/2)/m) / 3
+
+	// Winkler boost for a common prefix of up to four characters
+	prefix := 0
+	for prefix < min(4, len(s), len(t)) && s[prefix] == t[prefix] {
+		prefix++
+	}
+
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
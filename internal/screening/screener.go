@@ -0,0 +1,145 @@
+package screening
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+)
+
+const (
+	// DefaultMatchThreshold is the name score from which a name matches.
+	DefaultMatchThreshold = 0.9
+	// DefaultTokenThreshold is the similarity from which two words are
+	// considered the same, allowing for transliteration and typos.
+	DefaultTokenThreshold = 0.88
+)
+
+// Hit is a list entry a name matched.
+type Hit struct {
+	Source string
+	UID    string
+	// ListedName is the primary name of the entry, MatchedName the name or
+	// alias that matched.
+	ListedName  string
+	MatchedName string
+	Score       float64
+}
+
+type indexedName struct {
+	name   string
+	tokens []string
+}
+
+type indexedEntry struct {
+	source string
+	entry  Entry
+	names  []indexedName
+}
+
+// Screener matches names against the loaded lists. It is safe for
+// concurrent use and the lists can be replaced while it runs.
+type Screener struct {
+	matchThreshold float64
+	tokenThreshold float64
+
+	mu      sync.RWMutex
+	entries []indexedEntry
+}
+
+// NewScreener returns a screener without lists. Thresholds of zero use the
+// defaults.
+func NewScreener(matchThreshold, tokenThreshold float64) *Screener {
+	if matchThreshold <= 0 {
+		matchThreshold = DefaultMatchThreshold
+	}
+	if tokenThreshold <= 0 {
+		tokenThreshold = DefaultTokenThreshold
+	}
+
+	return &Screener{
+		matchThreshold: matchThreshold,
+		tokenThreshold: tokenThreshold,
+	}
+}
+
+// Load reads the list files and replaces the loaded lists with them. The
+// loaded lists stay in place when a file cannot be read.
+func (s *Screener) Load(paths ...string) error {
+	lists := make([]List, 0, len(paths))
+	for _, path := range paths {
+		list, err := LoadFile(path)
+		if err != nil {
+			return err
+		}
+		lists = append(lists, list)
+	}
+
+	s.Replace(lists...)
+	return nil
+}
+
+// Replace swaps the loaded lists for the given ones.
+func (s *Screener) Replace(lists ...List) {
+	var entries []indexedEntry
+	for _, list := range lists {
+		for _, entry := range list.Entries {
+			indexed := indexedEntry{source: list.Source, entry: entry}
+			for _, name := range append([]string{entry.Name}, entry.Aliases...) {
+				if tokens := tokenize(name); len(tokens) > 0 {
+					indexed.names = append(indexed.names, indexedName{name: name, tokens: tokens})
+				}
+			}
+			entries = append(entries, indexed)
+		}
+	}
+
+	s.mu.Lock()
+	s.entries = entries
+	s.mu.Unlock()
+}
+
+// Len returns the number of loaded entries.
+func (s *Screener) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.entries)
+}
+
+// Screen returns the entries the name matches, best match first. Each entry
+// is reported once, with the best matching of its names.
+func (s *Screener) Screen(name string) []Hit {
+	tokens := tokenize(name)
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var hits []Hit
+	for _, indexed := range s.entries {
+		best := Hit{Score: -1}
+		for _, candidate := range indexed.names {
+			if score := nameScore(tokens, candidate.tokens, s.tokenThreshold); score > best.Score {
+				best = Hit{
+					Source:      indexed.source,
+					UID:         indexed.entry.UID,
+					ListedName:  indexed.entry.Name,
+					MatchedName: candidate.name,
+					Score:       score,
+				}
+			}
+		}
+
+		if best.Score >= s.matchThreshold {
+			hits = append(hits, best)
+		}
+	}
+
+	slices.SortFunc(hits, func(a, b Hit) int {
+		return cmp.Or(cmp.Compare(b.Score, a.Score), cmp.Compare(a.Source, b.Source), cmp.Compare(a.UID, b.UID))
+	})
+
+	return hits
+}
@@ -0,0 +1,123 @@
+package screening
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testSDNCSV = `ent_num,SDN_Name,SDN_Type,Program,Title,Call_Sign,Vess_type,Tonnage,GRT,Vess_flag,Vess_owner,Remarks
+36,"AEROCARIBBEAN AIRLINES",-0- ,"CUBA",-0- ,-0- ,-0- ,-0- ,-0- ,-0- ,-0- ,-0-
+2674,"PETROV, Ivan Sergeyevich","individual","RUSSIA-EO14024",-0- ,-0- ,-0- ,-0- ,-0- ,-0- ,-0- ,"DOB 01 Jan 1970."
+`
+
+const testSDNXML = `<?xml version="1.0" standalone="yes"?>
+<sdnList xmlns="https://sanctionslistservice.ofac.treas.gov/api/PublicationPreview/exports/XML">
+  <publshInformation><Publish_Date>10/01/2026</Publish_Date></publshInformation>
+  <sdnEntry>
+    <uid>7001</uid>
+    <firstName>José</firstName>
+    <lastName>GARCÍA MÁRQUEZ</lastName>
+    <sdnType>Individual</sdnType>
+    <programList><program>SDNT</program></programList>
+    <akaList>
+      <aka><uid>9001</uid><type>a.k.a.</type><category>strong</category><lastName>EL PROFESOR</lastName></aka>
+    </akaList>
+  </sdnEntry>
+</sdnList>`
+
+func TestParseCSV(t *testing.T) {
+	list, err := ParseCSV(strings.NewReader(testSDNCSV), "sdn.csv")
+	require.NoError(t, err)
+	require.Equal(t, "sdn.csv", list.Source)
+	require.Len(t, list.Entries, 2)
+
+	require.Equal(t, "36", list.Entries[0].UID)
+	require.Equal(t, "AEROCARIBBEAN AIRLINES", list.Entries[0].Name)
+	require.Empty(t, list.Entries[0].Type)
+
+	require.Equal(t, "2674", list.Entries[1].UID)
+	require.Equal(t, "PETROV, Ivan Sergeyevich", list.Entries[1].Name)
+	require.Equal(t, []string{"RUSSIA-EO14024"}, list.Entries[1].Programs)
+}
+
+func TestParseXML(t *testing.T) {
+	list, err := ParseXML(strings.NewReader(testSDNXML), "sdn.xml")
+	require.NoError(t, err)
+	require.Len(t, list.Entries, 1)
+
+	entry := list.Entries[0]
+	require.Equal(t, "7001", entry.UID)
+	require.Equal(t, "José GARCÍA MÁRQUEZ", entry.Name)
+	require.Equal(t, "Individual", entry.Type)
+	require.Equal(t, []string{"SDNT"}, entry.Programs)
+	require.Equal(t, []string{"EL PROFESOR"}, entry.Aliases)
+}
+
+func TestJaroWinkler(t *testing.T) {
+	require.InDelta(t, 0.961, jaroWinkler("martha", "marhta"), 0.001)
+	require.InDelta(t, 0.840, jaroWinkler("dwayne", "duane"), 0.001)
+	require.InDelta(t, 0.813, jaroWinkler("dixon", "dicksonx"), 0.001)
+	require.Equal(t, 1.0, jaroWinkler("ivan", "ivan"))
+	require.Zero(t, jaroWinkler("abc", "xyz"))
+}
+
+func TestTokenize(t *testing.T) {
+	require.Equal(t, []string{"petrov", "ivan"}, tokenize("PETROV, Ivan"))
+	require.Equal(t, []string{"jose", "garcia", "marquez"}, tokenize("José García-Márquez"))
+	require.Empty(t, tokenize(" ,. "))
+}
+
+func TestScreen(t *testing.T) {
+	csvList, err := ParseCSV(strings.NewReader(testSDNCSV), "sdn.csv")
+	require.NoError(t, err)
+	xmlList, err := ParseXML(strings.NewReader(testSDNXML), "sdn.xml")
+	require.NoError(t, err)
+
+	screener := NewScreener(0, 0)
+	screener.Replace(csvList, xmlList)
+	require.Equal(t, 3, screener.Len())
+
+	testCases := []struct {
+		name    string
+		wantUID string
+	}{
+		{"Ivan Petrov", "2674"},
+		{"ivan sergeyevich petrov", "2674"},
+		{"Ivan Petrow", "2674"},
+		{"Jose Garcia Marquez", "7001"},
+		{"El Profesor", "7001"},
+		{"Aerocaribbean Airlines", "36"},
+		{"Ivan Ivanov", ""},
+		{"Ivan", ""},
+		{"Maria Garcia", ""},
+		{"", ""},
+	}
+
+	for _, tc := range testCases {
+		hits := screener.Screen(tc.name)
+		if tc.wantUID == "" {
+			require.Empty(t, hits, tc.name)
+			continue
+		}
+		require.NotEmpty(t, hits, tc.name)
+		require.Equal(t, tc.wantUID, hits[0].UID, tc.name)
+		require.GreaterOrEqual(t, hits[0].Score, DefaultMatchThreshold, tc.name)
+	}
+
+	hits := screener.Screen("El Profesor")
+	require.Equal(t, "EL PROFESOR", hits[0].MatchedName)
+	require.Equal(t, "José GARCÍA MÁRQUEZ", hits[0].ListedName)
+	require.Equal(t, "sdn.xml", hits[0].Source)
+}
+
+func TestScreenThreshold(t *testing.T) {
+	list, err := ParseCSV(strings.NewReader(testSDNCSV), "sdn.csv")
+	require.NoError(t, err)
+
+	strict := NewScreener(0.999, 0.999)
+	strict.Replace(list)
+	require.Empty(t, strict.Screen("Ivan Petrow"))
+	require.NotEmpty(t, strict.Screen("Ivan Petrov"))
+}
@@ -0,0 +1,80 @@
+// Package snapshot takes the end-of-day balance snapshots that historical
+// balance queries start from.
+package snapshot
+
+import (
+	"context"
+	"time"
+
+	db "github.com/vlone310/bss/internal/db/sqlc"
+)
+
+// settleDelay is how long after midnight a day is considered closed. Entries
+// carry the start time of their transaction, so a transfer that commits
+// just after midnight can still belong to the day before.
+const settleDelay = 5 * time.Minute
+
+type Snapshotter struct {
+	store db.Store
+	now   func() time.Time
+}
+
+func NewSnapshotter(store db.Store) *Snapshotter {
+	return &Snapshotter{store: store, now: time.Now}
+}
+
+// Run snapshots every day after the latest snapshot up to and including
+// through. Days that are not closed yet are skipped, they are picked up by
+// the next run.
+func (s *Snapshotter) Run(ctx context.Context, through time.Time) (db.SnapshotBalancesTxResult, error) {
+	var from time.Time
+
+	latest, err := s.store.GetLatestDailyBalanceDate(ctx)
+	if err != nil {
+		return db.SnapshotBalancesTxResult{}, err
+	}
+
+	if latest.Valid {
+		from = latest.Time.AddDate(0, 0, 1)
+	} else {
+		first, err := s.store.GetFirstEntryDate(ctx)
+		if err != nil {
+			return db.SnapshotBalancesTxResult{}, err
+		}
+		if !first.Valid {
+			return db.SnapshotBalancesTxResult{}, nil
+		}
+		from = first.Time
+	}
+
+	return s.snapshot(ctx, from, through, false)
+}
+
+// Rebuild replaces the snapshots from the given day on, e.g. after a
+// backdated correction was booked into a day that was already snapshotted.
+func (s *Snapshotter) Rebuild(ctx context.Context, from time.Time, through time.Time) (db.SnapshotBalancesTxResult, error) {
+	return s.snapshot(ctx, from, through, true)
+}
+
+func (s *Snapshotter) snapshot(ctx context.Context, from time.Time, through time.Time, rebuild bool) (db.SnapshotBalancesTxResult, error) {
+	from = dateOf(from)
+	through = dateOf(through)
+	if closed := dateOf(s.now().Add(-settleDelay)).AddDate(0, 0, -1); through.After(closed) {
+		through = closed
+	}
+
+	if from.After(through) && !rebuild {
+		return db.SnapshotBalancesTxResult{}, nil
+	}
+
+	return s.store.SnapshotBalancesTx(ctx, db.SnapshotBalancesTxParams{
+		From:    from,
+		Through: through,
+		Rebuild: rebuild,
+	})
+}
+
+func dateOf(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
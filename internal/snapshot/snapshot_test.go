@@ -0,0 +1,108 @@
+package snapshot
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/vlone310/bss/internal/db/mock"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+)
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestSnapshotterRun(t *testing.T) {
+	now := time.Date(2025, 3, 10, 0, 2, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name       string
+		through    time.Time
+		buildStubs func(store *mockdb.MockStore)
+	}{
+		{
+			name:    "ContinuesAfterLatest",
+			through: now,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetLatestDailyBalanceDate(gomock.Any()).Times(1).
+					Return(pgtype.Date{Time: date(2025, 3, 5), Valid: true}, nil)
+				store.EXPECT().GetFirstEntryDate(gomock.Any()).Times(0)
+				// March 9 ended only two minutes ago and is left for the next run
+				store.EXPECT().SnapshotBalancesTx(gomock.Any(), gomock.Eq(db.SnapshotBalancesTxParams{
+					From:    date(2025, 3, 6),
+					Through: date(2025, 3, 8),
+				})).Times(1)
+			},
+		},
+		{
+			name:    "StartsAtFirstEntry",
+			through: date(2025, 3, 2),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetLatestDailyBalanceDate(gomock.Any()).Times(1).Return(pgtype.Date{}, nil)
+				store.EXPECT().GetFirstEntryDate(gomock.Any()).Times(1).
+					Return(pgtype.Date{Time: date(2025, 2, 27), Valid: true}, nil)
+				store.EXPECT().SnapshotBalancesTx(gomock.Any(), gomock.Eq(db.SnapshotBalancesTxParams{
+					From:    date(2025, 2, 27),
+					Through: date(2025, 3, 2),
+				})).Times(1)
+			},
+		},
+		{
+			name:    "NoEntries",
+			through: now,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetLatestDailyBalanceDate(gomock.Any()).Times(1).Return(pgtype.Date{}, nil)
+				store.EXPECT().GetFirstEntryDate(gomock.Any()).Times(1).Return(pgtype.Date{}, nil)
+				store.EXPECT().SnapshotBalancesTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name:    "UpToDate",
+			through: now,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetLatestDailyBalanceDate(gomock.Any()).Times(1).
+					Return(pgtype.Date{Time: date(2025, 3, 8), Valid: true}, nil)
+				store.EXPECT().SnapshotBalancesTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			snapshotter := NewSnapshotter(store)
+			snapshotter.now = func() time.Time { return now }
+
+			_, err := snapshotter.Run(context.Background(), tc.through)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestSnapshotterRebuild(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().SnapshotBalancesTx(gomock.Any(), gomock.Eq(db.SnapshotBalancesTxParams{
+		From:    date(2025, 3, 1),
+		Through: date(2025, 3, 9),
+		Rebuild: true,
+	})).Times(1).Return(db.SnapshotBalancesTxResult{Deleted: 18, Created: 18}, nil)
+
+	snapshotter := NewSnapshotter(store)
+	snapshotter.now = func() time.Time { return time.Date(2025, 3, 10, 8, 0, 0, 0, time.UTC) }
+
+	result, err := snapshotter.Rebuild(context.Background(), time.Date(2025, 3, 1, 15, 0, 0, 0, time.UTC), time.Now())
+	require.NoError(t, err)
+	require.Equal(t, int64(18), result.Created)
+}
@@ -0,0 +1,121 @@
+// Package stream fans the entries the store notifies out to the clients
+// that follow an account.
+//
+// A single listener connection serves every subscriber. A subscriber that
+// falls behind is dropped rather than slowing the others down, and all of
+// them are dropped when the listener fails; a client resumes from the
+// entries table with the id of the last entry it got.
+package stream
+
+import (
+	"context"
+	"sync"
+
+	db "github.com/vlone310/bss/internal/db/sqlc"
+)
+
+const defaultBuffer = 64
+
+type Hub struct {
+	buffer int
+
+	mu   sync.Mutex
+	subs map[int64]map[*Subscription]struct{}
+}
+
+// Subscription receives the entries of an account on C. C is closed when the
+// subscriber is dropped.
+type Subscription struct {
+	C <-chan db.EntryNotification
+
+	hub       *Hub
+	accountID int64
+	c         chan db.EntryNotification
+}
+
+// NewHub returns a hub that keeps up to buffer entries per subscriber, or a
+// default for zero.
+func NewHub(buffer int) *Hub {
+	if buffer <= 0 {
+		buffer = defaultBuffer
+	}
+	return &Hub{buffer: buffer, subs: map[int64]map[*Subscription]struct{}{}}
+}
+
+// Run listens for entries until ctx is done or the listener fails. The
+// subscribers are dropped when it returns, since they miss entries until it
+// runs again.
+func (h *Hub) Run(ctx context.Context, store db.Store) error {
+	defer h.dropAll()
+	return store.ListenEntries(ctx, h.Publish)
+}
+
+func (h *Hub) Subscribe(accountID int64) *Subscription {
+	c := make(chan db.EntryNotification, h.buffer)
+	sub := &Subscription{C: c, hub: h, accountID: accountID, c: c}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.subs[accountID] == nil {
+		h.subs[accountID] = map[*Subscription]struct{}{}
+	}
+	h.subs[accountID][sub] = struct{}{}
+	return sub
+}
+
+// Close unsubscribes. It is safe to call after the subscriber was dropped.
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+
+	s.hub.drop(s)
+}
+
+// Publish hands the entry to the subscribers of its account without
+// blocking.
+func (h *Hub) Publish(n db.EntryNotification) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for sub := range h.subs[n.Entry.AccountID] {
+		select {
+		case sub.c <- n:
+		default:
+			h.drop(sub)
+		}
+	}
+}
+
+// Subscribers returns how many clients follow the account.
+func (h *Hub) Subscribers(accountID int64) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return len(h.subs[accountID])
+}
+
+// drop removes a subscriber and closes its channel. The caller must hold mu.
+func (h *Hub) drop(sub *Subscription) {
+	subs, ok := h.subs[sub.accountID]
+	if _, subscribed := subs[sub]; !ok || !subscribed {
+		return
+	}
+
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(h.subs, sub.accountID)
+	}
+	close(sub.c)
+}
+
+func (h *Hub) dropAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, subs := range h.subs {
+		for sub := range subs {
+			h.drop(sub)
+		}
+	}
+}
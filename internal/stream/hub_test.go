@@ -0,0 +1,86 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/vlone310/bss/internal/db/mock"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+)
+
+func notification(entryID, accountID int64) db.EntryNotification {
+	return db.EntryNotification{Entry: db.Entry{ID: entryID, AccountID: accountID}, Balance: entryID * 100}
+}
+
+func TestHubFanOut(t *testing.T) {
+	hub := NewHub(4)
+
+	first := hub.Subscribe(1)
+	second := hub.Subscribe(1)
+	other := hub.Subscribe(2)
+	require.Equal(t, 2, hub.Subscribers(1))
+
+	hub.Publish(notification(10, 1))
+
+	require.Equal(t, notification(10, 1), <-first.C)
+	require.Equal(t, notification(10, 1), <-second.C)
+	require.Empty(t, other.C)
+
+	first.Close()
+	first.Close()
+	require.Equal(t, 1, hub.Subscribers(1))
+	_, ok := <-first.C
+	require.False(t, ok)
+
+	second.Close()
+	other.Close()
+	require.Zero(t, hub.Subscribers(1))
+	require.Empty(t, hub.subs)
+}
+
+func TestHubDropsSlowSubscriber(t *testing.T) {
+	hub := NewHub(2)
+
+	slow := hub.Subscribe(1)
+	fast := hub.Subscribe(1)
+
+	for id := range int64(3) {
+		hub.Publish(notification(id+1, 1))
+		<-fast.C
+	}
+
+	// the buffered entries are still read before the channel closes
+	require.Equal(t, notification(1, 1), <-slow.C)
+	require.Equal(t, notification(2, 1), <-slow.C)
+	_, ok := <-slow.C
+	require.False(t, ok)
+
+	require.Equal(t, 1, hub.Subscribers(1))
+	slow.Close()
+	fast.Close()
+}
+
+func TestHubRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	hub := NewHub(0)
+	sub := hub.Subscribe(1)
+
+	store.EXPECT().ListenEntries(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, fn func(db.EntryNotification)) error {
+			fn(notification(10, 1))
+			return errors.New("connection reset")
+		})
+
+	require.Error(t, hub.Run(context.Background(), store))
+
+	// a failed listener drops the subscribers, they missed entries
+	require.Equal(t, notification(10, 1), <-sub.C)
+	_, ok := <-sub.C
+	require.False(t, ok)
+	require.Zero(t, hub.Subscribers(1))
+}
@@ -0,0 +1,100 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+	"github.com/vlone310/bss/internal/money"
+	"github.com/vlone310/bss/internal/screening"
+)
+
+// ErrAccountNotFound is also returned for an account the sender does not
+// own, so that its id reveals nothing.
+var ErrAccountNotFound = errors.New("account not found")
+
+// Service makes the transfers customers send, for the HTTP and the gRPC
+// server alike. The transfer rules are left to the store, its errors are
+// returned as they are.
+type Service struct {
+	store    db.Store
+	screener *screening.Screener
+}
+
+func NewService(store db.Store, screener *screening.Screener) *Service {
+	return &Service{store: store, screener: screener}
+}
+
+type Request struct {
+	// Sender must own the account the money leaves.
+	Sender        string
+	FromAccountID int64
+	ToAccountID   int64
+	Amount        money.Money
+}
+
+type Result struct {
+	// Transfer is pending when it was held for screening or by the fraud
+	// engine.
+	Transfer db.Transfer
+	// ConfirmationRequired is set when the fraud engine challenged the
+	// transfer and the sender has to confirm it.
+	ConfirmationRequired bool
+}
+
+// Create moves money from an account of the sender. A transfer between
+// parties on a sanctions list is held for compliance, the sender only
+// learns that it is pending.
+func (s *Service) Create(ctx context.Context, req Request) (Result, error) {
+	from, err := s.account(ctx, req.FromAccountID)
+	if err != nil {
+		return Result{}, err
+	}
+	if from.Owner != req.Sender {
+		return Result{}, ErrAccountNotFound
+	}
+
+	to, err := s.account(ctx, req.ToAccountID)
+	if err != nil {
+		return Result{}, err
+	}
+
+	arg := db.TransferTxParams{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        req.Amount,
+	}
+
+	cases, err := s.screener.ScreenParties(ctx, s.store, from.Owner, to.Owner)
+	if err != nil {
+		return Result{}, err
+	}
+
+	if len(cases) > 0 {
+		result, err := s.store.HoldTransferTx(ctx, db.HoldTransferTxParams{TransferTxParams: arg, Cases: cases})
+		if err != nil {
+			return Result{}, err
+		}
+		return Result{Transfer: result.Transfer}, nil
+	}
+
+	result, err := s.store.TransferTx(ctx, arg)
+	if err != nil {
+		return Result{}, err
+	}
+
+	decision := result.FraudDecision
+	return Result{
+		Transfer:             result.Transfer,
+		ConfirmationRequired: decision != nil && decision.Action == db.FraudActionChallenge,
+	}, nil
+}
+
+func (s *Service) account(ctx context.Context, id int64) (db.Account, error) {
+	account, err := s.store.GetAccount(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return account, ErrAccountNotFound
+	}
+	return account, err
+}
@@ -0,0 +1,177 @@
+package transfer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/vlone310/bss/internal/db/mock"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+	"github.com/vlone310/bss/internal/money"
+	"github.com/vlone310/bss/internal/screening"
+	"github.com/vlone310/bss/testutil"
+)
+
+const sanctionedName = "Ivan Petrov"
+
+func randomAccount() db.Account {
+	return db.Account{
+		ID:       testutil.RandomInt(1, 1000),
+		Owner:    testutil.RandomOwner(),
+		Balance:  1_000,
+		Currency: testutil.RandomCurrency(),
+		Product:  db.ProductChecking,
+		Status:   db.AccountStatusActive,
+	}
+}
+
+func TestCreate(t *testing.T) {
+	from := randomAccount()
+	to := randomAccount()
+	to.ID = from.ID + 1
+	to.Currency = from.Currency
+
+	arg := db.TransferTxParams{FromAccountID: from.ID, ToAccountID: to.ID, Amount: money.New(100, from.Currency)}
+	booked := db.Transfer{
+		ID:            7,
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		AmountCents:   100,
+		Status:        db.TransferStatusCompleted,
+		CreatedAt:     pgtype.Timestamptz{Time: time.Now(), Valid: true},
+	}
+	pending := booked
+	pending.Status = db.TransferStatusPending
+
+	testCases := []struct {
+		name       string
+		sender     string
+		sanctioned bool
+		buildStubs func(store *mockdb.MockStore)
+		check      func(t *testing.T, result Result, err error)
+	}{
+		{
+			name:   "OK",
+			sender: from.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(from.ID)).Times(1).Return(from, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(to.ID)).Times(1).Return(to, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.TransferTxResult{Transfer: booked}, nil)
+			},
+			check: func(t *testing.T, result Result, err error) {
+				require.NoError(t, err)
+				require.Equal(t, booked, result.Transfer)
+				require.False(t, result.ConfirmationRequired)
+			},
+		},
+		{
+			name:   "Challenged",
+			sender: from.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(from.ID)).Times(1).Return(from, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(to.ID)).Times(1).Return(to, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.TransferTxResult{
+					Transfer:      pending,
+					FraudDecision: &db.FraudDecision{Action: db.FraudActionChallenge},
+				}, nil)
+			},
+			check: func(t *testing.T, result Result, err error) {
+				require.NoError(t, err)
+				require.Equal(t, db.TransferStatusPending, result.Transfer.Status)
+				require.True(t, result.ConfirmationRequired)
+			},
+		},
+		{
+			name:       "Held",
+			sender:     from.Owner,
+			sanctioned: true,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(from.ID)).Times(1).Return(from, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(to.ID)).Times(1).Return(to, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(from.Owner)).Times(1).
+					Return(db.User{Username: from.Owner, FullName: sanctionedName}, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(to.Owner)).Times(1).
+					Return(db.User{Username: to.Owner, FullName: "Jane Doe"}, nil)
+				store.EXPECT().ListClearedScreeningEntries(gomock.Any(), gomock.Eq(from.Owner)).Times(1).Return(nil, nil)
+				store.EXPECT().HoldTransferTx(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, arg db.HoldTransferTxParams) (db.HoldTransferTxResult, error) {
+						require.Len(t, arg.Cases, 1)
+						require.Equal(t, from.Owner, arg.Cases[0].Username)
+						return db.HoldTransferTxResult{Transfer: pending}, nil
+					})
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, result Result, err error) {
+				require.NoError(t, err)
+				require.Equal(t, db.TransferStatusPending, result.Transfer.Status)
+				require.False(t, result.ConfirmationRequired)
+			},
+		},
+		{
+			name:   "NotOwner",
+			sender: to.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(from.ID)).Times(1).Return(from, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, result Result, err error) {
+				require.ErrorIs(t, err, ErrAccountNotFound)
+			},
+		},
+		{
+			name:   "ToAccountNotFound",
+			sender: from.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(from.ID)).Times(1).Return(from, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(to.ID)).Times(1).Return(db.Account{}, pgx.ErrNoRows)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, result Result, err error) {
+				require.ErrorIs(t, err, ErrAccountNotFound)
+			},
+		},
+		{
+			name:   "StoreError",
+			sender: from.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(from.ID)).Times(1).Return(from, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(to.ID)).Times(1).Return(to, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.TransferTxResult{}, db.ErrInsufficientFunds)
+			},
+			check: func(t *testing.T, result Result, err error) {
+				require.ErrorIs(t, err, db.ErrInsufficientFunds)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			screener := screening.NewScreener(0, 0)
+			if tc.sanctioned {
+				screener.Replace(screening.List{
+					Source:  "sdn.csv",
+					Entries: []screening.Entry{{UID: "2674", Name: "PETROV, Ivan Sergeyevich", Type: "individual"}},
+				})
+			}
+
+			service := NewService(store, screener)
+			result, err := service.Create(context.Background(), Request{
+				Sender:        tc.sender,
+				FromAccountID: from.ID,
+				ToAccountID:   to.ID,
+				Amount:        arg.Amount,
+			})
+			tc.check(t, result, err)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"syscall"
+)
+
+// ErrForbiddenAddress is returned for an endpoint on a loopback, private or
+// link-local network, which a customer must not be able to make the server
+// call.
+var ErrForbiddenAddress = errors.New("webhook address is not public")
+
+// NewClient returns the client deliveries are sent with. It does not follow
+// redirects, and unless allowPrivate it refuses to connect to a forbidden
+// address. The address is checked when the connection is made, so a name
+// that resolves to a public address when the endpoint is registered and to
+// a private one later is refused as well.
+func NewClient(allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: defaultTimeout}
+	if !allowPrivate {
+		dialer.Control = checkDial
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// a proxy would connect to the endpoint in place of the dialer
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   defaultTimeout,
+		// a redirect is answered like any other status that is not 2xx
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// CheckHost fails with ErrForbiddenAddress for the host of an endpoint
+// that is a forbidden address or a name for the local host. Other names are
+// only checked when a delivery connects, see NewClient.
+func CheckHost(host string) error {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+	}
+
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return nil
+	}
+	if forbiddenAddr(addr) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, addr)
+	}
+	return nil
+}
+
+// checkDial is the net.Dialer Control that refuses forbidden addresses. It
+// sees the address the name was resolved to.
+func checkDial(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, address)
+	}
+	if forbiddenAddr(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, addrPort.Addr())
+	}
+	return nil
+}
+
+func forbiddenAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsLoopback() ||
+		addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() ||
+		addr.IsUnspecified()
+}
@@ -0,0 +1,54 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientRefusesPrivateAddresses(t *testing.T) {
+	receiver := httptest.NewServer(http.NotFoundHandler())
+	defer receiver.Close()
+
+	request, err := http.NewRequestWithContext(context.Background(), http.MethodPost, receiver.URL, nil)
+	require.NoError(t, err)
+
+	_, err = NewClient(false).Do(request)
+	require.ErrorIs(t, err, ErrForbiddenAddress)
+
+	response, err := NewClient(true).Do(request)
+	require.NoError(t, err)
+	response.Body.Close()
+	require.Equal(t, http.StatusNotFound, response.StatusCode)
+}
+
+func TestClientDoesNotFollowRedirects(t *testing.T) {
+	var followed bool
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/internal" {
+			followed = true
+			return
+		}
+		http.Redirect(w, r, "/internal", http.StatusTemporaryRedirect)
+	}))
+	defer receiver.Close()
+
+	response, err := NewClient(true).Post(receiver.URL, "application/json", nil)
+	require.NoError(t, err)
+	response.Body.Close()
+	require.Equal(t, http.StatusTemporaryRedirect, response.StatusCode)
+	require.False(t, followed)
+}
+
+func TestCheckHost(t *testing.T) {
+	for _, host := range []string{"localhost", "api.localhost", "127.0.0.1", "::1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254", "fe80::1", "fd00::1", "0.0.0.0", "::ffff:127.0.0.1"} {
+		require.ErrorIs(t, CheckHost(host), ErrForbiddenAddress, host)
+	}
+
+	for _, host := range []string{"example.com", "93.184.215.14", "2606:2800:21f:cb07:6820:80da:af6b:8b2c"} {
+		require.NoError(t, CheckHost(host), host)
+	}
+}
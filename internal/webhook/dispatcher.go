@@ -0,0 +1,89 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	db "github.com/vlone310/bss/internal/db/sqlc"
+	"github.com/vlone310/bss/internal/event"
+)
+
+// Dispatcher is the event.Publisher that queues a delivery of each event
+// for the endpoints of the users it concerns. The Sender makes them.
+type Dispatcher struct {
+	store db.Store
+}
+
+func NewDispatcher(store db.Store) *Dispatcher {
+	return &Dispatcher{store: store}
+}
+
+// Publish queues the event. An event published again is not queued twice.
+func (d *Dispatcher) Publish(ctx context.Context, e event.Event) error {
+	owners, err := d.owners(ctx, e)
+	if err != nil || len(owners) == 0 {
+		return err
+	}
+
+	endpoints, err := d.store.ListWebhookEndpointsForEvent(ctx, db.ListWebhookEndpointsForEventParams{
+		Owners:    owners,
+		EventType: e.Type,
+	})
+	if err != nil || len(endpoints) == 0 {
+		return err
+	}
+
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	for _, endpoint := range endpoints {
+		err = d.store.CreateWebhookDelivery(ctx, db.CreateWebhookDeliveryParams{
+			EndpointID: endpoint.ID,
+			EventID:    e.ID,
+			EventType:  e.Type,
+			Payload:    payload,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// owners returns the users an event concerns: the owner of an account, or
+// the owners of both accounts of a transfer.
+func (d *Dispatcher) owners(ctx context.Context, e event.Event) ([]string, error) {
+	switch e.AggregateType {
+	case event.AggregateAccount:
+		var account event.AccountV1
+		if err := json.Unmarshal(e.Data, &account); err != nil {
+			return nil, fmt.Errorf("event [%d]: %w", e.ID, err)
+		}
+		return []string{account.Owner}, nil
+
+	case event.AggregateTransfer:
+		var transfer event.TransferV1
+		if err := json.Unmarshal(e.Data, &transfer); err != nil {
+			return nil, fmt.Errorf("event [%d]: %w", e.ID, err)
+		}
+
+		owners := make([]string, 0, 2)
+		for _, id := range []int64{transfer.FromAccountID, transfer.ToAccountID} {
+			account, err := d.store.GetAccount(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			if !slices.Contains(owners, account.Owner) {
+				owners = append(owners, account.Owner)
+			}
+		}
+		return owners, nil
+	}
+
+	return nil, nil
+}
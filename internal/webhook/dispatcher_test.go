@@ -0,0 +1,83 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/vlone310/bss/internal/db/mock"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+	"github.com/vlone310/bss/internal/event"
+	"github.com/vlone310/bss/internal/money"
+)
+
+func transferEvent(t *testing.T, from, to int64) event.Event {
+	data, err := json.Marshal(event.TransferV1{
+		ID:            5,
+		FromAccountID: from,
+		ToAccountID:   to,
+		Amount:        money.New(100, "USD").JSON(2),
+		Status:        db.TransferStatusCompleted,
+		CreatedAt:     time.Now(),
+	})
+	require.NoError(t, err)
+
+	return event.Event{
+		ID:            42,
+		Type:          event.TypeTransferCompleted,
+		Version:       event.TransferVersion,
+		AggregateType: event.AggregateTransfer,
+		AggregateID:   "5",
+		OccurredAt:    time.Now(),
+		Data:          data,
+	}
+}
+
+func TestDispatcherPublish(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	e := transferEvent(t, 1, 2)
+	store.EXPECT().GetAccount(gomock.Any(), int64(1)).Return(db.Account{ID: 1, Owner: "alice"}, nil)
+	store.EXPECT().GetAccount(gomock.Any(), int64(2)).Return(db.Account{ID: 2, Owner: "bob"}, nil)
+	store.EXPECT().ListWebhookEndpointsForEvent(gomock.Any(), db.ListWebhookEndpointsForEventParams{
+		Owners:    []string{"alice", "bob"},
+		EventType: event.TypeTransferCompleted,
+	}).Return([]db.WebhookEndpoint{{ID: 7, Owner: "alice"}, {ID: 8, Owner: "bob"}}, nil)
+
+	var queued []int64
+	store.EXPECT().CreateWebhookDelivery(gomock.Any(), gomock.Any()).Times(2).
+		DoAndReturn(func(_ context.Context, arg db.CreateWebhookDeliveryParams) error {
+			require.Equal(t, e.ID, arg.EventID)
+			require.Equal(t, e.Type, arg.EventType)
+
+			var payload event.Event
+			require.NoError(t, json.Unmarshal(arg.Payload, &payload))
+			require.Equal(t, e.ID, payload.ID)
+			require.JSONEq(t, string(e.Data), string(payload.Data))
+
+			queued = append(queued, arg.EndpointID)
+			return nil
+		})
+
+	require.NoError(t, NewDispatcher(store).Publish(context.Background(), e))
+	require.Equal(t, []int64{7, 8}, queued)
+}
+
+func TestDispatcherPublishOwnTransfer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	// a transfer between the accounts of one user concerns them once
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(2).Return(db.Account{Owner: "alice"}, nil)
+	store.EXPECT().ListWebhookEndpointsForEvent(gomock.Any(), db.ListWebhookEndpointsForEventParams{
+		Owners:    []string{"alice"},
+		EventType: event.TypeTransferCompleted,
+	}).Return([]db.WebhookEndpoint{}, nil)
+	store.EXPECT().CreateWebhookDelivery(gomock.Any(), gomock.Any()).Times(0)
+
+	require.NoError(t, NewDispatcher(store).Publish(context.Background(), transferEvent(t, 1, 2)))
+}
@@ -0,0 +1,177 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+)
+
+const (
+	defaultMaxAttempts = 8
+	defaultBackoff     = 30 * time.Second
+	defaultTimeout     = 10 * time.Second
+	maxBackoff         = 24 * time.Hour
+	claimBatchSize     = 50
+	// maxErrorBody is how much of the body of a failed response is kept.
+	maxErrorBody = 512
+)
+
+// Sender makes the queued deliveries. A delivery that fails is tried again
+// after a backoff that doubles with every attempt, and is dead once it ran
+// out of attempts. Only a 2xx response counts as delivered.
+type Sender struct {
+	store       db.Store
+	client      *http.Client
+	maxAttempts int32
+	backoff     time.Duration
+	now         func() time.Time
+}
+
+// NewSender returns a sender that gives up after maxAttempts and waits
+// backoff after the first failed attempt. Zero picks a default for either,
+// and a nil client that of NewClient for public endpoints only.
+func NewSender(store db.Store, client *http.Client, maxAttempts int32, backoff time.Duration) *Sender {
+	if client == nil {
+		client = NewClient(false)
+	}
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+	if backoff <= 0 {
+		backoff = defaultBackoff
+	}
+
+	return &Sender{
+		store:       store,
+		client:      client,
+		maxAttempts: maxAttempts,
+		backoff:     backoff,
+		now:         time.Now,
+	}
+}
+
+// Run makes the deliveries that are due and returns how many succeeded.
+func (s *Sender) Run(ctx context.Context) (int, error) {
+	// a claim outlives the request timeout, so that a delivery is not sent
+	// twice at once
+	claimUntil := s.now().Add(s.client.Timeout*claimBatchSize + time.Minute)
+
+	deliveries, err := s.store.ClaimDueWebhookDeliveries(ctx, db.ClaimDueWebhookDeliveriesParams{
+		ClaimUntil: pgtype.Timestamptz{Time: claimUntil, Valid: true},
+		LimitCount: claimBatchSize,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	endpoints := map[int64]db.WebhookEndpoint{}
+	var succeeded int
+	for _, delivery := range deliveries {
+		endpoint, ok := endpoints[delivery.EndpointID]
+		if !ok {
+			endpoint, err = s.store.GetWebhookEndpoint(ctx, delivery.EndpointID)
+			if err != nil {
+				return succeeded, err
+			}
+			endpoints[endpoint.ID] = endpoint
+		}
+
+		delivery, err = s.Deliver(ctx, endpoint, delivery)
+		if err != nil {
+			return succeeded, err
+		}
+		if delivery.Status == db.WebhookDeliverySucceeded {
+			succeeded++
+		}
+	}
+
+	return succeeded, nil
+}
+
+// Deliver makes one attempt and records it. The error is that of recording
+// it, a failed attempt is part of the returned delivery.
+func (s *Sender) Deliver(ctx context.Context, endpoint db.WebhookEndpoint, delivery db.WebhookDelivery) (db.WebhookDelivery, error) {
+	start := s.now()
+	statusCode, sendErr := s.send(ctx, endpoint, delivery, start)
+	duration := s.now().Sub(start)
+
+	attempt := db.CreateWebhookDeliveryAttemptParams{
+		DeliveryID: delivery.ID,
+		StatusCode: pgtype.Int4{Int32: int32(statusCode), Valid: statusCode != 0},
+		DurationMs: int32(duration.Milliseconds()),
+	}
+
+	next := db.UpdateWebhookDeliveryParams{
+		ID:       delivery.ID,
+		Status:   db.WebhookDeliverySucceeded,
+		Attempts: delivery.Attempts + 1,
+	}
+
+	if sendErr != nil {
+		attempt.Error = pgtype.Text{String: sendErr.Error(), Valid: true}
+		next.LastError = attempt.Error
+		next.Status = db.WebhookDeliveryPending
+		next.NextAttemptAt = pgtype.Timestamptz{Time: s.now().Add(s.Backoff(next.Attempts)), Valid: true}
+		if next.Attempts >= s.maxAttempts {
+			next.Status = db.WebhookDeliveryDead
+		}
+	} else {
+		next.NextAttemptAt = pgtype.Timestamptz{Time: s.now(), Valid: true}
+		next.DeliveredAt = next.NextAttemptAt
+	}
+
+	return s.store.RecordWebhookAttemptTx(ctx, db.RecordWebhookAttemptTxParams{
+		Attempt:  attempt,
+		Delivery: next,
+	})
+}
+
+// Backoff returns how long to wait after the given number of failed
+// attempts.
+func (s *Sender) Backoff(attempts int32) time.Duration {
+	backoff := s.backoff
+	for range attempts - 1 {
+		backoff *= 2
+		if backoff >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return backoff
+}
+
+// send posts the delivery and returns the status code of the response, zero
+// when there was none.
+func (s *Sender) send(ctx context.Context, endpoint db.WebhookEndpoint, delivery db.WebhookDelivery, at time.Time) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.Url, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(TimestampHeader, strconv.FormatInt(at.Unix(), 10))
+	req.Header.Set(SignatureHeader, Sign(endpoint.Secret, at, delivery.Payload))
+	req.Header.Set(EventHeader, delivery.EventType)
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(res.Body, maxErrorBody))
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		// the body is stored as text, whatever the endpoint sent
+		text := strings.ToValidUTF8(strings.ReplaceAll(string(bytes.TrimSpace(body)), "\x00", ""), "\uFFFD")
+		return res.StatusCode, fmt.Errorf("endpoint responded %s: %s", res.Status, text)
+	}
+	return res.StatusCode, nil
+}
@@ -0,0 +1,206 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/vlone310/bss/internal/db/mock"
+	db "github.com/vlone310/bss/internal/db/sqlc"
+	"github.com/vlone310/bss/internal/event"
+)
+
+// recordAttempt makes RecordWebhookAttemptTx return the delivery it is given
+// and keeps the params.
+func recordAttempt(store *mockdb.MockStore, recorded *db.RecordWebhookAttemptTxParams) {
+	store.EXPECT().RecordWebhookAttemptTx(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, arg db.RecordWebhookAttemptTxParams) (db.WebhookDelivery, error) {
+			*recorded = arg
+			return db.WebhookDelivery{
+				ID:            arg.Delivery.ID,
+				Status:        arg.Delivery.Status,
+				Attempts:      arg.Delivery.Attempts,
+				NextAttemptAt: arg.Delivery.NextAttemptAt,
+				LastError:     arg.Delivery.LastError,
+				DeliveredAt:   arg.Delivery.DeliveredAt,
+			}, nil
+		})
+}
+
+func TestSenderDeliver(t *testing.T) {
+	endpoint := db.WebhookEndpoint{ID: 7, Owner: "alice", Secret: NewSecret()}
+	delivery := db.WebhookDelivery{
+		ID:         3,
+		EndpointID: endpoint.ID,
+		EventID:    42,
+		EventType:  event.TypeAccountCreated,
+		Payload:    []byte(`{"id":42,"type":"account.created"}`),
+		Status:     db.WebhookDeliveryPending,
+	}
+
+	testCases := []struct {
+		name          string
+		attempts      int32
+		respond       func(w http.ResponseWriter)
+		checkRecorded func(t *testing.T, recorded db.RecordWebhookAttemptTxParams)
+	}{
+		{
+			name: "Delivered",
+			respond: func(w http.ResponseWriter) {
+				w.WriteHeader(http.StatusNoContent)
+			},
+			checkRecorded: func(t *testing.T, recorded db.RecordWebhookAttemptTxParams) {
+				require.Equal(t, int32(http.StatusNoContent), recorded.Attempt.StatusCode.Int32)
+				require.False(t, recorded.Attempt.Error.Valid)
+
+				require.Equal(t, db.WebhookDeliverySucceeded, recorded.Delivery.Status)
+				require.Equal(t, int32(1), recorded.Delivery.Attempts)
+				require.True(t, recorded.Delivery.DeliveredAt.Valid)
+			},
+		},
+		{
+			name:     "Failed",
+			attempts: 2,
+			respond: func(w http.ResponseWriter) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				io.WriteString(w, "down for maintenance\x00\xff")
+			},
+			checkRecorded: func(t *testing.T, recorded db.RecordWebhookAttemptTxParams) {
+				require.Equal(t, int32(http.StatusServiceUnavailable), recorded.Attempt.StatusCode.Int32)
+				require.Contains(t, recorded.Attempt.Error.String, "down for maintenance")
+				require.NotContains(t, recorded.Attempt.Error.String, "\x00")
+
+				// the third failure waits four times the backoff
+				require.Equal(t, db.WebhookDeliveryPending, recorded.Delivery.Status)
+				require.Equal(t, int32(3), recorded.Delivery.Attempts)
+				require.Equal(t, recorded.Attempt.Error, recorded.Delivery.LastError)
+				require.WithinDuration(t, time.Now().Add(4*time.Minute), recorded.Delivery.NextAttemptAt.Time, 5*time.Second)
+				require.False(t, recorded.Delivery.DeliveredAt.Valid)
+			},
+		},
+		{
+			name:     "Dead",
+			attempts: 4,
+			respond: func(w http.ResponseWriter) {
+				w.WriteHeader(http.StatusInternalServerError)
+			},
+			checkRecorded: func(t *testing.T, recorded db.RecordWebhookAttemptTxParams) {
+				require.Equal(t, db.WebhookDeliveryDead, recorded.Delivery.Status)
+				require.Equal(t, int32(5), recorded.Delivery.Attempts)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				require.Equal(t, delivery.Payload, body)
+				require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+				require.Equal(t, delivery.EventType, r.Header.Get(EventHeader))
+				require.Equal(t, "3", r.Header.Get(DeliveryHeader))
+
+				err = Verify(endpoint.Secret, r.Header.Get(TimestampHeader), r.Header.Get(SignatureHeader), body, time.Now(), time.Minute)
+				require.NoError(t, err)
+
+				tc.respond(w)
+			}))
+			defer receiver.Close()
+
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+
+			var recorded db.RecordWebhookAttemptTxParams
+			recordAttempt(store, &recorded)
+
+			endpoint := endpoint
+			endpoint.Url = receiver.URL
+			delivery := delivery
+			delivery.Attempts = tc.attempts
+
+			sender := NewSender(store, receiver.Client(), 5, time.Minute)
+			got, err := sender.Deliver(context.Background(), endpoint, delivery)
+			require.NoError(t, err)
+			require.Equal(t, recorded.Delivery.Status, got.Status)
+			require.Equal(t, delivery.ID, recorded.Attempt.DeliveryID)
+
+			tc.checkRecorded(t, recorded)
+		})
+	}
+}
+
+func TestSenderDeliverUnreachable(t *testing.T) {
+	receiver := httptest.NewServer(http.NotFoundHandler())
+	url := receiver.URL
+	receiver.Close()
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	var recorded db.RecordWebhookAttemptTxParams
+	recordAttempt(store, &recorded)
+
+	sender := NewSender(store, nil, 0, 0)
+	_, err := sender.Deliver(context.Background(), db.WebhookEndpoint{Url: url}, db.WebhookDelivery{ID: 1})
+	require.NoError(t, err)
+
+	// no response, no status code
+	require.False(t, recorded.Attempt.StatusCode.Valid)
+	require.True(t, recorded.Attempt.Error.Valid)
+	require.Equal(t, db.WebhookDeliveryPending, recorded.Delivery.Status)
+}
+
+func TestSenderRun(t *testing.T) {
+	var received int
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received++
+		if strings.HasSuffix(r.URL.Path, "/fail") {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer receiver.Close()
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	ok := db.WebhookEndpoint{ID: 1, Url: receiver.URL + "/ok"}
+	failing := db.WebhookEndpoint{ID: 2, Url: receiver.URL + "/fail"}
+
+	store.EXPECT().ClaimDueWebhookDeliveries(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, arg db.ClaimDueWebhookDeliveriesParams) ([]db.WebhookDelivery, error) {
+			require.True(t, arg.ClaimUntil.Time.After(time.Now()))
+			return []db.WebhookDelivery{
+				{ID: 1, EndpointID: ok.ID},
+				{ID: 2, EndpointID: failing.ID},
+				{ID: 3, EndpointID: ok.ID},
+			}, nil
+		})
+	// the endpoints are looked up once
+	store.EXPECT().GetWebhookEndpoint(gomock.Any(), ok.ID).Times(1).Return(ok, nil)
+	store.EXPECT().GetWebhookEndpoint(gomock.Any(), failing.ID).Times(1).Return(failing, nil)
+	store.EXPECT().RecordWebhookAttemptTx(gomock.Any(), gomock.Any()).Times(3).
+		DoAndReturn(func(_ context.Context, arg db.RecordWebhookAttemptTxParams) (db.WebhookDelivery, error) {
+			return db.WebhookDelivery{ID: arg.Delivery.ID, Status: arg.Delivery.Status}, nil
+		})
+
+	succeeded, err := NewSender(store, receiver.Client(), 0, 0).Run(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 2, succeeded)
+	require.Equal(t, 3, received)
+}
+
+func TestSenderBackoff(t *testing.T) {
+	sender := NewSender(nil, nil, 0, time.Minute)
+
+	require.Equal(t, time.Minute, sender.Backoff(1))
+	require.Equal(t, 2*time.Minute, sender.Backoff(2))
+	require.Equal(t, 8*time.Minute, sender.Backoff(4))
+	require.Equal(t, maxBackoff, sender.Backoff(30))
+}
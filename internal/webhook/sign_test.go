@@ -0,0 +1,46 @@
+package webhook
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignVerify(t *testing.T) {
+	secret := NewSecret()
+	require.Len(t, secret, len(secretPrefix)+64)
+	require.NotEqual(t, secret, NewSecret())
+
+	now := time.Unix(1_700_000_000, 0)
+	body := []byte(`{"id":1}`)
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	signature := Sign(secret, now, body)
+
+	require.NoError(t, Verify(secret, timestamp, signature, body, now.Add(time.Minute), 5*time.Minute))
+
+	testCases := []struct {
+		name      string
+		secret    string
+		timestamp string
+		signature string
+		body      []byte
+		wantErr   error
+	}{
+		{"OtherSecret", NewSecret(), timestamp, signature, body, ErrInvalidSignature},
+		{"OtherBody", secret, timestamp, signature, []byte(`{"id":2}`), ErrInvalidSignature},
+		{"OtherTimestamp", secret, strconv.FormatInt(now.Unix()+1, 10), signature, body, ErrInvalidSignature},
+		{"NoVersion", secret, timestamp, signature[len(signatureVersion):], body, ErrInvalidSignature},
+		{"NotHex", secret, timestamp, "v1=zz", body, ErrInvalidSignature},
+		{"Replayed", secret, strconv.FormatInt(now.Add(-time.Hour).Unix(), 10), Sign(secret, now.Add(-time.Hour), body), body, ErrTimestampOutOfRange},
+		{"NoTimestamp", secret, "", signature, body, ErrTimestampOutOfRange},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Verify(tc.secret, tc.timestamp, tc.signature, tc.body, now, 5*time.Minute)
+			require.ErrorIs(t, err, tc.wantErr)
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// Periodic runs a job at a fixed interval until its context is cancelled and
+// remembers how the last run went.
+type Periodic struct {
+	name     string
+	interval time.Duration
+	job      func(ctx context.Context) error
+
+	mu      sync.Mutex
+	running bool
+	lastRun time.Time
+	lastErr error
+}
+
+// Status is how the last run of a job went. Running is set while a run is
+// in progress, a job that blocks, like a listener, runs until it fails.
+type Status struct {
+	Name      string    `json:"name"`
+	Running   bool      `json:"running"`
+	LastRun   time.Time `json:"last_run"`
+	LastError string    `json:"last_error,omitempty"`
+}
+
+func NewPeriodic(name string, interval time.Duration, job func(ctx context.Context) error) *Periodic {
+	return &Periodic{
+		name:     name,
+		interval: interval,
+		job:      job,
+	}
+}
+
+// Run runs the job right away and then once every interval. A failed run is
+// logged and the job is tried again at the next tick.
+func (p *Periodic) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.runOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *Periodic) runOnce(ctx context.Context) {
+	p.mu.Lock()
+	p.running = true
+	p.mu.Unlock()
+
+	err := p.job(ctx)
+	if err != nil {
+		log.Printf("worker %s: %v", p.name, err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.running = false
+	p.lastRun = time.Now()
+	p.lastErr = err
+}
+
+func (p *Periodic) Status() Status {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	status := Status{
+		Name:    p.name,
+		Running: p.running,
+		LastRun: p.lastRun,
+	}
+	if p.lastErr != nil {
+		status.LastError = p.lastErr.Error()
+	}
+
+	return status
+}
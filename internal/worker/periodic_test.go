@@ -0,0 +1,71 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeriodic(t *testing.T) {
+	var runs atomic.Int32
+	p := NewPeriodic("test", 10*time.Millisecond, func(ctx context.Context) error {
+		if runs.Add(1) == 1 {
+			return errors.New("first run fails")
+		}
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		p.Run(ctx)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool { return runs.Load() >= 3 }, time.Second, 5*time.Millisecond)
+	cancel()
+	<-done
+
+	status := p.Status()
+	require.Equal(t, "test", status.Name)
+	require.NotZero(t, status.LastRun)
+	require.Empty(t, status.LastError)
+}
+
+func TestPeriodicStatusError(t *testing.T) {
+	p := NewPeriodic("failing", time.Hour, func(ctx context.Context) error {
+		return errors.New("boom")
+	})
+
+	p.runOnce(context.Background())
+
+	status := p.Status()
+	require.Equal(t, "boom", status.LastError)
+}
+
+func TestPeriodicStatusRunning(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	p := NewPeriodic("listener", time.Hour, func(ctx context.Context) error {
+		close(started)
+		<-release
+		return nil
+	})
+
+	done := make(chan struct{})
+	go func() {
+		p.runOnce(context.Background())
+		close(done)
+	}()
+
+	<-started
+	require.True(t, p.Status().Running)
+
+	close(release)
+	<-done
+	require.False(t, p.Status().Running)
+}
@@ -0,0 +1,60 @@
+package util
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTOTPCode(t *testing.T) {
+	// the SHA1 test vectors of RFC 6238, cut to six digits
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+	testCases := []struct {
+		unix int64
+		code string
+	}{
+		{unix: 59, code: "287082"},
+		{unix: 1111111109, code: "081804"},
+		{unix: 1234567890, code: "005924"},
+		{unix: 2000000000, code: "279037"},
+	}
+
+	for _, tc := range testCases {
+		code, err := TOTPCode(secret, TOTPStep(time.Unix(tc.unix, 0)))
+		require.NoError(t, err)
+		require.Equal(t, tc.code, code)
+	}
+}
+
+func TestCheckTOTP(t *testing.T) {
+	secret, err := NewTOTPSecret()
+	require.NoError(t, err)
+
+	now := time.Now()
+	step := TOTPStep(now)
+
+	for _, s := range []int64{step - 1, step, step + 1} {
+		code, err := TOTPCode(secret, s)
+		require.NoError(t, err)
+
+		got, err := CheckTOTP(secret, code, now)
+		require.NoError(t, err)
+		require.Equal(t, s, got)
+	}
+
+	code, err := TOTPCode(secret, step-2)
+	require.NoError(t, err)
+	_, err = CheckTOTP(secret, code, now)
+	require.ErrorIs(t, err, ErrInvalidTOTP)
+
+	_, err = CheckTOTP(secret, "abcdef", now)
+	require.ErrorIs(t, err, ErrInvalidTOTP)
+}
+
+func TestTOTPURL(t *testing.T) {
+	url := TOTPURL("bss", "alice", "JBSWY3DPEHPK3PXP")
+	require.Equal(t, "otpauth://totp/bss:alice?issuer=bss&secret=JBSWY3DPEHPK3PXP", url)
+}